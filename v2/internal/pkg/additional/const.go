@@ -5,4 +5,7 @@ const (
 	ociProtocol     = "oci://"
 	collectorPrefix = "[AdditionalImagesCollector] "
 	errMsg          = collectorPrefix + "%s"
+
+	additionalImagesDir = "additional-images"
+	resolvedTagsFile    = "resolved-tags.json"
 )
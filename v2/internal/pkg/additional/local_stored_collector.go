@@ -50,7 +50,11 @@ func (o LocalStorageCollector) AdditionalImagesCollector(ctx context.Context) ([
 	var allImages []v2alpha1.CopyImageSchema
 
 	o.Log.Debug(collectorPrefix+"setting copy option o.Opts.MultiArch=%s when collecting releases image", o.Opts.MultiArch)
-	for _, img := range o.Config.ImageSetConfigurationSpec.Mirror.AdditionalImages {
+	images, err := o.expandTaggedImages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, img := range images {
 		var src, dest, tmpSrc, tmpDest, origin string
 
 		imgSpec, err := image.ParseRef(img.Name)
@@ -63,21 +67,29 @@ func (o LocalStorageCollector) AdditionalImagesCollector(ctx context.Context) ([
 
 			tmpSrc = imgSpec.ReferenceWithTransport
 			origin = img.Name
+			// the cache keeps the source path: targetRepo, targetTag and rewrite rules
+			// only apply to the destination registry
+			destPath := strings.TrimPrefix(imgSpec.PathComponent, "/")
+			destTag := func(tag string) string { return tag }
+			if o.Opts.IsMirrorToMirror() {
+				destPath = o.destinationPath(img, imgSpec)
+				destTag = func(tag string) string { return destinationTag(img, tag) }
+			}
 			if imgSpec.Transport == dockerProtocol {
 				if imgSpec.IsImageByDigestOnly() {
-					tmpDest = strings.Join([]string{o.destinationRegistry(), imgSpec.PathComponent}, "/") + ":" + imgSpec.Algorithm + "-" + imgSpec.Digest
+					tmpDest = strings.Join([]string{o.destinationRegistry(), destPath}, "/") + ":" + destTag(imgSpec.Algorithm+"-"+imgSpec.Digest)
 				} else if imgSpec.IsImageByTagAndDigest() { // OCPBUGS-33196 + OCPBUGS-37867- check source image for tag and digest
 					// use tag only for both src and dest
 					o.Log.Warn(collectorPrefix+"%s has both tag and digest : using digest to pull, but tag only for mirroring", imgSpec.Reference)
 					tmpSrc = strings.Join([]string{imgSpec.Domain, imgSpec.PathComponent}, "/") + "@" + imgSpec.Algorithm + ":" + imgSpec.Digest
-					tmpDest = strings.Join([]string{o.destinationRegistry(), imgSpec.PathComponent}, "/") + ":" + imgSpec.Tag
+					tmpDest = strings.Join([]string{o.destinationRegistry(), destPath}, "/") + ":" + destTag(imgSpec.Tag)
 				} else {
-					tmpDest = strings.Join([]string{o.destinationRegistry(), imgSpec.PathComponent}, "/") + ":" + imgSpec.Tag
+					tmpDest = strings.Join([]string{o.destinationRegistry(), destPath}, "/") + ":" + destTag(imgSpec.Tag)
 				}
 			} else { // oci image
 				// Although fetching the digest of the oci image (using o.Manifest.GetDigest) might work in mirrorToDisk and mirrorToMirror
 				// it will not work during diskToMirror as the oci image might not be on the disk any longer
-				tmpDest = strings.Join([]string{o.destinationRegistry(), strings.TrimPrefix(imgSpec.PathComponent, "/")}, "/") + ":latest"
+				tmpDest = strings.Join([]string{o.destinationRegistry(), destPath}, "/") + ":" + destTag("latest")
 			}
 
 		} else if o.Opts.IsDiskToMirror() {
@@ -88,29 +100,30 @@ func (o LocalStorageCollector) AdditionalImagesCollector(ctx context.Context) ([
 				return nil, err
 			}
 
+			destPath := o.destinationPath(img, imgSpec)
 			if imgSpec.Transport == dockerProtocol {
 
 				if imgSpec.IsImageByDigestOnly() {
 					tmpSrc = strings.Join([]string{o.LocalStorageFQDN, imgSpec.PathComponent + ":" + imgSpec.Algorithm + "-" + imgSpec.Digest}, "/")
 					if o.generateV1DestTags {
-						tmpDest = strings.Join([]string{o.Opts.Destination, imgSpec.PathComponent + ":latest"}, "/")
+						tmpDest = strings.Join([]string{o.Opts.Destination, destPath + ":" + destinationTag(img, "latest")}, "/")
 
 					} else {
-						tmpDest = strings.Join([]string{o.Opts.Destination, imgSpec.PathComponent + ":" + imgSpec.Algorithm + "-" + imgSpec.Digest}, "/")
+						tmpDest = strings.Join([]string{o.Opts.Destination, destPath + ":" + destinationTag(img, imgSpec.Algorithm+"-"+imgSpec.Digest)}, "/")
 					}
 				} else if imgSpec.IsImageByTagAndDigest() { // OCPBUGS-33196 + OCPBUGS-37867- check source image for tag and digest
 					// use tag only for both src and dest
 					o.Log.Warn(collectorPrefix+"%s has both tag and digest : using tag only", imgSpec.Reference)
 					tmpSrc = strings.Join([]string{o.LocalStorageFQDN, imgSpec.PathComponent}, "/") + ":" + imgSpec.Tag
-					tmpDest = strings.Join([]string{o.Opts.Destination, imgSpec.PathComponent}, "/") + ":" + imgSpec.Tag
+					tmpDest = strings.Join([]string{o.Opts.Destination, destPath}, "/") + ":" + destinationTag(img, imgSpec.Tag)
 				} else {
 					tmpSrc = strings.Join([]string{o.LocalStorageFQDN, imgSpec.PathComponent}, "/") + ":" + imgSpec.Tag
-					tmpDest = strings.Join([]string{o.Opts.Destination, imgSpec.PathComponent}, "/") + ":" + imgSpec.Tag
+					tmpDest = strings.Join([]string{o.Opts.Destination, destPath}, "/") + ":" + destinationTag(img, imgSpec.Tag)
 				}
 
 			} else {
 				tmpSrc = strings.Join([]string{o.LocalStorageFQDN, strings.TrimPrefix(imgSpec.PathComponent, "/")}, "/") + ":latest"
-				tmpDest = strings.Join([]string{o.Opts.Destination, strings.TrimPrefix(imgSpec.PathComponent, "/")}, "/") + ":latest"
+				tmpDest = strings.Join([]string{o.Opts.Destination, destPath}, "/") + ":" + destinationTag(img, "latest")
 			}
 
 		}
@@ -139,3 +152,26 @@ func (o LocalStorageCollector) AdditionalImagesCollector(ctx context.Context) ([
 	}
 	return allImages, nil
 }
+
+// destinationPath returns the path of the image under the destination registry:
+// the targetRepo when set, otherwise the result of the first matching rewrite rule,
+// otherwise the path of the source image
+func (o LocalStorageCollector) destinationPath(img v2alpha1.Image, imgSpec image.ImageSpec) string {
+	if img.TargetRepo != "" {
+		return img.TargetRepo
+	}
+	if imgSpec.Transport == dockerProtocol {
+		if path, ok := v2alpha1.RewriteRepository(o.Config.Mirror.RewriteRules, imgSpec.Name); ok {
+			return path
+		}
+	}
+	return strings.TrimPrefix(imgSpec.PathComponent, "/")
+}
+
+// destinationTag returns the targetTag of the image when set, or tag otherwise
+func destinationTag(img v2alpha1.Image, tag string) string {
+	if img.TargetTag != "" {
+		return img.TargetTag
+	}
+	return tag
+}
@@ -2,7 +2,10 @@ package additional
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/containers/image/v5/types"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
@@ -248,6 +251,70 @@ func TestAdditionalImageCollector(t *testing.T) {
 	})
 }
 
+func TestAdditionalImageCollectorTargets(t *testing.T) {
+	log := clog.New("trace")
+
+	global := &mirror.GlobalOptions{SecurePolicy: false}
+	_, sharedOpts := mirror.SharedImageFlags()
+	_, deprecatedTLSVerifyOpt := mirror.DeprecatedTLSVerifyFlags()
+	_, srcOpts := mirror.ImageSrcFlags(global, sharedOpts, deprecatedTLSVerifyOpt, "src-", "screds")
+	_, destOpts := mirror.ImageDestFlags(global, sharedOpts, deprecatedTLSVerifyOpt, "dest-", "dcreds")
+
+	cfg := v2alpha1.ImageSetConfiguration{
+		ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+			Mirror: v2alpha1.Mirror{
+				AdditionalImages: []v2alpha1.Image{
+					{Name: "docker.io/library/nginx:1.25"},
+					{Name: "docker.io/library/redis:7", TargetRepo: "cache/redis"},
+					{Name: "sometest.registry.com/testns/test@sha256:f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea", TargetTag: "v1.0"},
+					{Name: "registry.redhat.io/ubi8/ubi:latest"},
+				},
+				RewriteRules: []v2alpha1.RewriteRule{
+					{Source: "docker.io/library/*", Target: "mirror/dockerhub/*"},
+				},
+			},
+		},
+	}
+	ctx := context.Background()
+
+	t.Run("Testing AdditionalImagesCollector with targets : mirrorToDisk keeps the source path", func(t *testing.T) {
+		opts := mirror.CopyOptions{Global: global, SrcImage: srcOpts, DestImage: destOpts, Destination: "file://test", Mode: mirror.MirrorToDisk, LocalStorageFQDN: "test.registry.com"}
+		ex := New(log, cfg, opts, MockMirror{}, MockManifest{Log: log})
+		res, err := ex.AdditionalImagesCollector(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, "docker://test.registry.com/library/nginx:1.25", res[0].Destination)
+		assert.Equal(t, "docker://test.registry.com/library/redis:7", res[1].Destination)
+		assert.Equal(t, "docker://test.registry.com/testns/test:sha256-f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea", res[2].Destination)
+	})
+
+	t.Run("Testing AdditionalImagesCollector with targets : mirrorToMirror should rewrite", func(t *testing.T) {
+		opts := mirror.CopyOptions{Global: global, SrcImage: srcOpts, DestImage: destOpts, Destination: "docker://mirror.acme.com", Mode: mirror.MirrorToMirror, LocalStorageFQDN: "test.registry.com"}
+		ex := New(log, cfg, opts, MockMirror{}, MockManifest{Log: log})
+		res, err := ex.AdditionalImagesCollector(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"docker://mirror.acme.com/mirror/dockerhub/nginx:1.25",
+			"docker://mirror.acme.com/cache/redis:7",
+			"docker://mirror.acme.com/testns/test:v1.0",
+			"docker://mirror.acme.com/ubi8/ubi:latest",
+		}, []string{res[0].Destination, res[1].Destination, res[2].Destination, res[3].Destination})
+	})
+
+	t.Run("Testing AdditionalImagesCollector with targets : diskToMirror should rewrite", func(t *testing.T) {
+		opts := mirror.CopyOptions{Global: global, SrcImage: srcOpts, DestImage: destOpts, Destination: "docker://mirror.acme.com", Mode: mirror.DiskToMirror, LocalStorageFQDN: "test.registry.com"}
+		ex := New(log, cfg, opts, MockMirror{}, MockManifest{Log: log})
+		res, err := ex.AdditionalImagesCollector(ctx)
+		assert.NoError(t, err)
+		expected := []v2alpha1.CopyImageSchema{
+			{Source: "docker://test.registry.com/library/nginx:1.25", Destination: "docker://mirror.acme.com/mirror/dockerhub/nginx:1.25", Origin: "docker.io/library/nginx:1.25", Type: v2alpha1.TypeGeneric},
+			{Source: "docker://test.registry.com/library/redis:7", Destination: "docker://mirror.acme.com/cache/redis:7", Origin: "docker.io/library/redis:7", Type: v2alpha1.TypeGeneric},
+			{Source: "docker://test.registry.com/testns/test:sha256-f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea", Destination: "docker://mirror.acme.com/testns/test:v1.0", Origin: "sometest.registry.com/testns/test@sha256:f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea", Type: v2alpha1.TypeGeneric},
+			{Source: "docker://test.registry.com/ubi8/ubi:latest", Destination: "docker://mirror.acme.com/ubi8/ubi:latest", Origin: "registry.redhat.io/ubi8/ubi:latest", Type: v2alpha1.TypeGeneric},
+		}
+		assert.Equal(t, expected, res)
+	})
+}
+
 func (o MockMirror) Run(ctx context.Context, src, dest string, mode mirror.Mode, opts *mirror.CopyOptions) error {
 	return nil
 }
@@ -319,3 +386,19 @@ func (o MockManifest) ConvertIndexToSingleManifest(dir string, oci *v2alpha1.OCI
 func (o MockManifest) GetDigest(ctx context.Context, sourceCtx *types.SystemContext, imgRef string) (string, error) {
 	return "123456", nil
 }
+
+func (o MockManifest) ListTags(ctx context.Context, sourceCtx *types.SystemContext, imgRef string) ([]string, error) {
+	if imgRef == "docker://quay.io/fail/image" {
+		return nil, fmt.Errorf("forced list tags error")
+	}
+	return []string{"latest", "v1.0.0", "v2.0.0", "v2.1.0", "v2.1.1-rc1", "v2.2.0", "v2.10.0", "v2.3", "sha256-1234.sig"}, nil
+}
+
+// GetImageCreationDate returns images created in 2024, the month being the minor version of the tag
+func (o MockManifest) GetImageCreationDate(ctx context.Context, sourceCtx *types.SystemContext, imgRef string) (time.Time, error) {
+	var major, minor, patch int
+	if _, err := fmt.Sscanf(imgRef[strings.LastIndex(imgRef, ":")+1:], "v%d.%d.%d", &major, &minor, &patch); err != nil {
+		return time.Time{}, fmt.Errorf("no creation date")
+	}
+	return time.Date(2024, time.Month(minor+1), 1, 0, 0, 0, 0, time.UTC), nil
+}
@@ -0,0 +1,210 @@
+package additional
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/blang/semver/v4"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+)
+
+// ResolvedTags maps an additional image repository (as set in the imageSetConfig)
+// to the tags that were selected by its tags filter
+type ResolvedTags map[string][]string
+
+// expandTaggedImages replaces each additional image using a tags filter by one image per selected tag.
+// During mirrorToDisk and mirrorToMirror, the tags are listed from the source registry and the result
+// is recorded in the working-dir. During diskToMirror (and delete) the recorded tags are used, so that
+// the same set of images is processed.
+func (o LocalStorageCollector) expandTaggedImages(ctx context.Context) ([]v2alpha1.Image, error) {
+	var images []v2alpha1.Image
+	var recorded ResolvedTags
+	resolved := ResolvedTags{}
+
+	for _, img := range o.Config.ImageSetConfigurationSpec.Mirror.AdditionalImages {
+		if img.Tags == nil {
+			images = append(images, img)
+			continue
+		}
+		var tags []string
+		if o.Opts.IsMirrorToDisk() || o.Opts.IsMirrorToMirror() {
+			var err error
+			tags, err = o.resolveTags(ctx, img)
+			if err != nil {
+				return nil, err
+			}
+			resolved[img.Name] = tags
+		} else {
+			if recorded == nil {
+				var err error
+				recorded, err = ReadResolvedTags(o.Opts.Global.WorkingDir)
+				if err != nil {
+					return nil, err
+				}
+			}
+			var ok bool
+			tags, ok = recorded[img.Name]
+			if !ok {
+				return nil, fmt.Errorf(collectorPrefix+"no resolved tags recorded in the working-dir for %s: it must be mirrored to disk first", img.Name)
+			}
+		}
+		o.Log.Debug(collectorPrefix+"tags selected for %s: %v", img.Name, tags)
+		for _, tag := range tags {
+			images = append(images, v2alpha1.Image{Name: img.Name + ":" + tag, TargetRepo: img.TargetRepo})
+		}
+	}
+
+	if len(resolved) > 0 {
+		if err := writeResolvedTags(o.Opts.Global.WorkingDir, resolved); err != nil {
+			return nil, err
+		}
+	}
+	return images, nil
+}
+
+// resolveTags lists the tags of the repository and applies the filter, in this order:
+// regex, exclude, since and finally latest
+func (o LocalStorageCollector) resolveTags(ctx context.Context, img v2alpha1.Image) ([]string, error) {
+	sourceCtx, err := o.Opts.SrcImage.NewSystemContext()
+	if err != nil {
+		return nil, err
+	}
+	allTags, err := o.Manifest.ListTags(ctx, sourceCtx, dockerProtocol+img.Name)
+	if err != nil {
+		return nil, fmt.Errorf(collectorPrefix+"unable to list tags for %s: %v", img.Name, err)
+	}
+
+	tags, err := filterTagsByRegex(allTags, img.Tags.Regex, img.Tags.Exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	if img.Tags.Since != "" {
+		since, err := time.Parse(time.DateOnly, img.Tags.Since)
+		if err != nil {
+			return nil, fmt.Errorf(collectorPrefix+"since %q for %s needs to be in format yyyy-MM-dd", img.Tags.Since, img.Name)
+		}
+		var recentTags []string
+		for _, tag := range tags {
+			created, err := o.Manifest.GetImageCreationDate(ctx, sourceCtx, dockerProtocol+img.Name+":"+tag)
+			if err != nil {
+				o.Log.Warn(collectorPrefix+"unable to determine the creation date of %s:%s, skipping : %v", img.Name, tag, err)
+				continue
+			}
+			if !created.Before(since) {
+				recentTags = append(recentTags, tag)
+			}
+		}
+		tags = recentTags
+	}
+
+	if img.Tags.Latest > 0 {
+		tags = latestSemverTags(tags, img.Tags.Latest)
+	}
+
+	if len(tags) == 0 {
+		o.Log.Warn(collectorPrefix+"no tag of %s matches the tags filter", img.Name)
+	}
+	return tags, nil
+}
+
+// filterTagsByRegex keeps the tags matching include (all if empty) and none of the exclude regular expressions
+func filterTagsByRegex(tags []string, include string, exclude []string) ([]string, error) {
+	var includeRe *regexp.Regexp
+	if include != "" {
+		re, err := regexp.Compile(include)
+		if err != nil {
+			return nil, fmt.Errorf(collectorPrefix+"invalid tags regex %q: %v", include, err)
+		}
+		includeRe = re
+	}
+	excludeRes := make([]*regexp.Regexp, 0, len(exclude))
+	for _, ex := range exclude {
+		re, err := regexp.Compile(ex)
+		if err != nil {
+			return nil, fmt.Errorf(collectorPrefix+"invalid tags exclude regex %q: %v", ex, err)
+		}
+		excludeRes = append(excludeRes, re)
+	}
+
+	var filtered []string
+	for _, tag := range tags {
+		if includeRe != nil && !includeRe.MatchString(tag) {
+			continue
+		}
+		excluded := false
+		for _, re := range excludeRes {
+			if re.MatchString(tag) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			filtered = append(filtered, tag)
+		}
+	}
+	return filtered, nil
+}
+
+// latestSemverTags returns the n highest semver tags, highest first.
+// A leading "v" is accepted, tags that are not semver are ignored.
+func latestSemverTags(tags []string, n int) []string {
+	type semverTag struct {
+		tag     string
+		version semver.Version
+	}
+	var versions []semverTag
+	for _, tag := range tags {
+		v, err := semver.ParseTolerant(tag)
+		if err != nil {
+			continue
+		}
+		versions = append(versions, semverTag{tag: tag, version: v})
+	}
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].version.GT(versions[j].version)
+	})
+	if len(versions) > n {
+		versions = versions[:n]
+	}
+	latest := make([]string, 0, len(versions))
+	for _, v := range versions {
+		latest = append(latest, v.tag)
+	}
+	return latest
+}
+
+// ReadResolvedTags reads the tags recorded in the working-dir during the last mirrorToDisk or mirrorToMirror
+func ReadResolvedTags(workingDir string) (ResolvedTags, error) {
+	data, err := os.ReadFile(filepath.Join(workingDir, additionalImagesDir, resolvedTagsFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ResolvedTags{}, nil
+		}
+		return nil, fmt.Errorf(collectorPrefix+"unable to read resolved tags: %v", err)
+	}
+	resolved := ResolvedTags{}
+	if err := json.Unmarshal(data, &resolved); err != nil {
+		return nil, fmt.Errorf(collectorPrefix+"unable to parse resolved tags: %v", err)
+	}
+	return resolved, nil
+}
+
+func writeResolvedTags(workingDir string, resolved ResolvedTags) error {
+	dir := filepath.Join(workingDir, additionalImagesDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf(collectorPrefix+"unable to create %s: %v", dir, err)
+	}
+	data, err := json.MarshalIndent(resolved, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, resolvedTagsFile), data, 0600)
+}
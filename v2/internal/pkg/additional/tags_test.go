@@ -0,0 +1,106 @@
+package additional
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterTagsByRegex(t *testing.T) {
+	tags := []string{"latest", "v1.0.0", "v2.0.0", "v2.1.1-rc1", "v2.10.0", "v2.3"}
+
+	t.Run("Testing filterTagsByRegex : include and exclude should pass", func(t *testing.T) {
+		res, err := filterTagsByRegex(tags, `^v2\.\d+\.\d+`, []string{"-rc"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"v2.0.0", "v2.10.0"}, res)
+	})
+	t.Run("Testing filterTagsByRegex : no include keeps all but excluded", func(t *testing.T) {
+		res, err := filterTagsByRegex(tags, "", []string{"^latest$", "^v1"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"v2.0.0", "v2.1.1-rc1", "v2.10.0", "v2.3"}, res)
+	})
+	t.Run("Testing filterTagsByRegex : invalid regex should fail", func(t *testing.T) {
+		_, err := filterTagsByRegex(tags, "(", nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestLatestSemverTags(t *testing.T) {
+	tags := []string{"latest", "v1.0.0", "v2.0.0", "2.1.0", "v2.1.1-rc1", "v2.10.0", "v2.3"}
+	assert.Equal(t, []string{"v2.10.0", "v2.3", "v2.1.1-rc1"}, latestSemverTags(tags, 3))
+	assert.Equal(t, 6, len(latestSemverTags(tags, 10)))
+}
+
+func TestExpandTaggedImages(t *testing.T) {
+	log := clog.New("trace")
+	workingDir := t.TempDir()
+	global := &mirror.GlobalOptions{WorkingDir: workingDir}
+	_, sharedOpts := mirror.SharedImageFlags()
+	_, deprecatedTLSVerifyOpt := mirror.DeprecatedTLSVerifyFlags()
+	_, srcOpts := mirror.ImageSrcFlags(global, sharedOpts, deprecatedTLSVerifyOpt, "src-", "screds")
+
+	cfg := v2alpha1.ImageSetConfiguration{
+		ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+			Mirror: v2alpha1.Mirror{
+				AdditionalImages: []v2alpha1.Image{
+					{Name: "registry.redhat.io/ubi8/ubi:latest"},
+					{Name: "quay.io/base/image", Tags: &v2alpha1.ImageTagsFilter{Regex: `^v2\.\d+\.\d+$`, Since: "2024-02-01", Latest: 2}},
+				},
+			},
+		},
+	}
+	ctx := context.Background()
+
+	t.Run("Testing expandTaggedImages : mirrorToDisk should resolve and record tags", func(t *testing.T) {
+		ex := &LocalStorageCollector{Log: log, Config: cfg, Manifest: MockManifest{Log: log}, Opts: mirror.CopyOptions{Global: global, SrcImage: srcOpts, Mode: mirror.MirrorToDisk}}
+		res, err := ex.expandTaggedImages(ctx)
+		assert.NoError(t, err)
+		// v2.0.0 created before since, v2.1.1-rc1 not matching regex
+		assert.Equal(t, []v2alpha1.Image{
+			{Name: "registry.redhat.io/ubi8/ubi:latest"},
+			{Name: "quay.io/base/image:v2.10.0"},
+			{Name: "quay.io/base/image:v2.2.0"},
+		}, res)
+		assert.FileExists(t, filepath.Join(workingDir, additionalImagesDir, resolvedTagsFile))
+	})
+
+	t.Run("Testing expandTaggedImages : diskToMirror should use recorded tags", func(t *testing.T) {
+		// overwrite the recorded tags: the registry must not be queried
+		err := writeResolvedTags(workingDir, ResolvedTags{"quay.io/base/image": {"v2.1.0"}})
+		assert.NoError(t, err)
+		ex := &LocalStorageCollector{Log: log, Config: cfg, Manifest: MockManifest{Log: log}, Opts: mirror.CopyOptions{Global: global, SrcImage: srcOpts, Mode: mirror.DiskToMirror}}
+		res, err := ex.expandTaggedImages(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []v2alpha1.Image{
+			{Name: "registry.redhat.io/ubi8/ubi:latest"},
+			{Name: "quay.io/base/image:v2.1.0"},
+		}, res)
+	})
+
+	t.Run("Testing expandTaggedImages : diskToMirror without recorded tags should fail", func(t *testing.T) {
+		err := os.RemoveAll(filepath.Join(workingDir, additionalImagesDir))
+		assert.NoError(t, err)
+		ex := &LocalStorageCollector{Log: log, Config: cfg, Manifest: MockManifest{Log: log}, Opts: mirror.CopyOptions{Global: global, SrcImage: srcOpts, Mode: mirror.DiskToMirror}}
+		_, err = ex.expandTaggedImages(ctx)
+		assert.Error(t, err)
+	})
+
+	t.Run("Testing expandTaggedImages : list tags error should fail", func(t *testing.T) {
+		failCfg := v2alpha1.ImageSetConfiguration{
+			ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+				Mirror: v2alpha1.Mirror{
+					AdditionalImages: []v2alpha1.Image{{Name: "quay.io/fail/image", Tags: &v2alpha1.ImageTagsFilter{}}},
+				},
+			},
+		}
+		ex := &LocalStorageCollector{Log: log, Config: failCfg, Manifest: MockManifest{Log: log}, Opts: mirror.CopyOptions{Global: global, SrcImage: srcOpts, Mode: mirror.MirrorToMirror}}
+		_, err := ex.expandTaggedImages(ctx)
+		assert.Error(t, err)
+	})
+}
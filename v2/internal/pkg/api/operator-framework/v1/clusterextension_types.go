@@ -0,0 +1,108 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ClusterExtensionCRDAPIVersion = GroupName + "/" + GroupVersion
+	ClusterExtensionKind          = "ClusterExtension"
+
+	SourceTypeCatalog = "Catalog"
+
+	UpgradeConstraintPolicyCatalogProvided UpgradeConstraintPolicy = "CatalogProvided"
+	UpgradeConstraintPolicySelfCertified   UpgradeConstraintPolicy = "SelfCertified"
+)
+
+// UpgradeConstraintPolicy defines how the upgrade constraints of the catalog are enforced
+type UpgradeConstraintPolicy string
+
+// ClusterExtension is the Schema for the clusterextensions API.
+// Only the fields needed to generate ClusterExtension resources are kept here.
+type ClusterExtension struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// metadata is the standard object's metadata.
+	metav1.ObjectMeta `json:"metadata"`
+
+	// spec is an optional field that defines the desired state of the ClusterExtension.
+	Spec ClusterExtensionSpec `json:"spec,omitempty"`
+}
+
+// ClusterExtensionSpec defines the desired state of ClusterExtension
+type ClusterExtensionSpec struct {
+	// namespace is a reference to a Kubernetes namespace.
+	// This is the namespace in which the provided ServiceAccount must exist.
+	// It also designates the default namespace where namespace-scoped resources
+	// for the extension are applied to the cluster.
+	Namespace string `json:"namespace"`
+
+	// serviceAccount is a reference to a ServiceAccount used to perform all interactions
+	// with the cluster that are required to manage the extension.
+	// The ServiceAccount must be configured with the necessary permissions to perform these interactions.
+	ServiceAccount ServiceAccountReference `json:"serviceAccount"`
+
+	// source is a required field which selects the installation source of content
+	// for this ClusterExtension. Selection is performed by setting the sourceType.
+	Source SourceConfig `json:"source"`
+}
+
+// SourceConfig is a discriminated union which selects the installation source.
+type SourceConfig struct {
+	// sourceType is a required reference to the type of install source.
+	// Allowed values are "Catalog"
+	SourceType string `json:"sourceType"`
+
+	// catalog is used to configure how information is sourced from a catalog.
+	// This field is required when sourceType is "Catalog", and forbidden otherwise.
+	Catalog *CatalogFilter `json:"catalog,omitempty"`
+}
+
+// CatalogFilter defines the attributes used to identify and filter content from a catalog.
+type CatalogFilter struct {
+	// packageName is a reference to the name of the package to be installed
+	// and is used to filter the content from catalogs.
+	PackageName string `json:"packageName"`
+
+	// version is an optional semver constraint (a specific version or range of versions).
+	// When unspecified, the latest version available will be installed.
+	Version string `json:"version,omitempty"`
+
+	// channels is an optional reference to a set of channels belonging to
+	// the package specified in the packageName field.
+	Channels []string `json:"channels,omitempty"`
+
+	// selector is an optional field that can be used
+	// to filter the set of ClusterCatalogs used in the bundle
+	// selection process.
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// upgradeConstraintPolicy is an optional field that controls whether
+	// the upgrade path(s) defined in the catalog are enforced for the package
+	// referenced in the packageName field.
+	UpgradeConstraintPolicy UpgradeConstraintPolicy `json:"upgradeConstraintPolicy,omitempty"`
+}
+
+// ServiceAccountReference identifies the serviceAccount used to install a ClusterExtension.
+type ServiceAccountReference struct {
+	// name is a required, immutable reference to the name of the ServiceAccount
+	// to be used for installation and management of the content for the package
+	// specified in the packageName field.
+	Name string `json:"name"`
+}
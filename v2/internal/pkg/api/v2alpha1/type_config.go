@@ -1,6 +1,8 @@
 package v2alpha1
 
 import (
+	"crypto/md5"
+	"encoding/json"
 	"fmt"
 	"regexp"
 	"strings"
@@ -60,8 +62,11 @@ type Mirror struct {
 	// from the mirroring process if they exist in other content
 	// types in the configuration.
 	BlockedImages []Image `json:"blockedImages,omitempty"`
-	// Samples defines the configuration for Sample content types.
-	// This is currently not implemented.
+	// RewriteRules is an ordered list of rules changing the path of additional
+	// and helm images under the destination registry. The first matching rule applies.
+	RewriteRules []RewriteRule `json:"rewriteRules,omitempty"`
+	// Samples defines the imagestreams of the samples operator to mirror,
+	// as found in the manifests of the mirrored releases.
 	Samples []SampleImages `json:"samples,omitempty"`
 }
 
@@ -76,8 +81,11 @@ type Delete struct {
 	AdditionalImages []Image `json:"additionalImages,omitempty"`
 	// Helm define the configuration for Helm content types.
 	Helm Helm `json:"helm,omitempty"`
-	// Samples defines the configuration for Sample content types.
-	// This is currently not implemented.
+	// RewriteRules must be the same as the ones used when mirroring,
+	// so that images are deleted from their rewritten location.
+	RewriteRules []RewriteRule `json:"rewriteRules,omitempty"`
+	// Samples defines the imagestreams of the samples operator to mirror,
+	// as found in the manifests of the mirrored releases.
 	Samples []SampleImages `json:"samples,omitempty"`
 }
 
@@ -154,7 +162,17 @@ type Operator struct {
 	// pulls on later mirrors.
 	// This image should be an exact image pin (registry/namespace/name@sha256:<hash>)
 	// but is not required to be.
+	// Catalog can also be a file-based catalog directory on disk (fbc:///path/to/configs),
+	// built into a catalog image from BaseImage and pushed to TargetCatalog.
+	// Catalog can also be a template using {{ .OCPVersion }} (registry.redhat.io/redhat/redhat-operator-index:v{{ .OCPVersion }}),
+	// expanded into a catalog per OCP version of OCPVersions.
 	Catalog string `json:"catalog"`
+	// OCPVersions are the OCP minor versions (4.16) the Catalog template is expanded with.
+	// If unset, they are derived from the release channels of the platform.
+	OCPVersions []string `json:"ocpVersions,omitempty"`
+	// BaseImage is the opm image a catalog is built from, when Catalog
+	// is a file-based catalog directory on disk.
+	BaseImage string `json:"baseImage,omitempty"`
 	// TargetCatalog replaces TargetName and allows for specifying the exact URL of the target
 	// catalog, including any path-components (organization, namespace) of the target catalog's location
 	// on the disconnected registry.
@@ -171,18 +189,41 @@ type Operator struct {
 	// TargetTag is the tag the catalog image will be built with. If unset,
 	// the catalog will be publish with the provided tag in the Catalog
 	// field or a tag calculated from the partial digest.
+	// For a Catalog template, TargetTag can use {{ .OCPVersion }} too, and defaults to v<OCP version>.
 	TargetTag string `json:"targetTag,omitempty"`
 	// Full defines whether all packages within the catalog
 	// or specified IncludeConfig will be mirrored or just channel heads.
 	Full bool `json:"full,omitempty"`
-	// SkipDependencies will not include dependencies
-	// of bundles included in the diff if true.
+	// KeepLast is the number of entries to include from the head of each channel of the catalog,
+	// unless the package or the channel sets its own.
+	KeepLast int `json:"keepLast,omitempty"`
+	// SkipDependencies will not include the packages and APIs
+	// required by the filtered bundles if true.
 	SkipDependencies bool `json:"skipDependencies,omitempty"`
+	// Deprecated defines how the packages, channels and bundles the catalog marks as
+	// deprecated (olm.deprecations) are mirrored: include (default), exclude or warn.
+	Deprecated DeprecationPolicy `json:"deprecated,omitempty"`
+	// DeepScan pulls the bundle images in order to mirror the images referenced
+	// in their manifests (deployments of the ClusterServiceVersion, RELATED_IMAGE_*
+	// environment variables) which are missing from the relatedImages of the bundles.
+	DeepScan bool `json:"deepScan,omitempty"`
 	// path on disk for a template to use to complete catalogSource custom resource
 	// generated by oc-mirror
 	TargetCatalogSourceTemplate string `json:"targetCatalogSourceTemplate,omitempty"`
 }
 
+// DeprecationPolicy defines how the content marked as deprecated in a catalog is mirrored
+type DeprecationPolicy string
+
+const (
+	// DeprecatedInclude mirrors the deprecated content as any other content
+	DeprecatedInclude DeprecationPolicy = "include"
+	// DeprecatedExclude removes the deprecated content from the filtered catalog
+	DeprecatedExclude DeprecationPolicy = "exclude"
+	// DeprecatedWarn mirrors the deprecated content and reports its deprecation messages
+	DeprecatedWarn DeprecationPolicy = "warn"
+)
+
 // GetUniqueName determines the catalog name that will
 // be tracked in the metadata and built. This depends on what fields
 // are set between Catalog, TargetName, and TargetTag.
@@ -228,6 +269,30 @@ func (o Operator) IsFBCOCI() bool {
 	return strings.HasPrefix(o.Catalog, "oci:")
 }
 
+// IsCatalogTemplate determines if the catalog is a template expanded per OCP version
+func (o Operator) IsCatalogTemplate() bool {
+	return strings.Contains(o.Catalog, "{{")
+}
+
+// IsLocalFBC determines if the catalog is a file-based catalog directory on disk
+func (o Operator) IsLocalFBC() bool {
+	return strings.HasPrefix(o.Catalog, "fbc://")
+}
+
+// FilterDigest identifies the catalog along with its filter, regardless of where it is mirrored to.
+// The filtered catalog is kept in the working-dir under filtered-catalogs/<filter digest>.
+func (o Operator) FilterDigest() (string, error) {
+	c := o
+	c.TargetCatalog = ""
+	c.TargetTag = ""
+	c.TargetCatalogSourceTemplate = ""
+	pkgs, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", md5.Sum(pkgs))[0:32], nil
+}
+
 // Helm defines the configuration for Helm chart download
 // and image mirroring
 type Helm struct {
@@ -268,12 +333,75 @@ type Chart struct {
 type Image struct {
 	// Name of the image. This should be an exact image pin (registry/namespace/name@sha256:<hash>)
 	// but is not required to be.
+	// When Tags is set, Name is the repository (registry/namespace/name)
+	// without tag nor digest.
 	Name string `json:"name"`
+	// Tags selects the tags of the repository to mirror.
+	// The resolved tags are recorded in the working-dir so that
+	// diskToMirror and delete act on the same set.
+	Tags *ImageTagsFilter `json:"tags,omitempty"`
+	// TargetRepo replaces the path of the image under the destination registry.
+	// It takes precedence over the rewriteRules.
+	TargetRepo string `json:"targetRepo,omitempty"`
+	// TargetTag is the tag used for the image in the destination registry.
+	// It can only be set for an image referenced by digest.
+	TargetTag string `json:"targetTag,omitempty"`
+}
+
+// RewriteRule changes the path under which images are placed in the destination registry.
+type RewriteRule struct {
+	// Source is the repository to match, including the registry. A trailing /* matches
+	// all the repositories under that namespace (i.e. docker.io/library/*).
+	Source string `json:"source"`
+	// Target is the path under the destination registry replacing the matched Source
+	// (i.e. mirror/dockerhub/*).
+	Target string `json:"target"`
+}
+
+// Rewrite returns the path under the destination registry for repository (registry/path, without tag
+// nor digest), and whether the rule matched.
+func (r RewriteRule) Rewrite(repository string) (string, bool) {
+	source, isNamespace := strings.CutSuffix(r.Source, "/*")
+	if !isNamespace {
+		if repository == r.Source {
+			return r.Target, true
+		}
+		return "", false
+	}
+	remainder, found := strings.CutPrefix(repository, source+"/")
+	if !found || remainder == "" {
+		return "", false
+	}
+	return strings.TrimSuffix(r.Target, "*") + remainder, true
+}
+
+// RewriteRepository applies the first matching rule to repository.
+func RewriteRepository(rules []RewriteRule, repository string) (string, bool) {
+	for _, rule := range rules {
+		if path, ok := rule.Rewrite(repository); ok {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// ImageTagsFilter defines how tags of a repository are selected.
+// All criteria set are combined.
+type ImageTagsFilter struct {
+	// Regex is the regular expression tags must match (i.e. ^v2\.\d+\.\d+$).
+	Regex string `json:"regex,omitempty"`
+	// Exclude is a list of regular expressions, tags matching any of them are excluded.
+	Exclude []string `json:"exclude,omitempty"`
+	// Latest keeps only the N highest semver tags.
+	// Tags that are not semver are ignored when set.
+	Latest int `json:"latest,omitempty"`
+	// Since keeps only the tags of images created after that date (format yyyy-MM-dd).
+	Since string `json:"since,omitempty"`
 }
 
-// SampleImages define the configuration
-// for Sameple content types.
-// Not implemented.
+// SampleImages selects an imagestream of the samples operator.
+// The name is the imagestream name (e.g. ruby) to mirror all its tags,
+// or name:tag (e.g. ruby:3.0-ubi8) to mirror a single tag.
 type SampleImages struct {
 	Image `json:",inline"`
 }
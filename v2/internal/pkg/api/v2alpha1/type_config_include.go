@@ -25,6 +25,20 @@ type IncludePackage struct {
 
 	// All channels containing these bundles are parsed for an upgrade graph.
 	IncludeBundle `json:",inline"`
+
+	// SelectedBundles are the bundles of the package to include, by name.
+	// It cannot be mixed with channels or versions.
+	SelectedBundles []SelectedBundle `json:"bundles,omitempty" yaml:"bundles,omitempty"`
+	// SkipUpgradePath will only include the selected bundles if true.
+	// Otherwise, the channel entries between the selected bundles are included too,
+	// so that each channel keeps a valid upgrade graph.
+	SkipUpgradePath bool `json:"skipUpgradePath,omitempty" yaml:"skipUpgradePath,omitempty"`
+}
+
+// SelectedBundle is a bundle of a package, selected by name.
+type SelectedBundle struct {
+	// Name of the bundle, as found in the catalog.
+	Name string `json:"name" yaml:"name"`
 }
 
 // IncludeChannel contains a name (required) and versions (optional)
@@ -44,6 +58,10 @@ type IncludeBundle struct {
 	MinVersion string `json:"minVersion,omitempty" yaml:"minVersion,omitempty"`
 	// MaxVersion to include as the channel head version.
 	MaxVersion string `json:"maxVersion,omitempty" yaml:"maxVersion,omitempty"`
+	// KeepLast is the number of entries to include from the channel head,
+	// following the upgrade graph (replaces, skips and skipRange) of the channel.
+	// Set on a channel, it overrides the one of the package, which overrides the one of the catalog.
+	KeepLast int `json:"keepLast,omitempty" yaml:"keepLast,omitempty"`
 	// MinBundle to include, plus all bundles in the upgrade graph to the channel head.
 	// Set this field only if the named bundle has no semantic version metadata.
 	// MinBundle string `json:"minBundle,omitempty" yaml:"minBundle,omitempty"`
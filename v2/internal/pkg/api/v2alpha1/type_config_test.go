@@ -39,3 +39,31 @@ func TestIsValidPathComponent(t *testing.T) {
 		})
 	}
 }
+
+func TestRewriteRepository(t *testing.T) {
+	rules := []RewriteRule{
+		{Source: "docker.io/library/*", Target: "mirror/dockerhub/*"},
+		{Source: "quay.io/team/app", Target: "apps/team-app"},
+		{Source: "quay.io/*", Target: "mirror/quay/*"},
+	}
+	tests := []struct {
+		name         string
+		repository   string
+		expectedPath string
+		expectedOk   bool
+	}{
+		{name: "namespace rule", repository: "docker.io/library/nginx", expectedPath: "mirror/dockerhub/nginx", expectedOk: true},
+		{name: "namespace rule with nested path", repository: "quay.io/org/sub/app", expectedPath: "mirror/quay/org/sub/app", expectedOk: true},
+		{name: "first matching rule wins", repository: "quay.io/team/app", expectedPath: "apps/team-app", expectedOk: true},
+		{name: "namespace itself doesn't match", repository: "docker.io/library", expectedPath: "", expectedOk: false},
+		{name: "no matching rule", repository: "registry.redhat.io/ubi8/ubi", expectedPath: "", expectedOk: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path, ok := RewriteRepository(rules, test.repository)
+			if path != test.expectedPath || ok != test.expectedOk {
+				t.Errorf("Expected (%s, %v), but got (%s, %v)", test.expectedPath, test.expectedOk, path, ok)
+			}
+		})
+	}
+}
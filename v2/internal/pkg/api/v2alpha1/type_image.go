@@ -20,30 +20,36 @@ const (
 	TypeGeneric
 	TypeKubeVirtContainer
 	TypeHelmImage
+	TypeSampleImage
+	TypeOperatorDiscoveredImage
 )
 
 // ImageTypeString defines the string
 // respresentation of every ImageType.
 var imageTypeStrings = map[ImageType]string{
-	TypeOCPReleaseContent:    "ocpReleaseContent",
-	TypeCincinnatiGraph:      "cincinnatiGraph",
-	TypeOCPRelease:           "ocpRelease",
-	TypeOperatorCatalog:      "operatorCatalog",
-	TypeOperatorBundle:       "operatorBundle",
-	TypeOperatorRelatedImage: "operatorRelatedImage",
-	TypeGeneric:              "generic",
-	TypeHelmImage:            "helmImage",
+	TypeOCPReleaseContent:       "ocpReleaseContent",
+	TypeCincinnatiGraph:         "cincinnatiGraph",
+	TypeOCPRelease:              "ocpRelease",
+	TypeOperatorCatalog:         "operatorCatalog",
+	TypeOperatorBundle:          "operatorBundle",
+	TypeOperatorRelatedImage:    "operatorRelatedImage",
+	TypeOperatorDiscoveredImage: "operatorDiscoveredImage",
+	TypeGeneric:                 "generic",
+	TypeHelmImage:               "helmImage",
+	TypeSampleImage:             "sampleImage",
 }
 
 var imageStringsType = map[string]ImageType{
-	"ocpReleaseContent":    TypeOCPReleaseContent,
-	"cincinnatiGraph":      TypeCincinnatiGraph,
-	"ocpRelease":           TypeOCPRelease,
-	"operatorCatalog":      TypeOperatorCatalog,
-	"operatorBundle":       TypeOperatorBundle,
-	"operatorRelatedImage": TypeOperatorRelatedImage,
-	"generic":              TypeGeneric,
-	"helmImage":            TypeHelmImage,
+	"ocpReleaseContent":       TypeOCPReleaseContent,
+	"cincinnatiGraph":         TypeCincinnatiGraph,
+	"ocpRelease":              TypeOCPRelease,
+	"operatorCatalog":         TypeOperatorCatalog,
+	"operatorBundle":          TypeOperatorBundle,
+	"operatorRelatedImage":    TypeOperatorRelatedImage,
+	"operatorDiscoveredImage": TypeOperatorDiscoveredImage,
+	"generic":                 TypeGeneric,
+	"helmImage":               TypeHelmImage,
+	"sampleImage":             TypeSampleImage,
 }
 
 func (it ImageType) IsRelease() bool {
@@ -51,7 +57,7 @@ func (it ImageType) IsRelease() bool {
 }
 
 func (it ImageType) IsOperator() bool {
-	return it == TypeOperatorBundle || it == TypeOperatorCatalog || it == TypeOperatorRelatedImage
+	return it == TypeOperatorBundle || it == TypeOperatorCatalog || it == TypeOperatorRelatedImage || it == TypeOperatorDiscoveredImage
 }
 
 func (it ImageType) IsOperatorCatalog() bool {
@@ -66,6 +72,10 @@ func (it ImageType) IsHelmImage() bool {
 	return it == TypeHelmImage
 }
 
+func (it ImageType) IsSampleImage() bool {
+	return it == TypeSampleImage
+}
+
 // String returns the string representation
 // of an Image Type
 func (it ImageType) String() string {
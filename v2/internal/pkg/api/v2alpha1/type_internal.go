@@ -117,37 +117,38 @@ type OperatorConfig struct {
 }
 
 type OperatorLabels struct {
-	License                                    string `json:"License"`
-	Architecture                               string `json:"architecture"`
-	BuildDate                                  string `json:"build-date"`
-	ComRedhatBuildHost                         string `json:"com.redhat.build-host"`
-	ComRedhatComponent                         string `json:"com.redhat.component"`
-	ComRedhatIndexDeliveryDistributionScope    string `json:"com.redhat.index.delivery.distribution_scope"`
-	ComRedhatIndexDeliveryVersion              string `json:"com.redhat.index.delivery.version"`
-	ComRedhatLicenseTerms                      string `json:"com.redhat.license_terms"`
-	Description                                string `json:"description"`
-	DistributionScope                          string `json:"distribution-scope"`
-	IoBuildahVersion                           string `json:"io.buildah.version"`
-	IoK8SDescription                           string `json:"io.k8s.description"`
-	IoK8SDisplayName                           string `json:"io.k8s.display-name"`
-	IoOpenshiftBuildCommitID                   string `json:"io.openshift.build.commit.id"`
-	IoOpenshiftBuildCommitURL                  string `json:"io.openshift.build.commit.url"`
-	IoOpenshiftBuildSourceLocation             string `json:"io.openshift.build.source-location"`
-	IoOpenshiftExposeServices                  string `json:"io.openshift.expose-services"`
-	IoOpenshiftMaintainerComponent             string `json:"io.openshift.maintainer.component"`
-	IoOpenshiftMaintainerProduct               string `json:"io.openshift.maintainer.product"`
-	IoOpenshiftMaintainerProject               string `json:"io.openshift.maintainer.project"`
-	IoOpenshiftTags                            string `json:"io.openshift.tags"`
-	Maintainer                                 string `json:"maintainer"`
-	Name                                       string `json:"name"`
-	OperatorsOperatorframeworkIoIndexConfigsV1 string `json:"operators.operatorframework.io.index.configs.v1"`
-	Release                                    string `json:"release"`
-	Summary                                    string `json:"summary"`
-	URL                                        string `json:"url"`
-	VcsRef                                     string `json:"vcs-ref"`
-	VcsType                                    string `json:"vcs-type"`
-	Vendor                                     string `json:"vendor"`
-	Version                                    string `json:"version"`
+	License                                       string `json:"License"`
+	Architecture                                  string `json:"architecture"`
+	BuildDate                                     string `json:"build-date"`
+	ComRedhatBuildHost                            string `json:"com.redhat.build-host"`
+	ComRedhatComponent                            string `json:"com.redhat.component"`
+	ComRedhatIndexDeliveryDistributionScope       string `json:"com.redhat.index.delivery.distribution_scope"`
+	ComRedhatIndexDeliveryVersion                 string `json:"com.redhat.index.delivery.version"`
+	ComRedhatLicenseTerms                         string `json:"com.redhat.license_terms"`
+	Description                                   string `json:"description"`
+	DistributionScope                             string `json:"distribution-scope"`
+	IoBuildahVersion                              string `json:"io.buildah.version"`
+	IoK8SDescription                              string `json:"io.k8s.description"`
+	IoK8SDisplayName                              string `json:"io.k8s.display-name"`
+	IoOpenshiftBuildCommitID                      string `json:"io.openshift.build.commit.id"`
+	IoOpenshiftBuildCommitURL                     string `json:"io.openshift.build.commit.url"`
+	IoOpenshiftBuildSourceLocation                string `json:"io.openshift.build.source-location"`
+	IoOpenshiftExposeServices                     string `json:"io.openshift.expose-services"`
+	IoOpenshiftMaintainerComponent                string `json:"io.openshift.maintainer.component"`
+	IoOpenshiftMaintainerProduct                  string `json:"io.openshift.maintainer.product"`
+	IoOpenshiftMaintainerProject                  string `json:"io.openshift.maintainer.project"`
+	IoOpenshiftTags                               string `json:"io.openshift.tags"`
+	Maintainer                                    string `json:"maintainer"`
+	Name                                          string `json:"name"`
+	OperatorsOperatorframeworkIoIndexConfigsV1    string `json:"operators.operatorframework.io.index.configs.v1"`
+	OperatorsOperatorframeworkIoBundleManifestsV1 string `json:"operators.operatorframework.io.bundle.manifests.v1"`
+	Release                                       string `json:"release"`
+	Summary                                       string `json:"summary"`
+	URL                                           string `json:"url"`
+	VcsRef                                        string `json:"vcs-ref"`
+	VcsType                                       string `json:"vcs-type"`
+	Vendor                                        string `json:"vendor"`
+	Version                                       string `json:"version"`
 }
 
 type OperatorRootFS struct {
@@ -248,6 +249,11 @@ type DeleteImageList struct {
 	Kind       string       `json:"kind"`
 	APIVersion string       `json:"apiVersion"`
 	Items      []DeleteItem `json:"items"`
+	// Keep lists the images referenced by the keep configurations and workspaces,
+	// which are checked again right before deleting
+	Keep []DeleteItem `json:"keep,omitempty"`
+	// Conflicts lists the images left out of Items, as they are referenced by Keep
+	Conflicts []DeleteItem `json:"conflicts,omitempty"`
 }
 
 type DeleteItem struct {
@@ -260,4 +266,8 @@ type CatalogFilterResult struct {
 	OperatorFilter     Operator
 	FilteredConfigPath string
 	ToRebuild          bool
+	// CatalogDir is the directory of the catalog in the working-dir (operator-catalogs/<name>/<catalog digest>)
+	CatalogDir string
+	// Deprecations holds the deprecation messages reported by the deprecation policy of the catalog
+	Deprecations []string
 }
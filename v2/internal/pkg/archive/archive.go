@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 
 	digest "github.com/opencontainers/go-digest"
@@ -23,7 +24,10 @@ type MirrorArchive struct {
 	workingDir   string
 	cacheDir     string
 	history      history.History
+	baseline     HistoryBaseline
 	blobGatherer BlobsGatherer
+	// cacheRegistry prefixes the destination of the images recorded in the history
+	cacheRegistry string
 }
 
 // NewMirrorArchive creates a new MirrorArchive instance with strictAdder:
@@ -36,7 +40,11 @@ func NewMirrorArchive(opts *mirror.CopyOptions, destination, iscPath, workingDir
 		logg.Warn("unable to delete past archives from %s: %v", destination, err)
 	}
 	// create the history interface
-	history, err := history.NewHistory(workingDir, opts.Global.Since, logg, history.OSFileCreator{})
+	srcCtx, err := opts.SrcImage.NewSystemContext()
+	if err != nil {
+		return &MirrorArchive{}, err
+	}
+	history, err := history.New(opts.Global.HistoryBackend, workingDir, opts.Global.Since, logg, srcCtx)
 	if err != nil {
 		return &MirrorArchive{}, err
 	}
@@ -53,13 +61,15 @@ func NewMirrorArchive(opts *mirror.CopyOptions, destination, iscPath, workingDir
 		return &MirrorArchive{}, err
 	}
 	ma := MirrorArchive{
-		destination:  destination,
-		history:      history,
-		blobGatherer: bg,
-		workingDir:   workingDir,
-		cacheDir:     cacheDir,
-		iscPath:      iscPath,
-		adder:        a,
+		destination:   destination,
+		history:       history,
+		baseline:      historyBaseline(opts),
+		cacheRegistry: dockerProtocol + opts.LocalStorageFQDN,
+		blobGatherer:  bg,
+		workingDir:    workingDir,
+		cacheDir:      cacheDir,
+		iscPath:       iscPath,
+		adder:         a,
 	}
 	return &ma, nil
 }
@@ -70,7 +80,11 @@ func NewMirrorArchive(opts *mirror.CopyOptions, destination, iscPath, workingDir
 func NewPermissiveMirrorArchive(opts *mirror.CopyOptions, destination, iscPath, workingDir, cacheDir string, maxSize int64, logg clog.PluggableLoggerInterface) (*MirrorArchive, error) {
 
 	// create the history interface
-	history, err := history.NewHistory(workingDir, opts.Global.Since, logg, history.OSFileCreator{})
+	srcCtx, err := opts.SrcImage.NewSystemContext()
+	if err != nil {
+		return &MirrorArchive{}, err
+	}
+	history, err := history.New(opts.Global.HistoryBackend, workingDir, opts.Global.Since, logg, srcCtx)
 	if err != nil {
 		return &MirrorArchive{}, err
 	}
@@ -88,12 +102,14 @@ func NewPermissiveMirrorArchive(opts *mirror.CopyOptions, destination, iscPath,
 	}
 
 	ma := MirrorArchive{
-		destination:  destination,
-		history:      history,
-		blobGatherer: bg,
-		workingDir:   workingDir,
-		cacheDir:     cacheDir,
-		iscPath:      iscPath,
+		destination:   destination,
+		history:       history,
+		baseline:      historyBaseline(opts),
+		cacheRegistry: dockerProtocol + opts.LocalStorageFQDN,
+		blobGatherer:  bg,
+		workingDir:    workingDir,
+		cacheDir:      cacheDir,
+		iscPath:       iscPath,
 
 		adder: a,
 	}
@@ -105,6 +121,7 @@ func NewPermissiveMirrorArchive(opts *mirror.CopyOptions, destination, iscPath,
 // * docker/v2/blobs/sha256 : blobs that haven't been mirrored (diff)
 // * working-dir
 // * image set config
+// * archive metadata (images, history baseline...)
 func (o *MirrorArchive) BuildArchive(ctx context.Context, collectedImages []v2alpha1.CopyImageSchema) error {
 	// 0 - make sure that any tarWriters or files opened by the adder are closed as we leave this method
 	defer o.adder.close()
@@ -132,39 +149,71 @@ func (o *MirrorArchive) BuildArchive(ctx context.Context, collectedImages []v2al
 	}
 	// ignoring the error otherwise: continuing with an empty map in blobsInHistory
 
-	addedBlobs, err := o.addImagesDiff(ctx, collectedImages, blobsInHistory, o.cacheDir)
+	addedBlobs, runImages, err := o.addImagesDiff(ctx, collectedImages, blobsInHistory, o.cacheDir)
 	if err != nil {
 		return fmt.Errorf("unable to add image blobs to the archive : %v", err)
 	}
-	//5 - update history file with addedBlobs
-	_, err = o.history.Append(addedBlobs)
+	//5 - record the run (images and addedBlobs) in history
+	_, err = o.history.AppendRun(history.Run{
+		Archive:     o.destination,
+		Destination: o.cacheRegistry,
+		Images:      runImages,
+		Blobs:       sortedKeys(addedBlobs),
+	})
 	if err != nil {
 		return fmt.Errorf("unable to update history metadata: %v", err)
 	}
+	//6 - Add the archive metadata
+	baseline := o.baseline
+	baseline.Blobs = len(blobsInHistory)
+	err = addMetadata(o.adder, newArchiveMetadata(baseline, runImages))
+	if err != nil {
+		return fmt.Errorf("unable to add archive metadata to the archive : %v", err)
+	}
 
 	return nil
 }
 
-func (o *MirrorArchive) addImagesDiff(ctx context.Context, collectedImages []v2alpha1.CopyImageSchema, historyBlobs map[string]string, cacheDir string) (map[string]string, error) {
+// historyBaseline returns the history options the archives are generated against
+func historyBaseline(opts *mirror.CopyOptions) HistoryBaseline {
+	baseline := HistoryBaseline{Backend: opts.Global.HistoryBackend}
+	if !opts.Global.Since.IsZero() {
+		since := opts.Global.Since
+		baseline.Since = &since
+	}
+	return baseline
+}
+
+// addImagesDiff adds the blobs of the collected images that are not in history to the archive.
+// It returns the added blobs and the images, as recorded in the history.
+func (o *MirrorArchive) addImagesDiff(ctx context.Context, collectedImages []v2alpha1.CopyImageSchema, historyBlobs map[string]string, cacheDir string) (map[string]string, []history.RunImage, error) {
 	allAddedBlobs := map[string]string{}
+	runImages := make([]history.RunImage, 0, len(collectedImages))
 	for _, img := range collectedImages {
-		imgBlobs, err := o.blobGatherer.GatherBlobs(ctx, img.Destination)
+		manifestDigest, imgBlobs, err := o.blobGatherer.GatherImage(ctx, img.Destination)
 		if err != nil {
-			return nil, fmt.Errorf("unable to find blobs corresponding to %s: %v", img.Destination, err)
+			return nil, nil, fmt.Errorf("unable to find blobs corresponding to %s: %v", img.Destination, err)
 		}
 
 		addedBlobs, err := o.addBlobsDiff(imgBlobs, historyBlobs, allAddedBlobs)
 		if err != nil {
-			return nil, fmt.Errorf("unable to add blobs corresponding to %s: %v", img.Destination, err)
+			return nil, nil, fmt.Errorf("unable to add blobs corresponding to %s: %v", img.Destination, err)
 		}
 
 		for hash, value := range addedBlobs {
 			allAddedBlobs[hash] = value
 		}
 
+		runImages = append(runImages, history.RunImage{
+			Origin:      img.Origin,
+			Destination: img.Destination,
+			Digest:      manifestDigest,
+			Type:        img.Type.String(),
+			Blobs:       sortedKeys(imgBlobs),
+		})
 	}
 
-	return allAddedBlobs, nil
+	return allAddedBlobs, runImages, nil
 }
 
 func (o *MirrorArchive) addBlobsDiff(collectedBlobs, historyBlobs map[string]string, alreadyAddedBlobs map[string]string) (map[string]string, error) {
@@ -190,6 +239,15 @@ func (o *MirrorArchive) addBlobsDiff(collectedBlobs, historyBlobs map[string]str
 	return blobsInDiff, nil
 }
 
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func removePastArchives(destination string) error {
 	_, err := os.Stat(destination)
 	if err == nil {
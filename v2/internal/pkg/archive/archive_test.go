@@ -12,6 +12,7 @@ import (
 
 	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/common"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/history"
 	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
 	"github.com/stretchr/testify/assert"
@@ -51,6 +52,7 @@ var expectedTarContents = []string{
 	"docker/registry/v2/repositories/ubi8/ubi/_uploads/97e2891e-4cb2-4289-a87a-e9b8cd006d20/hashstates/sha256/0",
 	"docker/registry/v2/repositories/ubi8/ubi/_uploads/97e2891e-4cb2-4289-a87a-e9b8cd006d20/startedat",
 	"isc",
+	archiveMetadataName,
 	"working-dir-fake/hold-release/ocp-release/v4.13.10/release-manifests/0000_50_installer_coreos-bootimages.yaml",
 	"working-dir-fake/hold-release/ocp-release/v4.13.9/release-manifests/0000_50_installer_coreos-bootimages.yaml",
 	"working-dir-fake/hold-release/ocp-release/4.14.1-x86_64/release-manifests/image-references",
@@ -396,6 +398,19 @@ func (mbg mockBlobGatherer) GatherBlobs(ctx context.Context, imgRef string) (map
 	return blobs, nil
 }
 
+func (mbg mockBlobGatherer) GatherImage(ctx context.Context, imgRef string) (string, map[string]string, error) {
+	blobs, err := mbg.GatherBlobs(ctx, imgRef)
+	return "sha256:2e39d55595ea56337b5b788e96e6afdec3db09d2759d903cbe120468187c4644", blobs, err
+}
+
+func (m mockHistory) AppendRun(run history.Run) (map[string]string, error) {
+	blobs := map[string]string{}
+	for _, blob := range run.Blobs {
+		blobs[blob] = ""
+	}
+	return m.Append(blobs)
+}
+
 func (m mockHistory) Read() (map[string]string, error) {
 	historyMap := map[string]string{
 
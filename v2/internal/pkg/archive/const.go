@@ -11,4 +11,15 @@ const (
 	segMultiplier         int64 = 1024 * 1024 * 1024
 	defaultSegSize        int64 = 500
 	archiveFileNameFormat       = "%s_%06d.tar"
+	mergedConfigName            = "isc-merged.yaml"
+	archiveMetadataName         = "archive-metadata.json"
+	operatorCatalogsDir         = "operator-catalogs"
+	filteredCatalogsDir         = "filtered-catalogs"
+	dockerProtocol              = "docker://"
+)
+
+// working-dir files that are merged instead of overwritten when archives are merged
+const (
+	resolvedTagsPath        = "working-dir/additional-images/resolved-tags.json"
+	skippedImageStreamsPath = "working-dir/samples/skipped-imagestreams.json"
 )
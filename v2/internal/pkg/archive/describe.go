@@ -0,0 +1,362 @@
+package archive
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/config"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+)
+
+// MirrorArchiveDescriber describes a mirror archive, reading its chunks without extracting them
+type MirrorArchiveDescriber struct {
+	Describer
+	archiveFiles []string
+	logger       clog.PluggableLoggerInterface
+}
+
+// Description is the content of a mirror archive
+type Description struct {
+	Chunks          []string         `json:"chunks"`
+	CreationTime    time.Time        `json:"creationTime"`
+	OcMirrorVersion string           `json:"ocMirrorVersion,omitempty"`
+	HistoryBaseline *HistoryBaseline `json:"historyBaseline,omitempty"`
+	// HasMetadata is false for the archives generated by versions of oc-mirror which did not
+	// record the archive metadata: the images and the sizes per type are then unknown
+	HasMetadata           bool                           `json:"hasMetadata"`
+	ImageSetConfiguration v2alpha1.ImageSetConfiguration `json:"imageSetConfiguration"`
+	Releases              []string                       `json:"releases"`
+	Catalogs              []CatalogDescription           `json:"catalogs"`
+	AdditionalImages      []string                       `json:"additionalImages"`
+	HelmCharts            []string                       `json:"helmCharts"`
+	Sizes                 Sizes                          `json:"sizes"`
+}
+
+// CatalogDescription is an operator catalog of the archive, along with its packages
+type CatalogDescription struct {
+	Catalog  string               `json:"catalog"`
+	Packages []PackageDescription `json:"packages"`
+}
+
+// PackageDescription is an operator package, along with the versions of its bundles in the archive
+type PackageDescription struct {
+	Name    string   `json:"name"`
+	Bundles []string `json:"bundles"`
+}
+
+// Sizes is the size of the archive, and of the blobs of each content type.
+// A blob shared by several content types is counted in each of them.
+type Sizes struct {
+	Total int64      `json:"total"`
+	Blobs int64      `json:"blobs"`
+	Types []TypeSize `json:"types"`
+}
+
+// TypeSize is the number of images of a content type, and the size of their blobs in the archive
+type TypeSize struct {
+	Type   string `json:"type"`
+	Images int    `json:"images"`
+	Bytes  int64  `json:"bytes"`
+}
+
+// bundle is an operator bundle of a filtered catalog of the working-dir
+type bundle struct {
+	// filter is the filter digest of the catalog the bundle belongs to
+	filter  string
+	pkg     string
+	version string
+	image   string
+}
+
+// archiveContent is what is read from the chunks of an archive
+type archiveContent struct {
+	iscName  string
+	isc      []byte
+	metadata *ArchiveMetadata
+	blobs    map[string]int64
+	bundles  []bundle
+}
+
+// NewArchiveDescriber creates a MirrorArchiveDescriber for the archive in archivePath
+func NewArchiveDescriber(archivePath string, logg clog.PluggableLoggerInterface) (MirrorArchiveDescriber, error) {
+	archiveFiles, err := archiveChunks(archivePath)
+	if err != nil {
+		return MirrorArchiveDescriber{}, err
+	}
+	if len(archiveFiles) == 0 {
+		return MirrorArchiveDescriber{}, fmt.Errorf("no archive found in %s", archivePath)
+	}
+	return MirrorArchiveDescriber{archiveFiles: archiveFiles, logger: logg}, nil
+}
+
+// Describe reads the chunks of the archive and describes its content
+func (o MirrorArchiveDescriber) Describe() (Description, error) {
+	description := Description{
+		Chunks:           []string{},
+		Releases:         []string{},
+		Catalogs:         []CatalogDescription{},
+		AdditionalImages: []string{},
+		HelmCharts:       []string{},
+		Sizes:            Sizes{Types: []TypeSize{}},
+	}
+	content := archiveContent{blobs: map[string]int64{}}
+	for _, chunkPath := range o.archiveFiles {
+		info, err := os.Stat(chunkPath)
+		if err != nil {
+			return description, err
+		}
+		description.Chunks = append(description.Chunks, path.Base(chunkPath))
+		description.Sizes.Total += info.Size()
+		if err := o.readChunk(chunkPath, &content); err != nil {
+			return description, err
+		}
+	}
+
+	if content.isc == nil {
+		return description, fmt.Errorf("no image set configuration found in the archive")
+	}
+	cfg, err := config.LoadConfig[v2alpha1.ImageSetConfiguration](content.isc, v2alpha1.ImageSetConfigurationKind)
+	if err != nil {
+		return description, fmt.Errorf("unable to read image set configuration %s: %v", content.iscName, err)
+	}
+	description.ImageSetConfiguration = cfg
+	for _, size := range content.blobs {
+		description.Sizes.Blobs += size
+	}
+
+	if content.metadata != nil {
+		description.HasMetadata = true
+		description.CreationTime = content.metadata.CreationTime
+		description.OcMirrorVersion = content.metadata.OcMirrorVersion
+		description.HistoryBaseline = &content.metadata.HistoryBaseline
+		describeImages(&description, content)
+	} else {
+		o.logger.Debug("no archive metadata found, the archive was generated by an older version of oc-mirror")
+		// the image set configuration is named after the creation time of the archive
+		creationTime, err := time.Parse(time.RFC3339, strings.TrimPrefix(content.iscName, imageSetConfigPrefix))
+		if err == nil {
+			description.CreationTime = creationTime
+		}
+	}
+	description.Catalogs, err = describeCatalogs(cfg, content)
+	if err != nil {
+		return description, err
+	}
+	description.HelmCharts = describeHelmCharts(cfg)
+	return description, nil
+}
+
+// readChunk reads the image set configuration, the metadata, the size of the blobs
+// and the bundles of the filtered catalogs from a chunk
+func (o MirrorArchiveDescriber) readChunk(chunkPath string, content *archiveContent) error {
+	chunkFile, err := os.Open(chunkPath)
+	if err != nil {
+		return err
+	}
+	defer chunkFile.Close()
+	reader := tar.NewReader(chunkFile)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading archive %s: %v", chunkFile.Name(), err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(header.Name, imageSetConfigPrefix):
+			content.iscName = header.Name
+			if content.isc, err = io.ReadAll(reader); err != nil {
+				return err
+			}
+		case header.Name == archiveMetadataName:
+			var metadata ArchiveMetadata
+			if err := json.NewDecoder(reader).Decode(&metadata); err != nil {
+				return fmt.Errorf("unable to read archive metadata: %v", err)
+			}
+			content.metadata = &metadata
+		case strings.HasPrefix(header.Name, cacheBlobsDir) && path.Base(header.Name) == "data":
+			// docker/registry/v2/blobs/<algorithm>/<2 first chars>/<encoded>/data
+			encodedDir := path.Dir(header.Name)
+			algorithm := path.Base(path.Dir(path.Dir(encodedDir)))
+			content.blobs[algorithm+":"+path.Base(encodedDir)] = header.Size
+		case strings.HasPrefix(header.Name, path.Join(workingDirectory, operatorCatalogsDir)) &&
+			strings.Contains(header.Name, "/"+filteredCatalogsDir+"/") && path.Ext(header.Name) == ".json":
+			// working-dir/operator-catalogs/<catalog>/<digest>/filtered-catalogs/<filter digest>/catalog-config/<package>/catalog.json
+			_, filterPath, _ := strings.Cut(header.Name, "/"+filteredCatalogsDir+"/")
+			filter, _, _ := strings.Cut(filterPath, "/")
+			bundles, err := readBundles(reader, filter)
+			if err != nil {
+				o.logger.Debug("unable to read the bundles of %s: %v", header.Name, err)
+				continue
+			}
+			content.bundles = append(content.bundles, bundles...)
+		}
+	}
+}
+
+// readBundles reads the bundles of a file based catalog, filtered with the filter digest
+func readBundles(reader io.Reader, filter string) ([]bundle, error) {
+	var bundles []bundle
+	err := declcfg.WalkMetasReader(reader, func(meta *declcfg.Meta, err error) error {
+		if err != nil {
+			return err
+		}
+		if meta.Schema != declcfg.SchemaBundle {
+			return nil
+		}
+		var b declcfg.Bundle
+		if err := json.Unmarshal(meta.Blob, &b); err != nil {
+			return err
+		}
+		props, err := property.Parse(b.Properties)
+		if err != nil {
+			return err
+		}
+		version := b.Name
+		if len(props.Packages) > 0 {
+			version = props.Packages[0].Version
+		}
+		bundles = append(bundles, bundle{filter: filter, pkg: b.Package, version: version, image: b.Image})
+		return nil
+	})
+	return bundles, err
+}
+
+// describeImages describes the releases, the additional images and the size
+// of each content type from the images recorded in the archive metadata
+func describeImages(description *Description, content archiveContent) {
+	sizes := map[string]*TypeSize{}
+	counted := map[string]bool{}
+	for _, img := range content.metadata.Images {
+		switch img.Type {
+		case v2alpha1.TypeOCPRelease.String():
+			description.Releases = append(description.Releases, trimTransport(img.Origin))
+		case v2alpha1.TypeGeneric.String():
+			description.AdditionalImages = append(description.AdditionalImages, trimTransport(img.Origin))
+		}
+
+		size, ok := sizes[img.Type]
+		if !ok {
+			size = &TypeSize{Type: img.Type}
+			sizes[img.Type] = size
+		}
+		size.Images++
+		for _, blob := range img.Blobs {
+			blobSize, ok := content.blobs[blob]
+			if !ok || counted[img.Type+blob] {
+				// already mirrored, or shared with another image of the same type
+				continue
+			}
+			counted[img.Type+blob] = true
+			size.Bytes += blobSize
+		}
+	}
+	for _, size := range sizes {
+		description.Sizes.Types = append(description.Sizes.Types, *size)
+	}
+	sort.Slice(description.Sizes.Types, func(i, j int) bool {
+		if description.Sizes.Types[i].Bytes != description.Sizes.Types[j].Bytes {
+			return description.Sizes.Types[i].Bytes > description.Sizes.Types[j].Bytes
+		}
+		return description.Sizes.Types[i].Type < description.Sizes.Types[j].Type
+	})
+	sort.Strings(description.Releases)
+	sort.Strings(description.AdditionalImages)
+}
+
+// describeCatalogs describes the packages and the bundle versions of each catalog of the
+// image set configuration, from the filtered catalogs of the working-dir. When the archive
+// has metadata, only the bundles of the images in the archive are described.
+func describeCatalogs(cfg v2alpha1.ImageSetConfiguration, content archiveContent) ([]CatalogDescription, error) {
+	var bundleImages map[string]bool
+	if content.metadata != nil {
+		bundleImages = map[string]bool{}
+		for _, img := range content.metadata.Images {
+			if img.Type == v2alpha1.TypeOperatorBundle.String() {
+				bundleImages[trimTransport(img.Origin)] = true
+			}
+		}
+	}
+	// filter digest of the catalog -> package -> bundle versions.
+	// The catalogs of the same repository, such as the catalogs of several OCP versions, have different filter digests.
+	versions := map[string]map[string][]string{}
+	for _, b := range content.bundles {
+		if bundleImages != nil && !bundleImages[trimTransport(b.image)] {
+			continue
+		}
+		if versions[b.filter] == nil {
+			versions[b.filter] = map[string][]string{}
+		}
+		if !slices.Contains(versions[b.filter][b.pkg], b.version) {
+			versions[b.filter][b.pkg] = append(versions[b.filter][b.pkg], b.version)
+		}
+	}
+
+	// the catalog templates are expanded as they are when mirroring
+	config.Complete(&cfg)
+	catalogs := make([]CatalogDescription, 0, len(cfg.Mirror.Operators))
+	for _, op := range cfg.Mirror.Operators {
+		filter, err := op.FilterDigest()
+		if err != nil {
+			return nil, err
+		}
+		catalog := CatalogDescription{Catalog: op.Catalog, Packages: []PackageDescription{}}
+		packages := versions[filter]
+		if len(packages) == 0 {
+			// the bundles are unknown, the packages are the ones of the image set configuration
+			for _, pkg := range op.Packages {
+				catalog.Packages = append(catalog.Packages, PackageDescription{Name: pkg.Name, Bundles: []string{}})
+			}
+		}
+		for name, bundleVersions := range packages {
+			sort.Strings(bundleVersions)
+			catalog.Packages = append(catalog.Packages, PackageDescription{Name: name, Bundles: bundleVersions})
+		}
+		sort.Slice(catalog.Packages, func(i, j int) bool { return catalog.Packages[i].Name < catalog.Packages[j].Name })
+		catalogs = append(catalogs, catalog)
+	}
+	return catalogs, nil
+}
+
+// describeHelmCharts returns the helm charts of the image set configuration, as name:version
+func describeHelmCharts(cfg v2alpha1.ImageSetConfiguration) []string {
+	charts := []string{}
+	for _, repository := range cfg.Mirror.Helm.Repositories {
+		for _, chart := range repository.Charts {
+			charts = append(charts, repository.Name+"/"+chartName(chart))
+		}
+	}
+	for _, chart := range cfg.Mirror.Helm.Local {
+		charts = append(charts, chartName(chart))
+	}
+	return charts
+}
+
+func chartName(chart v2alpha1.Chart) string {
+	if chart.Version == "" {
+		return chart.Name
+	}
+	return chart.Name + ":" + chart.Version
+}
+
+func trimTransport(imgRef string) string {
+	return strings.TrimPrefix(imgRef, dockerProtocol)
+}
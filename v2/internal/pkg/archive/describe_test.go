@@ -0,0 +1,158 @@
+package archive
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/config"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/history"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+)
+
+const (
+	describeISC = `kind: ImageSetConfiguration
+apiVersion: mirror.openshift.io/v2alpha1
+mirror:
+  platform:
+    channels:
+    - name: stable-4.16
+  operators:
+  - catalog: registry.redhat.io/redhat/redhat-operator-index:v4.16
+    packages:
+    - name: aws-load-balancer-operator
+  additionalImages:
+  - name: registry.redhat.io/ubi8/ubi:latest
+  helm:
+    repositories:
+    - name: sbo
+      url: https://redhat-developer.github.io/service-binding-operator-helm-chart/
+      charts:
+      - name: pipelines-operator
+        version: 0.1.0
+`
+	describeCatalog = `{"schema":"olm.package","name":"aws-load-balancer-operator"}
+{"schema":"olm.bundle","name":"aws-load-balancer-operator.v1.1.0","package":"aws-load-balancer-operator","image":"registry.redhat.io/albo/aws-load-balancer-operator-bundle@sha256:01","properties":[{"type":"olm.package","value":{"packageName":"aws-load-balancer-operator","version":"1.1.0"}}]}
+{"schema":"olm.bundle","name":"aws-load-balancer-operator.v1.1.1","package":"aws-load-balancer-operator","image":"registry.redhat.io/albo/aws-load-balancer-operator-bundle@sha256:02","properties":[{"type":"olm.package","value":{"packageName":"aws-load-balancer-operator","version":"1.1.1"}}]}
+`
+	releaseBlob = "docker/registry/v2/blobs/sha256/04/0400000000000000000000000000000000000000000000000000000000000000/data"
+	ubiBlob     = "docker/registry/v2/blobs/sha256/05/0500000000000000000000000000000000000000000000000000000000000000/data"
+	bundleBlob  = "docker/registry/v2/blobs/sha256/06/0600000000000000000000000000000000000000000000000000000000000000/data"
+)
+
+func TestArchive_Describe(t *testing.T) {
+	cfg, err := config.LoadConfig[v2alpha1.ImageSetConfiguration]([]byte(describeISC), v2alpha1.ImageSetConfigurationKind)
+	assert.NoError(t, err)
+	filter, err := cfg.Mirror.Operators[0].FilterDigest()
+	assert.NoError(t, err)
+	files := map[string]string{
+		"isc_2024-10-01T10:00:00Z": describeISC,
+		"working-dir/operator-catalogs/redhat-operator-index/8e6b1a0f/filtered-catalogs/" + filter + "/catalog-config/aws-load-balancer-operator/catalog.json": describeCatalog,
+		releaseBlob: "release",
+		ubiBlob:     "ubi",
+		bundleBlob:  "bundle",
+	}
+
+	t.Run("should describe an archive generated without metadata", func(t *testing.T) {
+		archiveDir := t.TempDir()
+		writeTestArchive(t, archiveDir, files)
+		describer, err := NewArchiveDescriber(archiveDir, clog.New("error"))
+		assert.NoError(t, err)
+		description, err := describer.Describe()
+		assert.NoError(t, err)
+
+		assert.False(t, description.HasMetadata)
+		assert.Equal(t, time.Date(2024, 10, 1, 10, 0, 0, 0, time.UTC), description.CreationTime)
+		assert.Equal(t, []string{"mirror_000001.tar"}, description.Chunks)
+		assert.Equal(t, "stable-4.16", description.ImageSetConfiguration.Mirror.Platform.Channels[0].Name)
+		assert.Equal(t, []CatalogDescription{{
+			Catalog:  "registry.redhat.io/redhat/redhat-operator-index:v4.16",
+			Packages: []PackageDescription{{Name: "aws-load-balancer-operator", Bundles: []string{"1.1.0", "1.1.1"}}},
+		}}, description.Catalogs)
+		assert.Equal(t, []string{"sbo/pipelines-operator:0.1.0"}, description.HelmCharts)
+		assert.Equal(t, int64(len("release")+len("ubi")+len("bundle")), description.Sizes.Blobs)
+		assert.Empty(t, description.Sizes.Types)
+	})
+
+	t.Run("should describe the images of an archive from its metadata", func(t *testing.T) {
+		since := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+		metadata := ArchiveMetadata{
+			CreationTime:    time.Date(2024, 10, 1, 10, 0, 5, 0, time.UTC),
+			OcMirrorVersion: "4.17.0",
+			HistoryBaseline: HistoryBaseline{Since: &since, Blobs: 12},
+			Images: []history.RunImage{
+				{Origin: "docker://quay.io/openshift-release-dev/ocp-release:4.16.10-x86_64", Type: "ocpRelease", Blobs: []string{"sha256:0400000000000000000000000000000000000000000000000000000000000000", "sha256:0100000000000000000000000000000000000000000000000000000000000000"}},
+				{Origin: "docker://registry.redhat.io/ubi8/ubi:latest", Type: "generic", Blobs: []string{"sha256:0500000000000000000000000000000000000000000000000000000000000000"}},
+				// only the bundle 1.1.1 is in the archive
+				{Origin: "docker://registry.redhat.io/albo/aws-load-balancer-operator-bundle@sha256:02", Type: "operatorBundle", Blobs: []string{"sha256:0600000000000000000000000000000000000000000000000000000000000000", "sha256:0500000000000000000000000000000000000000000000000000000000000000"}},
+			},
+		}
+		data, err := json.Marshal(metadata)
+		assert.NoError(t, err)
+		files[archiveMetadataName] = string(data)
+		archiveDir := t.TempDir()
+		writeTestArchive(t, archiveDir, files)
+
+		describer, err := NewArchiveDescriber(archiveDir, clog.New("error"))
+		assert.NoError(t, err)
+		description, err := describer.Describe()
+		assert.NoError(t, err)
+
+		assert.True(t, description.HasMetadata)
+		assert.Equal(t, metadata.CreationTime, description.CreationTime)
+		assert.Equal(t, "4.17.0", description.OcMirrorVersion)
+		assert.Equal(t, &metadata.HistoryBaseline, description.HistoryBaseline)
+		assert.Equal(t, []string{"quay.io/openshift-release-dev/ocp-release:4.16.10-x86_64"}, description.Releases)
+		assert.Equal(t, []string{"registry.redhat.io/ubi8/ubi:latest"}, description.AdditionalImages)
+		assert.Equal(t, []PackageDescription{{Name: "aws-load-balancer-operator", Bundles: []string{"1.1.1"}}}, description.Catalogs[0].Packages)
+		// the blobs which are not in the archive (already mirrored) are not counted
+		assert.Equal(t, []TypeSize{
+			{Type: "operatorBundle", Images: 1, Bytes: int64(len("bundle") + len("ubi"))},
+			{Type: "ocpRelease", Images: 1, Bytes: int64(len("release"))},
+			{Type: "generic", Images: 1, Bytes: int64(len("ubi"))},
+		}, description.Sizes.Types)
+	})
+
+	t.Run("should describe the bundles of each catalog of the same repository", func(t *testing.T) {
+		isc := strings.Replace(describeISC, "  additionalImages:", `  - catalog: registry.redhat.io/redhat/redhat-operator-index:v4.15
+    packages:
+    - name: aws-load-balancer-operator
+  additionalImages:`, 1)
+		cfg, err := config.LoadConfig[v2alpha1.ImageSetConfiguration]([]byte(isc), v2alpha1.ImageSetConfigurationKind)
+		assert.NoError(t, err)
+		filter415, err := cfg.Mirror.Operators[1].FilterDigest()
+		assert.NoError(t, err)
+		catalog415 := `{"schema":"olm.package","name":"aws-load-balancer-operator"}
+{"schema":"olm.bundle","name":"aws-load-balancer-operator.v1.0.1","package":"aws-load-balancer-operator","image":"registry.redhat.io/albo/aws-load-balancer-operator-bundle@sha256:00","properties":[{"type":"olm.package","value":{"packageName":"aws-load-balancer-operator","version":"1.0.1"}}]}
+`
+		archiveDir := t.TempDir()
+		writeTestArchive(t, archiveDir, map[string]string{
+			"isc_2024-10-01T10:00:00Z": isc,
+			"working-dir/operator-catalogs/redhat-operator-index/8e6b1a0f/filtered-catalogs/" + filter + "/catalog-config/aws-load-balancer-operator/catalog.json":    describeCatalog,
+			"working-dir/operator-catalogs/redhat-operator-index/4f1c2d3e/filtered-catalogs/" + filter415 + "/catalog-config/aws-load-balancer-operator/catalog.json": catalog415,
+		})
+		describer, err := NewArchiveDescriber(archiveDir, clog.New("error"))
+		assert.NoError(t, err)
+		description, err := describer.Describe()
+		assert.NoError(t, err)
+		assert.Equal(t, []CatalogDescription{
+			{
+				Catalog:  "registry.redhat.io/redhat/redhat-operator-index:v4.16",
+				Packages: []PackageDescription{{Name: "aws-load-balancer-operator", Bundles: []string{"1.1.0", "1.1.1"}}},
+			},
+			{
+				Catalog:  "registry.redhat.io/redhat/redhat-operator-index:v4.15",
+				Packages: []PackageDescription{{Name: "aws-load-balancer-operator", Bundles: []string{"1.0.1"}}},
+			},
+		}, description.Catalogs)
+	})
+
+	t.Run("should fail when there is no archive", func(t *testing.T) {
+		_, err := NewArchiveDescriber(t.TempDir(), clog.New("error"))
+		assert.ErrorContains(t, err, "no archive found in")
+	})
+}
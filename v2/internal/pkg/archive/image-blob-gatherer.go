@@ -21,14 +21,20 @@ func NewImageBlobGatherer(opts *mirror.CopyOptions) BlobsGatherer {
 		opts: opts,
 	}
 }
-func (o *ImageBlobGatherer) GatherBlobs(ctx context.Context, imgRef string) (blobs map[string]string, retErr error) {
-	blobs = map[string]string{}
+func (o *ImageBlobGatherer) GatherBlobs(ctx context.Context, imgRef string) (map[string]string, error) {
+	_, blobs, err := o.GatherImage(ctx, imgRef)
+	return blobs, err
+}
+
+// GatherImage returns the digest of the manifest of imgRef along with the blobs it references
+func (o *ImageBlobGatherer) GatherImage(ctx context.Context, imgRef string) (string, map[string]string, error) {
+	blobs := map[string]string{}
 	o.opts.DeprecatedTLSVerify.WarnIfUsed([]string{"--src-tls-verify", "--dest-tls-verify"})
 	// o.opts.All = true
 	o.opts.RemoveSignatures, _ = strconv.ParseBool("true")
 
 	if err := mirror.ReexecIfNecessaryForImages([]string{imgRef}...); err != nil {
-		return blobs, err
+		return "", blobs, err
 	}
 
 	// TODO should we verify signatures while gathering blobs?
@@ -38,65 +44,65 @@ func (o *ImageBlobGatherer) GatherBlobs(ctx context.Context, imgRef string) (blo
 
 	srcRef, err := alltransports.ParseImageName(imgRef)
 	if err != nil {
-		return nil, fmt.Errorf("invalid source name %s: %v", imgRef, err)
+		return "", nil, fmt.Errorf("invalid source name %s: %v", imgRef, err)
 	}
 	// we are always gathering blobs from the local cache registry - skipping tls verification
 	sourceCtx, err := o.opts.SrcImage.NewSystemContext()
 	sourceCtx.DockerInsecureSkipTLSVerify = types.NewOptionalBool(true)
 	if err != nil {
-		return nil, err
+		return "", nil, err
 	}
 
 	img, err := srcRef.NewImageSource(ctx, sourceCtx)
 	if err != nil {
-		return nil, err
+		return "", nil, err
 	}
 
 	manifestBytes, mime, err := img.GetManifest(ctx, nil)
 	if err != nil {
-		return nil, err
+		return "", nil, err
 	}
 
 	digest, err := manifest.Digest(manifestBytes)
 	if err != nil {
-		return nil, err
+		return "", nil, err
 	}
 	blobs[digest.String()] = ""
 
 	if manifest.MIMETypeIsMultiImage(mime) {
 		manifestList, err := manifest.ListFromBlob(manifestBytes, mime)
 		if err != nil {
-			return nil, err
+			return "", nil, err
 		}
 		instances := manifestList.Instances()
 		for _, digest := range instances {
 			blobs[digest.String()] = ""
 			singleArchManifest, singleArchMime, err := img.GetManifest(ctx, &digest)
 			if err != nil {
-				return nil, err
+				return "", nil, err
 			}
 			singleArchBlobs, err := o.getBlobsOfManifest(singleArchManifest, singleArchMime)
 			if err != nil {
-				return nil, err
+				return "", nil, err
 			}
 			for _, digest := range singleArchBlobs {
 				blobs[digest] = ""
 			}
 			if err != nil {
-				return nil, err
+				return "", nil, err
 			}
 		}
 	} else {
 
 		manifestBlobs, err := o.getBlobsOfManifest(manifestBytes, mime)
 		if err != nil {
-			return nil, err
+			return "", nil, err
 		}
 		for _, digest := range manifestBlobs {
 			blobs[digest] = ""
 		}
 	}
-	return blobs, nil
+	return digest.String(), blobs, nil
 }
 
 func (o *ImageBlobGatherer) getBlobsOfManifest(manifestBytes []byte, mimeType string) ([]string, error) {
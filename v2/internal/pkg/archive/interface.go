@@ -8,6 +8,8 @@ import (
 
 type BlobsGatherer interface {
 	GatherBlobs(ctx context.Context, imgRef string) (map[string]string, error)
+	// GatherImage returns the digest of the manifest of imgRef along with the blobs it references
+	GatherImage(ctx context.Context, imgRef string) (string, map[string]string, error)
 }
 
 type Archiver interface {
@@ -18,8 +20,16 @@ type UnArchiver interface {
 	Unarchive() error
 }
 
+type Merger interface {
+	Merge() error
+}
+
 type archiveAdder interface {
 	addFile(pathToFile string, pathInTar string) error
 	addAllFolder(folderToAdd string, relativeTo string) error
 	close() error
 }
+
+type Describer interface {
+	Describe() (Description, error)
+}
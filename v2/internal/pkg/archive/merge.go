@@ -0,0 +1,390 @@
+package archive
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/config"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/history"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+)
+
+// MirrorArchiveMerger builds a single archive out of several mirror archives,
+// without access to any registry:
+// * blobs and manifests of the caches are added once
+// * the working-dirs (filtered catalogs, signatures, release metadata...) are combined
+// * the histories are merged
+// * the image set configurations are merged
+type MirrorArchiveMerger struct {
+	Merger
+	archivePaths []string
+	destination  string
+	maxSize      int64
+	strict       bool
+	logger       clog.PluggableLoggerInterface
+}
+
+// mergeStaging is where the content of the archives is extracted before being archived again
+type mergeStaging struct {
+	dir        string
+	workingDir string
+	cacheDir   string
+	// historyDirs is the working-dir of each archive, only containing its history
+	historyDirs []string
+	iscPaths    []string
+	// metadataPaths is the metadata of each archive, when it has some
+	metadataPaths []string
+	// resolvedTagsPaths and skippedImageStreamsPaths are the records of each
+	// archive, which are merged instead of overwriting each other
+	resolvedTagsPaths        []string
+	skippedImageStreamsPaths []string
+}
+
+// NewArchiveMerger creates a MirrorArchiveMerger, generating the merged archive
+// and the merged image set configuration in destination.
+// When maxSize is 0, the biggest archiveSize of the image set configurations is used.
+func NewArchiveMerger(archivePaths []string, destination string, maxSize int64, strict bool, logg clog.PluggableLoggerInterface) *MirrorArchiveMerger {
+	return &MirrorArchiveMerger{
+		archivePaths: archivePaths,
+		destination:  destination,
+		maxSize:      maxSize,
+		strict:       strict,
+		logger:       logg,
+	}
+}
+
+// Merge generates the merged archive. The archives are merged in order:
+// when a file of the working-dir is in several archives, the last one wins,
+// except for the resolved tags and the skipped imagestreams which are merged.
+func (o *MirrorArchiveMerger) Merge() error {
+	err := removePastArchives(o.destination)
+	if err != nil {
+		o.logger.Warn("unable to delete past archives from %s: %v", o.destination, err)
+	}
+	if err := os.MkdirAll(o.destination, 0755); err != nil {
+		return fmt.Errorf(errMessageFolder, o.destination, err)
+	}
+	stagingDir, err := os.MkdirTemp(o.destination, ".merge-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(stagingDir)
+
+	staging := mergeStaging{
+		dir:        stagingDir,
+		workingDir: filepath.Join(stagingDir, workingDirectory),
+		cacheDir:   filepath.Join(stagingDir, "cache"),
+	}
+	for i, archivePath := range o.archivePaths {
+		if err := o.extract(i, archivePath, &staging); err != nil {
+			return err
+		}
+	}
+
+	// merge the image set configurations
+	cfgs := make([]v2alpha1.ImageSetConfiguration, 0, len(staging.iscPaths))
+	for _, iscPath := range staging.iscPaths {
+		cfg, err := config.ReadConfig(iscPath, v2alpha1.ImageSetConfigurationKind)
+		if err != nil {
+			return fmt.Errorf("unable to read image set configuration %s: %v", filepath.Base(iscPath), err)
+		}
+		cfgs = append(cfgs, cfg.(v2alpha1.ImageSetConfiguration))
+	}
+	mergedConfig, err := config.Merge(cfgs...)
+	if err != nil {
+		return fmt.Errorf("unable to merge image set configurations: %v", err)
+	}
+	mergedConfigData, err := yaml.Marshal(mergedConfig)
+	if err != nil {
+		return err
+	}
+	// kept next to the archive: it is the configuration to use when mirroring the merged archive
+	mergedConfigPath := filepath.Join(o.destination, mergedConfigName)
+	if err := os.WriteFile(mergedConfigPath, mergedConfigData, 0600); err != nil {
+		return err
+	}
+
+	if err := mergeResolvedTags(staging); err != nil {
+		return fmt.Errorf("unable to merge resolved tags: %v", err)
+	}
+	if err := mergeSkippedImageStreams(staging); err != nil {
+		return fmt.Errorf("unable to merge skipped imagestreams: %v", err)
+	}
+
+	// merge the histories
+	if err := history.Merge(staging.workingDir, staging.historyDirs, o.logger); err != nil {
+		return fmt.Errorf("unable to merge history metadata: %v", err)
+	}
+
+	return o.build(staging, mergedConfigPath, mergedConfig.ArchiveSize)
+}
+
+// extract extracts the chunks of the archive to the staging directory.
+// Blobs already extracted from a previous archive are skipped.
+func (o *MirrorArchiveMerger) extract(index int, archivePath string, staging *mergeStaging) error {
+	chunks, err := archiveChunks(archivePath)
+	if err != nil {
+		return err
+	}
+	if len(chunks) == 0 {
+		return fmt.Errorf("no archive found in %s", archivePath)
+	}
+	o.logger.Info("merging archive %s (%d chunks)", archivePath, len(chunks))
+
+	historyDir := filepath.Join(staging.dir, "history", strconv.Itoa(index), workingDirectory)
+	staging.historyDirs = append(staging.historyDirs, historyDir)
+	skippedBlobs := 0
+	for _, chunkPath := range chunks {
+		skipped, err := extractChunk(index, chunkPath, historyDir, staging)
+		if err != nil {
+			return err
+		}
+		skippedBlobs += skipped
+	}
+	o.logger.Debug("%d blobs of %s were already in the merged archive", skippedBlobs, archivePath)
+	return nil
+}
+
+// extractChunk extracts a chunk of the archive of index to the staging directory,
+// and returns the number of blobs skipped as they were already extracted
+func extractChunk(index int, chunkPath, historyDir string, staging *mergeStaging) (int, error) {
+	chunkFile, err := os.Open(chunkPath)
+	if err != nil {
+		return 0, err
+	}
+	defer chunkFile.Close()
+	skippedBlobs := 0
+	reader := tar.NewReader(chunkFile)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return skippedBlobs, fmt.Errorf("error reading archive %s: %v", chunkFile.Name(), err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		descriptor := ""
+		switch {
+		case strings.HasPrefix(header.Name, imageSetConfigPrefix):
+			descriptor = filepath.Join(staging.dir, "isc", strconv.Itoa(index), header.Name)
+			staging.iscPaths = append(staging.iscPaths, descriptor)
+		case header.Name == archiveMetadataName:
+			descriptor = filepath.Join(staging.dir, "metadata", strconv.Itoa(index), header.Name)
+			staging.metadataPaths = append(staging.metadataPaths, descriptor)
+		case header.Name == resolvedTagsPath:
+			descriptor = filepath.Join(staging.dir, "resolved-tags", strconv.Itoa(index), header.Name)
+			staging.resolvedTagsPaths = append(staging.resolvedTagsPaths, descriptor)
+		case header.Name == skippedImageStreamsPath:
+			descriptor = filepath.Join(staging.dir, "skipped-imagestreams", strconv.Itoa(index), header.Name)
+			staging.skippedImageStreamsPaths = append(staging.skippedImageStreamsPaths, descriptor)
+		case strings.HasPrefix(header.Name, filepath.Join(workingDirectory, ".history")):
+			descriptor = filepath.Join(filepath.Dir(historyDir), header.Name)
+		case strings.HasPrefix(header.Name, filepath.Join(workingDirectory, "logs")):
+			continue
+		case strings.HasPrefix(header.Name, workingDirectory):
+			descriptor = filepath.Join(staging.dir, header.Name)
+		case strings.HasPrefix(header.Name, cacheBlobsDir):
+			descriptor = filepath.Join(staging.cacheDir, header.Name)
+			if _, err := os.Stat(descriptor); err == nil {
+				skippedBlobs++
+				continue
+			}
+		case strings.HasPrefix(header.Name, cacheFilePrefix):
+			descriptor = filepath.Join(staging.cacheDir, header.Name)
+		default:
+			continue
+		}
+
+		if err := extractFile(reader, header, descriptor); err != nil {
+			return skippedBlobs, err
+		}
+	}
+	return skippedBlobs, nil
+}
+
+// build archives the staging directory the same way MirrorArchive does
+func (o *MirrorArchiveMerger) build(staging mergeStaging, iscPath string, archiveSize int64) error {
+	maxSize := o.maxSize
+	if maxSize == 0 {
+		maxSize = archiveSize
+	}
+	if maxSize == 0 {
+		maxSize = defaultSegSize
+	}
+	maxSize = maxSize * segMultiplier
+
+	var adder archiveAdder
+	var err error
+	if o.strict {
+		adder, err = newStrictAdder(maxSize, o.destination, o.logger)
+	} else {
+		adder, err = newPermissiveAdder(maxSize, o.destination, o.logger)
+	}
+	if err != nil {
+		return err
+	}
+	defer adder.close()
+
+	repositoriesDir := filepath.Join(staging.cacheDir, cacheRepositoriesDir)
+	if _, err := os.Stat(repositoriesDir); err == nil {
+		if err := adder.addAllFolder(repositoriesDir, staging.cacheDir); err != nil {
+			return fmt.Errorf("unable to add cache repositories to the archive : %v", err)
+		}
+	}
+	if err := adder.addAllFolder(staging.workingDir, staging.dir); err != nil {
+		return fmt.Errorf("unable to add working-dir to the archive : %v", err)
+	}
+	if err := adder.addFile(iscPath, imageSetConfigPrefix+time.Now().UTC().Format(time.RFC3339)); err != nil {
+		return fmt.Errorf("unable to add image set configuration to the archive : %v", err)
+	}
+	blobsDir := filepath.Join(staging.cacheDir, cacheBlobsDir)
+	if _, err := os.Stat(blobsDir); err == nil {
+		if err := adder.addAllFolder(blobsDir, staging.cacheDir); err != nil {
+			return fmt.Errorf("unable to add image blobs to the archive : %v", err)
+		}
+	}
+	if len(staging.metadataPaths) > 0 {
+		metadata, err := mergeMetadata(staging.metadataPaths)
+		if err != nil {
+			return fmt.Errorf("unable to merge archive metadata: %v", err)
+		}
+		if err := addMetadata(adder, metadata); err != nil {
+			return fmt.Errorf("unable to add archive metadata to the archive : %v", err)
+		}
+	}
+	return nil
+}
+
+// mergeMetadata merges the metadata of the archives: the images are added once,
+// and the history baseline is the one of the first archive, which the others build on
+func mergeMetadata(metadataPaths []string) (ArchiveMetadata, error) {
+	var baseline HistoryBaseline
+	images := []history.RunImage{}
+	seen := map[string]bool{}
+	for i, metadataPath := range metadataPaths {
+		data, err := os.ReadFile(metadataPath)
+		if err != nil {
+			return ArchiveMetadata{}, err
+		}
+		var metadata ArchiveMetadata
+		if err := json.Unmarshal(data, &metadata); err != nil {
+			return ArchiveMetadata{}, fmt.Errorf("%s: %v", metadataPath, err)
+		}
+		if i == 0 {
+			baseline = metadata.HistoryBaseline
+		}
+		for _, img := range metadata.Images {
+			key := img.Origin + "@" + img.Digest
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			images = append(images, img)
+		}
+	}
+	return newArchiveMetadata(baseline, images), nil
+}
+
+// mergeResolvedTags writes to the merged working-dir the tags resolved by all the
+// archives: each image keeps the tags resolved by any of them
+func mergeResolvedTags(staging mergeStaging) error {
+	if len(staging.resolvedTagsPaths) == 0 {
+		return nil
+	}
+	merged := map[string][]string{}
+	for _, resolvedTagsPath := range staging.resolvedTagsPaths {
+		resolved := map[string][]string{}
+		if err := readJSON(resolvedTagsPath, &resolved); err != nil {
+			return err
+		}
+		for img, tags := range resolved {
+			for _, tag := range tags {
+				if !slices.Contains(merged[img], tag) {
+					merged[img] = append(merged[img], tag)
+				}
+			}
+		}
+	}
+	return writeJSON(filepath.Join(staging.dir, resolvedTagsPath), merged)
+}
+
+// mergeSkippedImageStreams writes to the merged working-dir the samples imagestreams
+// skipped by all the archives which mirrored a release: an imagestream selected by
+// one of them is selected by the merged image set configuration
+func mergeSkippedImageStreams(staging mergeStaging) error {
+	if len(staging.skippedImageStreamsPaths) == 0 {
+		return nil
+	}
+	var merged []string
+	for i, skippedPath := range staging.skippedImageStreamsPaths {
+		var skipped []string
+		if err := readJSON(skippedPath, &skipped); err != nil {
+			return err
+		}
+		if i == 0 {
+			merged = skipped
+			continue
+		}
+		merged = slices.DeleteFunc(merged, func(name string) bool {
+			return !slices.Contains(skipped, name)
+		})
+	}
+	if merged == nil {
+		merged = []string{}
+	}
+	return writeJSON(filepath.Join(staging.dir, skippedImageStreamsPath), merged)
+}
+
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	return nil
+}
+
+func writeJSON(path string, v any) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf(errMessageFolder, filepath.Dir(path), err)
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+func extractFile(reader io.Reader, header *tar.Header, descriptor string) error {
+	descriptorParent := filepath.Dir(descriptor)
+	if err := os.MkdirAll(descriptorParent, 0755); err != nil {
+		return fmt.Errorf(errMessageFolder, descriptorParent, err)
+	}
+	f, err := os.OpenFile(descriptor, os.O_CREATE|os.O_RDWR|os.O_TRUNC, os.FileMode(header.Mode)|0755)
+	if err != nil {
+		return fmt.Errorf("unable to create file %s: %v", descriptor, err)
+	}
+	defer f.Close()
+	if _, err := io.Copy(f, reader); err != nil {
+		return fmt.Errorf("error copying file %s: %v", descriptor, err)
+	}
+	return nil
+}
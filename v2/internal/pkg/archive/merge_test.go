@@ -0,0 +1,176 @@
+package archive
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/config"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+)
+
+const (
+	sharedBlob = "docker/registry/v2/blobs/sha256/2e/2e39d55595ea56337b5b788e96e6afdec3db09d2759d903cbe120468187c4644/data"
+	team1Blob  = "docker/registry/v2/blobs/sha256/4c/4c0f6aace7053de3b9c1476b33c9a763e45a099c8c7ae9117773c9a8e5b8506b/data"
+	team2Blob  = "docker/registry/v2/blobs/sha256/53/53c56977ccd20c0d87df0ad52036c55b27201e1a63874c2644383d0c532f5aee/data"
+)
+
+func TestArchive_Merge(t *testing.T) {
+	team1 := t.TempDir()
+	writeTestArchive(t, team1, map[string]string{
+		"isc_2024-10-01T10:00:00Z": "kind: ImageSetConfiguration\napiVersion: mirror.openshift.io/v2alpha1\nmirror:\n  additionalImages:\n  - name: registry.redhat.io/ubi8/ubi:latest\n",
+		"docker/registry/v2/repositories/ubi8/ubi/_manifests/tags/latest/current/link": "sha256:2e39d55595ea56337b5b788e96e6afdec3db09d2759d903cbe120468187c4644",
+		sharedBlob: "shared",
+		team1Blob:  "team1",
+		"working-dir/signatures/4.16.10-x86_64-sha256-signature": "signature",
+		"working-dir/logs/oc-mirror.log":                         "logs",
+		"working-dir/.history/.history-2024-10-01T10:00:00Z":     "sha256:2e39d55595ea56337b5b788e96e6afdec3db09d2759d903cbe120468187c4644\n",
+		archiveMetadataName:                                      `{"historyBaseline":{"blobs":3},"images":[{"origin":"docker://registry.redhat.io/ubi8/ubi:latest","digest":"sha256:2e39d55595ea","type":"generic"}]}`,
+	})
+	team2 := t.TempDir()
+	writeTestArchive(t, team2, map[string]string{
+		"isc_2024-10-02T10:00:00Z": "kind: ImageSetConfiguration\napiVersion: mirror.openshift.io/v2alpha1\narchiveSize: 2\nmirror:\n  additionalImages:\n  - name: registry.redhat.io/ubi8/ubi:latest\n  - name: registry.redhat.io/ubi9/ubi:latest\n",
+		"docker/registry/v2/repositories/ubi9/ubi/_manifests/tags/latest/current/link": "sha256:53c56977ccd20c0d87df0ad52036c55b27201e1a63874c2644383d0c532f5aee",
+		sharedBlob: "shared",
+		team2Blob:  "team2",
+		"working-dir/operator-catalogs/redhat-operator-index/filtered-catalogs/digest": "sha256:f992cb38fce6",
+		archiveMetadataName: `{"historyBaseline":{"blobs":1},"images":[{"origin":"docker://registry.redhat.io/ubi8/ubi:latest","digest":"sha256:2e39d55595ea","type":"generic"},{"origin":"docker://registry.redhat.io/ubi9/ubi:latest","digest":"sha256:53c56977ccd2","type":"generic"}]}`,
+	})
+
+	destination := filepath.Join(t.TempDir(), "merged")
+	merger := NewArchiveMerger([]string{team1, team2}, destination, 0, true, clog.New("trace"))
+	assert.NoError(t, merger.Merge())
+
+	chunks, err := archiveChunks(destination)
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 1)
+	contents := readTestArchive(t, chunks[0])
+
+	assert.Equal(t, 1, contents.count[sharedBlob])
+	assert.Equal(t, "team1", contents.files[team1Blob])
+	assert.Equal(t, "team2", contents.files[team2Blob])
+	assert.Contains(t, contents.files, "docker/registry/v2/repositories/ubi8/ubi/_manifests/tags/latest/current/link")
+	assert.Contains(t, contents.files, "docker/registry/v2/repositories/ubi9/ubi/_manifests/tags/latest/current/link")
+	assert.Contains(t, contents.files, "working-dir/signatures/4.16.10-x86_64-sha256-signature")
+	assert.Contains(t, contents.files, "working-dir/operator-catalogs/redhat-operator-index/filtered-catalogs/digest")
+	assert.NotContains(t, contents.files, "working-dir/.history/.history-2024-10-01T10:00:00Z")
+	historyFiles := 0
+	for name, content := range contents.files {
+		if strings.HasPrefix(name, "working-dir/.history/.history-") {
+			historyFiles++
+			assert.Equal(t, "sha256:2e39d55595ea56337b5b788e96e6afdec3db09d2759d903cbe120468187c4644\n", content)
+		}
+	}
+	assert.Equal(t, 1, historyFiles)
+	assert.NotContains(t, contents.files, "working-dir/logs/oc-mirror.log")
+	assert.Len(t, contents.iscs, 1)
+
+	mergedConfig, err := config.LoadConfig[v2alpha1.ImageSetConfiguration]([]byte(contents.iscs[0]), v2alpha1.ImageSetConfigurationKind)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), mergedConfig.ArchiveSize)
+	assert.Equal(t, []v2alpha1.Image{{Name: "registry.redhat.io/ubi8/ubi:latest"}, {Name: "registry.redhat.io/ubi9/ubi:latest"}}, mergedConfig.Mirror.AdditionalImages)
+
+	var metadata ArchiveMetadata
+	assert.NoError(t, json.Unmarshal([]byte(contents.files[archiveMetadataName]), &metadata))
+	assert.Equal(t, HistoryBaseline{Blobs: 3}, metadata.HistoryBaseline)
+	assert.Len(t, metadata.Images, 2)
+
+	assert.FileExists(t, filepath.Join(destination, mergedConfigName))
+	// the staging directory is removed
+	entries, err := os.ReadDir(destination)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	t.Run("should fail when there is no archive", func(t *testing.T) {
+		merger := NewArchiveMerger([]string{team1, t.TempDir()}, filepath.Join(t.TempDir(), "merged"), 0, true, clog.New("trace"))
+		assert.ErrorContains(t, merger.Merge(), "no archive found in")
+	})
+}
+
+func TestArchive_MergeResolvedTags(t *testing.T) {
+	team1 := t.TempDir()
+	writeTestArchive(t, team1, map[string]string{
+		"isc_2024-10-01T10:00:00Z": "kind: ImageSetConfiguration\napiVersion: mirror.openshift.io/v2alpha1\nmirror:\n  additionalImages:\n  - name: quay.io/team1/app\n    tags:\n      latest: 2\n",
+		resolvedTagsPath:           `{"quay.io/team1/app":["v2","v1"],"quay.io/shared/tools":["v1"]}`,
+		skippedImageStreamsPath:    `["cli","jenkins","ruby"]`,
+	})
+	team2 := t.TempDir()
+	writeTestArchive(t, team2, map[string]string{
+		"isc_2024-10-02T10:00:00Z": "kind: ImageSetConfiguration\napiVersion: mirror.openshift.io/v2alpha1\nmirror:\n  additionalImages:\n  - name: quay.io/team2/app\n    tags:\n      latest: 1\n",
+		resolvedTagsPath:           `{"quay.io/team2/app":["v5"],"quay.io/shared/tools":["v1","v2"]}`,
+		skippedImageStreamsPath:    `["cli","ruby","tools"]`,
+	})
+
+	destination := filepath.Join(t.TempDir(), "merged")
+	merger := NewArchiveMerger([]string{team1, team2}, destination, 0, true, clog.New("trace"))
+	assert.NoError(t, merger.Merge())
+
+	chunks, err := archiveChunks(destination)
+	assert.NoError(t, err)
+	assert.Len(t, chunks, 1)
+	contents := readTestArchive(t, chunks[0])
+
+	var resolved map[string][]string
+	assert.NoError(t, json.Unmarshal([]byte(contents.files[resolvedTagsPath]), &resolved))
+	assert.Equal(t, map[string][]string{
+		"quay.io/team1/app":    {"v2", "v1"},
+		"quay.io/team2/app":    {"v5"},
+		"quay.io/shared/tools": {"v1", "v2"},
+	}, resolved)
+
+	// an imagestream mirrored by one of the archives is no longer skipped
+	var skipped []string
+	assert.NoError(t, json.Unmarshal([]byte(contents.files[skippedImageStreamsPath]), &skipped))
+	assert.Equal(t, []string{"cli", "ruby"}, skipped)
+}
+
+type testArchiveContents struct {
+	files map[string]string
+	count map[string]int
+	iscs  []string
+}
+
+func writeTestArchive(t *testing.T, dir string, files map[string]string) {
+	chunk, err := os.Create(filepath.Join(dir, "mirror_000001.tar"))
+	assert.NoError(t, err)
+	defer chunk.Close()
+	tw := tar.NewWriter(chunk)
+	defer tw.Close()
+	for name, content := range files {
+		assert.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+}
+
+func readTestArchive(t *testing.T, chunkPath string) testArchiveContents {
+	contents := testArchiveContents{files: map[string]string{}, count: map[string]int{}}
+	chunk, err := os.Open(chunkPath)
+	assert.NoError(t, err)
+	defer chunk.Close()
+	reader := tar.NewReader(chunk)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		assert.NoError(t, err)
+		data, err := io.ReadAll(reader)
+		assert.NoError(t, err)
+		if strings.HasPrefix(header.Name, imageSetConfigPrefix) {
+			contents.iscs = append(contents.iscs, string(data))
+			continue
+		}
+		contents.files[header.Name] = string(data)
+		contents.count[header.Name]++
+	}
+	return contents
+}
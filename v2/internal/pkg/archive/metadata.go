@@ -0,0 +1,58 @@
+package archive
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/history"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/version"
+)
+
+// ArchiveMetadata is what an archive records about its own content, so that
+// it can be described without being extracted
+type ArchiveMetadata struct {
+	CreationTime    time.Time          `json:"creationTime"`
+	OcMirrorVersion string             `json:"ocMirrorVersion"`
+	HistoryBaseline HistoryBaseline    `json:"historyBaseline"`
+	Images          []history.RunImage `json:"images"`
+}
+
+// HistoryBaseline is the history the archive was generated against:
+// the blobs it recorded were already mirrored, and are not in the archive
+type HistoryBaseline struct {
+	// Backend is the registry repository of the history, empty for the working-dir
+	Backend string `json:"backend,omitempty"`
+	// Since is the date of the history used, when set with --since
+	Since *time.Time `json:"since,omitempty"`
+	Blobs int        `json:"blobs"`
+}
+
+// newArchiveMetadata returns the metadata of an archive built now, from the images it contains
+func newArchiveMetadata(baseline HistoryBaseline, images []history.RunImage) ArchiveMetadata {
+	return ArchiveMetadata{
+		CreationTime:    time.Now().UTC(),
+		OcMirrorVersion: version.Get().GitVersion,
+		HistoryBaseline: baseline,
+		Images:          images,
+	}
+}
+
+// addMetadata adds the metadata to the archive, at its root
+func addMetadata(adder archiveAdder, metadata ArchiveMetadata) error {
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp("", "archive-metadata-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(data)
+	f.Close()
+	if err != nil {
+		return err
+	}
+	return adder.addFile(f.Name(), archiveMetadataName)
+}
@@ -23,22 +23,33 @@ func NewArchiveExtractor(archivePath, workingDir, cacheDir string) (MirrorUnArch
 		workingDir: workingDir,
 		cacheDir:   cacheDir,
 	}
-	files, err := os.ReadDir(archivePath)
+	archiveFiles, err := archiveChunks(archivePath)
 	if err != nil {
 		return MirrorUnArchiver{}, err
 	}
+	ae.archiveFiles = archiveFiles
+	return ae, nil
+}
+
+// archiveChunks returns the paths of the chunks (mirror_*.tar) of the archive in archivePath
+func archiveChunks(archivePath string) ([]string, error) {
+	files, err := os.ReadDir(archivePath)
+	if err != nil {
+		return nil, err
+	}
 
 	rxp, err := regexp.Compile(archiveFilePrefix + "_[0-9]{6}\\.tar")
 	if err != nil {
-		return MirrorUnArchiver{}, err
+		return nil, err
 	}
+	var archiveFiles []string
 	for _, chunk := range files {
 
 		if rxp.MatchString(chunk.Name()) {
-			ae.archiveFiles = append(ae.archiveFiles, filepath.Join(archivePath, chunk.Name()))
+			archiveFiles = append(archiveFiles, filepath.Join(archivePath, chunk.Name()))
 		}
 	}
-	return ae, nil
+	return archiveFiles, nil
 }
 
 // Unarchive extracts:
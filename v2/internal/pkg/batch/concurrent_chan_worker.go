@@ -146,7 +146,7 @@ func (o *ChannelConcurrentBatch) Worker(ctx context.Context, collectorSchema v2a
 								bundles := collectorSchema.CopyImageSchemaMap.BundlesByImage[img.Origin]
 								result.err = &mirrorErrorSchema{image: img, err: err, operators: operators, bundles: bundles}
 								spinner.Abort(false)
-							case img.Type.IsRelease() || img.Type.IsAdditionalImage() || img.Type.IsHelmImage():
+							case img.Type.IsRelease() || img.Type.IsAdditionalImage() || img.Type.IsHelmImage() || img.Type.IsSampleImage():
 								result.err = &mirrorErrorSchema{image: img, err: err}
 								spinner.Abort(false)
 							}
@@ -354,11 +354,11 @@ func runOverallProgress(overallProgress *mpb.Bar, cancelCtx context.Context, pro
 
 func incrementTotals(imgType v2alpha1.ImageType, copiedImages *v2alpha1.CollectorSchema) {
 	switch imgType {
-	case v2alpha1.TypeCincinnatiGraph, v2alpha1.TypeOCPRelease, v2alpha1.TypeOCPReleaseContent:
+	case v2alpha1.TypeCincinnatiGraph, v2alpha1.TypeOCPRelease, v2alpha1.TypeOCPReleaseContent, v2alpha1.TypeSampleImage:
 		copiedImages.TotalReleaseImages++
 	case v2alpha1.TypeGeneric:
 		copiedImages.TotalAdditionalImages++
-	case v2alpha1.TypeOperatorBundle, v2alpha1.TypeOperatorCatalog, v2alpha1.TypeOperatorRelatedImage:
+	case v2alpha1.TypeOperatorBundle, v2alpha1.TypeOperatorCatalog, v2alpha1.TypeOperatorRelatedImage, v2alpha1.TypeOperatorDiscoveredImage:
 		copiedImages.TotalOperatorImages++
 	case v2alpha1.TypeHelmImage:
 		copiedImages.TotalHelmImages++
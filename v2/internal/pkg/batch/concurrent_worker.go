@@ -127,13 +127,13 @@ func (o *ConcurrentBatch) Worker(ctx context.Context, collectorSchema v2alpha1.C
 					spinner.Increment()
 					var itype string
 					switch img.Type {
-					case v2alpha1.TypeCincinnatiGraph, v2alpha1.TypeOCPRelease, v2alpha1.TypeOCPReleaseContent:
+					case v2alpha1.TypeCincinnatiGraph, v2alpha1.TypeOCPRelease, v2alpha1.TypeOCPReleaseContent, v2alpha1.TypeSampleImage:
 						o.CopiedImages.TotalReleaseImages++
 						itype = "release"
 					case v2alpha1.TypeGeneric:
 						o.CopiedImages.TotalAdditionalImages++
 						itype = "generic"
-					case v2alpha1.TypeOperatorBundle, v2alpha1.TypeOperatorCatalog, v2alpha1.TypeOperatorRelatedImage:
+					case v2alpha1.TypeOperatorBundle, v2alpha1.TypeOperatorCatalog, v2alpha1.TypeOperatorRelatedImage, v2alpha1.TypeOperatorDiscoveredImage:
 						o.CopiedImages.TotalOperatorImages++
 						itype = "operator"
 					case v2alpha1.TypeHelmImage:
@@ -158,7 +158,7 @@ func (o *ConcurrentBatch) Worker(ctx context.Context, collectorSchema v2alpha1.C
 					spinner.Abort(false)
 					mu.Unlock()
 					return NewUnsafeError(currentMirrorError)
-				case img.Type.IsAdditionalImage() || img.Type.IsHelmImage():
+				case img.Type.IsAdditionalImage() || img.Type.IsHelmImage() || img.Type.IsSampleImage():
 					errArray = append(errArray, mirrorErrorSchema{image: img, err: err})
 					spinner.Abort(false)
 					if !opts.Global.IsTerminal {
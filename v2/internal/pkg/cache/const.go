@@ -0,0 +1,10 @@
+package cache
+
+const (
+	cacheErrMsg     string = "[cache] %v"
+	dockerProtocol  string = "docker://"
+	repositoriesDir string = "docker/registry/v2/repositories"
+	blobsDir        string = "docker/registry/v2/blobs"
+	manifestsDir    string = "_manifests"
+	tagsDir         string = "tags"
+)
@@ -0,0 +1,259 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/containers/image/v5/manifest"
+	"github.com/distribution/distribution/v3/registry/storage"
+	"github.com/distribution/distribution/v3/registry/storage/driver/factory"
+	_ "github.com/distribution/distribution/v3/registry/storage/driver/filesystem"
+	digest "github.com/opencontainers/go-digest"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/image"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+)
+
+// LocalStorageCollector garbage collects the local cache (the storage of the local registry),
+// working directly on its filesystem: the local registry must not be running
+type LocalStorageCollector struct {
+	Log              clog.PluggableLoggerInterface
+	LocalStorageDisk string
+}
+
+// Report is what the garbage collection removed, or would remove when DryRun is set
+type Report struct {
+	DryRun              bool     `json:"dryRun"`
+	RemovedRepositories []string `json:"removedRepositories"`
+	RemovedTags         []string `json:"removedTags"`
+	KeptTags            int      `json:"keptTags"`
+	RemovedBlobs        int      `json:"removedBlobs"`
+	FreedBytes          int64    `json:"freedBytes"`
+}
+
+// Collect - the referenced images can be references to any registry (i.e the local cache,
+// or the destination of the mirroring): they are matched on their repository path and tag or digest
+func (o *LocalStorageCollector) Collect(ctx context.Context, referenced []string, dryRun bool) (Report, error) {
+	report := Report{DryRun: dryRun, RemovedRepositories: []string{}, RemovedTags: []string{}}
+
+	keepTags, keepDigests, err := referencedSets(referenced)
+	if err != nil {
+		return report, fmt.Errorf(cacheErrMsg, err)
+	}
+	tags, err := Tags(o.LocalStorageDisk)
+	if err != nil {
+		return report, fmt.Errorf(cacheErrMsg, err)
+	}
+
+	// a repository is removed when none of its tags are referenced
+	keptByRepository := map[string]int{}
+	var kept, removed []Tag
+	for _, tag := range tags {
+		if keepTags[tag.String()] || keepDigests[tag.Repository+"@"+tag.Digest] {
+			kept = append(kept, tag)
+			keptByRepository[tag.Repository]++
+		} else {
+			removed = append(removed, tag)
+		}
+	}
+	removedRepositories := map[string]bool{}
+	for _, tag := range removed {
+		if keptByRepository[tag.Repository] == 0 {
+			removedRepositories[tag.Repository] = true
+		} else {
+			report.RemovedTags = append(report.RemovedTags, tag.String())
+		}
+	}
+	for repository := range removedRepositories {
+		report.RemovedRepositories = append(report.RemovedRepositories, repository)
+	}
+	sort.Strings(report.RemovedRepositories)
+	sort.Strings(report.RemovedTags)
+	report.KeptTags = len(kept)
+
+	blobsBefore, err := o.blobs()
+	if err != nil {
+		return report, fmt.Errorf(cacheErrMsg, err)
+	}
+
+	if dryRun {
+		// the blobs which are not reachable from the kept tags are the ones the garbage collector removes
+		reachable := map[string]bool{}
+		for _, tag := range kept {
+			o.markManifest(tag.Digest, reachable)
+		}
+		for blob, size := range blobsBefore {
+			if !reachable[blob] {
+				report.RemovedBlobs++
+				report.FreedBytes += size
+			}
+		}
+		return report, nil
+	}
+
+	for _, repository := range report.RemovedRepositories {
+		if err := o.removeRepository(repository); err != nil {
+			return report, fmt.Errorf(cacheErrMsg, err)
+		}
+	}
+	for _, tag := range removed {
+		if removedRepositories[tag.Repository] {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(o.LocalStorageDisk, repositoriesDir, tag.Repository, manifestsDir, tagsDir, tag.Name)); err != nil {
+			return report, fmt.Errorf(cacheErrMsg, err)
+		}
+	}
+	if err := o.markAndSweep(ctx); err != nil {
+		return report, fmt.Errorf(cacheErrMsg, err)
+	}
+
+	blobsAfter, err := o.blobs()
+	if err != nil {
+		return report, fmt.Errorf(cacheErrMsg, err)
+	}
+	for blob, size := range blobsBefore {
+		if _, ok := blobsAfter[blob]; !ok {
+			report.RemovedBlobs++
+			report.FreedBytes += size
+		}
+	}
+	return report, nil
+}
+
+// markAndSweep runs the garbage collector of the distribution registry on the local cache,
+// removing the untagged manifests and the blobs that are no longer referenced
+func (o *LocalStorageCollector) markAndSweep(ctx context.Context) error {
+	storageDriver, err := factory.Create(ctx, "filesystem", map[string]interface{}{"rootdirectory": o.LocalStorageDisk})
+	if err != nil {
+		return err
+	}
+	storageReg, err := storage.NewRegistry(ctx, storageDriver)
+	if err != nil {
+		return err
+	}
+	return storage.MarkAndSweep(ctx, storageDriver, storageReg, storage.GCOpts{
+		DryRun:         false,
+		RemoveUntagged: true,
+	})
+}
+
+// removeRepository removes the content of a repository, leaving
+// the repositories nested under its path untouched
+func (o *LocalStorageCollector) removeRepository(repository string) error {
+	repositoryDir := filepath.Join(o.LocalStorageDisk, repositoriesDir, repository)
+	entries, err := os.ReadDir(repositoryDir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), "_") {
+			if err := os.RemoveAll(filepath.Join(repositoryDir, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	// only succeeds when there is no nested repository
+	_ = os.Remove(repositoryDir)
+	return nil
+}
+
+// markManifest marks the manifest and the blobs it references (recursively for an index)
+func (o *LocalStorageCollector) markManifest(manifestDigest string, reachable map[string]bool) {
+	if manifestDigest == "" || reachable[manifestDigest] {
+		return
+	}
+	reachable[manifestDigest] = true
+	data, err := os.ReadFile(o.blobPath(manifestDigest))
+	if err != nil {
+		o.Log.Debug("unable to read manifest %s: %v", manifestDigest, err)
+		return
+	}
+	mimeType := manifest.GuessMIMEType(data)
+	if manifest.MIMETypeIsMultiImage(mimeType) {
+		list, err := manifest.ListFromBlob(data, mimeType)
+		if err != nil {
+			o.Log.Debug("unable to parse manifest list %s: %v", manifestDigest, err)
+			return
+		}
+		for _, instance := range list.Instances() {
+			o.markManifest(instance.String(), reachable)
+		}
+		return
+	}
+	single, err := manifest.FromBlob(data, mimeType)
+	if err != nil {
+		o.Log.Debug("unable to parse manifest %s: %v", manifestDigest, err)
+		return
+	}
+	for _, layer := range single.LayerInfos() {
+		reachable[layer.Digest.String()] = true
+	}
+	reachable[single.ConfigInfo().Digest.String()] = true
+}
+
+// blobs returns the blobs of the local cache along with their size
+func (o *LocalStorageCollector) blobs() (map[string]int64, error) {
+	blobs := map[string]int64{}
+	root := filepath.Join(o.LocalStorageDisk, blobsDir)
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		return blobs, nil
+	}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || d.Name() != "data" {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		// blobs/<algorithm>/<2 first chars>/<encoded>/data
+		encoded := filepath.Base(filepath.Dir(path))
+		algorithm := filepath.Base(filepath.Dir(filepath.Dir(filepath.Dir(path))))
+		blobs[algorithm+":"+encoded] = info.Size()
+		return nil
+	})
+	return blobs, err
+}
+
+func (o *LocalStorageCollector) blobPath(blob string) string {
+	d := digest.Digest(blob)
+	encoded := d.Encoded()
+	if len(encoded) < 2 {
+		return ""
+	}
+	return filepath.Join(o.LocalStorageDisk, blobsDir, d.Algorithm().String(), encoded[:2], encoded, "data")
+}
+
+// referencedSets returns the referenced tags (repository:tag) and digests (repository@digest),
+// without the registry of the references
+func referencedSets(referenced []string) (map[string]bool, map[string]bool, error) {
+	tags := map[string]bool{}
+	digests := map[string]bool{}
+	for _, ref := range referenced {
+		if !strings.Contains(ref, "://") {
+			ref = dockerProtocol + ref
+		}
+		spec, err := image.ParseRef(ref)
+		if err != nil {
+			return nil, nil, err
+		}
+		if spec.Tag != "" {
+			tags[spec.PathComponent+":"+spec.Tag] = true
+		}
+		if spec.Digest != "" {
+			digests[spec.PathComponent+"@"+spec.Algorithm+":"+spec.Digest] = true
+			// images mirrored by digest are tagged <algorithm>-<digest> in the cache
+			tags[spec.PathComponent+":"+spec.Algorithm+"-"+spec.Digest] = true
+		}
+	}
+	return tags, digests, nil
+}
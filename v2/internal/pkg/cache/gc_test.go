@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+)
+
+// fakeCache writes blobs and tagged manifests in the layout of the local registry storage
+type fakeCache struct {
+	t    *testing.T
+	root string
+}
+
+func (o fakeCache) blob(data []byte) string {
+	blob := fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+	encoded := blob[len("sha256:"):]
+	dir := filepath.Join(o.root, blobsDir, "sha256", encoded[:2], encoded)
+	assert.NoError(o.t, os.MkdirAll(dir, 0755))
+	assert.NoError(o.t, os.WriteFile(filepath.Join(dir, "data"), data, 0600))
+	return blob
+}
+
+// image writes a manifest referencing a config and layers of the given sizes, and tags it
+func (o fakeCache) image(repository, tag string, layers ...[]byte) string {
+	config := o.blob([]byte(`{"architecture":"amd64","os":"linux"}`))
+	type descriptor struct {
+		MediaType string `json:"mediaType"`
+		Digest    string `json:"digest"`
+		Size      int    `json:"size"`
+	}
+	manifest := struct {
+		SchemaVersion int          `json:"schemaVersion"`
+		MediaType     string       `json:"mediaType"`
+		Config        descriptor   `json:"config"`
+		Layers        []descriptor `json:"layers"`
+	}{
+		SchemaVersion: 2,
+		MediaType:     "application/vnd.oci.image.manifest.v1+json",
+		Config:        descriptor{MediaType: "application/vnd.oci.image.config.v1+json", Digest: config, Size: 38},
+	}
+	for _, layer := range layers {
+		manifest.Layers = append(manifest.Layers, descriptor{MediaType: "application/vnd.oci.image.layer.v1.tar+gzip", Digest: o.blob(layer), Size: len(layer)})
+	}
+	data, err := json.Marshal(manifest)
+	assert.NoError(o.t, err)
+	manifestDigest := o.blob(data)
+
+	tagDir := filepath.Join(o.root, repositoriesDir, repository, manifestsDir, tagsDir, tag, "current")
+	assert.NoError(o.t, os.MkdirAll(tagDir, 0755))
+	assert.NoError(o.t, os.WriteFile(filepath.Join(tagDir, "link"), []byte(manifestDigest), 0600))
+	return manifestDigest
+}
+
+func TestCollect(t *testing.T) {
+	cacheDir := t.TempDir()
+	fc := fakeCache{t: t, root: cacheDir}
+
+	base := make([]byte, 4*1024)
+	ubi89 := fc.image("ubi8/ubi", "8.9", base, make([]byte, 1024))
+	fc.image("ubi8/ubi", "8.10", base, make([]byte, 2*1024))
+	postgresql := fc.image("rhel9/postgresql-15", "sha256-0000000000000000000000000000000000000000000000000000000000000001", base, make([]byte, 3*1024))
+
+	gc := New(clog.New("error"), cacheDir)
+
+	t.Run("Testing Tags : should return the tagged manifests of the cache", func(t *testing.T) {
+		tags, err := Tags(cacheDir)
+		assert.NoError(t, err)
+		assert.Len(t, tags, 3)
+		assert.Contains(t, tags, Tag{Repository: "ubi8/ubi", Name: "8.9", Digest: ubi89})
+		assert.Contains(t, tags, Tag{Repository: "rhel9/postgresql-15", Name: "sha256-0000000000000000000000000000000000000000000000000000000000000001", Digest: postgresql})
+	})
+
+	t.Run("Testing Collect : should estimate the blobs to remove in dry-run", func(t *testing.T) {
+		report, err := gc.Collect(context.Background(), []string{"docker://mirror.acme.com/ubi8/ubi:8.9"}, true)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"rhel9/postgresql-15"}, report.RemovedRepositories)
+		assert.Equal(t, []string{"ubi8/ubi:8.10"}, report.RemovedTags)
+		assert.Equal(t, 1, report.KeptTags)
+		// the 2 manifests and their layer that is not shared with ubi 8.9
+		assert.Equal(t, 4, report.RemovedBlobs)
+		assert.Equal(t, int64(5*1024), report.FreedBytes-manifestSizes(t, cacheDir, report))
+
+		// nothing was removed
+		tags, err := Tags(cacheDir)
+		assert.NoError(t, err)
+		assert.Len(t, tags, 3)
+	})
+
+	t.Run("Testing Collect : should keep the images referenced by digest", func(t *testing.T) {
+		report, err := gc.Collect(context.Background(), []string{
+			"localhost:55000/ubi8/ubi:8.9",
+			"localhost:55000/ubi8/ubi:8.10",
+			"localhost:55000/rhel9/postgresql-15@sha256:0000000000000000000000000000000000000000000000000000000000000001",
+		}, true)
+		assert.NoError(t, err)
+		assert.Empty(t, report.RemovedRepositories)
+		assert.Empty(t, report.RemovedTags)
+		assert.Equal(t, 3, report.KeptTags)
+		assert.Equal(t, 0, report.RemovedBlobs)
+	})
+
+	t.Run("Testing Collect : should fail on an invalid reference", func(t *testing.T) {
+		_, err := gc.Collect(context.Background(), []string{"docker://mirror.acme.com/ubi8/ubi@sha256:123"}, true)
+		assert.Error(t, err)
+	})
+}
+
+// manifestSizes returns the size of the manifests of the removed tags and repositories
+func manifestSizes(t *testing.T, cacheDir string, report Report) int64 {
+	removed := map[string]bool{}
+	for _, repository := range report.RemovedRepositories {
+		removed[repository] = true
+	}
+	for _, tag := range report.RemovedTags {
+		removed[tag] = true
+	}
+	tags, err := Tags(cacheDir)
+	assert.NoError(t, err)
+	var size int64
+	for _, tag := range tags {
+		if removed[tag.Repository] || removed[tag.String()] {
+			encoded := tag.Digest[len("sha256:"):]
+			info, err := os.Stat(filepath.Join(cacheDir, blobsDir, "sha256", encoded[:2], encoded, "data"))
+			assert.NoError(t, err)
+			size += info.Size()
+		}
+	}
+	return size
+}
+
+func TestHumanBytes(t *testing.T) {
+	assert.Equal(t, "512 B", HumanBytes(512))
+	assert.Equal(t, "1.5 KiB", HumanBytes(1536))
+	assert.Equal(t, "2.0 GiB", HumanBytes(2*1024*1024*1024))
+}
@@ -0,0 +1,10 @@
+package cache
+
+import "context"
+
+type GarbageCollector interface {
+	// Collect removes the repositories and tags of the local cache which are not
+	// referenced, and the blobs that are no longer used. With dryRun, nothing is removed
+	// and the report estimates what would be.
+	Collect(ctx context.Context, referenced []string, dryRun bool) (Report, error)
+}
@@ -0,0 +1,12 @@
+package cache
+
+import (
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+)
+
+func New(log clog.PluggableLoggerInterface, localStorageDisk string) GarbageCollector {
+	return &LocalStorageCollector{
+		Log:              log,
+		LocalStorageDisk: localStorageDisk,
+	}
+}
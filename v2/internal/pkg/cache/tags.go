@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Tag is a tagged manifest of the local cache
+type Tag struct {
+	Repository string
+	Name       string
+	// Digest of the manifest currently tagged
+	Digest string
+}
+
+// String returns the reference of the tag, without the registry
+func (t Tag) String() string {
+	return t.Repository + ":" + t.Name
+}
+
+// Tags returns the tagged manifests of the local cache stored under localStorageDisk
+func Tags(localStorageDisk string) ([]Tag, error) {
+	root := filepath.Join(localStorageDisk, repositoriesDir)
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		return nil, nil
+	}
+	tags := []Tag{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		// the content of a repository is under _manifests, _layers and _uploads
+		if !d.IsDir() || !strings.HasPrefix(d.Name(), "_") {
+			return nil
+		}
+		if d.Name() != manifestsDir {
+			return filepath.SkipDir
+		}
+		repository, err := filepath.Rel(root, filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		entries, err := os.ReadDir(filepath.Join(path, tagsDir))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		for _, entry := range entries {
+			link, err := os.ReadFile(filepath.Join(path, tagsDir, entry.Name(), "current", "link"))
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			tags = append(tags, Tag{
+				Repository: filepath.ToSlash(repository),
+				Name:       entry.Name(),
+				Digest:     strings.TrimSpace(string(link)),
+			})
+		}
+		return filepath.SkipDir
+	})
+	return tags, err
+}
@@ -0,0 +1,495 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/otiai10/copy"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/additional"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/archive"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/config"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/emoji"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/helm"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/image"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/imagebuilder"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/manifest"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/operator"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/release"
+)
+
+const (
+	archiveErrMsg                  = "[archive] %v"
+	historyDir                     = ".history"
+	operatorCatalogFilteredDir     = "filtered-catalogs"
+	operatorCatalogLastFilteredDir = "last-filtered"
+	operatorCatalogDiffDir         = "catalog-diffs"
+)
+
+// Enclave is the name of an enclave and the ImageSetConfiguration of its content
+type Enclave struct {
+	Name       string
+	ConfigPath string
+}
+
+// ArchiveSchema holds what the archive sub commands need
+// in order to work on mirror archives, without access to any registry
+type ArchiveSchema struct {
+	ExecutorSchema
+	EnclaveFlags []string
+	Enclaves     []Enclave
+	Sources      []string
+	ArchiveSize  int64
+	OutputDir    string
+}
+
+// NewArchiveCommand - setup the 'archive' sub command, working on
+// existing mirror archives (or unpacked caches)
+func NewArchiveCommand(log clog.PluggableLoggerInterface, opts *mirror.CopyOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "archive",
+		Short: "Work on mirror archives generated by the mirrorToDisk workflow, without access to any registry",
+	}
+	cmd.AddCommand(newArchiveSplitCommand(log, opts))
+	cmd.AddCommand(newArchiveMergeCommand(log, opts))
+	return cmd
+}
+
+func newArchiveSplitCommand(log clog.PluggableLoggerInterface, opts *mirror.CopyOptions) *cobra.Command {
+	ex := &ArchiveSchema{
+		ExecutorSchema: ExecutorSchema{
+			Log:     log,
+			Opts:    opts,
+			MakeDir: MakeDir{},
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:   "split <file://output-dir>",
+		Short: "Split a mirror archive into one archive per enclave, containing only the images of the enclave",
+		Example: `  # Split the archive generated by mirrorToDisk in /disk-central into 2 enclave archives,
+  # generated under /disk-enclaves/enclave1 and /disk-enclaves/enclave2
+  oc-mirror archive split --from file:///disk-central --enclave enclave1=isc-enclave1.yaml --enclave enclave2=isc-enclave2.yaml file:///disk-enclaves --v2
+
+  # Same, from the cache and workspace of a previous mirrorToDisk (no need to extract the archive)
+  oc-mirror archive split --workspace file:///disk-central --enclave enclave1=isc-enclave1.yaml file:///disk-enclaves --v2`,
+		Args: cobra.ExactArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			opts.Function = string(mirror.CopyMode)
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			err := ex.ValidateSplit(args)
+			if err != nil {
+				log.Error("%v ", err)
+				os.Exit(1)
+			}
+			err = ex.CompleteSplit(args)
+			if err != nil {
+				log.Error("%v ", err)
+				os.Exit(1)
+			}
+			defer ex.logFile.Close()
+			cmd.SetOutput(ex.logFile)
+
+			// prepare internal storage
+			err = ex.setupLocalStorage(cmd.Context())
+			if err != nil {
+				log.Error(" %v ", err)
+				os.Exit(1)
+			}
+
+			err = ex.RunSplit(cmd.Context())
+			if err != nil {
+				log.Error("%v ", err)
+				os.Exit(1)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&opts.Global.From, "from", "", "Directory containing the mirror archive to split (generated by the mirrorToDisk workflow)")
+	cmd.Flags().StringArrayVar(&ex.EnclaveFlags, "enclave", nil, "Enclave name and path to its imageset configuration (name=path), can be repeated")
+	cmd.Flags().BoolVar(&opts.Global.StrictArchiving, "strict-archive", false, "If set, generates archives that are strictly less than archiveSize (set in the imageSetConfig of each enclave)")
+	return cmd
+}
+
+func newArchiveMergeCommand(log clog.PluggableLoggerInterface, opts *mirror.CopyOptions) *cobra.Command {
+	ex := &ArchiveSchema{
+		ExecutorSchema: ExecutorSchema{
+			Log:  log,
+			Opts: opts,
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:   "merge <file://output-dir>",
+		Short: "Merge several mirror archives into a single archive, that can be mirrored with diskToMirror",
+		Example: `  # Merge the archives generated by 2 mirrorToDisk in /disk-team1 and /disk-team2 into /disk-merged
+  oc-mirror archive merge --from file:///disk-team1 --from file:///disk-team2 file:///disk-merged --v2
+
+  # Mirror the merged archive, using the merged imageset configuration it contains
+  oc-mirror -c /disk-merged/isc-merged.yaml --from file:///disk-merged docker://registry.example.com --v2`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			err := ex.ValidateMerge(args)
+			if err != nil {
+				log.Error("%v ", err)
+				os.Exit(1)
+			}
+			log.Level(opts.Global.LogLevel)
+			err = ex.RunMerge()
+			if err != nil {
+				log.Error("%v ", err)
+				os.Exit(1)
+			}
+		},
+	}
+	cmd.Flags().StringArrayVar(&ex.Sources, "from", nil, "Directory containing a mirror archive to merge (generated by the mirrorToDisk workflow), can be repeated")
+	cmd.Flags().Int64Var(&ex.ArchiveSize, "archive-size", 0, "Size in GB of the chunks of the merged archive (defaults to the biggest archiveSize of the merged imageset configurations)")
+	cmd.Flags().BoolVar(&opts.Global.StrictArchiving, "strict-archive", false, "If set, generates archives that are strictly less than the archive size")
+	return cmd
+}
+
+// ValidateMerge - cobra validation
+func (o *ArchiveSchema) ValidateMerge(args []string) error {
+	if !strings.HasPrefix(args[0], fileProtocol) {
+		return fmt.Errorf("the output directory must have file:// prefix")
+	}
+	if len(o.Sources) < 2 {
+		return fmt.Errorf("use the --from flag at least twice, to set the archives to merge")
+	}
+	if o.ArchiveSize < 0 {
+		return fmt.Errorf("archive-size must be a positive number of GB")
+	}
+	outputDir := filepath.Clean(strings.TrimPrefix(args[0], fileProtocol))
+	for _, source := range o.Sources {
+		if !strings.HasPrefix(source, fileProtocol) {
+			return fmt.Errorf("the archives to merge must have file:// prefix: %s", source)
+		}
+		if filepath.Clean(strings.TrimPrefix(source, fileProtocol)) == outputDir {
+			return fmt.Errorf("the output directory must be different from the archives to merge: %s", source)
+		}
+	}
+	o.OutputDir = outputDir
+	return nil
+}
+
+// RunMerge - generates the merged archive
+func (o *ArchiveSchema) RunMerge() error {
+	startTime := time.Now()
+	archivePaths := make([]string, 0, len(o.Sources))
+	for _, source := range o.Sources {
+		archivePaths = append(archivePaths, strings.TrimPrefix(source, fileProtocol))
+	}
+
+	o.Log.Info(emoji.Package+" Merging %d archives into %s...", len(archivePaths), o.OutputDir)
+	merger := archive.NewArchiveMerger(archivePaths, o.OutputDir, o.ArchiveSize, o.Opts.Global.StrictArchiving, o.Log)
+	if err := merger.Merge(); err != nil {
+		return fmt.Errorf(archiveErrMsg, err)
+	}
+
+	o.Log.Info("merge time     : %v", time.Since(startTime))
+	o.Log.Info(emoji.WavingHandSign + " Goodbye, thank you for using oc-mirror")
+	return nil
+}
+
+// ValidateSplit - cobra validation
+func (o *ArchiveSchema) ValidateSplit(args []string) error {
+	if !strings.HasPrefix(args[0], fileProtocol) {
+		return fmt.Errorf("the output directory must have file:// prefix")
+	}
+	if (o.Opts.Global.From == "") == (o.Opts.Global.WorkingDir == "") {
+		return fmt.Errorf("exactly one of --from (mirror archive) or --workspace (mirrorToDisk destination, using the cache) is required")
+	}
+	if o.Opts.Global.From != "" && !strings.HasPrefix(o.Opts.Global.From, fileProtocol) {
+		return fmt.Errorf("when --from is used, it must have file:// prefix")
+	}
+	if len(o.EnclaveFlags) == 0 {
+		return fmt.Errorf("use the --enclave flag, at least one enclave is required")
+	}
+
+	o.Enclaves = nil
+	seen := map[string]bool{}
+	for _, flag := range o.EnclaveFlags {
+		name, configPath, ok := strings.Cut(flag, "=")
+		if !ok || name == "" || configPath == "" {
+			return fmt.Errorf("invalid --enclave %s: it should be name=path-to-imageset-config", flag)
+		}
+		if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+			return fmt.Errorf("invalid enclave name %s: %s", name, strings.Join(errs, ", "))
+		}
+		if seen[name] {
+			return fmt.Errorf("enclave %s is set more than once", name)
+		}
+		seen[name] = true
+		if _, err := os.Stat(configPath); err != nil {
+			return fmt.Errorf("imageset configuration of enclave %s: %v", name, err)
+		}
+		o.Enclaves = append(o.Enclaves, Enclave{Name: name, ConfigPath: configPath})
+	}
+	return nil
+}
+
+// CompleteSplit - do the final setup of modules
+func (o *ArchiveSchema) CompleteSplit(args []string) error {
+	if envOverride, ok := os.LookupEnv("CONTAINERS_REGISTRIES_CONF"); ok {
+		o.Opts.Global.RegistriesConfPath = envOverride
+	}
+
+	// the collectors read the metadata of the working-dir and the images of the cache,
+	// as they do when mirroring an archive to a registry
+	o.Opts.Mode = mirror.DiskToMirror
+	// source is the local cache, which is HTTP
+	o.Opts.SrcImage.TlsVerify = false
+	o.Opts.MultiArch = "all"
+	o.Opts.RemoveSignatures = true
+	o.OutputDir = strings.TrimPrefix(args[0], fileProtocol)
+
+	rootDir := strings.TrimPrefix(o.Opts.Global.From, fileProtocol)
+	if o.Opts.Global.From == "" {
+		rootDir = strings.TrimPrefix(o.Opts.Global.WorkingDir, fileProtocol)
+	}
+	o.Opts.Global.WorkingDir = rootDir
+	if filepath.Base(o.Opts.Global.WorkingDir) != workingDir {
+		o.Opts.Global.WorkingDir = filepath.Join(o.Opts.Global.WorkingDir, workingDir)
+	}
+
+	err := o.setupLogsLevelAndDir()
+	if err != nil {
+		return err
+	}
+	o.Log.Info(emoji.TwistedRighwardsArrows+" workflow mode: %s / split", o.Opts.Mode)
+
+	if o.isLocalStoragePortBound() {
+		return fmt.Errorf("%d is already bound and cannot be used", o.Opts.Global.Port)
+	}
+	o.Opts.LocalStorageFQDN = "localhost:" + strconv.Itoa(int(o.Opts.Global.Port))
+	// the destination of the collected images is the cache: it is where the archives are built from
+	o.Opts.Destination = dockerProtocol + o.Opts.LocalStorageFQDN
+	o.Opts.DestImage.TlsVerify = false
+
+	err = o.setupLocalStorageDir()
+	if err != nil {
+		return err
+	}
+
+	o.Manifest = manifest.New(o.Log)
+	o.Mirror = mirror.New(mirror.NewMirrorCopy(), nil)
+	o.CatalogBuilder = imagebuilder.NewGCRCatalogBuilder(o.Log, *o.Opts)
+
+	if o.Opts.Global.From != "" {
+		o.MirrorUnArchiver, err = archive.NewArchiveExtractor(rootDir, o.Opts.Global.WorkingDir, o.LocalStorageDisk)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunSplit - generates the archive of each enclave
+func (o *ArchiveSchema) RunSplit(ctx context.Context) error {
+	startTime := time.Now()
+
+	go o.startLocalRegistry()
+	defer o.stopLocalRegistry(ctx)
+
+	if o.MirrorUnArchiver != nil {
+		if err := o.MirrorUnArchiver.Unarchive(); err != nil {
+			return err
+		}
+	}
+
+	for _, enclave := range o.Enclaves {
+		o.Log.Info(emoji.Package+" Preparing the archive of enclave %s...", enclave.Name)
+		if err := o.splitEnclave(ctx, enclave); err != nil {
+			return fmt.Errorf(archiveErrMsg, fmt.Errorf("enclave %s: %w", enclave.Name, err))
+		}
+	}
+
+	o.Log.Info("split time     : %v", time.Since(startTime))
+	o.Log.Info(emoji.WavingHandSign + " Goodbye, thank you for using oc-mirror")
+	return nil
+}
+
+// splitEnclave collects the images of the enclave from the cache, and builds its archive
+// with a working-dir (and history) of its own, under the output directory
+func (o *ArchiveSchema) splitEnclave(ctx context.Context, enclave Enclave) error {
+	cfg, err := config.ReadConfig(enclave.ConfigPath, v2alpha1.ImageSetConfigurationKind)
+	if err != nil {
+		return err
+	}
+	o.Config = cfg.(v2alpha1.ImageSetConfiguration)
+
+	client, _ := release.NewOCPClient(uuid.New(), o.Log)
+	signature := release.NewSignatureClient(o.Log, o.Config, *o.Opts)
+	cn := release.NewCincinnati(o.Log, &o.Config, *o.Opts, client, false, signature)
+	o.Release = release.New(o.Log, o.LogsDir, o.Config, *o.Opts, o.Mirror, o.Manifest, cn, nil)
+	o.Operator = operator.NewWithFilter(o.Log, o.LogsDir, o.Config, *o.Opts, o.Mirror, o.Manifest)
+	o.AdditionalImages = additional.New(o.Log, o.Config, *o.Opts, o.Mirror, o.Manifest)
+	o.HelmCollector = helm.New(o.Log, o.Config, *o.Opts, nil, nil, &http.Client{Timeout: time.Duration(5) * time.Second})
+
+	collectorSchema, err := o.CollectAll(ctx)
+	if err != nil {
+		return err
+	}
+	// the catalogs filtered differently from the mirrorToDisk configuration are rebuilt
+	// from the catalog images of the working-dir, and pushed to the cache
+	if err := o.rebuildCatalogs(ctx, collectorSchema); err != nil {
+		return err
+	}
+
+	enclaveDir := filepath.Join(o.OutputDir, enclave.Name)
+	enclaveWorkingDir := filepath.Join(enclaveDir, workingDir)
+	if err := copyWorkingDir(o.Opts.Global.WorkingDir, enclaveWorkingDir, newEnclaveContent(collectorSchema)); err != nil {
+		return err
+	}
+
+	var archiver archive.Archiver
+	if o.Opts.Global.StrictArchiving {
+		archiver, err = archive.NewMirrorArchive(o.Opts, enclaveDir, enclave.ConfigPath, enclaveWorkingDir, o.LocalStorageDisk, o.Config.ImageSetConfigurationSpec.ArchiveSize, o.Log)
+	} else {
+		archiver, err = archive.NewPermissiveMirrorArchive(o.Opts, enclaveDir, enclave.ConfigPath, enclaveWorkingDir, o.LocalStorageDisk, o.Config.ImageSetConfigurationSpec.ArchiveSize, o.Log)
+	}
+	if err != nil {
+		return err
+	}
+	return archiver.BuildArchive(ctx, cachedImages(collectorSchema.AllImages))
+}
+
+// cachedImages returns the images as they are stored in the cache, which is
+// what the archive is built from (the source of the images in diskToMirror)
+func cachedImages(images []v2alpha1.CopyImageSchema) []v2alpha1.CopyImageSchema {
+	cached := make([]v2alpha1.CopyImageSchema, 0, len(images))
+	for _, img := range images {
+		img.Destination = img.Source
+		cached = append(cached, img)
+	}
+	return cached
+}
+
+// enclaveContent is what the working-dir of an enclave keeps from the working-dir it is split from:
+// the catalogs (and their filtering) and the releases of the images collected for the enclave
+type enclaveContent struct {
+	// catalogDirs holds the catalog directories (operator-catalogs/<name>/<catalog digest>)
+	catalogDirs map[string]struct{}
+	// filters holds the digests of the operator filters of the catalogs
+	filters map[string]struct{}
+	// releaseDirs holds the release directories (<repository>/<tag>) of release-images and hold-release
+	releaseDirs map[string]struct{}
+	// releaseTags holds the tags and digests of the releases, the release signatures are named after
+	releaseTags map[string]struct{}
+}
+
+func newEnclaveContent(collectorSchema v2alpha1.CollectorSchema) enclaveContent {
+	content := enclaveContent{
+		catalogDirs: map[string]struct{}{},
+		filters:     map[string]struct{}{},
+		releaseDirs: map[string]struct{}{},
+		releaseTags: map[string]struct{}{},
+	}
+	for _, result := range collectorSchema.CatalogToFBCMap {
+		if result.CatalogDir != "" {
+			content.catalogDirs[filepath.Join(filepath.Base(filepath.Dir(result.CatalogDir)), filepath.Base(result.CatalogDir))] = struct{}{}
+		}
+		if filterDigest, err := result.OperatorFilter.FilterDigest(); err == nil {
+			content.filters[filterDigest] = struct{}{}
+		}
+	}
+	for _, img := range collectorSchema.AllImages {
+		if img.Type != v2alpha1.TypeOCPRelease {
+			continue
+		}
+		// same layout as the release collector: the last path component of the release, with the tag as a sub directory
+		hld := strings.Split(img.Origin, "/")
+		content.releaseDirs[strings.Replace(hld[len(hld)-1], ":", "/", -1)] = struct{}{}
+		if imgSpec, err := image.ParseRef(img.Origin); err == nil {
+			if imgSpec.Tag != "" {
+				content.releaseTags[imgSpec.Tag] = struct{}{}
+			}
+			if imgSpec.Digest != "" {
+				content.releaseTags[imgSpec.Digest] = struct{}{}
+			}
+		}
+	}
+	return content
+}
+
+// skip tells whether the path, relative to the working-dir, is left out of the working-dir of the enclave
+func (c enclaveContent) skip(rel string) bool {
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	switch parts[0] {
+	case historyDir, logsDir, clusterResourcesDir:
+		return true
+	case operatorCatalogsDir:
+		return c.skipCatalog(parts[1:])
+	case releaseImageDir, releaseImageExtractDir:
+		// the graph data is not specific to a release
+		if len(parts) < 3 || parts[1] == cincinnatiGraphDataDir {
+			return false
+		}
+		_, ok := c.releaseDirs[parts[1]+"/"+parts[2]]
+		return !ok
+	case signaturesDir:
+		// signatures are named <tag>-sha256-<digest>
+		if len(parts) < 2 {
+			return false
+		}
+		tag, digest, _ := strings.Cut(parts[1], "-sha256-")
+		_, tagOk := c.releaseTags[tag]
+		_, digestOk := c.releaseTags[digest]
+		return !tagOk && !digestOk
+	}
+	return false
+}
+
+// skipCatalog tells whether the path, relative to operator-catalogs, belongs to another catalog or filter
+func (c enclaveContent) skipCatalog(parts []string) bool {
+	if len(parts) < 2 {
+		return false
+	}
+	if parts[1] == operatorCatalogLastFilteredDir {
+		return len(parts) > 2 && !c.hasFilter(parts[2])
+	}
+	if _, ok := c.catalogDirs[parts[0]+"/"+parts[1]]; !ok {
+		return true
+	}
+	if len(parts) < 4 {
+		return false
+	}
+	switch parts[2] {
+	case operatorCatalogFilteredDir:
+		return !c.hasFilter(parts[3])
+	case operatorCatalogDiffDir:
+		return !c.hasFilter(strings.TrimSuffix(parts[3], filepath.Ext(parts[3])))
+	}
+	return false
+}
+
+func (c enclaveContent) hasFilter(filterDigest string) bool {
+	_, ok := c.filters[filterDigest]
+	return ok
+}
+
+// copyWorkingDir copies to the working-dir of an enclave the metadata of the working-dir
+// (releases, catalogs, charts, signatures) its content needs. The history and logs are specific to each working-dir.
+func copyWorkingDir(src, dest string, content enclaveContent) error {
+	return copy.Copy(src, dest, copy.Options{
+		Skip: func(info os.FileInfo, srcPath, destPath string) (bool, error) {
+			rel, err := filepath.Rel(src, srcPath)
+			if err != nil {
+				return false, err
+			}
+			return content.skip(rel), nil
+		},
+	})
+}
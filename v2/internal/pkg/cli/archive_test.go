@@ -0,0 +1,292 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+)
+
+func TestArchiveSplitValidate(t *testing.T) {
+	tempDir := t.TempDir()
+	iscPath := filepath.Join(tempDir, "isc.yaml")
+	assert.NoError(t, os.WriteFile(iscPath, []byte("kind: ImageSetConfiguration\napiVersion: mirror.openshift.io/v2alpha1\n"), 0600))
+
+	type testCase struct {
+		caseName      string
+		from          string
+		workspace     string
+		enclaves      []string
+		args          []string
+		expectedError string
+	}
+	testCases := []testCase{
+		{
+			caseName: "Testing archive split validate : should pass (--from)",
+			from:     "file://" + tempDir,
+			enclaves: []string{"enclave1=" + iscPath, "enclave2=" + iscPath},
+			args:     []string{"file:///tmp/enclaves"},
+		},
+		{
+			caseName:  "Testing archive split validate : should pass (--workspace)",
+			workspace: "file://" + tempDir,
+			enclaves:  []string{"enclave1=" + iscPath},
+			args:      []string{"file:///tmp/enclaves"},
+		},
+		{
+			caseName:      "Testing archive split validate : should fail (output not file://)",
+			from:          "file://" + tempDir,
+			enclaves:      []string{"enclave1=" + iscPath},
+			args:          []string{"docker://localhost:5000"},
+			expectedError: "the output directory must have file:// prefix",
+		},
+		{
+			caseName:      "Testing archive split validate : should fail (both --from and --workspace)",
+			from:          "file://" + tempDir,
+			workspace:     "file://" + tempDir,
+			enclaves:      []string{"enclave1=" + iscPath},
+			args:          []string{"file:///tmp/enclaves"},
+			expectedError: "exactly one of --from (mirror archive) or --workspace (mirrorToDisk destination, using the cache) is required",
+		},
+		{
+			caseName:      "Testing archive split validate : should fail (no enclave)",
+			from:          "file://" + tempDir,
+			args:          []string{"file:///tmp/enclaves"},
+			expectedError: "use the --enclave flag, at least one enclave is required",
+		},
+		{
+			caseName:      "Testing archive split validate : should fail (enclave without config)",
+			from:          "file://" + tempDir,
+			enclaves:      []string{"enclave1"},
+			args:          []string{"file:///tmp/enclaves"},
+			expectedError: "invalid --enclave enclave1: it should be name=path-to-imageset-config",
+		},
+		{
+			caseName:      "Testing archive split validate : should fail (invalid enclave name)",
+			from:          "file://" + tempDir,
+			enclaves:      []string{"Enclave_1=" + iscPath},
+			args:          []string{"file:///tmp/enclaves"},
+			expectedError: "invalid enclave name Enclave_1",
+		},
+		{
+			caseName:      "Testing archive split validate : should fail (duplicate enclave)",
+			from:          "file://" + tempDir,
+			enclaves:      []string{"enclave1=" + iscPath, "enclave1=" + iscPath},
+			args:          []string{"file:///tmp/enclaves"},
+			expectedError: "enclave enclave1 is set more than once",
+		},
+		{
+			caseName:      "Testing archive split validate : should fail (missing config)",
+			from:          "file://" + tempDir,
+			enclaves:      []string{"enclave1=" + filepath.Join(tempDir, "missing.yaml")},
+			args:          []string{"file:///tmp/enclaves"},
+			expectedError: "imageset configuration of enclave enclave1",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			ex := &ArchiveSchema{
+				ExecutorSchema: ExecutorSchema{
+					Log:  clog.New("error"),
+					Opts: &mirror.CopyOptions{Global: &mirror.GlobalOptions{From: testCase.from, WorkingDir: testCase.workspace}},
+				},
+				EnclaveFlags: testCase.enclaves,
+			}
+			err := ex.ValidateSplit(testCase.args)
+			if testCase.expectedError != "" {
+				assert.ErrorContains(t, err, testCase.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, ex.Enclaves, len(testCase.enclaves))
+			assert.Equal(t, Enclave{Name: "enclave1", ConfigPath: iscPath}, ex.Enclaves[0])
+		})
+	}
+}
+
+func TestArchiveSplitCachedImages(t *testing.T) {
+	images := []v2alpha1.CopyImageSchema{
+		{
+			Source:      "docker://localhost:55000/ubi8/ubi:latest",
+			Destination: "docker://mirror.enclave1.com/ubi8/ubi:latest",
+			Origin:      "docker://registry.redhat.io/ubi8/ubi:latest",
+			Type:        v2alpha1.TypeGeneric,
+		},
+	}
+	cached := cachedImages(images)
+	assert.Equal(t, "docker://localhost:55000/ubi8/ubi:latest", cached[0].Destination)
+	assert.Equal(t, "docker://registry.redhat.io/ubi8/ubi:latest", cached[0].Origin)
+	// the images of the collectors are left as they are
+	assert.Equal(t, "docker://mirror.enclave1.com/ubi8/ubi:latest", images[0].Destination)
+}
+
+func TestArchiveSplitCopyWorkingDir(t *testing.T) {
+	src := filepath.Join(t.TempDir(), workingDir)
+	dest := filepath.Join(t.TempDir(), "enclave1", workingDir)
+
+	enclaveOperator := v2alpha1.Operator{
+		Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.16",
+		IncludeConfig: v2alpha1.IncludeConfig{
+			Packages: []v2alpha1.IncludePackage{{Name: "aws-load-balancer-operator"}},
+		},
+	}
+	otherOperator := enclaveOperator
+	otherOperator.IncludeConfig = v2alpha1.IncludeConfig{
+		Packages: []v2alpha1.IncludePackage{{Name: "devworkspace-operator"}},
+	}
+	enclaveFilter, err := enclaveOperator.FilterDigest()
+	assert.NoError(t, err)
+	otherFilter, err := otherOperator.FilterDigest()
+	assert.NoError(t, err)
+
+	enclaveCatalog := filepath.Join(operatorCatalogsDir, "redhat-operator-index", "f30638e")
+	otherCatalog := filepath.Join(operatorCatalogsDir, "certified-operator-index", "a5a0f2b")
+	files := []string{
+		filepath.Join(historyDir, "history.json"),
+		filepath.Join(logsDir, "oc-mirror.log"),
+		filepath.Join(clusterResourcesDir, "idms-oc-mirror.yaml"),
+		filepath.Join(enclaveCatalog, "catalog-image", "index.json"),
+		filepath.Join(enclaveCatalog, operatorCatalogFilteredDir, enclaveFilter, "digest"),
+		filepath.Join(enclaveCatalog, operatorCatalogFilteredDir, otherFilter, "digest"),
+		filepath.Join(enclaveCatalog, operatorCatalogDiffDir, enclaveFilter+".md"),
+		filepath.Join(enclaveCatalog, operatorCatalogDiffDir, otherFilter+".md"),
+		filepath.Join(operatorCatalogsDir, "redhat-operator-index", operatorCatalogLastFilteredDir, enclaveFilter),
+		filepath.Join(operatorCatalogsDir, "redhat-operator-index", operatorCatalogLastFilteredDir, otherFilter),
+		filepath.Join(otherCatalog, "catalog-image", "index.json"),
+		filepath.Join(releaseImageDir, "ocp-release", "4.16.0-x86_64", "index.json"),
+		filepath.Join(releaseImageDir, "ocp-release", "4.15.0-x86_64", "index.json"),
+		filepath.Join(releaseImageExtractDir, "ocp-release", "4.16.0-x86_64", "release-manifests", "image-references"),
+		filepath.Join(releaseImageExtractDir, "ocp-release", "4.15.0-x86_64", "release-manifests", "image-references"),
+		filepath.Join(releaseImageExtractDir, cincinnatiGraphDataDir, "channels", "stable-4.16.yaml"),
+		filepath.Join(signaturesDir, "4.16.0-x86_64-sha256-3a5d3c7d"),
+		filepath.Join(signaturesDir, "4.15.0-x86_64-sha256-8e9b9bd0"),
+		filepath.Join(helmDir, helmChartDir, "podinfo-5.0.0.tgz"),
+	}
+	for _, file := range files {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(src, file)), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(src, file), []byte("content"), 0600))
+	}
+
+	content := newEnclaveContent(v2alpha1.CollectorSchema{
+		AllImages: []v2alpha1.CopyImageSchema{
+			{
+				Source: "docker://localhost:55000/openshift/release-images:4.16.0-x86_64",
+				Origin: "docker://quay.io/openshift-release-dev/ocp-release:4.16.0-x86_64",
+				Type:   v2alpha1.TypeOCPRelease,
+			},
+		},
+		CatalogToFBCMap: map[string]v2alpha1.CatalogFilterResult{
+			"docker://registry.redhat.io/redhat/redhat-operator-index:v4.16": {
+				OperatorFilter: enclaveOperator,
+				CatalogDir:     filepath.Join(src, enclaveCatalog),
+				ToRebuild:      true,
+			},
+		},
+	})
+	assert.NoError(t, copyWorkingDir(src, dest, content))
+
+	kept := []string{
+		filepath.Join(enclaveCatalog, "catalog-image", "index.json"),
+		filepath.Join(enclaveCatalog, operatorCatalogFilteredDir, enclaveFilter, "digest"),
+		filepath.Join(enclaveCatalog, operatorCatalogDiffDir, enclaveFilter+".md"),
+		filepath.Join(operatorCatalogsDir, "redhat-operator-index", operatorCatalogLastFilteredDir, enclaveFilter),
+		filepath.Join(releaseImageDir, "ocp-release", "4.16.0-x86_64", "index.json"),
+		filepath.Join(releaseImageExtractDir, "ocp-release", "4.16.0-x86_64", "release-manifests", "image-references"),
+		filepath.Join(releaseImageExtractDir, cincinnatiGraphDataDir, "channels", "stable-4.16.yaml"),
+		filepath.Join(signaturesDir, "4.16.0-x86_64-sha256-3a5d3c7d"),
+		filepath.Join(helmDir, helmChartDir, "podinfo-5.0.0.tgz"),
+	}
+	for _, file := range kept {
+		assert.FileExists(t, filepath.Join(dest, file))
+	}
+	skipped := []string{
+		historyDir,
+		logsDir,
+		clusterResourcesDir,
+		filepath.Join(enclaveCatalog, operatorCatalogFilteredDir, otherFilter),
+		filepath.Join(enclaveCatalog, operatorCatalogDiffDir, otherFilter+".md"),
+		filepath.Join(operatorCatalogsDir, "redhat-operator-index", operatorCatalogLastFilteredDir, otherFilter),
+		filepath.Join(operatorCatalogsDir, "certified-operator-index", "a5a0f2b"),
+		filepath.Join(releaseImageDir, "ocp-release", "4.15.0-x86_64"),
+		filepath.Join(releaseImageExtractDir, "ocp-release", "4.15.0-x86_64"),
+		filepath.Join(signaturesDir, "4.15.0-x86_64-sha256-8e9b9bd0"),
+	}
+	for _, file := range skipped {
+		assert.NoFileExists(t, filepath.Join(dest, file))
+		assert.NoDirExists(t, filepath.Join(dest, file))
+	}
+}
+
+func TestArchiveMergeValidate(t *testing.T) {
+	type testCase struct {
+		caseName      string
+		sources       []string
+		archiveSize   int64
+		args          []string
+		expectedError string
+	}
+	testCases := []testCase{
+		{
+			caseName: "Testing archive merge validate : should pass",
+			sources:  []string{"file:///disk-team1", "file:///disk-team2"},
+			args:     []string{"file:///disk-merged"},
+		},
+		{
+			caseName:      "Testing archive merge validate : should fail (output not file://)",
+			sources:       []string{"file:///disk-team1", "file:///disk-team2"},
+			args:          []string{"docker://localhost:5000"},
+			expectedError: "the output directory must have file:// prefix",
+		},
+		{
+			caseName:      "Testing archive merge validate : should fail (single archive)",
+			sources:       []string{"file:///disk-team1"},
+			args:          []string{"file:///disk-merged"},
+			expectedError: "use the --from flag at least twice, to set the archives to merge",
+		},
+		{
+			caseName:      "Testing archive merge validate : should fail (archive not file://)",
+			sources:       []string{"file:///disk-team1", "/disk-team2"},
+			args:          []string{"file:///disk-merged"},
+			expectedError: "the archives to merge must have file:// prefix: /disk-team2",
+		},
+		{
+			caseName:      "Testing archive merge validate : should fail (output is an archive to merge)",
+			sources:       []string{"file:///disk-team1", "file:///disk-team2/"},
+			args:          []string{"file:///disk-team2"},
+			expectedError: "the output directory must be different from the archives to merge: file:///disk-team2/",
+		},
+		{
+			caseName:      "Testing archive merge validate : should fail (negative archive size)",
+			sources:       []string{"file:///disk-team1", "file:///disk-team2"},
+			archiveSize:   -1,
+			args:          []string{"file:///disk-merged"},
+			expectedError: "archive-size must be a positive number of GB",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			ex := &ArchiveSchema{
+				ExecutorSchema: ExecutorSchema{
+					Log:  clog.New("error"),
+					Opts: &mirror.CopyOptions{Global: &mirror.GlobalOptions{}},
+				},
+				Sources:     testCase.sources,
+				ArchiveSize: testCase.archiveSize,
+			}
+			err := ex.ValidateMerge(testCase.args)
+			if testCase.expectedError != "" {
+				assert.EqualError(t, err, testCase.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "/disk-merged", ex.OutputDir)
+		})
+	}
+}
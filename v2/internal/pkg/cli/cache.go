@@ -0,0 +1,298 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/additional"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/cache"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/config"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/emoji"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/helm"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/history"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/manifest"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/operator"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/release"
+)
+
+const cacheErrMsg = "[cache] %v"
+
+// CacheSchema holds what the cache sub commands need
+// in order to maintain the local cache
+type CacheSchema struct {
+	ExecutorSchema
+	KeepConfigPaths  []string
+	KeepRuns         int
+	DryRun           bool
+	Database         history.Database
+	GarbageCollector cache.GarbageCollector
+}
+
+// NewCacheCommand - setup the 'cache' sub command, maintaining the local cache (--cache-dir)
+func NewCacheCommand(log clog.PluggableLoggerInterface, opts *mirror.CopyOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Maintain the local cache of oc-mirror",
+	}
+	cmd.AddCommand(newCacheGCCommand(log, opts))
+	return cmd
+}
+
+func newCacheGCCommand(log clog.PluggableLoggerInterface, opts *mirror.CopyOptions) *cobra.Command {
+	ex := &CacheSchema{
+		ExecutorSchema: ExecutorSchema{
+			Log:     log,
+			Opts:    opts,
+			MakeDir: MakeDir{},
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Remove the images of the local cache which are not referenced by imageset configurations or by the last runs of the history, and their blobs",
+		Example: `  # Show what would be removed from the cache, keeping the images of 2 imageset configurations
+  oc-mirror cache gc --workspace file:///home/user/oc-mirror/mirror1 --keep-config isc-4.15.yaml --keep-config isc-4.16.yaml --dry-run --v2
+
+  # Keep the images mirrored by the last 3 runs recorded in the history of the workspace
+  oc-mirror cache gc --workspace file:///home/user/oc-mirror/mirror1 --keep-runs 3 --v2`,
+		Args: cobra.NoArgs,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			opts.Function = string(mirror.CopyMode)
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			err := ex.ValidateGC()
+			if err != nil {
+				log.Error("%v ", err)
+				os.Exit(1)
+			}
+			err = ex.CompleteGC()
+			if err != nil {
+				log.Error("%v ", err)
+				os.Exit(1)
+			}
+			defer ex.logFile.Close()
+			cmd.SetOutput(ex.logFile)
+
+			// prepare internal storage
+			err = ex.setupLocalStorage(cmd.Context())
+			if err != nil {
+				log.Error(" %v ", err)
+				os.Exit(1)
+			}
+
+			err = ex.RunGC(cmd.Context())
+			if err != nil {
+				log.Error("%v ", err)
+				os.Exit(1)
+			}
+		},
+	}
+	cmd.Flags().StringArrayVar(&ex.KeepConfigPaths, "keep-config", nil, "Imageset configuration of which the images are kept in the cache, can be repeated. Its content must have been mirrored with the workspace")
+	cmd.Flags().IntVar(&ex.KeepRuns, "keep-runs", 0, "Number of the last runs recorded in the history of the workspace of which the mirrored images are kept in the cache")
+	cmd.Flags().BoolVar(&ex.DryRun, "dry-run", false, "Report what would be removed from the cache, and the space that would be freed, without removing anything")
+	return cmd
+}
+
+// ValidateGC - cobra validation
+func (o *CacheSchema) ValidateGC() error {
+	if !strings.HasPrefix(o.Opts.Global.WorkingDir, fileProtocol) {
+		return fmt.Errorf("use the --workspace flag with a file:// prefix, it is mandatory: it is where the images of the cache were mirrored from")
+	}
+	if o.KeepRuns < 0 {
+		return fmt.Errorf("--keep-runs must be a positive number of runs")
+	}
+	if len(o.KeepConfigPaths) == 0 && o.KeepRuns == 0 {
+		return fmt.Errorf("use the --keep-config or the --keep-runs flag: the images they reference are the ones kept in the cache")
+	}
+	for _, keepConfig := range o.KeepConfigPaths {
+		if _, err := os.Stat(keepConfig); err != nil {
+			return fmt.Errorf("keep configuration: %v", err)
+		}
+	}
+	return nil
+}
+
+// CompleteGC - do the final setup of modules
+func (o *CacheSchema) CompleteGC() error {
+	// the collectors read the metadata of the working-dir and the images of the cache,
+	// as they do when mirroring an archive to a registry
+	o.Opts.Mode = mirror.DiskToMirror
+	// source is the local cache, which is HTTP
+	o.Opts.SrcImage.TlsVerify = false
+	o.Opts.MultiArch = "all"
+	o.Opts.RemoveSignatures = true
+
+	o.Opts.Global.WorkingDir = filepath.Join(strings.TrimPrefix(o.Opts.Global.WorkingDir, fileProtocol), workingDir)
+
+	err := o.setupLogsLevelAndDir()
+	if err != nil {
+		return err
+	}
+	o.Log.Info(emoji.TwistedRighwardsArrows+" workflow mode: %s / cache gc", o.Opts.Mode)
+
+	if o.isLocalStoragePortBound() {
+		return fmt.Errorf("%d is already bound and cannot be used", o.Opts.Global.Port)
+	}
+	o.Opts.LocalStorageFQDN = "localhost:" + strconv.Itoa(int(o.Opts.Global.Port))
+	o.Opts.Destination = dockerProtocol + o.Opts.LocalStorageFQDN
+
+	err = o.setupLocalStorageDir()
+	if err != nil {
+		return err
+	}
+
+	o.Manifest = manifest.New(o.Log)
+	o.Mirror = mirror.New(mirror.NewMirrorCopy(), nil)
+	o.GarbageCollector = cache.New(o.Log, o.LocalStorageDisk)
+	return nil
+}
+
+// RunGC - collects the images to keep, and garbage collects the rest of the cache
+func (o *CacheSchema) RunGC(ctx context.Context) error {
+	startTime := time.Now()
+
+	referenced, err := o.referencedImages(ctx)
+	if err != nil {
+		return fmt.Errorf(cacheErrMsg, err)
+	}
+	// an empty referenced set would remove every repository of the cache
+	if len(referenced) == 0 && !o.DryRun {
+		return fmt.Errorf(cacheErrMsg, "no image to keep was found: refusing to remove every image of the cache, use --dry-run to review what would be removed")
+	}
+
+	o.Log.Info(emoji.Gear+" garbage collecting the cache %s, keeping %d images...", o.LocalStorageDisk, len(referenced))
+	report, err := o.GarbageCollector.Collect(ctx, referenced, o.DryRun)
+	if err != nil {
+		return err
+	}
+
+	verb := "removed"
+	if o.DryRun {
+		verb = "to remove"
+	}
+	for _, repository := range report.RemovedRepositories {
+		o.Log.Debug("repository %s : %s", verb, repository)
+	}
+	for _, tag := range report.RemovedTags {
+		o.Log.Debug("tag %s : %s", verb, tag)
+	}
+	o.Log.Info("%d repositories and %d tags %s, %d tags kept", len(report.RemovedRepositories), len(report.RemovedTags), verb, report.KeptTags)
+	if o.DryRun {
+		o.Log.Info(emoji.PageFacingUp+" %d blobs would be removed, freeing %s (dry-run, nothing was removed)", report.RemovedBlobs, cache.HumanBytes(report.FreedBytes))
+	} else {
+		o.Log.Info(emoji.CheckMarkButton+" %d blobs removed, %s freed", report.RemovedBlobs, cache.HumanBytes(report.FreedBytes))
+	}
+
+	o.Log.Info("cache gc time     : %v", time.Since(startTime))
+	o.Log.Info(emoji.WavingHandSign + " Goodbye, thank you for using oc-mirror")
+	return nil
+}
+
+// referencedImages returns the images to keep in the cache: the images of the keep
+// configurations and the images mirrored by the last runs of the history
+func (o *CacheSchema) referencedImages(ctx context.Context) ([]string, error) {
+	var referenced []string
+	if len(o.KeepConfigPaths) > 0 {
+		// the local registry is needed by the collectors only: it is stopped
+		// before the cache is garbage collected
+		go o.startLocalRegistry()
+		defer o.stopLocalRegistry(ctx)
+
+		for _, keepConfig := range o.KeepConfigPaths {
+			images, err := o.configImages(ctx, keepConfig)
+			if err != nil {
+				return nil, fmt.Errorf("keep configuration %s: %v", keepConfig, err)
+			}
+			referenced = append(referenced, images...)
+		}
+	}
+
+	if o.KeepRuns > 0 {
+		images, err := o.runsImages()
+		if err != nil {
+			return nil, err
+		}
+		referenced = append(referenced, images...)
+	}
+	return referenced, nil
+}
+
+// configImages returns the images of the imageset configuration, as stored in the cache
+func (o *CacheSchema) configImages(ctx context.Context, configPath string) ([]string, error) {
+	cfg, err := config.ReadConfig(configPath, v2alpha1.ImageSetConfigurationKind)
+	if err != nil {
+		return nil, err
+	}
+	o.Config = cfg.(v2alpha1.ImageSetConfiguration)
+
+	client, _ := release.NewOCPClient(uuid.New(), o.Log)
+	signature := release.NewSignatureClient(o.Log, o.Config, *o.Opts)
+	cn := release.NewCincinnati(o.Log, &o.Config, *o.Opts, client, false, signature)
+	o.Release = release.New(o.Log, o.LogsDir, o.Config, *o.Opts, o.Mirror, o.Manifest, cn, nil)
+	o.Operator = operator.NewWithFilter(o.Log, o.LogsDir, o.Config, *o.Opts, o.Mirror, o.Manifest)
+	o.AdditionalImages = additional.New(o.Log, o.Config, *o.Opts, o.Mirror, o.Manifest)
+	o.HelmCollector = helm.New(o.Log, o.Config, *o.Opts, nil, nil, &http.Client{Timeout: time.Duration(5) * time.Second})
+
+	collectorSchema, err := o.CollectAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	images := make([]string, 0, len(collectorSchema.AllImages))
+	for _, img := range collectorSchema.AllImages {
+		images = append(images, img.Source)
+	}
+	return images, nil
+}
+
+// runsImages returns the images mirrored by the last o.KeepRuns runs of the history
+func (o *CacheSchema) runsImages() ([]string, error) {
+	if o.Database == nil {
+		db, err := history.NewDatabase(o.Opts.Global.WorkingDir, o.Log)
+		if err != nil {
+			return nil, err
+		}
+		o.Database = db
+	}
+	runs, err := o.Database.Runs()
+	if err != nil {
+		return nil, err
+	}
+	// workspaces mirrored before the runs were recorded, or whose history is kept
+	// in a registry, have no run: keeping nothing would empty the cache
+	if len(runs) == 0 {
+		return nil, fmt.Errorf("--keep-runs %d: the history of %s has no recorded run", o.KeepRuns, o.Opts.Global.WorkingDir)
+	}
+	if len(runs) < o.KeepRuns {
+		return nil, fmt.Errorf("--keep-runs %d: the history of %s only has %d recorded runs", o.KeepRuns, o.Opts.Global.WorkingDir, len(runs))
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].ID > runs[j].ID })
+	if len(runs) > o.KeepRuns {
+		runs = runs[:o.KeepRuns]
+	}
+
+	var images []string
+	for _, summary := range runs {
+		run, err := o.Database.Run(summary.ID)
+		if err != nil {
+			return nil, err
+		}
+		o.Log.Debug("keeping the %d images mirrored by run %d (%s)", len(run.Images), run.ID, run.Date.Format(time.RFC3339))
+		for _, img := range run.Images {
+			images = append(images, img.Destination)
+		}
+	}
+	return images, nil
+}
@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/history"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+)
+
+func TestCacheGCValidate(t *testing.T) {
+	type testCase struct {
+		caseName      string
+		workspace     string
+		keepConfigs   []string
+		keepRuns      int
+		expectedError string
+	}
+	missingConfig := filepath.Join(t.TempDir(), "isc.yaml")
+	testCases := []testCase{
+		{
+			caseName:      "Testing cache gc validate : should fail (workspace not file://)",
+			workspace:     "/tmp/mirror1",
+			keepRuns:      1,
+			expectedError: "use the --workspace flag with a file:// prefix, it is mandatory: it is where the images of the cache were mirrored from",
+		},
+		{
+			caseName:      "Testing cache gc validate : should fail (negative keep-runs)",
+			workspace:     "file:///tmp/mirror1",
+			keepRuns:      -1,
+			expectedError: "--keep-runs must be a positive number of runs",
+		},
+		{
+			caseName:      "Testing cache gc validate : should fail (nothing to keep)",
+			workspace:     "file:///tmp/mirror1",
+			expectedError: "use the --keep-config or the --keep-runs flag: the images they reference are the ones kept in the cache",
+		},
+		{
+			caseName:      "Testing cache gc validate : should fail (keep config not found)",
+			workspace:     "file:///tmp/mirror1",
+			keepConfigs:   []string{missingConfig},
+			expectedError: "keep configuration: stat " + missingConfig + ": no such file or directory",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			ex := &CacheSchema{
+				ExecutorSchema: ExecutorSchema{
+					Log:  clog.New("error"),
+					Opts: &mirror.CopyOptions{Global: &mirror.GlobalOptions{WorkingDir: testCase.workspace}},
+				},
+				KeepConfigPaths: testCase.keepConfigs,
+				KeepRuns:        testCase.keepRuns,
+			}
+			err := ex.ValidateGC()
+			assert.EqualError(t, err, testCase.expectedError)
+		})
+	}
+}
+
+func TestCacheGCRunsImages(t *testing.T) {
+	date := time.Date(2024, 10, 1, 10, 0, 0, 0, time.UTC)
+	db := mockHistoryDatabase{runs: map[uint64]history.Run{
+		1: {ID: 1, Date: date, Images: []history.RunImage{
+			{Origin: "quay.io/foo/bar:v1", Destination: "docker://localhost:55000/foo/bar:v1", Type: "additionalImage"},
+		}},
+		2: {ID: 2, Date: date.Add(24 * time.Hour), Images: []history.RunImage{
+			{Origin: "quay.io/foo/bar:v2", Destination: "docker://localhost:55000/foo/bar:v2", Type: "additionalImage"},
+		}},
+		3: {ID: 3, Date: date.Add(48 * time.Hour), Images: []history.RunImage{
+			{Origin: "quay.io/foo/bar:v3", Destination: "docker://localhost:55000/foo/bar:v3", Type: "additionalImage"},
+		}},
+	}}
+
+	ex := &CacheSchema{
+		ExecutorSchema: ExecutorSchema{
+			Log:  clog.New("error"),
+			Opts: &mirror.CopyOptions{Global: &mirror.GlobalOptions{}},
+		},
+		Database: db,
+		KeepRuns: 2,
+	}
+
+	t.Run("Testing cache gc : should keep the images of the last runs", func(t *testing.T) {
+		images, err := ex.runsImages()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"docker://localhost:55000/foo/bar:v3", "docker://localhost:55000/foo/bar:v2"}, images)
+	})
+
+	t.Run("Testing cache gc : should fail when fewer runs than kept are recorded", func(t *testing.T) {
+		ex.KeepRuns = 4
+		defer func() { ex.KeepRuns = 2 }()
+		_, err := ex.runsImages()
+		assert.EqualError(t, err, "--keep-runs 4: the history of  only has 3 recorded runs")
+	})
+
+	t.Run("Testing cache gc : should fail when no run is recorded", func(t *testing.T) {
+		empty := &CacheSchema{
+			ExecutorSchema: ExecutorSchema{
+				Log:  clog.New("error"),
+				Opts: &mirror.CopyOptions{Global: &mirror.GlobalOptions{WorkingDir: "/tmp/working-dir"}},
+			},
+			Database: mockHistoryDatabase{runs: map[uint64]history.Run{}},
+			KeepRuns: 1,
+		}
+		err := empty.RunGC(context.Background())
+		assert.EqualError(t, err, "[cache] --keep-runs 1: the history of /tmp/working-dir has no recorded run")
+	})
+}
+
+func TestCacheGCEmptyReferenced(t *testing.T) {
+	db := mockHistoryDatabase{runs: map[uint64]history.Run{
+		1: {ID: 1, Date: time.Date(2024, 10, 1, 10, 0, 0, 0, time.UTC), Blobs: []string{"sha256:1dddb0988d16"}},
+	}}
+	ex := &CacheSchema{
+		ExecutorSchema: ExecutorSchema{
+			Log:  clog.New("error"),
+			Opts: &mirror.CopyOptions{Global: &mirror.GlobalOptions{}},
+		},
+		Database: db,
+		KeepRuns: 1,
+	}
+
+	t.Run("Testing cache gc : should refuse to empty the cache", func(t *testing.T) {
+		err := ex.RunGC(context.Background())
+		assert.EqualError(t, err, "[cache] no image to keep was found: refusing to remove every image of the cache, use --dry-run to review what would be removed")
+	})
+}
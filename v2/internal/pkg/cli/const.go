@@ -6,6 +6,7 @@ const (
 	ociProtocol                   string = "oci://"
 	dirProtocol                   string = "dir://"
 	fileProtocol                  string = "file://"
+	fbcProtocol                   string = "fbc://"
 	releaseImageDir               string = "release-images"
 	logsDir                       string = "logs"
 	workingDir                    string = "working-dir"
@@ -23,6 +24,7 @@ const (
 	dryRunOutDir                  string = "dry-run"
 	mappingFile                   string = "mapping.txt"
 	missingImgsFile               string = "missing.txt"
+	deprecationsFile              string = "deprecations.txt"
 	clusterResourcesDir           string = "cluster-resources"
 	helmDir                       string = "helm"
 	helmChartDir                  string = "charts"
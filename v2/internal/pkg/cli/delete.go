@@ -1,11 +1,13 @@
 package cli
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -23,6 +25,7 @@ import (
 	"github.com/openshift/oc-mirror/v2/internal/pkg/delete"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/emoji"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/helm"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/history"
 	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/manifest"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
@@ -40,6 +43,18 @@ const (
 type DeleteSchema struct {
 	ExecutorSchema
 	V1Tags bool
+	// PreviousConfigPath and PreviousRun are the previous revision of the
+	// imageset configuration (-c) when generating the delete list from
+	// the content that is no longer included
+	PreviousConfigPath string
+	PreviousRun        uint64
+	PreviousConfig     v2alpha1.ImageSetConfiguration
+	Database           history.Database
+	// KeepConfigPaths and KeepWorkspaces reference the images that other
+	// consumers of the registry still need, and that must not be deleted
+	KeepConfigPaths []string
+	KeepConfigs     []v2alpha1.ImageSetConfiguration
+	KeepWorkspaces  []string
 }
 
 // NewDeleteCommand - setup all the relevant support structs
@@ -94,6 +109,11 @@ func NewDeleteCommand(log clog.PluggableLoggerInterface, opts *mirror.CopyOption
 	cmd.Flags().BoolVar(&opts.Global.ForceCacheDelete, "force-cache-delete", false, "Used to force delete  the local cache manifests and blobs")
 	cmd.Flags().BoolVar(&opts.Global.DeleteGenerate, "generate", false, "Used to generate the delete yaml for the list of manifests and blobs , used in the step to actually delete from local cahce and remote registry")
 	cmd.Flags().BoolVar(&ex.V1Tags, "delete-v1-images", false, "Used during the migration, along with --generate, in order to target images previously mirrored with oc-mirror v1")
+	cmd.Flags().StringVar(&ex.PreviousConfigPath, "previous-config", "", "Used along with --generate, the previous imageset configuration: the delete yaml lists the images it includes which are no longer included by the imageset configuration set with --config")
+	cmd.MarkFlagFilename("previous-config", "yaml")
+	cmd.Flags().StringArrayVar(&ex.KeepConfigPaths, "keep-config", nil, "Used along with --generate, an imageset configuration of images which must not be deleted (i.e mirrored by another team to the same registry). Can be repeated")
+	cmd.Flags().StringArrayVar(&ex.KeepWorkspaces, "keep-workspace", nil, "A workspace (file://) of which the images mirrored by the last run, as recorded in its history, must not be deleted. Checked again right before deleting. Can be repeated")
+	cmd.Flags().Uint64Var(&ex.PreviousRun, "previous-run", 0, "Used along with --generate, the run of the workspace history (as listed by `oc-mirror history list`): the delete yaml lists the images it mirrored which are no longer included by the imageset configuration set with --config")
 
 	// hide flags
 	HideFlags(cmd)
@@ -118,6 +138,33 @@ func (o DeleteSchema) ValidateDelete(args []string) error {
 	if o.V1Tags && !o.Opts.Global.DeleteGenerate {
 		return fmt.Errorf("the --delete-v1-images flag can only be used alongside the --generate flag")
 	}
+	if len(o.KeepConfigPaths) > 0 && !o.Opts.Global.DeleteGenerate {
+		return fmt.Errorf("the --keep-config flag can only be used alongside the --generate flag, the images it references are recorded in the delete yaml file")
+	}
+	for _, keepConfig := range o.KeepConfigPaths {
+		if _, err := os.Stat(keepConfig); err != nil {
+			return fmt.Errorf("keep configuration: %v", err)
+		}
+	}
+	for _, workspace := range o.KeepWorkspaces {
+		if !strings.HasPrefix(workspace, fileProtocol) {
+			return fmt.Errorf("keep workspace %s must have a file:// protocol prefix", workspace)
+		}
+	}
+	if o.isPrune() {
+		if !o.Opts.Global.DeleteGenerate {
+			return fmt.Errorf("the --previous-config and --previous-run flags can only be used alongside the --generate flag")
+		}
+		if len(o.PreviousConfigPath) > 0 && o.PreviousRun > 0 {
+			return fmt.Errorf("the --previous-config and --previous-run flags are mutually exclusive")
+		}
+		if o.V1Tags {
+			return fmt.Errorf("the --delete-v1-images flag cannot be used with the --previous-config and --previous-run flags")
+		}
+		if _, err := os.Stat(o.PreviousConfigPath); len(o.PreviousConfigPath) > 0 && err != nil {
+			return fmt.Errorf("previous imageset configuration: %v", err)
+		}
+	}
 	if len(args) < 1 {
 		return fmt.Errorf("the destination registry is missing in the command arguments")
 	}
@@ -144,7 +191,24 @@ func (o *DeleteSchema) CompleteDelete(args []string) error {
 	}
 	o.Opts.Destination = args[0]
 	o.Opts.Global.DeleteDestination = args[0]
-	if o.Opts.Global.DeleteGenerate {
+	if o.Opts.Global.DeleteGenerate && o.isPrune() {
+		// the delete list is the difference between two revisions of an imagesetconfig
+		cfg, err := config.ReadConfig(o.Opts.Global.ConfigPath, v2alpha1.ImageSetConfigurationKind)
+		if err != nil {
+			return err
+		}
+		o.Config = cfg.(v2alpha1.ImageSetConfiguration)
+		if len(o.PreviousConfigPath) > 0 {
+			cfg, err := config.ReadConfig(o.PreviousConfigPath, v2alpha1.ImageSetConfigurationKind)
+			if err != nil {
+				return fmt.Errorf("previous imageset configuration: %v", err)
+			}
+			o.PreviousConfig = cfg.(v2alpha1.ImageSetConfiguration)
+		}
+		o.Opts.RemoveSignatures = true
+		// nolint: errcheck
+		o.Opts.SrcImage.TlsVerify = false
+	} else if o.Opts.Global.DeleteGenerate {
 		o.Log.Debug("delete imagesetconfig file %s ", o.Opts.Global.ConfigPath)
 		// read and validate the DeleteImageSetConfiguration
 		cfg, err := config.ReadConfig(o.Opts.Global.ConfigPath, v2alpha1.DeleteImageSetConfigurationKind)
@@ -165,6 +229,8 @@ func (o *DeleteSchema) CompleteDelete(args []string) error {
 					Operators:        converted.Delete.Operators,
 					AdditionalImages: converted.Delete.AdditionalImages,
 					Helm:             converted.Delete.Helm,
+					RewriteRules:     converted.Delete.RewriteRules,
+					Samples:          converted.Delete.Samples,
 				},
 			},
 		}
@@ -173,6 +239,13 @@ func (o *DeleteSchema) CompleteDelete(args []string) error {
 		// nolint: errcheck
 		o.Opts.SrcImage.TlsVerify = false
 	}
+	for _, keepConfig := range o.KeepConfigPaths {
+		cfg, err := config.ReadConfig(keepConfig, v2alpha1.ImageSetConfigurationKind)
+		if err != nil {
+			return fmt.Errorf("keep configuration %s: %v", keepConfig, err)
+		}
+		o.KeepConfigs = append(o.KeepConfigs, cfg.(v2alpha1.ImageSetConfiguration))
+	}
 
 	o.Opts.Mode = mirror.DiskToMirror
 
@@ -230,11 +303,26 @@ func (o *DeleteSchema) CompleteDelete(args []string) error {
 		return err
 	}
 
+	o.setupCollectors()
+	o.Batch = batch.New(batch.ChannelConcurrentWorker, o.Log, o.LogsDir, o.Mirror, o.Opts.ParallelImages)
+	// instantiate delete module
+	bg := archive.NewImageBlobGatherer(o.Opts)
+	deleteConfig := o.Config
+	if o.isPrune() {
+		// the delete list does not derive from a deleteimagesetconfig
+		deleteConfig = v2alpha1.ImageSetConfiguration{}
+	}
+	o.Delete = delete.New(o.Log, *o.Opts, o.Batch, bg, deleteConfig, o.Manifest, o.LocalStorageDisk)
+
+	return nil
+}
+
+// setupCollectors - (re)creates the collectors for o.Config
+func (o *DeleteSchema) setupCollectors() {
 	client, _ := release.NewOCPClient(uuid.New(), o.Log)
 	signature := release.NewSignatureClient(o.Log, o.Config, *o.Opts)
 	cn := release.NewCincinnati(o.Log, &o.Config, *o.Opts, client, false, signature)
 	o.Release = release.New(o.Log, o.LogsDir, o.Config, *o.Opts, o.Mirror, o.Manifest, cn, o.ImageBuilder)
-	o.Batch = batch.New(batch.ChannelConcurrentWorker, o.Log, o.LogsDir, o.Mirror, o.Opts.ParallelImages)
 	o.Operator = operator.NewWithFilter(o.Log, o.LogsDir, o.Config, *o.Opts, o.Mirror, o.Manifest)
 
 	o.AdditionalImages = additional.New(o.Log, o.Config, *o.Opts, o.Mirror, o.Manifest)
@@ -244,11 +332,12 @@ func (o *DeleteSchema) CompleteDelete(args []string) error {
 		o.AdditionalImages = additional.WithV1Tags(o.AdditionalImages)
 		o.HelmCollector = helm.WithV1Tags(o.HelmCollector)
 	}
-	// instantiate delete module
-	bg := archive.NewImageBlobGatherer(o.Opts)
-	o.Delete = delete.New(o.Log, *o.Opts, o.Batch, bg, o.Config, o.Manifest, o.LocalStorageDisk)
+}
 
-	return nil
+// isPrune - the delete list is generated from the previous revision
+// of the imageset configuration rather than from a deleteimagesetconfig
+func (o DeleteSchema) isPrune() bool {
+	return len(o.PreviousConfigPath) > 0 || o.PreviousRun > 0
 }
 
 // RunDelete - cobra run
@@ -261,13 +350,27 @@ func (o *DeleteSchema) RunDelete(cmd *cobra.Command) error {
 	defer o.stopLocalRegistry(cmd.Context())
 
 	if o.Opts.Global.DeleteGenerate {
+		var images []v2alpha1.CopyImageSchema
+		if o.isPrune() {
+			var err error
+			images, err = o.pruneImages(cmd.Context())
+			if err != nil {
+				return err
+			}
+		} else {
+			collectorSchema, err := o.CollectAll(cmd.Context())
+			if err != nil {
+				return err
+			}
+			images = collectorSchema.AllImages
+		}
 
-		collectorSchema, err := o.CollectAll(cmd.Context())
+		keep, err := o.keepImages(cmd.Context())
 		if err != nil {
 			return err
 		}
 
-		err = o.Delete.WriteDeleteMetaData(collectorSchema.AllImages)
+		err = o.Delete.WriteDeleteMetaData(images, keep)
 		if err != nil {
 			return err
 		}
@@ -278,6 +381,15 @@ func (o *DeleteSchema) RunDelete(cmd *cobra.Command) error {
 			return err
 		}
 
+		// the workspaces may have mirrored new content since phase 1
+		keep, err := o.keepWorkspacesImages()
+		if err != nil {
+			return err
+		}
+		for _, img := range keep {
+			deleteList.Keep = append(deleteList.Keep, v2alpha1.DeleteItem{ImageName: img.Origin, ImageReference: img.Destination, Type: img.Type})
+		}
+
 		err = o.Delete.DeleteRegistryImages(deleteList)
 		if err != nil {
 			return err
@@ -305,6 +417,113 @@ func (o *DeleteSchema) RunDelete(cmd *cobra.Command) error {
 	return nil
 }
 
+// pruneImages - collects the images of both the current and the previous
+// revision of the imageset configuration, and returns the images
+// of the previous revision that are no longer referenced
+func (o *DeleteSchema) pruneImages(ctx context.Context) ([]v2alpha1.CopyImageSchema, error) {
+	o.Log.Info(emoji.LeftPointingMagnifyingGlass + " collecting the images of the current imageset configuration...")
+	current, err := o.CollectAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var previous []v2alpha1.CopyImageSchema
+	if len(o.PreviousConfigPath) > 0 {
+		o.Log.Info(emoji.LeftPointingMagnifyingGlass+" collecting the images of the previous imageset configuration %s...", o.PreviousConfigPath)
+		currentConfig := o.Config
+		o.Config = o.PreviousConfig
+		o.setupCollectors()
+		collectorSchema, err := o.CollectAll(ctx)
+		o.Config = currentConfig
+		if err != nil {
+			return nil, err
+		}
+		previous = collectorSchema.AllImages
+	} else {
+		if o.Database == nil {
+			o.Database, err = history.NewDatabase(o.Opts.Global.WorkingDir, o.Log)
+			if err != nil {
+				return nil, err
+			}
+		}
+		run, err := o.Database.Run(o.PreviousRun)
+		if err != nil {
+			return nil, err
+		}
+		o.Log.Info(emoji.LeftPointingMagnifyingGlass+" using the %d images mirrored by run %d (%s)", len(run.Images), run.ID, run.Date.Format(time.RFC3339))
+		previous, err = delete.RunImages(run, o.Opts.Global.DeleteDestination)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	images, err := delete.PruneImages(previous, current.AllImages)
+	if err != nil {
+		return nil, fmt.Errorf(deleteErrMsg, err)
+	}
+	o.Log.Info("%d images are no longer included by the imageset configuration", len(images))
+	return images, nil
+}
+
+// keepImages - collects the images of the keep configurations and workspaces,
+// which must not be deleted as other consumers of the registry still reference them
+func (o *DeleteSchema) keepImages(ctx context.Context) ([]v2alpha1.CopyImageSchema, error) {
+	var keep []v2alpha1.CopyImageSchema
+	if len(o.KeepConfigs) > 0 {
+		currentConfig := o.Config
+		for i, keepConfig := range o.KeepConfigs {
+			o.Log.Info(emoji.LeftPointingMagnifyingGlass+" collecting the images to keep of %s...", o.KeepConfigPaths[i])
+			o.Config = keepConfig
+			o.setupCollectors()
+			collectorSchema, err := o.CollectAll(ctx)
+			if err != nil {
+				o.Config = currentConfig
+				return nil, fmt.Errorf("keep configuration %s: %v", o.KeepConfigPaths[i], err)
+			}
+			keep = append(keep, collectorSchema.AllImages...)
+		}
+		o.Config = currentConfig
+	}
+
+	workspacesImages, err := o.keepWorkspacesImages()
+	if err != nil {
+		return nil, err
+	}
+	return append(keep, workspacesImages...), nil
+}
+
+// keepWorkspacesImages - returns the images mirrored by the last run
+// recorded in the history of each keep workspace
+func (o *DeleteSchema) keepWorkspacesImages() ([]v2alpha1.CopyImageSchema, error) {
+	var keep []v2alpha1.CopyImageSchema
+	for _, workspace := range o.KeepWorkspaces {
+		db, err := history.NewDatabase(filepath.Join(strings.TrimPrefix(workspace, fileProtocol), workingDir), o.Log)
+		if err != nil {
+			return nil, fmt.Errorf("keep workspace %s: %v", workspace, err)
+		}
+		runs, err := db.Runs()
+		if err != nil {
+			return nil, fmt.Errorf("keep workspace %s: %v", workspace, err)
+		}
+		if len(runs) == 0 {
+			o.Log.Warn("keep workspace %s has no run recorded in its history", workspace)
+			continue
+		}
+		last := slices.MaxFunc(runs, func(a, b history.RunSummary) int { return cmp.Compare(a.ID, b.ID) })
+		run, err := db.Run(last.ID)
+		if err != nil {
+			return nil, fmt.Errorf("keep workspace %s: %v", workspace, err)
+		}
+		o.Log.Info(emoji.LeftPointingMagnifyingGlass+" keeping the %d images mirrored by run %d of %s", len(run.Images), run.ID, workspace)
+		images, err := delete.RunImages(run, o.Opts.Global.DeleteDestination)
+		if err != nil {
+			return nil, fmt.Errorf("keep workspace %s: %v", workspace, err)
+		}
+		keep = append(keep, images...)
+	}
+	return keep, nil
+}
+
 // startLocalRegistryGarbageCollect
 func (o *DeleteSchema) startLocalRegistryGarbageCollect() error {
 	ctx := context.Background()
@@ -97,6 +97,46 @@ func TestExecutorValidateDelete(t *testing.T) {
 		err = ex.ValidateDelete([]string{"docker://test"})
 		assert.Equal(t, "file not found ../../nothing", err.Error())
 
+		// check the previous revision flags without --generate
+		opts.Global.DeleteYaml = common.TestFolder + "delete/delete-images.yaml"
+		ex.PreviousRun = 2
+		err = ex.ValidateDelete([]string{"docker://test"})
+		assert.Equal(t, "the --previous-config and --previous-run flags can only be used alongside the --generate flag", err.Error())
+
+		// check both previous revision flags
+		opts.Global.DeleteGenerate = true
+		ex.PreviousConfigPath = common.TestFolder + "isc.yaml"
+		err = ex.ValidateDelete([]string{"docker://test"})
+		assert.Equal(t, "the --previous-config and --previous-run flags are mutually exclusive", err.Error())
+
+		// check when the previous imageset configuration is not found
+		ex.PreviousRun = 0
+		ex.PreviousConfigPath = "../../nothing"
+		err = ex.ValidateDelete([]string{"docker://test"})
+		assert.ErrorContains(t, err, "previous imageset configuration: stat ../../nothing")
+
+		ex.PreviousConfigPath = common.TestFolder + "isc.yaml"
+		err = ex.ValidateDelete([]string{"docker://test"})
+		assert.NoError(t, err)
+
+		// check the keep flags
+		ex.KeepWorkspaces = []string{"/home/team2/oc-mirror"}
+		err = ex.ValidateDelete([]string{"docker://test"})
+		assert.Equal(t, "keep workspace /home/team2/oc-mirror must have a file:// protocol prefix", err.Error())
+
+		ex.KeepWorkspaces = []string{"file:///home/team2/oc-mirror"}
+		ex.KeepConfigPaths = []string{"../../nothing"}
+		err = ex.ValidateDelete([]string{"docker://test"})
+		assert.ErrorContains(t, err, "keep configuration: stat ../../nothing")
+
+		ex.KeepConfigPaths = []string{common.TestFolder + "isc.yaml"}
+		err = ex.ValidateDelete([]string{"docker://test"})
+		assert.NoError(t, err)
+
+		ex.PreviousConfigPath = ""
+		opts.Global.DeleteGenerate = false
+		err = ex.ValidateDelete([]string{"docker://test"})
+		assert.Equal(t, "the --keep-config flag can only be used alongside the --generate flag, the images it references are recorded in the delete yaml file", err.Error())
 	})
 }
 
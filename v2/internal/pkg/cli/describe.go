@@ -0,0 +1,184 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/archive"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/cache"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+)
+
+const describeErrMsg = "[describe] %v"
+
+// DescribeSchema holds what the describe command needs
+// in order to describe a mirror archive
+type DescribeSchema struct {
+	Log       clog.PluggableLoggerInterface
+	Opts      *mirror.CopyOptions
+	Describer archive.Describer
+	Output    string
+	out       io.Writer
+}
+
+// NewDescribeCommand - setup the 'describe' sub command, describing the content
+// of the archive generated by the mirrorToDisk workflow, without extracting it
+func NewDescribeCommand(log clog.PluggableLoggerInterface, opts *mirror.CopyOptions) *cobra.Command {
+	ex := &DescribeSchema{
+		Log:  log,
+		Opts: opts,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "describe <file://archive-dir>",
+		Short: "Describe the content of a mirror archive, without extracting it",
+		Example: `  # Describe the archive generated by mirrorToDisk in /home/user/disk
+  oc-mirror describe file:///home/user/disk --v2
+
+  # Describe the archive as json
+  oc-mirror describe file:///home/user/disk -o json --v2`,
+		Args: cobra.ExactArgs(1),
+		// replaces the root PersistentPreRun: the output of describe is meant to be parsed
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if !slices.Contains([]string{"info", "debug", "trace", "error"}, opts.Global.LogLevel) {
+				return fmt.Errorf("log-level has an invalid value %s , it should be one of (info,debug,trace, error)", opts.Global.LogLevel)
+			}
+			log.Level(opts.Global.LogLevel)
+			if err := ex.Validate(args); err != nil {
+				return err
+			}
+			ex.out = cmd.OutOrStdout()
+			if ex.Describer != nil {
+				return nil
+			}
+			describer, err := archive.NewArchiveDescriber(strings.TrimPrefix(args[0], fileProtocol), log)
+			if err != nil {
+				return fmt.Errorf(describeErrMsg, err)
+			}
+			ex.Describer = describer
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return ex.Run()
+		},
+	}
+	cmd.Flags().StringVarP(&ex.Output, "output", "o", outputTable, "Output format, one of (table, json, yaml)")
+	return cmd
+}
+
+// Validate - cobra validation
+func (o *DescribeSchema) Validate(args []string) error {
+	if !strings.HasPrefix(args[0], fileProtocol) {
+		return fmt.Errorf("the archive argument must have a file:// protocol prefix")
+	}
+	if !slices.Contains([]string{outputTable, outputJSON, outputYAML}, o.Output) {
+		return fmt.Errorf("output has an invalid value %s, it should be one of (%s, %s, %s)", o.Output, outputTable, outputJSON, outputYAML)
+	}
+	return nil
+}
+
+// Run - describes the archive
+func (o *DescribeSchema) Run() error {
+	description, err := o.Describer.Describe()
+	if err != nil {
+		return fmt.Errorf(describeErrMsg, err)
+	}
+	if !description.HasMetadata {
+		o.Log.Warn("the archive was generated by an older version of oc-mirror: its images and their size per type are unknown")
+	}
+	if o.Output != outputTable {
+		return writeOutput(o.out, o.Output, description, nil, nil)
+	}
+	return o.writeTable(description)
+}
+
+// writeTable renders the description as a summary followed by a table per content
+func (o *DescribeSchema) writeTable(description archive.Description) error {
+	tw := tabwriter.NewWriter(o.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "CHUNKS\t%s\n", strings.Join(description.Chunks, ","))
+	fmt.Fprintf(tw, "CREATED\t%s\n", description.CreationTime.Format(time.RFC3339))
+	if description.OcMirrorVersion != "" {
+		fmt.Fprintf(tw, "OC-MIRROR VERSION\t%s\n", description.OcMirrorVersion)
+	}
+	if description.HistoryBaseline != nil {
+		fmt.Fprintf(tw, "HISTORY BASELINE\t%s\n", historyBaseline(*description.HistoryBaseline))
+	}
+	fmt.Fprintf(tw, "SIZE\t%s (blobs %s)\n", cache.HumanBytes(description.Sizes.Total), cache.HumanBytes(description.Sizes.Blobs))
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(description.Sizes.Types) > 0 {
+		rows := make([][]string, 0, len(description.Sizes.Types))
+		for _, size := range description.Sizes.Types {
+			rows = append(rows, []string{size.Type, strconv.Itoa(size.Images), cache.HumanBytes(size.Bytes)})
+		}
+		if err := o.writeSection([]string{"TYPE", "IMAGES", "SIZE"}, rows); err != nil {
+			return err
+		}
+	}
+	if err := o.writeSection([]string{"RELEASE"}, singleColumn(description.Releases)); err != nil {
+		return err
+	}
+	rows := [][]string{}
+	for _, catalog := range description.Catalogs {
+		for _, pkg := range catalog.Packages {
+			rows = append(rows, []string{catalog.Catalog, pkg.Name, strings.Join(pkg.Bundles, ",")})
+		}
+	}
+	if err := o.writeSection([]string{"CATALOG", "PACKAGE", "BUNDLES"}, rows); err != nil {
+		return err
+	}
+	if err := o.writeSection([]string{"ADDITIONAL IMAGE"}, singleColumn(description.AdditionalImages)); err != nil {
+		return err
+	}
+	if err := o.writeSection([]string{"HELM CHART"}, singleColumn(description.HelmCharts)); err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(description.ImageSetConfiguration)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(o.out, "\nIMAGESET CONFIGURATION\n%s", data)
+	return err
+}
+
+// writeSection writes a table preceded by an empty line, when it has rows
+func (o *DescribeSchema) writeSection(headers []string, rows [][]string) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	fmt.Fprintln(o.out)
+	return writeOutput(o.out, outputTable, nil, headers, rows)
+}
+
+func singleColumn(values []string) [][]string {
+	rows := make([][]string, 0, len(values))
+	for _, value := range values {
+		rows = append(rows, []string{value})
+	}
+	return rows
+}
+
+// historyBaseline describes the history the archive was generated against
+func historyBaseline(baseline archive.HistoryBaseline) string {
+	backend := "working-dir"
+	if baseline.Backend != "" {
+		backend = baseline.Backend
+	}
+	description := fmt.Sprintf("%s, %d blobs already mirrored", backend, baseline.Blobs)
+	if baseline.Since != nil {
+		description += ", since " + baseline.Since.Format(time.RFC3339)
+	}
+	return description
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/archive"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+)
+
+type mockDescriber struct {
+	description archive.Description
+}
+
+func (o mockDescriber) Describe() (archive.Description, error) {
+	return o.description, nil
+}
+
+func TestDescribeValidate(t *testing.T) {
+	ex := &DescribeSchema{
+		Log:    clog.New("error"),
+		Opts:   &mirror.CopyOptions{Global: &mirror.GlobalOptions{}},
+		Output: outputTable,
+	}
+	assert.EqualError(t, ex.Validate([]string{"/home/user/disk"}), "the archive argument must have a file:// protocol prefix")
+	ex.Output = "csv"
+	assert.EqualError(t, ex.Validate([]string{"file:///home/user/disk"}), "output has an invalid value csv, it should be one of (table, json, yaml)")
+}
+
+func TestDescribeRun(t *testing.T) {
+	description := archive.Description{
+		Chunks:          []string{"mirror_000001.tar", "mirror_000002.tar"},
+		CreationTime:    time.Date(2024, 10, 1, 10, 0, 0, 0, time.UTC),
+		OcMirrorVersion: "4.17.0",
+		HistoryBaseline: &archive.HistoryBaseline{Blobs: 12},
+		HasMetadata:     true,
+		ImageSetConfiguration: v2alpha1.ImageSetConfiguration{
+			ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+				Mirror: v2alpha1.Mirror{AdditionalImages: []v2alpha1.Image{{Name: "registry.redhat.io/ubi8/ubi:latest"}}},
+			},
+		},
+		Releases: []string{"quay.io/openshift-release-dev/ocp-release:4.16.10-x86_64"},
+		Catalogs: []archive.CatalogDescription{{
+			Catalog:  "registry.redhat.io/redhat/redhat-operator-index:v4.16",
+			Packages: []archive.PackageDescription{{Name: "aws-load-balancer-operator", Bundles: []string{"1.1.0", "1.1.1"}}},
+		}},
+		AdditionalImages: []string{"registry.redhat.io/ubi8/ubi:latest"},
+		HelmCharts:       []string{},
+		Sizes: archive.Sizes{
+			Total: 3 * 1024 * 1024,
+			Blobs: 2 * 1024 * 1024,
+			Types: []archive.TypeSize{{Type: "ocpRelease", Images: 1, Bytes: 2 * 1024 * 1024}},
+		},
+	}
+	newDescribeSchema := func(output string) (*DescribeSchema, *bytes.Buffer) {
+		out := &bytes.Buffer{}
+		return &DescribeSchema{
+			Log:       clog.New("error"),
+			Opts:      &mirror.CopyOptions{Global: &mirror.GlobalOptions{}},
+			Describer: mockDescriber{description: description},
+			Output:    output,
+			out:       out,
+		}, out
+	}
+
+	t.Run("Testing describe : table", func(t *testing.T) {
+		ex, out := newDescribeSchema(outputTable)
+		assert.NoError(t, ex.Run())
+		assert.Contains(t, out.String(), "CHUNKS             mirror_000001.tar,mirror_000002.tar\n")
+		assert.Contains(t, out.String(), "HISTORY BASELINE   working-dir, 12 blobs already mirrored\n")
+		assert.Contains(t, out.String(), "SIZE               3.0 MiB (blobs 2.0 MiB)\n")
+		assert.Contains(t, out.String(), "ocpRelease  1       2.0 MiB\n")
+		assert.Contains(t, out.String(), "registry.redhat.io/redhat/redhat-operator-index:v4.16  aws-load-balancer-operator  1.1.0,1.1.1\n")
+		assert.Contains(t, out.String(), "IMAGESET CONFIGURATION\n")
+		assert.NotContains(t, out.String(), "HELM CHART")
+	})
+
+	t.Run("Testing describe : json", func(t *testing.T) {
+		ex, out := newDescribeSchema(outputJSON)
+		assert.NoError(t, ex.Run())
+		var actual archive.Description
+		assert.NoError(t, json.Unmarshal(out.Bytes(), &actual))
+		assert.Equal(t, description, actual)
+	})
+}
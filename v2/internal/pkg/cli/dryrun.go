@@ -3,14 +3,16 @@ package cli
 import (
 	"bytes"
 	"context"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 
 	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/emoji"
 )
 
-func (o *ExecutorSchema) DryRun(ctx context.Context, allImages []v2alpha1.CopyImageSchema) error {
+func (o *ExecutorSchema) DryRun(ctx context.Context, allImages []v2alpha1.CopyImageSchema, catalogs map[string]v2alpha1.CatalogFilterResult) error {
 	// set up location of logs dir
 	outDir := filepath.Join(o.Opts.Global.WorkingDir, dryRunOutDir)
 	// clean up logs directory
@@ -67,9 +69,34 @@ func (o *ExecutorSchema) DryRun(ctx context.Context, allImages []v2alpha1.CopyIm
 		o.Log.Warn("List of missing images in : %s.\nplease re-run the mirror to disk process", missingImgsFilePath)
 	}
 
+	if err := o.dryRunDeprecations(outDir, catalogs); err != nil {
+		return err
+	}
+
 	if len(imagesAvailable) > 0 {
 		o.Log.Info("all %d images required for mirroring are available in local cache. You may proceed with mirroring from disk to disconnected registry", len(imagesAvailable))
 	}
 	o.Log.Info(emoji.PageFacingUp+" list of all images for mirroring in : %s", mappingTxtFilePath)
 	return nil
 }
+
+// dryRunDeprecations lists the deprecations reported by the deprecation policy of the catalogs
+func (o *ExecutorSchema) dryRunDeprecations(outDir string, catalogs map[string]v2alpha1.CatalogFilterResult) error {
+	var buff bytes.Buffer
+	nbDeprecations := 0
+	for _, catalog := range slices.Sorted(maps.Keys(catalogs)) {
+		for _, deprecation := range catalogs[catalog].Deprecations {
+			buff.WriteString(catalog + ": " + deprecation + "\n")
+			nbDeprecations++
+		}
+	}
+	if nbDeprecations == 0 {
+		return nil
+	}
+	deprecationsFilePath := filepath.Join(outDir, deprecationsFile)
+	if err := os.WriteFile(deprecationsFilePath, buff.Bytes(), 0644); err != nil {
+		return err
+	}
+	o.Log.Warn(emoji.Warning+"  %d deprecations reported for the operator catalogs in : %s", nbDeprecations, deprecationsFilePath)
+	return nil
+}
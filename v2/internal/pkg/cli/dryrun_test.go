@@ -92,13 +92,22 @@ func TestDryRun(t *testing.T) {
 			MakeDir:             MakeDir{},
 		}
 
-		err = ex.DryRun(context.TODO(), imgs)
+		catalogs := map[string]v2alpha1.CatalogFilterResult{
+			"docker://registry.redhat.io/redhat/redhat-operator-index:v4.16": {
+				Deprecations: []string{"package op1: bundle op1.v1.0.0 is deprecated: upgrade to op1.v1.1.0"},
+			},
+		}
+		err = ex.DryRun(context.TODO(), imgs, catalogs)
 		if err != nil {
 			t.Fatalf("should not fail")
 		}
 		mappingPath := filepath.Join(testFolder, dryRunOutDir, "mapping.txt")
 		assert.FileExists(t, mappingPath)
 
+		deprecations, err := os.ReadFile(filepath.Join(testFolder, dryRunOutDir, deprecationsFile))
+		assert.NoError(t, err)
+		assert.Equal(t, "docker://registry.redhat.io/redhat/redhat-operator-index:v4.16: package op1: bundle op1.v1.0.0 is deprecated: upgrade to op1.v1.1.0\n", string(deprecations))
+
 		mappingBytes, err := os.ReadFile(mappingPath)
 		if err != nil {
 			t.Fatalf("failed to read mapping file: %v", err)
@@ -165,7 +174,7 @@ func TestDryRun(t *testing.T) {
 			MakeDir:             MakeDir{},
 		}
 
-		err = ex.DryRun(context.TODO(), imgs)
+		err = ex.DryRun(context.TODO(), imgs, nil)
 		if err != nil {
 			t.Fatalf("should not fail")
 		}
@@ -252,6 +252,12 @@ func NewMirrorCmd(log clog.PluggableLoggerInterface) *cobra.Command {
 	}
 	cmd.AddCommand(version.NewVersionCommand(log))
 	cmd.AddCommand(NewDeleteCommand(log, opts))
+	cmd.AddCommand(NewListCommand(log, opts))
+	cmd.AddCommand(NewHistoryCommand(log, opts))
+	cmd.AddCommand(NewArchiveCommand(log, opts))
+	cmd.AddCommand(NewVerifyCommand(log, opts))
+	cmd.AddCommand(NewCacheCommand(log, opts))
+	cmd.AddCommand(NewDescribeCommand(log, opts))
 	// common flags
 	cmd.PersistentFlags().StringVarP(&opts.Global.ConfigPath, "config", "c", "", "Path to imageset configuration file")
 	cmd.MarkPersistentFlagFilename("config", "yaml")
@@ -274,12 +280,18 @@ func NewMirrorCmd(log clog.PluggableLoggerInterface) *cobra.Command {
 	cmd.Flags().BoolVarP(&opts.IsDryRun, "dry-run", "", false, "Print actions without mirroring images")
 	cmd.Flags().BoolVarP(&opts.Global.Quiet, "quiet", "q", false, "Enable detailed logging when copying images")
 	cmd.Flags().BoolVarP(&opts.Global.Force, "force", "f", false, "Force the copy and mirror functionality")
+	cmd.Flags().StringVar(&opts.Global.HistoryBackend, "history-backend", "", "Registry repository where the history of mirrored blobs is kept (docker://registry/namespace/repository:tag), so that mirrorToDisk runs on different hosts share the same incremental baseline. It is accessed with the credentials and TLS settings of the source registries. Default is the working-dir")
 	cmd.Flags().StringVar(&opts.Global.SinceString, "since", "", "Include all new content since specified date (format yyyy-MM-dd). When not provided, new content since previous mirroring is mirrored")
 	cmd.Flags().DurationVar(&opts.Global.CommandTimeout, "image-timeout", 10*time.Minute, "Timeout for mirroring an image")
 	cmd.Flags().BoolVar(&opts.Global.SecurePolicy, "secure-policy", false, "If set, will enable signature verification (secure policy for signature verification)")
 	cmd.Flags().IntVar(&opts.Global.MaxNestedPaths, "max-nested-paths", 0, "Number of nested paths, for destination registries that limit nested paths")
 	cmd.Flags().BoolVar(&opts.Global.StrictArchiving, "strict-archive", false, "If set, generates archives that are strictly less than archiveSize (set in the imageSetConfig). Mirroring will exit in error if a file being archived exceed archiveSize(GB)")
 	cmd.Flags().StringVar(&opts.RootlessStoragePath, "rootless-storage-path", "", "Override the default container rootless storage path (usually in etc/containers/storage.conf)")
+	cmd.Flags().BoolVar(&opts.Global.RegistriesConf, "registries-conf", false, "If set, generates a registries.conf.d drop-in in cluster-resources, for hosts not managed by the machine-config-operator")
+	cmd.Flags().BoolVar(&opts.Global.PolicyJSON, "policy-json", false, "If set along with --registries-conf, generates a policy.json for the mirrored sources in cluster-resources")
+	cmd.Flags().BoolVar(&opts.Global.OperatorInstalls, "operator-install-templates", false, "If set, generates in cluster-resources a ClusterExtension per operator package mirrored, as well as Subscription and OperatorGroup stubs")
+	cmd.Flags().StringVar(&opts.Global.RegistriesWrapper, "registries-conf-wrapper", "", "Wraps the generated registries.conf.d drop-in in a MachineConfig or a Butane config, one of (machineconfig, butane)")
+	cmd.Flags().StringVar(&opts.Global.RegistriesRole, "registries-conf-role", "", "MachineConfigPool role targeted by the MachineConfig or Butane config of --registries-conf-wrapper (default worker)")
 	HideFlags(cmd)
 
 	ex.Opts.Stdout = cmd.OutOrStdout()
@@ -323,6 +335,7 @@ func HideFlags(cmd *cobra.Command) {
 // Validate - cobra validation
 func (o ExecutorSchema) Validate(dest []string) error {
 	keyWords := []string{
+		"additional-images",
 		"cluster-resources",
 		"dry-run",
 		"graph-preparation",
@@ -366,6 +379,23 @@ func (o ExecutorSchema) Validate(dest []string) error {
 			return fmt.Errorf("--since flag needs to be in format yyyy-MM-dd")
 		}
 	}
+	if o.Opts.Global.HistoryBackend != "" && !strings.HasPrefix(o.Opts.Global.HistoryBackend, dockerProtocol) {
+		return fmt.Errorf("when --history-backend is used, it must have docker:// prefix")
+	}
+	if o.Opts.Global.HistoryBackend != "" && len(o.Opts.Global.From) > 0 {
+		o.Log.Warn("history-backend flag is only taken into account during mirrorToDisk workflow")
+	}
+	if !o.Opts.Global.RegistriesConf && (o.Opts.Global.PolicyJSON || o.Opts.Global.RegistriesWrapper != "") {
+		return fmt.Errorf("--policy-json and --registries-conf-wrapper can only be used along with --registries-conf")
+	}
+	switch o.Opts.Global.RegistriesWrapper {
+	case clusterresources.WrapperNone, clusterresources.WrapperMachineConfig, clusterresources.WrapperButane:
+	default:
+		return fmt.Errorf("--registries-conf-wrapper must be one of (%s, %s)", clusterresources.WrapperMachineConfig, clusterresources.WrapperButane)
+	}
+	if o.Opts.Global.RegistriesRole != "" && o.Opts.Global.RegistriesWrapper == clusterresources.WrapperNone {
+		return fmt.Errorf("--registries-conf-role can only be used along with --registries-conf-wrapper")
+	}
 	if strings.Contains(dest[0], fileProtocol) && o.Opts.Global.WorkingDir != "" {
 		return fmt.Errorf("when destination is file://, mirrorToDisk workflow is assumed, and the --workspace argument is not needed")
 	}
@@ -815,7 +845,7 @@ func (o *ExecutorSchema) RunMirrorToDisk(cmd *cobra.Command, args []string) erro
 			return err
 		}
 	} else {
-		err = o.DryRun(cmd.Context(), collectorSchema.AllImages)
+		err = o.DryRun(cmd.Context(), collectorSchema.AllImages, collectorSchema.CatalogToFBCMap)
 		if err != nil {
 			return err
 		}
@@ -880,6 +910,19 @@ func (o *ExecutorSchema) RunMirrorToMirror(cmd *cobra.Command, args []string) er
 			return err
 		}
 
+		// create registries.conf.d drop-in
+		if o.Opts.Global.RegistriesConf {
+			registriesConfOpts := clusterresources.RegistriesConfOptions{
+				PolicyJSON: o.Opts.Global.PolicyJSON,
+				Wrapper:    o.Opts.Global.RegistriesWrapper,
+				Role:       o.Opts.Global.RegistriesRole,
+			}
+			err = o.ClusterResources.RegistriesConfGenerator(copiedSchema.AllImages, forceRepositoryScope, registriesConfOpts)
+			if err != nil {
+				return err
+			}
+		}
+
 		err = o.ClusterResources.CatalogSourceGenerator(copiedSchema.AllImages)
 		if err != nil {
 			return err
@@ -889,6 +932,12 @@ func (o *ExecutorSchema) RunMirrorToMirror(cmd *cobra.Command, args []string) er
 			return err
 		}
 
+		if o.Opts.Global.OperatorInstalls {
+			if err := o.ClusterResources.OperatorInstallGenerator(copiedSchema.AllImages); err != nil {
+				return err
+			}
+		}
+
 		// generate signature config map
 		err = o.ClusterResources.GenerateSignatureConfigMap(copiedSchema.AllImages)
 		if err != nil {
@@ -896,6 +945,11 @@ func (o *ExecutorSchema) RunMirrorToMirror(cmd *cobra.Command, args []string) er
 			o.Log.Warn("%s", err)
 		}
 
+		// create samples operator config
+		if err := o.ClusterResources.SamplesConfigGenerator(copiedSchema.AllImages); err != nil {
+			return err
+		}
+
 		// create updateService
 		if o.Config.Mirror.Platform.Graph {
 			graphImage, err := o.Release.GraphImage()
@@ -912,7 +966,7 @@ func (o *ExecutorSchema) RunMirrorToMirror(cmd *cobra.Command, args []string) er
 			}
 		}
 	} else {
-		err = o.DryRun(cmd.Context(), collectorSchema.AllImages)
+		err = o.DryRun(cmd.Context(), collectorSchema.AllImages, collectorSchema.CatalogToFBCMap)
 		if err != nil {
 			return err
 		}
@@ -980,6 +1034,19 @@ func (o *ExecutorSchema) RunDiskToMirror(cmd *cobra.Command, args []string) erro
 			return err
 		}
 
+		// create registries.conf.d drop-in
+		if o.Opts.Global.RegistriesConf {
+			registriesConfOpts := clusterresources.RegistriesConfOptions{
+				PolicyJSON: o.Opts.Global.PolicyJSON,
+				Wrapper:    o.Opts.Global.RegistriesWrapper,
+				Role:       o.Opts.Global.RegistriesRole,
+			}
+			err = o.ClusterResources.RegistriesConfGenerator(copiedSchema.AllImages, forceRepositoryScope, registriesConfOpts)
+			if err != nil {
+				return err
+			}
+		}
+
 		// create catalog source
 		err = o.ClusterResources.CatalogSourceGenerator(copiedSchema.AllImages)
 		if err != nil {
@@ -990,6 +1057,12 @@ func (o *ExecutorSchema) RunDiskToMirror(cmd *cobra.Command, args []string) erro
 			return err
 		}
 
+		if o.Opts.Global.OperatorInstalls {
+			if err := o.ClusterResources.OperatorInstallGenerator(copiedSchema.AllImages); err != nil {
+				return err
+			}
+		}
+
 		// generate signature config map
 		err = o.ClusterResources.GenerateSignatureConfigMap(copiedSchema.AllImages)
 		if err != nil {
@@ -997,6 +1070,11 @@ func (o *ExecutorSchema) RunDiskToMirror(cmd *cobra.Command, args []string) erro
 			o.Log.Warn("%s", err)
 		}
 
+		// create samples operator config
+		if err := o.ClusterResources.SamplesConfigGenerator(copiedSchema.AllImages); err != nil {
+			return err
+		}
+
 		// create updateService
 		if o.Config.Mirror.Platform.Graph {
 			graphImage, err := o.Release.GraphImage()
@@ -1013,7 +1091,7 @@ func (o *ExecutorSchema) RunDiskToMirror(cmd *cobra.Command, args []string) erro
 			}
 		}
 	} else {
-		err = o.DryRun(cmd.Context(), collectorSchema.AllImages)
+		err = o.DryRun(cmd.Context(), collectorSchema.AllImages, collectorSchema.CatalogToFBCMap)
 		if err != nil {
 			return err
 		}
@@ -1146,61 +1224,77 @@ func (o *ExecutorSchema) CollectAll(ctx context.Context) (v2alpha1.CollectorSche
 
 func (o *ExecutorSchema) RebuildCatalogs(ctx context.Context, operatorImgs v2alpha1.CollectorSchema) error {
 	// CLID-230 rebuild-catalogs
-	oImgs := operatorImgs.AllImages
 	if o.Opts.IsMirrorToDisk() || o.Opts.IsMirrorToMirror() {
-		o.Log.Info(emoji.RepeatSingleButton + " rebuilding catalogs")
+		return o.rebuildCatalogs(ctx, operatorImgs)
+	}
+	return nil
+}
 
-		for _, copyImage := range oImgs {
+// rebuildCatalogs rebuilds, with the filtered declarative config, the catalogs of the collected images that need it
+func (o *ExecutorSchema) rebuildCatalogs(ctx context.Context, operatorImgs v2alpha1.CollectorSchema) error {
+	oImgs := operatorImgs.AllImages
+	o.Log.Info(emoji.RepeatSingleButton + " rebuilding catalogs")
 
-			if copyImage.Type == v2alpha1.TypeOperatorCatalog {
-				if o.Opts.IsMirrorToMirror() && strings.Contains(copyImage.Source, o.Opts.LocalStorageFQDN) {
-					// CLID-275: this is the ref to the already rebuilt catalog, which needs to be mirrored to destination.
-					continue
-				}
-				if !o.Opts.Global.IsTerminal {
-					o.Log.Info("Rebuilding catalog %s", copyImage.Origin)
-				}
-				p := mpb.New(mpb.ContainerOptional(mpb.WithOutput(io.Discard), !o.Opts.Global.IsTerminal))
-				spinner := p.AddSpinner(
-					1, mpb.BarFillerMiddleware(spinners.PositionSpinnerLeft),
-					mpb.BarWidth(3),
-					mpb.PrependDecorators(
-						decor.OnComplete(spinners.EmptyDecorator(), "\x1b[1;92m ✓ \x1b[0m"),
-						decor.OnAbort(spinners.EmptyDecorator(), "\x1b[1;91m ✗ \x1b[0m"),
-					),
-					mpb.AppendDecorators(
-						decor.Name("("),
-						decor.Elapsed(decor.ET_STYLE_GO),
-						decor.Name(") Rebuilding catalog "+copyImage.Origin+" "),
-					),
-					mpb.BarFillerClearOnComplete(),
-					spinners.BarFillerClearOnAbort(),
-				)
-				ref, err := image.ParseRef(copyImage.Origin)
+	for i, copyImage := range oImgs {
+
+		if copyImage.Type == v2alpha1.TypeOperatorCatalog {
+			if o.Opts.IsMirrorToMirror() && strings.Contains(copyImage.Source, o.Opts.LocalStorageFQDN) {
+				// CLID-275: this is the ref to the already rebuilt catalog, which needs to be mirrored to destination.
+				continue
+			}
+			if !o.Opts.Global.IsTerminal {
+				o.Log.Info("Rebuilding catalog %s", copyImage.Origin)
+			}
+			p := mpb.New(mpb.ContainerOptional(mpb.WithOutput(io.Discard), !o.Opts.Global.IsTerminal))
+			spinner := p.AddSpinner(
+				1, mpb.BarFillerMiddleware(spinners.PositionSpinnerLeft),
+				mpb.BarWidth(3),
+				mpb.PrependDecorators(
+					decor.OnComplete(spinners.EmptyDecorator(), "\x1b[1;92m ✓ \x1b[0m"),
+					decor.OnAbort(spinners.EmptyDecorator(), "\x1b[1;91m ✗ \x1b[0m"),
+				),
+				mpb.AppendDecorators(
+					decor.Name("("),
+					decor.Elapsed(decor.ET_STYLE_GO),
+					decor.Name(") Rebuilding catalog "+copyImage.Origin+" "),
+				),
+				mpb.BarFillerClearOnComplete(),
+				spinners.BarFillerClearOnAbort(),
+			)
+			ref, err := image.ParseRef(copyImage.Origin)
+			if err != nil {
+				spinner.Abort(false)
+				return fmt.Errorf("unable to rebuild catalog %s: %v", copyImage.Origin, err)
+			}
+			// a catalog from a file-based catalog directory has no image to copy:
+			// the image copied is the catalog built in the cache
+			if strings.HasPrefix(copyImage.Source, fbcProtocol) {
+				rebuiltRef, err := image.ParseRef(copyImage.Destination)
 				if err != nil {
 					spinner.Abort(false)
 					return fmt.Errorf("unable to rebuild catalog %s: %v", copyImage.Origin, err)
 				}
-				filteredConfigPath := ""
-				ctlgFilterResult, ok := operatorImgs.CatalogToFBCMap[ref.ReferenceWithTransport]
-				if ok {
-					filteredConfigPath = ctlgFilterResult.FilteredConfigPath
-					if !ctlgFilterResult.ToRebuild {
-						spinner.Abort(true)
-						continue
-					}
-				} else {
-					spinner.Abort(false)
-					return fmt.Errorf("unable to rebuild catalog %s: filtered declarative config not found", copyImage.Origin)
-				}
-				err = o.CatalogBuilder.RebuildCatalog(ctx, copyImage, filteredConfigPath)
-				if err != nil {
-					spinner.Abort(false)
-					return fmt.Errorf("unable to rebuild catalog %s: %v", copyImage.Origin, err)
+				oImgs[i].Source = rebuiltRef.SetTag(copyImage.RebuiltTag).ReferenceWithTransport
+			}
+			filteredConfigPath := ""
+			ctlgFilterResult, ok := operatorImgs.CatalogToFBCMap[ref.ReferenceWithTransport]
+			if ok {
+				filteredConfigPath = ctlgFilterResult.FilteredConfigPath
+				if !ctlgFilterResult.ToRebuild {
+					spinner.Abort(true)
+					continue
 				}
-				spinner.Increment()
-				p.Wait()
+			} else {
+				spinner.Abort(false)
+				return fmt.Errorf("unable to rebuild catalog %s: filtered declarative config not found", copyImage.Origin)
+			}
+			err = o.CatalogBuilder.RebuildCatalog(ctx, copyImage, filteredConfigPath)
+			if err != nil {
+				spinner.Abort(false)
+				return fmt.Errorf("unable to rebuild catalog %s: %v", copyImage.Origin, err)
 			}
+			spinner.Increment()
+			p.Wait()
 		}
 	}
 	return nil
@@ -16,6 +16,7 @@ import (
 	"github.com/distribution/distribution/v3/configuration"
 	"github.com/distribution/distribution/v3/registry"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/clusterresources"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/common"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/config"
 	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
@@ -564,6 +565,13 @@ func TestExecutorValidate(t *testing.T) {
 		opts.Global.WorkingDir = "" //reset
 		assert.Equal(t, "when destination is docker://, either --from (assumes disk to mirror workflow) or --workspace (assumes mirror to mirror workflow) need to be provided", ex.Validate([]string{"docker://test"}).Error())
 
+		// --registries-conf-role needs the MachineConfig or Butane wrapper
+		opts.Global.WorkingDir = "file://test"
+		opts.Global.RegistriesConf = true
+		opts.Global.RegistriesRole = "master"
+		assert.Equal(t, "--registries-conf-role can only be used along with --registries-conf-wrapper", ex.Validate([]string{"docker://test"}).Error())
+		opts.Global.RegistriesWrapper = "machineconfig"
+		assert.NoError(t, ex.Validate([]string{"docker://test"}))
 	})
 }
 
@@ -991,6 +999,18 @@ func (o MockClusterResources) ClusterCatalogGenerator(allRelatedImages []v2alpha
 	return nil
 }
 
+func (o MockClusterResources) RegistriesConfGenerator(allRelatedImages []v2alpha1.CopyImageSchema, forceRepositoryScope bool, opts clusterresources.RegistriesConfOptions) error {
+	return nil
+}
+
+func (o MockClusterResources) SamplesConfigGenerator(allRelatedImages []v2alpha1.CopyImageSchema) error {
+	return nil
+}
+
+func (o MockClusterResources) OperatorInstallGenerator(allRelatedImages []v2alpha1.CopyImageSchema) error {
+	return nil
+}
+
 func (o Batch) Worker(ctx context.Context, collectorSchema v2alpha1.CollectorSchema, opts mirror.CopyOptions) (v2alpha1.CollectorSchema, error) {
 	copiedImages := v2alpha1.CollectorSchema{
 		AllImages:             []v2alpha1.CopyImageSchema{},
@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/history"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+)
+
+const historyErrMsg = "[history] %v"
+
+// HistorySchema holds what the history sub commands need
+// in order to read the runs recorded in the working-dir
+type HistorySchema struct {
+	Log      clog.PluggableLoggerInterface
+	Opts     *mirror.CopyOptions
+	Database history.Database
+	Output   string
+	out      io.Writer
+}
+
+// NewHistoryCommand - setup the 'history' sub command, listing the runs recorded
+// in the history of a working-dir, the images of a run, and the difference between runs
+func NewHistoryCommand(log clog.PluggableLoggerInterface, opts *mirror.CopyOptions) *cobra.Command {
+	ex := &HistorySchema{
+		Log:  log,
+		Opts: opts,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show the mirroring runs recorded in the history of a workspace",
+		Example: `  # List the runs of a mirrorToDisk workflow
+  oc-mirror history list --workspace file://<archive-dir> --v2
+
+  # Show the images mirrored by run 3
+  oc-mirror history show 3 --workspace file://<archive-dir> --v2
+
+  # Show the images added, removed or updated between runs 2 and 3, as json
+  oc-mirror history diff 2 3 --workspace file://<archive-dir> -o json --v2`,
+		// replaces the root PersistentPreRun: the output of history is meant to be parsed
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if !slices.Contains([]string{"info", "debug", "trace", "error"}, opts.Global.LogLevel) {
+				return fmt.Errorf("log-level has an invalid value %s , it should be one of (info,debug,trace, error)", opts.Global.LogLevel)
+			}
+			log.Level(opts.Global.LogLevel)
+			if !slices.Contains([]string{outputTable, outputJSON, outputYAML}, ex.Output) {
+				return fmt.Errorf("output has an invalid value %s, it should be one of (%s, %s, %s)", ex.Output, outputTable, outputJSON, outputYAML)
+			}
+			if !strings.HasPrefix(opts.Global.WorkingDir, fileProtocol) {
+				return fmt.Errorf("use the --workspace flag with file:// prefix, it is mandatory")
+			}
+			ex.out = cmd.OutOrStdout()
+			if ex.Database != nil {
+				return nil
+			}
+			workingDirPath := strings.TrimPrefix(opts.Global.WorkingDir, fileProtocol)
+			if filepath.Base(workingDirPath) != workingDir {
+				workingDirPath = filepath.Join(workingDirPath, workingDir)
+			}
+			db, err := history.NewDatabase(workingDirPath, log)
+			if err != nil {
+				return fmt.Errorf(historyErrMsg, err)
+			}
+			ex.Database = db
+			return nil
+		},
+	}
+	cmd.PersistentFlags().StringVarP(&ex.Output, "output", "o", outputTable, "Output format, one of (table, json, yaml)")
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the recorded runs",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return ex.listRuns()
+		},
+	}
+	showCmd := &cobra.Command{
+		Use:   "show <run>",
+		Short: "Show the images mirrored by a run",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseRunID(args[0])
+			if err != nil {
+				return err
+			}
+			return ex.showRun(id)
+		},
+	}
+	diffCmd := &cobra.Command{
+		Use:   "diff <from-run> <to-run>",
+		Short: "Show the images added, removed or updated between two runs",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			from, err := parseRunID(args[0])
+			if err != nil {
+				return err
+			}
+			to, err := parseRunID(args[1])
+			if err != nil {
+				return err
+			}
+			return ex.diffRuns(from, to)
+		},
+	}
+
+	cmd.AddCommand(listCmd, showCmd, diffCmd)
+	return cmd
+}
+
+func (o *HistorySchema) listRuns() error {
+	runs, err := o.Database.Runs()
+	if err != nil {
+		return fmt.Errorf(historyErrMsg, err)
+	}
+	rows := make([][]string, 0, len(runs))
+	for _, run := range runs {
+		rows = append(rows, []string{strconv.FormatUint(run.ID, 10), run.Date.Format(time.RFC3339), strconv.Itoa(run.Images), strconv.Itoa(run.Blobs), run.Archive})
+	}
+	return writeOutput(o.out, o.Output, runs, []string{"RUN", "DATE", "IMAGES", "NEW BLOBS", "ARCHIVE"}, rows)
+}
+
+func (o *HistorySchema) showRun(id uint64) error {
+	run, err := o.Database.Run(id)
+	if err != nil {
+		return fmt.Errorf(historyErrMsg, err)
+	}
+	rows := make([][]string, 0, len(run.Images))
+	for _, img := range run.Images {
+		rows = append(rows, []string{img.Origin, img.Type, img.Digest, strconv.Itoa(len(img.Blobs))})
+	}
+	return writeOutput(o.out, o.Output, run, []string{"IMAGE", "TYPE", "DIGEST", "BLOBS"}, rows)
+}
+
+func (o *HistorySchema) diffRuns(fromID, toID uint64) error {
+	from, err := o.Database.Run(fromID)
+	if err != nil {
+		return fmt.Errorf(historyErrMsg, err)
+	}
+	to, err := o.Database.Run(toID)
+	if err != nil {
+		return fmt.Errorf(historyErrMsg, err)
+	}
+	diff := history.DiffRuns(from, to)
+	rows := make([][]string, 0, len(diff.Added)+len(diff.Removed)+len(diff.Changed))
+	for _, img := range diff.Added {
+		rows = append(rows, []string{"added", img.Origin, "", img.Digest})
+	}
+	for _, img := range diff.Removed {
+		rows = append(rows, []string{"removed", img.Origin, img.Digest, ""})
+	}
+	for _, change := range diff.Changed {
+		rows = append(rows, []string{"updated", change.Origin, change.From, change.To})
+	}
+	return writeOutput(o.out, o.Output, diff, []string{"CHANGE", "IMAGE", "FROM", "TO"}, rows)
+}
+
+func parseRunID(arg string) (uint64, error) {
+	id, err := strconv.ParseUint(arg, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid run %s, it should be the number of the run as listed by `oc-mirror history list`", arg)
+	}
+	return id, nil
+}
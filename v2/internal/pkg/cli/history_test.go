@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/history"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+)
+
+type mockHistoryDatabase struct {
+	runs map[uint64]history.Run
+}
+
+func (o mockHistoryDatabase) Runs() ([]history.RunSummary, error) {
+	summaries := []history.RunSummary{}
+	for id := uint64(1); id <= uint64(len(o.runs)); id++ {
+		summaries = append(summaries, o.runs[id].Summary())
+	}
+	return summaries, nil
+}
+
+func (o mockHistoryDatabase) Run(id uint64) (history.Run, error) {
+	run, ok := o.runs[id]
+	if !ok {
+		return history.Run{}, fmt.Errorf("run %d not found in history", id)
+	}
+	return run, nil
+}
+
+func TestHistoryCommand(t *testing.T) {
+	date := time.Date(2024, 10, 1, 10, 0, 0, 0, time.UTC)
+	db := mockHistoryDatabase{runs: map[uint64]history.Run{
+		1: {ID: 1, Date: date, Archive: "/archives", Blobs: []string{"sha256:1dddb0988d16", "sha256:3658954f1990"}, Images: []history.RunImage{
+			{Origin: "quay.io/foo/bar:v1", Digest: "sha256:1dddb0988d16", Type: "additionalImage", Blobs: []string{"sha256:1dddb0988d16", "sha256:3658954f1990"}},
+		}},
+		2: {ID: 2, Date: date.Add(24 * time.Hour), Archive: "/archives", Blobs: []string{"sha256:e3dad360d035"}, Images: []history.RunImage{
+			{Origin: "quay.io/foo/bar:v1", Digest: "sha256:e3dad360d035", Type: "additionalImage", Blobs: []string{"sha256:e3dad360d035", "sha256:3658954f1990"}},
+		}},
+	}}
+
+	newHistorySchema := func(output string) (*HistorySchema, *bytes.Buffer) {
+		out := &bytes.Buffer{}
+		return &HistorySchema{
+			Log:      clog.New("error"),
+			Opts:     &mirror.CopyOptions{Global: &mirror.GlobalOptions{}},
+			Database: db,
+			Output:   output,
+			out:      out,
+		}, out
+	}
+
+	t.Run("Testing history list : table", func(t *testing.T) {
+		ex, out := newHistorySchema(outputTable)
+		assert.NoError(t, ex.listRuns())
+		assert.Equal(t, "RUN  DATE                  IMAGES  NEW BLOBS  ARCHIVE\n"+
+			"1    2024-10-01T10:00:00Z  1       2          /archives\n"+
+			"2    2024-10-02T10:00:00Z  1       1          /archives\n", out.String())
+	})
+
+	t.Run("Testing history show : json", func(t *testing.T) {
+		ex, out := newHistorySchema(outputJSON)
+		assert.NoError(t, ex.showRun(2))
+		assert.Contains(t, out.String(), `"digest": "sha256:e3dad360d035"`)
+
+		assert.EqualError(t, ex.showRun(3), "[history] run 3 not found in history")
+	})
+
+	t.Run("Testing history diff : table", func(t *testing.T) {
+		ex, out := newHistorySchema(outputTable)
+		assert.NoError(t, ex.diffRuns(1, 2))
+		assert.Equal(t, "CHANGE   IMAGE               FROM                 TO\n"+
+			"updated  quay.io/foo/bar:v1  sha256:1dddb0988d16  sha256:e3dad360d035\n", out.String())
+	})
+
+	t.Run("Testing history : run must be a number", func(t *testing.T) {
+		_, err := parseRunID("latest")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,311 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/manifest"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/operator"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/release"
+)
+
+const (
+	listErrMsg        = "[list] %v"
+	outputTable       = "table"
+	outputJSON        = "json"
+	outputYAML        = "yaml"
+	defaultListArch   = "amd64"
+	listWorkingDirTmp = "oc-mirror-list-"
+)
+
+// ListSchema holds what the list sub commands need in order
+// to render catalogs and query the update graph
+type ListSchema struct {
+	Log             clog.PluggableLoggerInterface
+	Opts            *mirror.CopyOptions
+	CatalogRenderer operator.CatalogRendererInterface
+	Cincinnati      release.CincinnatiSchema
+	Catalog         string
+	Package         string
+	Channel         string
+	Version         string
+	Arch            string
+	Output          string
+	out             io.Writer
+	tmpDir          string
+}
+
+// NewListCommand - setup the 'list' sub command, helping to write
+// an ImageSetConfiguration (catalog packages, channels, bundles and releases)
+func NewListCommand(log clog.PluggableLoggerInterface, opts *mirror.CopyOptions) *cobra.Command {
+	ex := &ListSchema{
+		Log:  log,
+		Opts: opts,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List operator catalog content and OpenShift releases available for mirroring",
+		Example: `  # List the packages of a catalog, with their default channel and its head
+  oc-mirror list operators --catalog registry.redhat.io/redhat/redhat-operator-index:v4.16 --v2
+
+  # List the channels of a package
+  oc-mirror list channels --catalog registry.redhat.io/redhat/redhat-operator-index:v4.16 --package aws-load-balancer-operator --v2
+
+  # List the bundles of a package channel, as json
+  oc-mirror list bundles --catalog registry.redhat.io/redhat/redhat-operator-index:v4.16 --package aws-load-balancer-operator --channel stable-v1 -o json --v2
+
+  # List the channels of an OpenShift version
+  oc-mirror list releases --version 4.16 --v2
+
+  # List the releases of a channel
+  oc-mirror list releases --channel stable-4.16 --v2`,
+		// replaces the root PersistentPreRun: the output of list is meant to be parsed
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if !slices.Contains([]string{"info", "debug", "trace", "error"}, opts.Global.LogLevel) {
+				return fmt.Errorf("log-level has an invalid value %s , it should be one of (info,debug,trace, error)", opts.Global.LogLevel)
+			}
+			log.Level(opts.Global.LogLevel)
+			if !slices.Contains([]string{outputTable, outputJSON, outputYAML}, ex.Output) {
+				return fmt.Errorf("output has an invalid value %s, it should be one of (%s, %s, %s)", ex.Output, outputTable, outputJSON, outputYAML)
+			}
+			if len(opts.Global.WorkingDir) > 0 && !strings.Contains(opts.Global.WorkingDir, fileProtocol) {
+				return fmt.Errorf("when --workspace is used, it must have file:// prefix")
+			}
+			ex.out = cmd.OutOrStdout()
+			return nil
+		},
+	}
+	cmd.PersistentFlags().StringVarP(&ex.Output, "output", "o", outputTable, "Output format, one of (table, json, yaml)")
+
+	operatorsCmd := &cobra.Command{
+		Use:   "operators",
+		Short: "List the packages of an operator catalog",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return ex.runCatalogList(cmd.Context(), ex.listOperators)
+		},
+	}
+	operatorsCmd.Flags().StringVar(&ex.Catalog, "catalog", "", "Operator catalog image (docker reference or oci:// path)")
+
+	channelsCmd := &cobra.Command{
+		Use:   "channels",
+		Short: "List the channels of a package, with their head",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if ex.Package == "" {
+				return fmt.Errorf("the --package flag is mandatory")
+			}
+			return ex.runCatalogList(cmd.Context(), ex.listChannels)
+		},
+	}
+	channelsCmd.Flags().StringVar(&ex.Catalog, "catalog", "", "Operator catalog image (docker reference or oci:// path)")
+	channelsCmd.Flags().StringVar(&ex.Package, "package", "", "Package of the catalog")
+
+	bundlesCmd := &cobra.Command{
+		Use:   "bundles",
+		Short: "List the bundles of a package, with the number of related images",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if ex.Package == "" {
+				return fmt.Errorf("the --package flag is mandatory")
+			}
+			return ex.runCatalogList(cmd.Context(), ex.listBundles)
+		},
+	}
+	bundlesCmd.Flags().StringVar(&ex.Catalog, "catalog", "", "Operator catalog image (docker reference or oci:// path)")
+	bundlesCmd.Flags().StringVar(&ex.Package, "package", "", "Package of the catalog")
+	bundlesCmd.Flags().StringVar(&ex.Channel, "channel", "", "Only list the bundles of this channel")
+
+	releasesCmd := &cobra.Command{
+		Use:   "releases",
+		Short: "List the channels of an OpenShift version, or the releases of a channel",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (ex.Channel == "") == (ex.Version == "") {
+				return fmt.Errorf("exactly one of --channel or --version is required")
+			}
+			cleanup, err := ex.setupWorkingDir()
+			if err != nil {
+				return err
+			}
+			defer cleanup()
+			if err := ex.setupCincinnati(); err != nil {
+				return err
+			}
+			return ex.listReleases(cmd.Context())
+		},
+	}
+	releasesCmd.Flags().StringVar(&ex.Channel, "channel", "", "List the releases of this channel (e.g. stable-4.16)")
+	releasesCmd.Flags().StringVar(&ex.Version, "version", "", "List the channels of this OpenShift version (e.g. 4.16)")
+	releasesCmd.Flags().StringVar(&ex.Arch, "arch", defaultListArch, "Architecture of the releases, one of (amd64, arm64, ppc64le, s390x, multi)")
+
+	cmd.AddCommand(operatorsCmd, channelsCmd, bundlesCmd, releasesCmd)
+	return cmd
+}
+
+// runCatalogList renders the catalog set with --catalog and lists its content
+func (o *ListSchema) runCatalogList(ctx context.Context, list func(operator.OperatorCatalog) error) error {
+	if o.Catalog == "" {
+		return fmt.Errorf("the --catalog flag is mandatory")
+	}
+	cleanup, err := o.setupWorkingDir()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if o.CatalogRenderer == nil {
+		// a single architecture is enough to read the declarative config
+		o.Opts.MultiArch = "system"
+		o.Opts.RemoveSignatures = true
+		o.CatalogRenderer = operator.NewCatalogRenderer(o.Log, *o.Opts, mirror.New(mirror.NewMirrorCopy(), nil), manifest.New(o.Log))
+	}
+	operatorCatalog, err := o.CatalogRenderer.RenderCatalog(ctx, o.Catalog)
+	if err != nil {
+		return fmt.Errorf(listErrMsg, err)
+	}
+	return list(operatorCatalog)
+}
+
+func (o *ListSchema) listOperators(operatorCatalog operator.OperatorCatalog) error {
+	packages := operator.ListPackages(operatorCatalog)
+	rows := make([][]string, 0, len(packages))
+	for _, pkg := range packages {
+		rows = append(rows, []string{pkg.Name, pkg.DefaultChannel, pkg.Head})
+	}
+	return o.writeList(packages, []string{"NAME", "DEFAULT CHANNEL", "HEAD"}, rows)
+}
+
+func (o *ListSchema) listChannels(operatorCatalog operator.OperatorCatalog) error {
+	channels, err := operator.ListChannels(operatorCatalog, o.Package)
+	if err != nil {
+		return fmt.Errorf(listErrMsg, err)
+	}
+	rows := make([][]string, 0, len(channels))
+	for _, ch := range channels {
+		rows = append(rows, []string{ch.Package, ch.Name, ch.Head, strconv.Itoa(ch.Bundles)})
+	}
+	return o.writeList(channels, []string{"PACKAGE", "CHANNEL", "HEAD", "BUNDLES"}, rows)
+}
+
+func (o *ListSchema) listBundles(operatorCatalog operator.OperatorCatalog) error {
+	bundles, err := operator.ListBundles(operatorCatalog, o.Package, o.Channel)
+	if err != nil {
+		return fmt.Errorf(listErrMsg, err)
+	}
+	rows := make([][]string, 0, len(bundles))
+	for _, b := range bundles {
+		rows = append(rows, []string{b.Name, b.Version, strings.Join(b.Channels, ","), strconv.Itoa(b.RelatedImages)})
+	}
+	return o.writeList(bundles, []string{"BUNDLE", "VERSION", "CHANNELS", "RELATED IMAGES"}, rows)
+}
+
+func (o *ListSchema) listReleases(ctx context.Context) error {
+	if o.Version != "" {
+		channels, err := release.ListChannels(ctx, o.Cincinnati, o.Version)
+		if err != nil {
+			return fmt.Errorf(listErrMsg, err)
+		}
+		rows := make([][]string, 0, len(channels))
+		for _, ch := range channels {
+			rows = append(rows, []string{ch})
+		}
+		return o.writeList(channels, []string{"CHANNEL"}, rows)
+	}
+	releases, err := release.ListReleases(ctx, o.Cincinnati, o.Channel)
+	if err != nil {
+		return fmt.Errorf(listErrMsg, err)
+	}
+	rows := make([][]string, 0, len(releases))
+	for _, r := range releases {
+		rows = append(rows, []string{r.Version, r.Image})
+	}
+	return o.writeList(releases, []string{"VERSION", "IMAGE"}, rows)
+}
+
+// setupWorkingDir uses the working-dir of --workspace when set, so that
+// catalogs are not pulled again when mirroring. Otherwise a temporary
+// directory is used and removed by the returned cleanup function.
+func (o *ListSchema) setupWorkingDir() (func(), error) {
+	if o.Opts.Global.WorkingDir != "" {
+		o.Opts.Global.WorkingDir = strings.TrimPrefix(o.Opts.Global.WorkingDir, fileProtocol)
+		if filepath.Base(o.Opts.Global.WorkingDir) != workingDir {
+			o.Opts.Global.WorkingDir = filepath.Join(o.Opts.Global.WorkingDir, workingDir)
+		}
+		return func() {}, os.MkdirAll(o.Opts.Global.WorkingDir, 0755)
+	}
+	tmpDir, err := os.MkdirTemp("", listWorkingDirTmp)
+	if err != nil {
+		return func() {}, fmt.Errorf(listErrMsg, err)
+	}
+	o.tmpDir = tmpDir
+	o.Opts.Global.WorkingDir = filepath.Join(tmpDir, workingDir)
+	return func() { os.RemoveAll(tmpDir) }, nil
+}
+
+func (o *ListSchema) setupCincinnati() error {
+	if o.Cincinnati.Client != nil {
+		return nil
+	}
+	client, err := release.NewOCPClient(uuid.New(), o.Log)
+	if err != nil {
+		return fmt.Errorf(listErrMsg, err)
+	}
+	graphDataDir := filepath.Join(o.Opts.Global.WorkingDir, releaseImageExtractDir, cincinnatiGraphDataDir)
+	if err := os.MkdirAll(graphDataDir, 0755); err != nil {
+		return fmt.Errorf(listErrMsg, err)
+	}
+	o.Cincinnati = release.CincinnatiSchema{
+		Log:    o.Log,
+		Opts:   *o.Opts,
+		Client: client,
+		CincinnatiParams: release.CincinnatiParams{
+			GraphDataDir: graphDataDir,
+			Arch:         o.Arch,
+		},
+	}
+	return nil
+}
+
+// writeList renders the items as a table (headers and rows), or marshals them as json or yaml
+func (o *ListSchema) writeList(items any, headers []string, rows [][]string) error {
+	return writeOutput(o.out, o.Output, items, headers, rows)
+}
+
+// writeOutput renders the items as a table (headers and rows), or marshals them as json or yaml
+func writeOutput(out io.Writer, format string, items any, headers []string, rows [][]string) error {
+	switch format {
+	case outputJSON:
+		data, err := json.MarshalIndent(items, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	case outputYAML:
+		data, err := yaml.Marshal(items)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(data)
+		return err
+	default:
+		tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.Join(headers, "\t"))
+		for _, row := range rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+	}
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/stretchr/testify/assert"
+
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/operator"
+)
+
+type mockCatalogRenderer struct{}
+
+func (o mockCatalogRenderer) RenderCatalog(ctx context.Context, catalog string) (operator.OperatorCatalog, error) {
+	return operator.OperatorCatalog{
+		Packages: map[string]declcfg.Package{
+			"foo": {Name: "foo", DefaultChannel: "stable"},
+		},
+		Channels: map[string][]declcfg.Channel{
+			"foo": {{Name: "stable", Package: "foo", Entries: []declcfg.ChannelEntry{{Name: "foo.v0.1.0"}}}},
+		},
+		ChannelEntries: map[string]map[string]map[string]declcfg.ChannelEntry{
+			"foo": {"stable": {"foo.v0.1.0": {Name: "foo.v0.1.0"}}},
+		},
+		BundlesByPkgAndName: map[string]map[string]declcfg.Bundle{
+			"foo": {"foo.v0.1.0": {Name: "foo.v0.1.0", Package: "foo", RelatedImages: []declcfg.RelatedImage{{Image: "quay.io/foo/foo:v0.1.0"}}}},
+		},
+	}, nil
+}
+
+func TestListCommand(t *testing.T) {
+	log := clog.New("error")
+
+	newListSchema := func(output string) (*ListSchema, *bytes.Buffer) {
+		out := &bytes.Buffer{}
+		return &ListSchema{
+			Log:             log,
+			Opts:            &mirror.CopyOptions{Global: &mirror.GlobalOptions{}},
+			CatalogRenderer: mockCatalogRenderer{},
+			Catalog:         "registry.example.com/foo/catalog:v1",
+			Package:         "foo",
+			Output:          output,
+			out:             out,
+		}, out
+	}
+
+	t.Run("Testing list operators : table", func(t *testing.T) {
+		ex, out := newListSchema(outputTable)
+		assert.NoError(t, ex.runCatalogList(context.Background(), ex.listOperators))
+		assert.Equal(t, "NAME  DEFAULT CHANNEL  HEAD\nfoo   stable           foo.v0.1.0\n", out.String())
+	})
+
+	t.Run("Testing list channels : json", func(t *testing.T) {
+		ex, out := newListSchema(outputJSON)
+		assert.NoError(t, ex.runCatalogList(context.Background(), ex.listChannels))
+		assert.JSONEq(t, `[{"package":"foo","name":"stable","head":"foo.v0.1.0","bundles":1}]`, out.String())
+	})
+
+	t.Run("Testing list bundles : yaml", func(t *testing.T) {
+		ex, out := newListSchema(outputYAML)
+		assert.NoError(t, ex.runCatalogList(context.Background(), ex.listBundles))
+		assert.Equal(t, "- channels:\n  - stable\n  name: foo.v0.1.0\n  package: foo\n  relatedImages: 1\n  version: 0.1.0\n", out.String())
+	})
+
+	t.Run("Testing list channels : unknown package should fail", func(t *testing.T) {
+		ex, _ := newListSchema(outputTable)
+		ex.Package = "bar"
+		assert.Error(t, ex.runCatalogList(context.Background(), ex.listChannels))
+	})
+
+	t.Run("Testing list operators : catalog is mandatory", func(t *testing.T) {
+		ex, _ := newListSchema(outputTable)
+		ex.Catalog = ""
+		assert.Error(t, ex.runCatalogList(context.Background(), ex.listOperators))
+	})
+}
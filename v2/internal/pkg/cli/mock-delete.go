@@ -11,7 +11,7 @@ func (o MockDelete) ReadDeleteMetaData() (v2alpha1.DeleteImageList, error) {
 	return v2alpha1.DeleteImageList{}, nil
 }
 
-func (o MockDelete) WriteDeleteMetaData([]v2alpha1.CopyImageSchema) error {
+func (o MockDelete) WriteDeleteMetaData(images []v2alpha1.CopyImageSchema, keep []v2alpha1.CopyImageSchema) error {
 	return nil
 }
 
@@ -0,0 +1,233 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/emoji"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/release"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/verify"
+)
+
+const (
+	verifyErrMsg     = "[verify] %v"
+	verifyDir        = "verify"
+	verifyReportFile = "verify-report.json"
+)
+
+// VerifySchema holds what the verify sub command needs in order
+// to compare a destination registry with an imageset configuration
+type VerifySchema struct {
+	ExecutorSchema
+	Verifier          verify.VerifyInterface
+	Output            string
+	MissingConfigPath string
+	out               io.Writer
+}
+
+// NewVerifyCommand - setup the 'verify' sub command, reporting the drift
+// between a destination registry and the images of an imageset configuration
+func NewVerifyCommand(log clog.PluggableLoggerInterface, opts *mirror.CopyOptions) *cobra.Command {
+	ex := &VerifySchema{
+		ExecutorSchema: ExecutorSchema{
+			Log:     log,
+			Opts:    opts,
+			MakeDir: MakeDir{},
+		},
+	}
+
+	cmd := &cobra.Command{
+		Use:   "verify <docker://destination>",
+		Short: "Verify that a registry holds the images of an imageset configuration, as mirrored by diskToMirror or mirrorToMirror",
+		Example: `  # Verify a registry mirrored from an archive (diskToMirror)
+  oc-mirror verify -c isc.yaml --from file:///disk-enc1 docker://registry.enc1.in --v2
+
+  # Verify a registry mirrored with mirrorToMirror, and generate an imageset configuration for the missing images
+  oc-mirror verify -c isc.yaml --workspace file:///home/user/oc-mirror/mirror1 docker://registry.example.com/mirror --missing-isc isc-missing.yaml --v2
+
+  # Same, with a json report
+  oc-mirror verify -c isc.yaml --workspace file:///home/user/oc-mirror/mirror1 docker://registry.example.com/mirror -o json --v2`,
+		Args: cobra.ExactArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			opts.Function = string(mirror.CopyMode)
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			err := ex.ValidateVerify(args)
+			if err != nil {
+				log.Error("%v ", err)
+				os.Exit(1)
+			}
+			err = ex.CompleteVerify(args)
+			if err != nil {
+				log.Error("%v ", err)
+				os.Exit(1)
+			}
+			defer ex.logFile.Close()
+			ex.out = cmd.OutOrStdout()
+			cmd.SetOutput(ex.logFile)
+
+			// prepare internal storage
+			err = ex.setupLocalStorage(cmd.Context())
+			if err != nil {
+				log.Error(" %v ", err)
+				os.Exit(1)
+			}
+
+			report, err := ex.RunVerify(cmd.Context())
+			if err != nil {
+				log.Error("%v ", err)
+				os.Exit(1)
+			}
+			if !report.IsVerified() {
+				os.Exit(1)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&opts.Global.From, "from", "", "Local storage directory of the archive mirrored with diskToMirror")
+	cmd.Flags().IntVar(&opts.Global.MaxNestedPaths, "max-nested-paths", 0, "Number of nested paths, as used when mirroring to the destination")
+	cmd.Flags().DurationVar(&opts.Global.CommandTimeout, "image-timeout", 10*time.Minute, "Timeout for checking an image")
+	cmd.Flags().StringVarP(&ex.Output, "output", "o", outputTable, "Output format of the report, one of (table, json, yaml)")
+	cmd.Flags().StringVar(&ex.MissingConfigPath, "missing-isc", "", "If set, generates an imageset configuration for the images missing in the destination, at this path")
+	cmd.MarkFlagFilename("missing-isc", "yaml")
+	return cmd
+}
+
+// ValidateVerify - cobra validation
+func (o *VerifySchema) ValidateVerify(args []string) error {
+	if !strings.HasPrefix(args[0], dockerProtocol) {
+		return fmt.Errorf("the destination registry argument must have a docker:// protocol prefix")
+	}
+	if !slices.Contains([]string{outputTable, outputJSON, outputYAML}, o.Output) {
+		return fmt.Errorf("output has an invalid value %s, it should be one of (%s, %s, %s)", o.Output, outputTable, outputJSON, outputYAML)
+	}
+	return o.Validate(args)
+}
+
+// CompleteVerify - sets up the modules as diskToMirror (--from)
+// or mirrorToMirror (--workspace) would
+func (o *VerifySchema) CompleteVerify(args []string) error {
+	err := o.Complete(args)
+	if err != nil {
+		return err
+	}
+
+	if o.Config.Mirror.Platform.Graph {
+		// the graph image is built and pushed by the collector, which verify must not do
+		o.Log.Warn("the graph image is not verified")
+		o.Config.Mirror.Platform.Graph = false
+		client, _ := release.NewOCPClient(uuid.New(), o.Log)
+		signature := release.NewSignatureClient(o.Log, o.Config, *o.Opts)
+		cn := release.NewCincinnati(o.Log, &o.Config, *o.Opts, client, false, signature)
+		o.Release = release.New(o.Log, o.LogsDir, o.Config, *o.Opts, o.Mirror, o.Manifest, cn, o.ImageBuilder)
+	}
+	o.Verifier = verify.New(o.Log, o.Opts, o.Mirror, o.Manifest)
+	return nil
+}
+
+// RunVerify - collects the images of the imageset configuration, and reports
+// the images missing or mismatched in the destination, and the extra repositories
+func (o *VerifySchema) RunVerify(ctx context.Context) (verify.Report, error) {
+	startTime := time.Now()
+
+	go o.startLocalRegistry()
+	defer o.stopLocalRegistry(ctx)
+
+	if o.Opts.IsDiskToMirror() {
+		if err := o.MirrorUnArchiver.Unarchive(); err != nil {
+			return verify.Report{}, err
+		}
+	}
+
+	collectorSchema, err := o.CollectAll(ctx)
+	if err != nil {
+		return verify.Report{}, err
+	}
+	if o.Opts.Global.MaxNestedPaths > 0 {
+		collectorSchema.AllImages, err = withMaxNestedPaths(collectorSchema.AllImages, o.Opts.Global.MaxNestedPaths)
+		if err != nil {
+			return verify.Report{}, err
+		}
+	}
+
+	o.Log.Info(emoji.LeftPointingMagnifyingGlass+" verifying %d images in %s...", len(collectorSchema.AllImages), o.Opts.Destination)
+	report, err := o.Verifier.Verify(ctx, collectorSchema.AllImages)
+	if err != nil {
+		return report, err
+	}
+	if err := o.writeReport(report); err != nil {
+		return report, fmt.Errorf(verifyErrMsg, err)
+	}
+
+	o.Log.Info("verify time     : %v", time.Since(startTime))
+	if report.HasDrift() {
+		o.Log.Warn(emoji.Warning+"  %d/%d images are missing and %d/%d images have a different digest in %s", report.Summary.Missing, report.Summary.Expected, report.Summary.Mismatched, report.Summary.Expected, o.Opts.Destination)
+	}
+	if report.Summary.Unverified > 0 {
+		o.Log.Warn(emoji.Warning+"  the digest of %d/%d images in %s could not be compared with the source", report.Summary.Unverified, report.Summary.Expected, o.Opts.Destination)
+	}
+	if report.IsVerified() {
+		o.Log.Info(emoji.CheckMarkButton+" all %d images are in %s", report.Summary.Expected, o.Opts.Destination)
+	}
+	return report, nil
+}
+
+// writeReport writes the report to the output, keeps it as json in the working-dir
+// and generates the imageset configuration of the missing images when requested
+func (o *VerifySchema) writeReport(report verify.Report) error {
+	reportDir := filepath.Join(o.Opts.Global.WorkingDir, verifyDir)
+	if err := o.MakeDir.makeDirAll(reportDir, 0755); err != nil {
+		return err
+	}
+	reportPath := filepath.Join(reportDir, verifyReportFile)
+	reportFile, err := os.Create(reportPath)
+	if err != nil {
+		return err
+	}
+	defer reportFile.Close()
+	if err := writeOutput(reportFile, outputJSON, report, nil, nil); err != nil {
+		return err
+	}
+	o.Log.Info(emoji.PageFacingUp+" verify report in : %s", reportPath)
+
+	if o.MissingConfigPath != "" && len(report.Missing) > 0 {
+		missingConfig, skipped := report.MissingConfig(o.Config)
+		for _, img := range skipped {
+			o.Log.Warn("%s (%s) can't be added to the imageset configuration of the missing images", img.Origin, img.Type)
+		}
+		data, err := yaml.Marshal(missingConfig)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(o.MissingConfigPath, data, 0600); err != nil {
+			return err
+		}
+		o.Log.Info(emoji.PageFacingUp+" imageset configuration of the missing images in : %s", o.MissingConfigPath)
+	}
+
+	rows := make([][]string, 0, len(report.Missing)+len(report.Mismatched)+len(report.Unverified)+len(report.ExtraRepositories))
+	for _, img := range report.Missing {
+		rows = append(rows, []string{"missing", img.Destination, img.Origin, "", ""})
+	}
+	for _, img := range report.Mismatched {
+		rows = append(rows, []string{"mismatched", img.Destination, img.Origin, img.ExpectedDigest, img.ActualDigest})
+	}
+	for _, img := range report.Unverified {
+		rows = append(rows, []string{"unverified", img.Destination, img.Origin, "", ""})
+	}
+	for _, repository := range report.ExtraRepositories {
+		rows = append(rows, []string{"extra", repository, "", "", ""})
+	}
+	return writeOutput(o.out, o.Output, report, []string{"STATUS", "DESTINATION", "ORIGIN", "EXPECTED DIGEST", "ACTUAL DIGEST"}, rows)
+}
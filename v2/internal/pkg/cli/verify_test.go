@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/verify"
+)
+
+func TestVerifyValidate(t *testing.T) {
+	type testCase struct {
+		caseName      string
+		output        string
+		args          []string
+		expectedError string
+	}
+	testCases := []testCase{
+		{
+			caseName:      "Testing verify validate : should fail (destination not docker://)",
+			output:        outputTable,
+			args:          []string{"file:///tmp/archive"},
+			expectedError: "the destination registry argument must have a docker:// protocol prefix",
+		},
+		{
+			caseName:      "Testing verify validate : should fail (invalid output)",
+			output:        "csv",
+			args:          []string{"docker://mirror.acme.com"},
+			expectedError: "output has an invalid value csv, it should be one of (table, json, yaml)",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			ex := &VerifySchema{
+				ExecutorSchema: ExecutorSchema{
+					Log:  clog.New("error"),
+					Opts: &mirror.CopyOptions{Global: &mirror.GlobalOptions{}},
+				},
+				Output: testCase.output,
+			}
+			err := ex.ValidateVerify(testCase.args)
+			assert.EqualError(t, err, testCase.expectedError)
+		})
+	}
+}
+
+func TestVerifyWriteReport(t *testing.T) {
+	tempDir := t.TempDir()
+	out := &bytes.Buffer{}
+	ex := &VerifySchema{
+		ExecutorSchema: ExecutorSchema{
+			Log:     clog.New("error"),
+			Opts:    &mirror.CopyOptions{Global: &mirror.GlobalOptions{WorkingDir: filepath.Join(tempDir, workingDir)}},
+			MakeDir: MakeDir{},
+		},
+		Output:            outputTable,
+		MissingConfigPath: filepath.Join(tempDir, "isc-missing.yaml"),
+		out:               out,
+	}
+	report := verify.Report{
+		Destination: "docker://mirror.acme.com",
+		Summary:     verify.ReportSummary{Expected: 4, Verified: 1, Missing: 1, Mismatched: 1, Unverified: 1, ExtraRepositories: 1},
+		Missing: []verify.ImageDrift{
+			{Origin: "docker://registry.redhat.io/ubi9/ubi:latest", Destination: "docker://mirror.acme.com/ubi9/ubi:latest", Type: "generic"},
+		},
+		Mismatched: []verify.ImageDrift{
+			{Origin: "docker://registry.redhat.io/ubi8/ubi:latest", Destination: "docker://mirror.acme.com/ubi8/ubi:latest", Type: "generic", ExpectedDigest: "sha256:1111", ActualDigest: "sha256:2222"},
+		},
+		Unverified: []verify.ImageDrift{
+			{Origin: "docker://registry.redhat.io/ubi8/ubi-minimal:latest", Destination: "docker://mirror.acme.com/ubi8/ubi-minimal:latest", Type: "generic"},
+		},
+		ExtraRepositories: []string{"manual/push"},
+	}
+
+	assert.NoError(t, ex.writeReport(report))
+
+	data, err := os.ReadFile(filepath.Join(tempDir, workingDir, verifyDir, verifyReportFile))
+	assert.NoError(t, err)
+	var written verify.Report
+	assert.NoError(t, json.Unmarshal(data, &written))
+	assert.Equal(t, report, written)
+
+	missingConfig, err := os.ReadFile(ex.MissingConfigPath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(missingConfig), "registry.redhat.io/ubi9/ubi:latest")
+	assert.NotContains(t, string(missingConfig), "registry.redhat.io/ubi8/ubi:latest")
+
+	assert.Contains(t, out.String(), "missing")
+	assert.Contains(t, out.String(), "sha256:2222")
+	assert.Contains(t, out.String(), "manual/push")
+	assert.Contains(t, out.String(), "unverified")
+	assert.Contains(t, out.String(), "mirror.acme.com/ubi8/ubi-minimal:latest")
+}
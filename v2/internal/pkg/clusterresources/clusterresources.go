@@ -254,31 +254,7 @@ func (o *ClusterResourcesGenerator) generateCatalogSource(catalogRef string, cat
 		return err
 	}
 
-	var csSuffix string
-	if catalogSpec.IsImageByDigestOnly() {
-		if len(catalogSpec.Digest) >= hashTruncLen {
-			csSuffix = catalogSpec.Digest[:hashTruncLen]
-		} else {
-			csSuffix = catalogSpec.Digest
-		}
-	} else {
-		tag := catalogSpec.Tag
-		if len(tag) >= hashTruncLen {
-			csSuffix = strings.Map(toRFC1035, tag[:hashTruncLen])
-		} else {
-			csSuffix = strings.Map(toRFC1035, tag)
-		}
-	}
-
-	if csSuffix == "" {
-		csSuffix = "0" // default value
-	}
-
-	pathComponents := strings.Split(catalogSpec.PathComponent, "/")
-	catalogRepository := pathComponents[len(pathComponents)-1]
-	catalogSourceName := "cs-" + catalogRepository + "-" + csSuffix
-	// maybe needs some updating (i.e other unwanted characters !@# etc )
-	catalogSourceName = strings.Replace(catalogSourceName, ".", "-", -1)
+	catalogSourceName := catalogResourceName("cs-", catalogSpec)
 	errs := validation.IsDNS1035Label(catalogSourceName)
 	if len(errs) != 0 && !isValidRFC1123(catalogSourceName) {
 		return fmt.Errorf("error creating catalog source name: %s", strings.Join(errs, ", "))
@@ -346,6 +322,35 @@ func (o *ClusterResourcesGenerator) generateCatalogSource(catalogRef string, cat
 	return err
 }
 
+// catalogResourceName returns the name of the CatalogSource (cs-) or ClusterCatalog (cc-) of a mirrored catalog:
+// the prefix, followed by the catalog repository and by the beginning of its tag or digest
+func catalogResourceName(prefix string, catalogSpec image.ImageSpec) string {
+	var suffix string
+	if catalogSpec.IsImageByDigestOnly() {
+		if len(catalogSpec.Digest) >= hashTruncLen {
+			suffix = catalogSpec.Digest[:hashTruncLen]
+		} else {
+			suffix = catalogSpec.Digest
+		}
+	} else {
+		tag := catalogSpec.Tag
+		if len(tag) >= hashTruncLen {
+			suffix = strings.Map(toRFC1035, tag[:hashTruncLen])
+		} else {
+			suffix = strings.Map(toRFC1035, tag)
+		}
+	}
+
+	if suffix == "" {
+		suffix = "0" // default value
+	}
+
+	pathComponents := strings.Split(catalogSpec.PathComponent, "/")
+	catalogRepository := pathComponents[len(pathComponents)-1]
+	// maybe needs some updating (i.e other unwanted characters !@# etc )
+	return strings.ReplaceAll(prefix+catalogRepository+"-"+suffix, ".", "-")
+}
+
 func catalogSourceContentFromTemplate(templateFile, catalogSourceName, image string) (ofv1alpha1.CatalogSource, error) {
 	// Initializing catalogSource `obj` from template
 	var obj ofv1alpha1.CatalogSource
@@ -400,31 +405,7 @@ func (o *ClusterResourcesGenerator) generateClusterCatalog(catalogRef string) er
 		return err
 	}
 
-	var ccSuffix string
-	if catalogSpec.IsImageByDigestOnly() {
-		if len(catalogSpec.Digest) >= hashTruncLen {
-			ccSuffix = catalogSpec.Digest[:hashTruncLen]
-		} else {
-			ccSuffix = catalogSpec.Digest
-		}
-	} else {
-		tag := catalogSpec.Tag
-		if len(tag) >= hashTruncLen {
-			ccSuffix = strings.Map(toRFC1035, tag[:hashTruncLen])
-		} else {
-			ccSuffix = strings.Map(toRFC1035, tag)
-		}
-	}
-
-	if ccSuffix == "" {
-		ccSuffix = "0" // default value
-	}
-
-	pathComponents := strings.Split(catalogSpec.PathComponent, "/")
-	catalogRepository := pathComponents[len(pathComponents)-1]
-	clusterCatalogName := "cc-" + catalogRepository + "-" + ccSuffix
-	// maybe needs some updating (i.e other unwanted characters !@# etc )
-	clusterCatalogName = strings.ReplaceAll(clusterCatalogName, ".", "-")
+	clusterCatalogName := catalogResourceName("cc-", catalogSpec)
 	errs := validation.IsDNS1035Label(clusterCatalogName)
 	if len(errs) != 0 && !isValidRFC1123(clusterCatalogName) {
 		return fmt.Errorf("error creating cluster catalog name: %s", strings.Join(errs, ", "))
@@ -654,7 +635,9 @@ func (o *ClusterResourcesGenerator) UpdateServiceGenerator(graphImageRef, releas
 }
 
 func attemptNamespaceScope(srcImgSpec, dstImgSpec image.ImageSpec) (string, string) {
-	if strings.HasSuffix(dstImgSpec.PathComponent, srcImgSpec.PathComponent) {
+	// the path boundary matters: images placed under another repository name
+	// (targetRepo, rewrite rules) need a repository scope
+	if dstImgSpec.PathComponent == srcImgSpec.PathComponent || strings.HasSuffix(dstImgSpec.PathComponent, "/"+srcImgSpec.PathComponent) {
 		return namespaceScope(srcImgSpec), namespaceScope(dstImgSpec)
 	} else {
 		return repositoryScope(srcImgSpec), repositoryScope(dstImgSpec)
@@ -703,7 +686,7 @@ func imageTypeToCategory(imageType v2alpha1.ImageType) mirrorCategory {
 		return operatorCategory
 	case v2alpha1.TypeOperatorCatalog:
 		return operatorCategory
-	case v2alpha1.TypeOperatorRelatedImage:
+	case v2alpha1.TypeOperatorRelatedImage, v2alpha1.TypeOperatorDiscoveredImage:
 		return operatorCategory
 	case v2alpha1.TypeInvalid:
 		return genericCategory
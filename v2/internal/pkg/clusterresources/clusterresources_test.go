@@ -132,6 +132,20 @@ var (
 		},
 	}
 
+	imageListRewritten = []v2alpha1.CopyImageSchema{
+		{
+			Source:      "docker://localhost:5000/library/nginx@sha256:7c4ef7434c97c8aaf6cd310874790b915b3c61fc902eea255f9177058ea9aff3",
+			Destination: "docker://myregistry/mirror/dockerhub/nginx@sha256:7c4ef7434c97c8aaf6cd310874790b915b3c61fc902eea255f9177058ea9aff3",
+			Origin:      "docker://docker.io/library/nginx@sha256:7c4ef7434c97c8aaf6cd310874790b915b3c61fc902eea255f9177058ea9aff3",
+			Type:        v2alpha1.TypeGeneric,
+		},
+		{
+			Source:      "docker://localhost:5000/stefanprodan/podinfo@sha256:6d76ffca7a233213325907bae611e835b49c5b933095be1328351f4f5fc67615",
+			Destination: "docker://myregistry/team-podinfo@sha256:6d76ffca7a233213325907bae611e835b49c5b933095be1328351f4f5fc67615",
+			Origin:      "docker://ghcr.io/stefanprodan/podinfo@sha256:6d76ffca7a233213325907bae611e835b49c5b933095be1328351f4f5fc67615",
+			Type:        v2alpha1.TypeGeneric,
+		},
+	}
 	imageListDigestsOnly = []v2alpha1.CopyImageSchema{
 		{
 			Source:      "docker://localhost:5000/openshift-release-dev/ocp-v4.0-art-dev@sha256:7c4ef7434c97c8aaf6cd310874790b915b3c61fc902eea255f9177058ea9aff3",
@@ -1264,6 +1278,22 @@ func TestGenerateImageMirrors(t *testing.T) {
 				},
 			},
 		},
+		{
+			caseName:             "Testing GenerateImageMirrors for IDMS - rewritten destinations : should use repository scope",
+			imgList:              imageListRewritten,
+			mode:                 DigestsOnlyMode,
+			forceRepositoryScope: false,
+			expectedError:        false,
+			expectedCategorizedMirrors: []categorizedMirrors{
+				{
+					category: genericCategory,
+					mirrors: map[string][]confv1.ImageMirror{
+						"docker.io/library/nginx":      {"myregistry/mirror/dockerhub/nginx"},
+						"ghcr.io/stefanprodan/podinfo": {"myregistry/team-podinfo"},
+					},
+				},
+			},
+		},
 	}
 
 	cr := &ClusterResourcesGenerator{
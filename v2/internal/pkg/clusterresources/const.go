@@ -13,4 +13,14 @@ const (
 	signatureLabel                        = "release.openshift.io/verification-signatures"
 	signatureConfigMapMsg                 = "[GenerateSignatureConfigMap] %v"
 	signatureDir                          = "signatures"
+	samplesConfigFilename                 = "samplesConfig.yaml"
+	samplesConfigKind                     = "Config"
+	samplesConfigName                     = "cluster"
+	samplesConfigMsg                      = "[SamplesConfigGenerator] %v"
+	samplesDir                            = "samples"
+	skippedImageStreamsFile               = "skipped-imagestreams.json"
+	operatorInstallMsg                    = "[OperatorInstallGenerator] %v"
+	serviceAccountSuffix                  = "-installer"
+	clusterExtensionsFileSuffix           = "-extensions.yaml"
+	subscriptionsFileSuffix               = "-subscriptions.yaml"
 )
@@ -10,4 +10,7 @@ type GeneratorInterface interface {
 	CatalogSourceGenerator(allRelatedImages []v2alpha1.CopyImageSchema) error
 	GenerateSignatureConfigMap(allRelatedImages []v2alpha1.CopyImageSchema) error
 	ClusterCatalogGenerator(allRelatedImages []v2alpha1.CopyImageSchema) error
+	RegistriesConfGenerator(allRelatedImages []v2alpha1.CopyImageSchema, forceRepositoryScope bool, opts RegistriesConfOptions) error
+	SamplesConfigGenerator(allRelatedImages []v2alpha1.CopyImageSchema) error
+	OperatorInstallGenerator(allRelatedImages []v2alpha1.CopyImageSchema) error
 }
@@ -0,0 +1,220 @@
+package clusterresources
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	operatorsv1 "github.com/openshift/oc-mirror/v2/internal/pkg/api/operator-framework/operators/v1"
+	ofv1 "github.com/openshift/oc-mirror/v2/internal/pkg/api/operator-framework/v1"
+	ofv1alpha1 "github.com/openshift/oc-mirror/v2/internal/pkg/api/operator-framework/v1alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/emoji"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/image"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// OperatorInstallGenerator generates, for each package of the imagesetconfig mirrored from a catalog,
+// the templates to install it from the mirrored catalog:
+// a ClusterExtension, along with a placeholder ServiceAccount, referencing the ClusterCatalog for OLM v1,
+// and a Subscription and an OperatorGroup referencing the CatalogSource for OLM v0.
+// The packages are installed in a namespace named after them, which is expected to be adapted.
+// The OperatorGroup targets this namespace only.
+func (o *ClusterResourcesGenerator) OperatorInstallGenerator(allRelatedImages []v2alpha1.CopyImageSchema) error {
+	if len(o.Config.Mirror.Operators) == 0 {
+		o.Log.Info(emoji.PageFacingUp + " No catalogs mirrored. Skipping operator install files generation.")
+		return nil
+	}
+
+	firstCatalog := true
+	for _, copyImage := range allRelatedImages {
+		// the catalog copied to the local cache during mirror to mirror is skipped, as for CatalogSourceGenerator
+		if copyImage.Type != v2alpha1.TypeOperatorCatalog || strings.Contains(copyImage.Destination, o.LocalStorageFQDN) {
+			continue
+		}
+		op, ok := o.operatorOf(copyImage.Origin)
+		if !ok || len(op.Packages) == 0 {
+			o.Log.Debug(operatorInstallMsg, fmt.Sprintf("no packages listed for catalog %s, skipping", copyImage.Origin))
+			continue
+		}
+		if firstCatalog {
+			o.Log.Info(emoji.PageFacingUp + " Generating operator install files...")
+			firstCatalog = false
+		}
+		catalogSpec, err := image.ParseRef(copyImage.Destination)
+		if err != nil {
+			return fmt.Errorf(operatorInstallMsg, err)
+		}
+		if err := o.generateClusterExtensions(op, catalogResourceName("cc-", catalogSpec)); err != nil {
+			return err
+		}
+		if err := o.generateSubscriptions(op, catalogResourceName("cs-", catalogSpec)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *ClusterResourcesGenerator) operatorOf(catalogRef string) (v2alpha1.Operator, bool) {
+	for _, op := range o.Config.Mirror.Operators {
+		if strings.Contains(catalogRef, op.Catalog) {
+			return op, true
+		}
+	}
+	return v2alpha1.Operator{}, false
+}
+
+func (o *ClusterResourcesGenerator) generateClusterExtensions(op v2alpha1.Operator, clusterCatalogName string) error {
+	objs := []interface{}{}
+	for _, pkg := range op.Packages {
+		serviceAccountName := pkg.Name + serviceAccountSuffix
+		objs = append(objs,
+			corev1.ServiceAccount{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "v1",
+					Kind:       "ServiceAccount",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      serviceAccountName,
+					Namespace: pkg.Name,
+				},
+			},
+			ofv1.ClusterExtension{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: ofv1.ClusterExtensionCRDAPIVersion,
+					Kind:       ofv1.ClusterExtensionKind,
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name: pkg.Name,
+				},
+				Spec: ofv1.ClusterExtensionSpec{
+					Namespace:      pkg.Name,
+					ServiceAccount: ofv1.ServiceAccountReference{Name: serviceAccountName},
+					Source: ofv1.SourceConfig{
+						SourceType: ofv1.SourceTypeCatalog,
+						Catalog: &ofv1.CatalogFilter{
+							PackageName: pkg.Name,
+							Version:     packageVersionRange(pkg),
+							Channels:    packageChannels(pkg),
+							Selector: &metav1.LabelSelector{
+								MatchLabels: map[string]string{ofv1.MetadataNameLabel: clusterCatalogName},
+							},
+						},
+					},
+				},
+			},
+		)
+	}
+	return o.writeOperatorInstallFile(clusterCatalogName+clusterExtensionsFileSuffix, objs)
+}
+
+func (o *ClusterResourcesGenerator) generateSubscriptions(op v2alpha1.Operator, catalogSourceName string) error {
+	objs := []interface{}{}
+	for _, pkg := range op.Packages {
+		channel := pkg.DefaultChannel
+		if channels := packageChannels(pkg); channel == "" && len(channels) > 0 {
+			channel = channels[0]
+		}
+		objs = append(objs,
+			operatorsv1.OperatorGroup{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: operatorsv1.OperatorGroupCRDAPIVersion,
+					Kind:       operatorsv1.OperatorGroupKind,
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      pkg.Name,
+					Namespace: pkg.Name,
+				},
+				// the OwnNamespace install mode: operators only supporting AllNamespaces need targetNamespaces removed
+				Spec: operatorsv1.OperatorGroupSpec{
+					TargetNamespaces: []string{pkg.Name},
+				},
+			},
+			ofv1alpha1.Subscription{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: ofv1alpha1.SubscriptionCRDAPIVersion,
+					Kind:       ofv1alpha1.SubscriptionKind,
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      pkg.Name,
+					Namespace: pkg.Name,
+				},
+				Spec: &ofv1alpha1.SubscriptionSpec{
+					CatalogSource:          catalogSourceName,
+					CatalogSourceNamespace: "openshift-marketplace",
+					Package:                pkg.Name,
+					Channel:                channel,
+				},
+			},
+		)
+	}
+	return o.writeOperatorInstallFile(catalogSourceName+subscriptionsFileSuffix, objs)
+}
+
+// packageChannels returns the channels mirrored for the package, or its default channel
+func packageChannels(pkg v2alpha1.IncludePackage) []string {
+	channels := []string{}
+	for _, ch := range pkg.Channels {
+		channels = append(channels, ch.Name)
+	}
+	if len(channels) == 0 && pkg.DefaultChannel != "" {
+		channels = append(channels, pkg.DefaultChannel)
+	}
+	return channels
+}
+
+// packageVersionRange returns the range of versions mirrored for the package:
+// the range of the package, or the union of the ranges of its channels when they all have one
+func packageVersionRange(pkg v2alpha1.IncludePackage) string {
+	if r := versionRange(pkg.IncludeBundle); r != "" {
+		return r
+	}
+	ranges := []string{}
+	for _, ch := range pkg.Channels {
+		r := versionRange(ch.IncludeBundle)
+		if r == "" {
+			// a channel mirrored without range leaves the versions unconstrained
+			return ""
+		}
+		ranges = append(ranges, r)
+	}
+	return strings.Join(ranges, " || ")
+}
+
+func versionRange(bundle v2alpha1.IncludeBundle) string {
+	constraints := []string{}
+	if bundle.MinVersion != "" {
+		constraints = append(constraints, ">="+bundle.MinVersion)
+	}
+	if bundle.MaxVersion != "" {
+		constraints = append(constraints, "<="+bundle.MaxVersion)
+	}
+	return strings.Join(constraints, " ")
+}
+
+// writeOperatorInstallFile writes the objects as a multi-document yaml file in cluster-resources
+func (o *ClusterResourcesGenerator) writeOperatorInstallFile(fileName string, objs []interface{}) error {
+	docs := [][]byte{}
+	for _, obj := range objs {
+		objBytes, err := yaml.Marshal(obj)
+		if err != nil {
+			return fmt.Errorf(operatorInstallMsg, err)
+		}
+		// creationTimestamp is a struct, omitempty does not apply
+		docs = append(docs, bytes.ReplaceAll(objBytes, []byte("  creationTimestamp: null\n"), []byte("")))
+	}
+
+	filePath := filepath.Join(o.WorkingDir, clusterResourcesDir, fileName)
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return fmt.Errorf(operatorInstallMsg, err)
+	}
+	if err := os.WriteFile(filePath, bytes.Join(docs, []byte("---\n")), 0644); err != nil {
+		return fmt.Errorf(operatorInstallMsg, err)
+	}
+	o.Log.Info("%s file created", filePath)
+	return nil
+}
@@ -0,0 +1,160 @@
+package clusterresources
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+)
+
+func TestOperatorInstallGenerator(t *testing.T) {
+	log := clog.New("trace")
+
+	imageList := []v2alpha1.CopyImageSchema{
+		{
+			Source:      "docker://localhost:5000/redhat/redhat-operator-index:v4.15",
+			Destination: "docker://myregistry/mynamespace/redhat/redhat-operator-index:v4.15",
+			Origin:      "docker://registry.redhat.io/redhat/redhat-operator-index:v4.15",
+			Type:        v2alpha1.TypeOperatorCatalog,
+		},
+		{ // OCPBUGS-41608 - this should be skipped because it mirrors to the cache
+			Source:      "docker://localhost:5000/redhat/redhat-operator-index:v4.15",
+			Destination: "docker://localhost:55000/redhat/redhat-operator-index:v4.15",
+			Origin:      "docker://registry.redhat.io/redhat/redhat-operator-index:v4.15",
+			Type:        v2alpha1.TypeOperatorCatalog,
+		},
+		{
+			Source:      "docker://localhost:5000/redhat/certified-operator-index:v4.15",
+			Destination: "docker://myregistry/mynamespace/redhat/certified-operator-index:v4.15",
+			Origin:      "docker://registry.redhat.io/redhat/certified-operator-index:v4.15",
+			Type:        v2alpha1.TypeOperatorCatalog,
+		},
+	}
+	config := v2alpha1.ImageSetConfiguration{
+		ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+			Mirror: v2alpha1.Mirror{
+				Operators: []v2alpha1.Operator{
+					{
+						Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.15",
+						IncludeConfig: v2alpha1.IncludeConfig{
+							Packages: []v2alpha1.IncludePackage{
+								{
+									Name:          "aws-load-balancer-operator",
+									IncludeBundle: v2alpha1.IncludeBundle{MinVersion: "1.1.0", MaxVersion: "1.2.0"},
+								},
+								{
+									Name:           "node-observability-operator",
+									DefaultChannel: "stable",
+									Channels: []v2alpha1.IncludeChannel{
+										{Name: "alpha", IncludeBundle: v2alpha1.IncludeBundle{MaxVersion: "0.1.0"}},
+										{Name: "stable", IncludeBundle: v2alpha1.IncludeBundle{MinVersion: "0.2.0"}},
+									},
+								},
+							},
+						},
+					},
+					// no packages: nothing to install
+					{Catalog: "registry.redhat.io/redhat/certified-operator-index:v4.15"},
+				},
+			},
+		},
+	}
+
+	t.Run("Testing OperatorInstallGenerator : should generate the ClusterExtensions and the Subscriptions of the packages", func(t *testing.T) {
+		workingDir := filepath.Join(t.TempDir(), "working-dir")
+		cr := &ClusterResourcesGenerator{Log: log, WorkingDir: workingDir, LocalStorageFQDN: "localhost:55000", Config: config}
+		err := cr.OperatorInstallGenerator(imageList)
+		assert.NoError(t, err)
+
+		entries, err := os.ReadDir(filepath.Join(workingDir, clusterResourcesDir))
+		assert.NoError(t, err)
+		assert.Len(t, entries, 2)
+
+		extensions, err := os.ReadFile(filepath.Join(workingDir, clusterResourcesDir, "cc-redhat-operator-index-v4-15-extensions.yaml"))
+		assert.NoError(t, err)
+		assert.Equal(t, `apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: aws-load-balancer-operator-installer
+  namespace: aws-load-balancer-operator
+---
+apiVersion: olm.operatorframework.io/v1
+kind: ClusterExtension
+metadata:
+  name: aws-load-balancer-operator
+spec:
+  namespace: aws-load-balancer-operator
+  serviceAccount:
+    name: aws-load-balancer-operator-installer
+  source:
+    catalog:
+      packageName: aws-load-balancer-operator
+      selector:
+        matchLabels:
+          olm.operatorframework.io/metadata.name: cc-redhat-operator-index-v4-15
+      version: '>=1.1.0 <=1.2.0'
+    sourceType: Catalog
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: node-observability-operator-installer
+  namespace: node-observability-operator
+---
+apiVersion: olm.operatorframework.io/v1
+kind: ClusterExtension
+metadata:
+  name: node-observability-operator
+spec:
+  namespace: node-observability-operator
+  serviceAccount:
+    name: node-observability-operator-installer
+  source:
+    catalog:
+      channels:
+      - alpha
+      - stable
+      packageName: node-observability-operator
+      selector:
+        matchLabels:
+          olm.operatorframework.io/metadata.name: cc-redhat-operator-index-v4-15
+      version: <=0.1.0 || >=0.2.0
+    sourceType: Catalog
+`, string(extensions))
+
+		subscriptions, err := os.ReadFile(filepath.Join(workingDir, clusterResourcesDir, "cs-redhat-operator-index-v4-15-subscriptions.yaml"))
+		assert.NoError(t, err)
+		assert.Contains(t, string(subscriptions), `apiVersion: operators.coreos.com/v1alpha1
+kind: Subscription
+metadata:
+  name: node-observability-operator
+  namespace: node-observability-operator
+spec:
+  channel: stable
+  name: node-observability-operator
+  source: cs-redhat-operator-index-v4-15
+  sourceNamespace: openshift-marketplace
+`)
+		assert.Contains(t, string(subscriptions), `apiVersion: operators.coreos.com/v1
+kind: OperatorGroup
+metadata:
+  name: aws-load-balancer-operator
+  namespace: aws-load-balancer-operator
+spec:
+  targetNamespaces:
+  - aws-load-balancer-operator
+`)
+	})
+
+	t.Run("Testing OperatorInstallGenerator : no catalogs mirrored - should not generate anything", func(t *testing.T) {
+		workingDir := filepath.Join(t.TempDir(), "working-dir")
+		cr := &ClusterResourcesGenerator{Log: log, WorkingDir: workingDir, LocalStorageFQDN: "localhost:55000"}
+		err := cr.OperatorInstallGenerator(imageList)
+		assert.NoError(t, err)
+		assert.NoDirExists(t, filepath.Join(workingDir, clusterResourcesDir))
+	})
+}
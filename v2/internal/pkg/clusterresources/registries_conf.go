@@ -0,0 +1,341 @@
+package clusterresources
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/emoji"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	registriesConfDir            = "registries.conf.d"
+	registriesConfFileName       = "99-oc-mirror.conf"
+	policyJSONFileName           = "policy-oc-mirror.json"
+	registriesConfHostDir        = "/etc/containers/registries.conf.d"
+	policyJSONHostPath           = "/etc/containers/" + policyJSONFileName
+	machineConfigFileName        = "mc-registries-oc-mirror.yaml"
+	butaneFileName               = "registries-oc-mirror.bu"
+	machineConfigName            = "99-%s-oc-mirror-registries"
+	machineConfigRoleLabel       = "machineconfiguration.openshift.io/role"
+	machineConfigAPIVersion      = "machineconfiguration.openshift.io/v1"
+	machineConfigKind            = "MachineConfig"
+	ignitionVersion              = "3.2.0"
+	butaneVariant                = "openshift"
+	butaneVersion                = "4.14.0"
+	registriesConfFileMode       = 0644
+	WrapperNone                  = ""
+	WrapperMachineConfig         = "machineconfig"
+	WrapperButane                = "butane"
+	defaultMachineConfigPoolRole = "worker"
+)
+
+// RegistriesConfOptions controls the optional artifacts generated
+// next to the registries.conf.d drop-in.
+type RegistriesConfOptions struct {
+	// PolicyJSON generates a policy.json for the mirrored sources
+	PolicyJSON bool
+	// Wrapper is one of WrapperNone, WrapperMachineConfig or WrapperButane
+	Wrapper string
+	// Role is the MachineConfigPool role targeted by the wrapper (default worker)
+	Role string
+}
+
+// registryEntry is a [[registry]] table of a registries.conf.d drop-in
+type registryEntry struct {
+	category           mirrorCategory
+	prefix             string
+	mirrors            []string
+	mirrorByDigestOnly bool
+}
+
+type policyRequirement struct {
+	Type string `json:"type"`
+}
+
+type containersPolicy struct {
+	Default    []policyRequirement                       `json:"default"`
+	Transports map[string]map[string][]policyRequirement `json:"transports"`
+}
+
+// minimal MachineConfig / ignition types, only what is needed to drop files on the nodes
+type ignitionFileContents struct {
+	Source string `json:"source"`
+}
+
+type ignitionFile struct {
+	Path      string               `json:"path"`
+	Mode      int                  `json:"mode"`
+	Overwrite bool                 `json:"overwrite"`
+	Contents  ignitionFileContents `json:"contents"`
+}
+
+type ignitionConfig struct {
+	Ignition struct {
+		Version string `json:"version"`
+	} `json:"ignition"`
+	Storage struct {
+		Files []ignitionFile `json:"files"`
+	} `json:"storage"`
+}
+
+type machineConfig struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Metadata   struct {
+		Name   string            `json:"name"`
+		Labels map[string]string `json:"labels"`
+	} `json:"metadata"`
+	Spec struct {
+		Config ignitionConfig `json:"config"`
+	} `json:"spec"`
+}
+
+type butaneFile struct {
+	Path      string `json:"path"`
+	Mode      int    `json:"mode"`
+	Overwrite bool   `json:"overwrite"`
+	Contents  struct {
+		Inline string `json:"inline"`
+	} `json:"contents"`
+}
+
+type butaneConfig struct {
+	Variant  string `json:"variant"`
+	Version  string `json:"version"`
+	Metadata struct {
+		Name   string            `json:"name"`
+		Labels map[string]string `json:"labels"`
+	} `json:"metadata"`
+	Storage struct {
+		Files []butaneFile `json:"files"`
+	} `json:"storage"`
+}
+
+// hostFile is a file generated in the working-dir that is also meant
+// to be laid down on the hosts by the MachineConfig/Butane wrapper
+type hostFile struct {
+	hostPath string
+	content  []byte
+}
+
+// RegistriesConfGenerator renders the mirrors used for IDMS/ITMS as a
+// containers-registries.conf.d(5) drop-in, for consumers that are not
+// driven by the machine-config-operator (RHEL/podman hosts, MicroShift, edge devices).
+// Sources only mirrored by digest get mirror-by-digest-only = true.
+func (o *ClusterResourcesGenerator) RegistriesConfGenerator(allRelatedImages []v2alpha1.CopyImageSchema, forceRepositoryScope bool, opts RegistriesConfOptions) error {
+	if len(allRelatedImages) == 0 {
+		o.Log.Info(emoji.PageFacingUp + " Nothing mirrored. Skipping registries.conf generation.")
+		return nil
+	}
+
+	entries, err := o.generateRegistryEntries(allRelatedImages, forceRepositoryScope)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		o.Log.Info(emoji.PageFacingUp + " No mirrors to declare. Skipping registries.conf generation.")
+		return nil
+	}
+
+	o.Log.Info(emoji.PageFacingUp + " Generating registries.conf file...")
+	registriesConf := renderRegistriesConf(entries)
+	if err := o.writeClusterResourceFile(filepath.Join(registriesConfDir, registriesConfFileName), registriesConf); err != nil {
+		return err
+	}
+	hostFiles := []hostFile{
+		{hostPath: filepath.Join(registriesConfHostDir, registriesConfFileName), content: registriesConf},
+	}
+
+	if opts.PolicyJSON {
+		o.Log.Info(emoji.PageFacingUp + " Generating policy.json file...")
+		policy, err := renderPolicy(entries)
+		if err != nil {
+			return err
+		}
+		if err := o.writeClusterResourceFile(policyJSONFileName, policy); err != nil {
+			return err
+		}
+		// the policy of the hosts is left untouched, the MachineConfig/Butane wrapper
+		// lays the policy down next to it, for the tools pulling from the mirrors
+		hostFiles = append(hostFiles, hostFile{hostPath: policyJSONHostPath, content: policy})
+		o.Log.Info(emoji.PageFacingUp+" %s is not used by default: pass it with --signature-policy (podman) or --policy (skopeo), or set it as signature_policy in crio.conf", policyJSONHostPath)
+	}
+
+	role := opts.Role
+	if role == "" {
+		role = defaultMachineConfigPoolRole
+	}
+	switch opts.Wrapper {
+	case WrapperNone:
+		return nil
+	case WrapperMachineConfig:
+		o.Log.Info(emoji.PageFacingUp + " Generating MachineConfig file for registries.conf...")
+		mc, err := renderMachineConfig(hostFiles, role)
+		if err != nil {
+			return err
+		}
+		return o.writeClusterResourceFile(machineConfigFileName, mc)
+	case WrapperButane:
+		o.Log.Info(emoji.PageFacingUp + " Generating Butane file for registries.conf...")
+		bu, err := renderButane(hostFiles, role)
+		if err != nil {
+			return err
+		}
+		return o.writeClusterResourceFile(butaneFileName, bu)
+	default:
+		return fmt.Errorf("unsupported registries.conf wrapper %q: use one of %s, %s", opts.Wrapper, WrapperMachineConfig, WrapperButane)
+	}
+}
+
+// generateRegistryEntries merges the digest and tag mirrors computed for IDMS/ITMS:
+// a source that is mirrored by tag (even partially) can't be restricted to digest pulls.
+func (o *ClusterResourcesGenerator) generateRegistryEntries(allRelatedImages []v2alpha1.CopyImageSchema, forceRepositoryScope bool) ([]registryEntry, error) {
+	byDigestMirrors, err := o.generateImageMirrors(allRelatedImages, DigestsOnlyMode, forceRepositoryScope)
+	if err != nil {
+		return nil, err
+	}
+	byTagMirrors, err := o.generateImageMirrors(allRelatedImages, TagsOnlyMode, forceRepositoryScope)
+	if err != nil {
+		return nil, err
+	}
+
+	entriesBySource := make(map[string]*registryEntry)
+	addMirrors := func(mirrorsByCategory []categorizedMirrors, byDigestOnly bool) {
+		for _, catMirrors := range mirrorsByCategory {
+			for source, imgMirrors := range catMirrors.mirrors {
+				entry, ok := entriesBySource[source]
+				if !ok {
+					entry = &registryEntry{category: catMirrors.category, prefix: source, mirrorByDigestOnly: byDigestOnly}
+					entriesBySource[source] = entry
+				}
+				if !byDigestOnly {
+					entry.mirrorByDigestOnly = false
+				}
+				for _, m := range imgMirrors {
+					if !slices.Contains(entry.mirrors, string(m)) {
+						entry.mirrors = append(entry.mirrors, string(m))
+					}
+				}
+			}
+		}
+	}
+	addMirrors(byDigestMirrors, true)
+	addMirrors(byTagMirrors, false)
+
+	entries := make([]registryEntry, 0, len(entriesBySource))
+	for _, entry := range entriesBySource {
+		entries = append(entries, *entry)
+	}
+	// sort for a stable output between runs
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].category != entries[j].category {
+			return entries[i].category < entries[j].category
+		}
+		return entries[i].prefix < entries[j].prefix
+	})
+	return entries, nil
+}
+
+func renderRegistriesConf(entries []registryEntry) []byte {
+	var sb strings.Builder
+	sb.WriteString("# Generated by oc-mirror. Copy to " + registriesConfHostDir + "/" + registriesConfFileName + "\n")
+	var currentCategory mirrorCategory = -1
+	for _, entry := range entries {
+		if entry.category != currentCategory {
+			currentCategory = entry.category
+			sb.WriteString("\n# " + currentCategory.toString() + " images\n")
+		}
+		sb.WriteString("[[registry]]\n")
+		sb.WriteString("  prefix = " + strconv.Quote(entry.prefix) + "\n")
+		sb.WriteString("  location = " + strconv.Quote(entry.prefix) + "\n")
+		sb.WriteString("  mirror-by-digest-only = " + strconv.FormatBool(entry.mirrorByDigestOnly) + "\n")
+		for _, m := range entry.mirrors {
+			sb.WriteString("\n  [[registry.mirror]]\n")
+			sb.WriteString("    location = " + strconv.Quote(m) + "\n")
+		}
+		sb.WriteString("\n")
+	}
+	return []byte(sb.String())
+}
+
+// renderPolicy generates a containers-policy.json(5) for the mirrored sources:
+// oc-mirror doesn't mirror sigstore signatures, so the sources are declared as insecureAcceptAnything.
+// The default and docker-daemon requirements are the ones of the containers-common policy,
+// so that the policy is complete on its own (podman --signature-policy), and its docker
+// transport can be merged in the policy.json of a host with stricter requirements.
+func renderPolicy(entries []registryEntry) ([]byte, error) {
+	policy := containersPolicy{
+		Default: []policyRequirement{{Type: "insecureAcceptAnything"}},
+		Transports: map[string]map[string][]policyRequirement{
+			"docker":        {},
+			"docker-daemon": {"": {{Type: "insecureAcceptAnything"}}},
+		},
+	}
+	for _, entry := range entries {
+		policy.Transports["docker"][entry.prefix] = []policyRequirement{{Type: "insecureAcceptAnything"}}
+	}
+	policyBytes, err := json.MarshalIndent(policy, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(policyBytes, '\n'), nil
+}
+
+func renderMachineConfig(files []hostFile, role string) ([]byte, error) {
+	mc := machineConfig{
+		APIVersion: machineConfigAPIVersion,
+		Kind:       machineConfigKind,
+	}
+	mc.Metadata.Name = fmt.Sprintf(machineConfigName, role)
+	mc.Metadata.Labels = map[string]string{machineConfigRoleLabel: role}
+	mc.Spec.Config.Ignition.Version = ignitionVersion
+	for _, f := range files {
+		mc.Spec.Config.Storage.Files = append(mc.Spec.Config.Storage.Files, ignitionFile{
+			Path:      f.hostPath,
+			Mode:      registriesConfFileMode,
+			Overwrite: true,
+			Contents:  ignitionFileContents{Source: "data:," + url.PathEscape(string(f.content))},
+		})
+	}
+	return yaml.Marshal(mc)
+}
+
+func renderButane(files []hostFile, role string) ([]byte, error) {
+	bu := butaneConfig{
+		Variant: butaneVariant,
+		Version: butaneVersion,
+	}
+	bu.Metadata.Name = fmt.Sprintf(machineConfigName, role)
+	bu.Metadata.Labels = map[string]string{machineConfigRoleLabel: role}
+	for _, f := range files {
+		bf := butaneFile{
+			Path:      f.hostPath,
+			Mode:      registriesConfFileMode,
+			Overwrite: true,
+		}
+		bf.Contents.Inline = string(f.content)
+		bu.Storage.Files = append(bu.Storage.Files, bf)
+	}
+	return yaml.Marshal(bu)
+}
+
+func (o *ClusterResourcesGenerator) writeClusterResourceFile(fileName string, content []byte) error {
+	filePath := filepath.Join(o.WorkingDir, clusterResourcesDir, fileName)
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filePath, content, 0644); err != nil {
+		return err
+	}
+	o.Log.Info("%s file created", filePath)
+	return nil
+}
@@ -0,0 +1,180 @@
+package clusterresources
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
+)
+
+func TestRegistriesConfGenerator(t *testing.T) {
+	log := clog.New("trace")
+
+	t.Run("Testing RegistriesConfGenerator - mixed digests and tags : should pass", func(t *testing.T) {
+		workingDir := t.TempDir() + "/working-dir"
+		cr := &ClusterResourcesGenerator{
+			Log:        log,
+			WorkingDir: workingDir,
+		}
+		err := cr.RegistriesConfGenerator(imageListMixed, false, RegistriesConfOptions{})
+		if err != nil {
+			t.Fatalf("should not fail: %v", err)
+		}
+
+		registriesConf, err := os.ReadFile(filepath.Join(workingDir, clusterResourcesDir, registriesConfDir, registriesConfFileName))
+		if err != nil {
+			t.Fatalf("failed to read file: %v", err)
+		}
+		entries := parseRegistriesConf(string(registriesConf))
+
+		// mirrored by tag only
+		assert.Equal(t, registryEntry{prefix: "gcr.io/kubebuilder", mirrors: []string{"myregistry/mynamespace/kubebuilder"}, mirrorByDigestOnly: false}, entries["gcr.io/kubebuilder"])
+		// mirrored by digest only
+		assert.Equal(t, registryEntry{prefix: "quay.io/openshift-community-operators", mirrors: []string{"myregistry/mynamespace/openshift-community-operators"}, mirrorByDigestOnly: true}, entries["quay.io/openshift-community-operators"])
+		assert.Equal(t, registryEntry{prefix: "quay.io/openshift-release-dev", mirrors: []string{"myregistry/mynamespace/openshift-release-dev"}, mirrorByDigestOnly: true}, entries["quay.io/openshift-release-dev"])
+		// image by tag within the same repository as images by digest
+		assert.False(t, entries["registry.redhat.io/ubi8"].mirrorByDigestOnly)
+		// catalogs and graph image are not mirrors
+		_, ok := entries["quay.io/openshift"]
+		assert.False(t, ok)
+		_, ok = entries["localhost:5000/openshift"]
+		assert.False(t, ok)
+
+		_, err = os.Stat(filepath.Join(workingDir, clusterResourcesDir, policyJSONFileName))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("Testing RegistriesConfGenerator - digests only with policy and machineconfig : should pass", func(t *testing.T) {
+		workingDir := t.TempDir() + "/working-dir"
+		cr := &ClusterResourcesGenerator{
+			Log:        log,
+			WorkingDir: workingDir,
+		}
+		err := cr.RegistriesConfGenerator(imageListDigestsOnly, false, RegistriesConfOptions{PolicyJSON: true, Wrapper: WrapperMachineConfig})
+		if err != nil {
+			t.Fatalf("should not fail: %v", err)
+		}
+
+		registriesConf, err := os.ReadFile(filepath.Join(workingDir, clusterResourcesDir, registriesConfDir, registriesConfFileName))
+		if err != nil {
+			t.Fatalf("failed to read file: %v", err)
+		}
+		entries := parseRegistriesConf(string(registriesConf))
+		assert.Equal(t, 1, len(entries))
+		assert.True(t, entries["quay.io/openshift-release-dev"].mirrorByDigestOnly)
+
+		policyBytes, err := os.ReadFile(filepath.Join(workingDir, clusterResourcesDir, policyJSONFileName))
+		if err != nil {
+			t.Fatalf("failed to read file: %v", err)
+		}
+		policy := containersPolicy{}
+		if err := json.Unmarshal(policyBytes, &policy); err != nil {
+			t.Fatalf("failed to unmarshall file: %v", err)
+		}
+		// the policy is complete on its own
+		assert.Equal(t, []policyRequirement{{Type: "insecureAcceptAnything"}}, policy.Default)
+		assert.Equal(t, []policyRequirement{{Type: "insecureAcceptAnything"}}, policy.Transports["docker"]["quay.io/openshift-release-dev"])
+
+		mcBytes, err := os.ReadFile(filepath.Join(workingDir, clusterResourcesDir, machineConfigFileName))
+		if err != nil {
+			t.Fatalf("failed to read file: %v", err)
+		}
+		mc := machineConfig{}
+		if err := yaml.Unmarshal(mcBytes, &mc); err != nil {
+			t.Fatalf("failed to unmarshall file: %v", err)
+		}
+		assert.Equal(t, "99-worker-oc-mirror-registries", mc.Metadata.Name)
+		assert.Equal(t, "worker", mc.Metadata.Labels[machineConfigRoleLabel])
+		assert.Equal(t, 2, len(mc.Spec.Config.Storage.Files))
+		assert.Equal(t, "/etc/containers/registries.conf.d/99-oc-mirror.conf", mc.Spec.Config.Storage.Files[0].Path)
+		// the policy of the hosts is not overwritten
+		assert.Equal(t, "/etc/containers/policy-oc-mirror.json", mc.Spec.Config.Storage.Files[1].Path)
+		data, err := url.PathUnescape(strings.TrimPrefix(mc.Spec.Config.Storage.Files[0].Contents.Source, "data:,"))
+		assert.NoError(t, err)
+		assert.Equal(t, string(registriesConf), data)
+	})
+
+	t.Run("Testing RegistriesConfGenerator - butane wrapper : should pass", func(t *testing.T) {
+		workingDir := t.TempDir() + "/working-dir"
+		cr := &ClusterResourcesGenerator{
+			Log:        log,
+			WorkingDir: workingDir,
+		}
+		err := cr.RegistriesConfGenerator(imageListMaxNestedPaths, true, RegistriesConfOptions{Wrapper: WrapperButane, Role: "master"})
+		if err != nil {
+			t.Fatalf("should not fail: %v", err)
+		}
+		buBytes, err := os.ReadFile(filepath.Join(workingDir, clusterResourcesDir, butaneFileName))
+		if err != nil {
+			t.Fatalf("failed to read file: %v", err)
+		}
+		bu := butaneConfig{}
+		if err := yaml.Unmarshal(buBytes, &bu); err != nil {
+			t.Fatalf("failed to unmarshall file: %v", err)
+		}
+		assert.Equal(t, butaneVariant, bu.Variant)
+		assert.Equal(t, "master", bu.Metadata.Labels[machineConfigRoleLabel])
+		assert.Equal(t, 1, len(bu.Storage.Files))
+		assert.Contains(t, bu.Storage.Files[0].Contents.Inline, `prefix = "quay.io/cockroachdb/cockroach-helm-operator"`)
+		assert.Contains(t, bu.Storage.Files[0].Contents.Inline, `location = "myregistry/mynamespace/cockroachdb-cockroach-helm-operator"`)
+	})
+
+	t.Run("Testing RegistriesConfGenerator - unknown wrapper : should fail", func(t *testing.T) {
+		cr := &ClusterResourcesGenerator{
+			Log:        log,
+			WorkingDir: t.TempDir() + "/working-dir",
+		}
+		err := cr.RegistriesConfGenerator(imageListMixed, false, RegistriesConfOptions{Wrapper: "ignition"})
+		assert.Error(t, err)
+	})
+
+	t.Run("Testing RegistriesConfGenerator - nothing mirrored : should not generate", func(t *testing.T) {
+		workingDir := t.TempDir() + "/working-dir"
+		cr := &ClusterResourcesGenerator{
+			Log:        log,
+			WorkingDir: workingDir,
+		}
+		err := cr.RegistriesConfGenerator([]v2alpha1.CopyImageSchema{}, false, RegistriesConfOptions{})
+		assert.NoError(t, err)
+		_, err = os.Stat(filepath.Join(workingDir, clusterResourcesDir))
+		assert.True(t, os.IsNotExist(err))
+	})
+}
+
+// parseRegistriesConf is a minimal reader of the drop-in rendered by renderRegistriesConf
+func parseRegistriesConf(content string) map[string]registryEntry {
+	entries := make(map[string]registryEntry)
+	var current *registryEntry
+	flush := func() {
+		if current != nil {
+			entries[current.prefix] = *current
+		}
+	}
+	inMirror := false
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "[[registry]]":
+			flush()
+			current = &registryEntry{}
+			inMirror = false
+		case line == "[[registry.mirror]]":
+			inMirror = true
+		case strings.HasPrefix(line, "prefix = "):
+			current.prefix = strings.Trim(strings.TrimPrefix(line, "prefix = "), `"`)
+		case strings.HasPrefix(line, "location = ") && inMirror:
+			current.mirrors = append(current.mirrors, strings.Trim(strings.TrimPrefix(line, "location = "), `"`))
+		case strings.HasPrefix(line, "mirror-by-digest-only = "):
+			current.mirrorByDigestOnly = strings.TrimPrefix(line, "mirror-by-digest-only = ") == "true"
+		}
+	}
+	flush()
+	return entries
+}
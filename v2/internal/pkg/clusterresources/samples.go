@@ -0,0 +1,115 @@
+package clusterresources
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	samplesv1 "github.com/openshift/api/samples/v1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/emoji"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/image"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// SamplesConfigGenerator generates the samples.operator.openshift.io Config
+// pointing the samples operator at the registry where the sample images were mirrored.
+// The imagestreams of the releases that were not selected in the imagesetconfig
+// are skipped, so that the samples operator doesn't try to import them.
+func (o *ClusterResourcesGenerator) SamplesConfigGenerator(allRelatedImages []v2alpha1.CopyImageSchema) error {
+	samplesRegistry := ""
+	for _, img := range allRelatedImages {
+		if !img.Type.IsSampleImage() {
+			continue
+		}
+		registry, err := samplesRegistryOf(img)
+		if err != nil {
+			return err
+		}
+		if samplesRegistry == "" {
+			samplesRegistry = registry
+		} else if samplesRegistry != registry {
+			return fmt.Errorf(samplesConfigMsg, fmt.Sprintf("sample images were mirrored to different registries (%s, %s)", samplesRegistry, registry))
+		}
+	}
+	if samplesRegistry == "" {
+		o.Log.Debug("[SamplesConfigGenerator] no sample images mirrored. Skipping samples operator config generation.")
+		return nil
+	}
+
+	o.Log.Info(emoji.PageFacingUp + " Generating samples operator Config file...")
+	skipped, err := o.readSkippedImageStreams()
+	if err != nil {
+		return err
+	}
+
+	config := samplesv1.Config{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: samplesv1.GroupVersion.String(),
+			Kind:       samplesConfigKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: samplesConfigName,
+		},
+		Spec: samplesv1.ConfigSpec{
+			ManagementState:     operatorv1.Managed,
+			SamplesRegistry:     samplesRegistry,
+			SkippedImagestreams: skipped,
+		},
+	}
+	configBytes, err := yaml.Marshal(config)
+	if err != nil {
+		return fmt.Errorf(samplesConfigMsg, err)
+	}
+	// creationTimestamp is a struct, omitempty does not apply
+	configBytes = bytes.ReplaceAll(configBytes, []byte("  creationTimestamp: null\n"), []byte(""))
+
+	configPath := filepath.Join(o.WorkingDir, clusterResourcesDir, samplesConfigFilename)
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		return fmt.Errorf(samplesConfigMsg, err)
+	}
+	if err := os.WriteFile(configPath, configBytes, 0644); err != nil {
+		return fmt.Errorf(samplesConfigMsg, err)
+	}
+	o.Log.Info("%s file created", configPath)
+	return nil
+}
+
+// samplesRegistryOf returns the part of the destination that replaces the registry
+// of the sample image: the samples are mirrored under their original path.
+func samplesRegistryOf(img v2alpha1.CopyImageSchema) (string, error) {
+	originSpec, err := image.ParseRef(img.Origin)
+	if err != nil {
+		return "", fmt.Errorf(samplesConfigMsg, err)
+	}
+	destSpec, err := image.ParseRef(img.Destination)
+	if err != nil {
+		return "", fmt.Errorf(samplesConfigMsg, err)
+	}
+	registry, found := strings.CutSuffix(destSpec.Name, "/"+originSpec.PathComponent)
+	if !found {
+		return "", fmt.Errorf(samplesConfigMsg, fmt.Sprintf("sample image %s was not mirrored under its original path (%s)", img.Origin, destSpec.Name))
+	}
+	return registry, nil
+}
+
+func (o *ClusterResourcesGenerator) readSkippedImageStreams() ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(o.WorkingDir, samplesDir, skippedImageStreamsFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf(samplesConfigMsg, err)
+	}
+	var skipped []string
+	if err := json.Unmarshal(data, &skipped); err != nil {
+		return nil, fmt.Errorf(samplesConfigMsg, err)
+	}
+	return skipped, nil
+}
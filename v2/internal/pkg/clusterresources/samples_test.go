@@ -0,0 +1,88 @@
+package clusterresources
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	operatorv1 "github.com/openshift/api/operator/v1"
+	samplesv1 "github.com/openshift/api/samples/v1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
+)
+
+var imageListSamples = []v2alpha1.CopyImageSchema{
+	{
+		Source:      "docker://localhost:55000/ubi8/ruby-30:latest",
+		Destination: "docker://myregistry/mynamespace/ubi8/ruby-30:latest",
+		Origin:      "registry.redhat.io/ubi8/ruby-30:latest",
+		Type:        v2alpha1.TypeSampleImage,
+	},
+	{
+		Source:      "docker://localhost:55000/ubi9/ruby-31:sha256-7c4ef7434c97c8aaf6cd310874790b915b3c61fc902eea255f9177058ea9aff3",
+		Destination: "docker://myregistry/mynamespace/ubi9/ruby-31:sha256-7c4ef7434c97c8aaf6cd310874790b915b3c61fc902eea255f9177058ea9aff3",
+		Origin:      "registry.redhat.io/ubi9/ruby-31@sha256:7c4ef7434c97c8aaf6cd310874790b915b3c61fc902eea255f9177058ea9aff3",
+		Type:        v2alpha1.TypeSampleImage,
+	},
+}
+
+func TestSamplesConfigGenerator(t *testing.T) {
+	log := clog.New("trace")
+
+	t.Run("Testing SamplesConfigGenerator - samples mirrored : should generate config", func(t *testing.T) {
+		workingDir := t.TempDir() + "/working-dir"
+		assert.NoError(t, os.MkdirAll(filepath.Join(workingDir, samplesDir), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(workingDir, samplesDir, skippedImageStreamsFile), []byte(`["perl","php"]`), 0600))
+		cr := &ClusterResourcesGenerator{
+			Log:        log,
+			WorkingDir: workingDir,
+		}
+		err := cr.SamplesConfigGenerator(append(imageListSamples, imageListMixed...))
+		assert.NoError(t, err)
+
+		configBytes, err := os.ReadFile(filepath.Join(workingDir, clusterResourcesDir, samplesConfigFilename))
+		if err != nil {
+			t.Fatalf("failed to read file: %v", err)
+		}
+		config := samplesv1.Config{}
+		if err := yaml.Unmarshal(configBytes, &config); err != nil {
+			t.Fatalf("failed to unmarshall file: %v", err)
+		}
+		assert.Equal(t, "samples.operator.openshift.io/v1", config.APIVersion)
+		assert.Equal(t, "Config", config.Kind)
+		assert.Equal(t, "cluster", config.Name)
+		assert.Equal(t, operatorv1.Managed, config.Spec.ManagementState)
+		assert.Equal(t, "myregistry/mynamespace", config.Spec.SamplesRegistry)
+		assert.Equal(t, []string{"perl", "php"}, config.Spec.SkippedImagestreams)
+	})
+
+	t.Run("Testing SamplesConfigGenerator - no samples : should not generate", func(t *testing.T) {
+		workingDir := t.TempDir() + "/working-dir"
+		cr := &ClusterResourcesGenerator{
+			Log:        log,
+			WorkingDir: workingDir,
+		}
+		err := cr.SamplesConfigGenerator(imageListMixed)
+		assert.NoError(t, err)
+		_, err = os.Stat(filepath.Join(workingDir, clusterResourcesDir, samplesConfigFilename))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("Testing SamplesConfigGenerator - samples path changed : should fail", func(t *testing.T) {
+		cr := &ClusterResourcesGenerator{
+			Log:        log,
+			WorkingDir: t.TempDir() + "/working-dir",
+		}
+		err := cr.SamplesConfigGenerator([]v2alpha1.CopyImageSchema{
+			{
+				Source:      "docker://localhost:55000/ubi8/ruby-30:latest",
+				Destination: "docker://myregistry/mynamespace/ubi8-ruby-30:latest",
+				Origin:      "registry.redhat.io/ubi8/ruby-30:latest",
+				Type:        v2alpha1.TypeSampleImage,
+			},
+		})
+		assert.Error(t, err)
+	})
+}
@@ -1,13 +1,27 @@
 package config
 
 import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/semver/v3"
+
 	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
 )
 
+var (
+	ocpVersionPattern     = regexp.MustCompile(`^[0-9]+\.[0-9]+$`)
+	channelVersionPattern = regexp.MustCompile(`-([0-9]+\.[0-9]+)$`)
+)
+
 // Complete set default values in the ImageSetConfiguration
 // when applicable
 func Complete(cfg *v2alpha1.ImageSetConfiguration) {
 	completeReleaseArchitectures(cfg)
+	cfg.Mirror.Operators = completeOperatorCatalogs(cfg.Mirror.Operators, cfg.Mirror.Platform)
 }
 
 func completeReleaseArchitectures(cfg *v2alpha1.ImageSetConfiguration) {
@@ -20,6 +34,7 @@ func completeReleaseArchitectures(cfg *v2alpha1.ImageSetConfiguration) {
 // when applicable
 func CompleteDelete(cfg *v2alpha1.DeleteImageSetConfiguration) {
 	completeReleaseArchitecturesDelete(cfg)
+	cfg.Delete.Operators = completeOperatorCatalogs(cfg.Delete.Operators, cfg.Delete.Platform)
 }
 
 func completeReleaseArchitecturesDelete(cfg *v2alpha1.DeleteImageSetConfiguration) {
@@ -27,3 +42,120 @@ func completeReleaseArchitecturesDelete(cfg *v2alpha1.DeleteImageSetConfiguratio
 		cfg.Delete.Platform.Architectures = []string{v2alpha1.DefaultPlatformArchitecture}
 	}
 }
+
+// completeOperatorCatalogs expands the catalog templates into a catalog per OCP version.
+// The templates that cannot be expanded are kept as is, and reported by the validation.
+func completeOperatorCatalogs(operators []v2alpha1.Operator, platform v2alpha1.Platform) []v2alpha1.Operator {
+	var completed []v2alpha1.Operator
+	for _, op := range operators {
+		if !op.IsCatalogTemplate() {
+			completed = append(completed, op)
+			continue
+		}
+		catalogs, err := expandCatalogTemplate(op, platform)
+		if err != nil {
+			completed = append(completed, op)
+			continue
+		}
+		completed = append(completed, catalogs...)
+	}
+	return completed
+}
+
+// expandCatalogTemplate returns a catalog per OCP version of the catalog template, with its catalog,
+// targetCatalog and targetTag rendered for the version. The targetTag defaults to v<OCP version>.
+func expandCatalogTemplate(op v2alpha1.Operator, platform v2alpha1.Platform) ([]v2alpha1.Operator, error) {
+	versions := op.OCPVersions
+	if len(versions) == 0 {
+		versions = platformOCPVersions(platform)
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("catalog %q: ocpVersions or a platform channel is required to expand the catalog template", op.Catalog)
+	}
+
+	catalogs := []v2alpha1.Operator{}
+	for _, version := range versions {
+		if !ocpVersionPattern.MatchString(version) {
+			return nil, fmt.Errorf("catalog %q: OCP version %q is not valid, it should be <major>.<minor>", op.Catalog, version)
+		}
+		catalog := op
+		catalog.OCPVersions = []string{version}
+		var err error
+		if catalog.Catalog, err = renderOCPVersion(op.Catalog, version); err != nil {
+			return nil, fmt.Errorf("catalog %q: %w", op.Catalog, err)
+		}
+		if catalog.TargetCatalog, err = renderOCPVersion(op.TargetCatalog, version); err != nil {
+			return nil, fmt.Errorf("catalog %q: targetCatalog: %w", op.Catalog, err)
+		}
+		if op.TargetTag == "" {
+			catalog.TargetTag = "v" + version
+		} else if catalog.TargetTag, err = renderOCPVersion(op.TargetTag, version); err != nil {
+			return nil, fmt.Errorf("catalog %q: targetTag: %w", op.Catalog, err)
+		}
+		catalogs = append(catalogs, catalog)
+	}
+	return catalogs, nil
+}
+
+func renderOCPVersion(text, version string) (string, error) {
+	tmpl, err := template.New("catalog").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var rendered strings.Builder
+	if err := tmpl.Execute(&rendered, struct{ OCPVersion string }{OCPVersion: version}); err != nil {
+		return "", err
+	}
+	return rendered.String(), nil
+}
+
+// platformOCPVersions returns the OCP minor versions of the release channels of the platform:
+// the minor versions from the lowest to the highest of the channel name (stable-4.16),
+// its minVersion and its maxVersion.
+func platformOCPVersions(platform v2alpha1.Platform) []string {
+	versions := map[string]*semver.Version{}
+	for _, ch := range platform.Channels {
+		var bounds []*semver.Version
+		for _, v := range []string{ch.MinVersion, ch.MaxVersion} {
+			if version, err := semver.NewVersion(v); err == nil {
+				bounds = append(bounds, version)
+			}
+		}
+		if match := channelVersionPattern.FindStringSubmatch(ch.Name); match != nil {
+			if version, err := semver.NewVersion(match[1]); err == nil {
+				bounds = append(bounds, version)
+			}
+		}
+		if len(bounds) == 0 {
+			continue
+		}
+		sort.Sort(semver.Collection(bounds))
+		lowest, highest := bounds[0], bounds[len(bounds)-1]
+		if lowest.Major() != highest.Major() {
+			// the minor versions of another major version are unknown
+			for _, version := range bounds {
+				addOCPVersion(versions, version.Major(), version.Minor())
+			}
+			continue
+		}
+		for minor := lowest.Minor(); minor <= highest.Minor(); minor++ {
+			addOCPVersion(versions, lowest.Major(), minor)
+		}
+	}
+
+	sorted := make([]*semver.Version, 0, len(versions))
+	for _, version := range versions {
+		sorted = append(sorted, version)
+	}
+	sort.Sort(semver.Collection(sorted))
+	result := []string{}
+	for _, version := range sorted {
+		result = append(result, fmt.Sprintf("%d.%d", version.Major(), version.Minor()))
+	}
+	return result
+}
+
+func addOCPVersion(versions map[string]*semver.Version, major, minor uint64) {
+	version := semver.New(major, minor, 0, "", "")
+	versions[version.String()] = version
+}
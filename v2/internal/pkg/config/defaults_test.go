@@ -53,6 +53,72 @@ func TestComplete(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Valid/CatalogTemplate",
+			config: v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						Operators: []v2alpha1.Operator{
+							{
+								Catalog:     "registry.redhat.io/redhat/redhat-operator-index:v{{ .OCPVersion }}",
+								OCPVersions: []string{"4.15", "4.16"},
+							},
+							{
+								Catalog:       "registry.redhat.io/redhat/certified-operator-index:v{{ .OCPVersion }}",
+								TargetCatalog: "certified/ocp-{{ .OCPVersion }}",
+								TargetTag:     "curated",
+							},
+							{
+								Catalog: "registry.redhat.io/redhat/community-operator-index:v4.16",
+							},
+						},
+						Platform: v2alpha1.Platform{
+							Architectures: []string{v2alpha1.DefaultPlatformArchitecture},
+							Channels: []v2alpha1.ReleaseChannel{
+								{
+									Name: "stable-4.14",
+								},
+							},
+						},
+					},
+				},
+			},
+			expConfig: v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						Operators: []v2alpha1.Operator{
+							{
+								Catalog:     "registry.redhat.io/redhat/redhat-operator-index:v4.15",
+								OCPVersions: []string{"4.15"},
+								TargetTag:   "v4.15",
+							},
+							{
+								Catalog:     "registry.redhat.io/redhat/redhat-operator-index:v4.16",
+								OCPVersions: []string{"4.16"},
+								TargetTag:   "v4.16",
+							},
+							{
+								Catalog:       "registry.redhat.io/redhat/certified-operator-index:v4.14",
+								OCPVersions:   []string{"4.14"},
+								TargetCatalog: "certified/ocp-4.14",
+								TargetTag:     "curated",
+							},
+							{
+								Catalog: "registry.redhat.io/redhat/community-operator-index:v4.16",
+							},
+						},
+						Platform: v2alpha1.Platform{
+							Architectures: []string{v2alpha1.DefaultPlatformArchitecture},
+							Channels: []v2alpha1.ReleaseChannel{
+								{
+									Name: "stable-4.14",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, c := range cases {
@@ -118,3 +184,37 @@ func TestDeleteComplete(t *testing.T) {
 		})
 	}
 }
+
+func TestPlatformOCPVersions(t *testing.T) {
+	cases := []struct {
+		name     string
+		channels []v2alpha1.ReleaseChannel
+		expected []string
+	}{
+		{
+			name:     "channel names",
+			channels: []v2alpha1.ReleaseChannel{{Name: "stable-4.16"}, {Name: "fast-4.15"}, {Name: "stable-4.16"}},
+			expected: []string{"4.15", "4.16"},
+		},
+		{
+			name:     "versions between minVersion and the channel",
+			channels: []v2alpha1.ReleaseChannel{{Name: "eus-4.16", MinVersion: "4.14.12"}},
+			expected: []string{"4.14", "4.15", "4.16"},
+		},
+		{
+			name:     "versions between minVersion and maxVersion",
+			channels: []v2alpha1.ReleaseChannel{{Name: "okd", MinVersion: "4.12.1", MaxVersion: "4.13.0"}},
+			expected: []string{"4.12", "4.13"},
+		},
+		{
+			name:     "no version",
+			channels: []v2alpha1.ReleaseChannel{{Name: "stable"}},
+			expected: []string{},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			require.Equal(t, c.expected, platformOCPVersions(v2alpha1.Platform{Channels: c.channels}))
+		})
+	}
+}
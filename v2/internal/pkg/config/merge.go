@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+)
+
+// Merge returns the union of the content of the imageset configurations.
+// Identical entries are kept once. A catalog can only be selected with the same
+// filtering in all the configurations: its filtered catalog is identified by
+// that filtering when mirroring an archive to a registry.
+func Merge(cfgs ...v2alpha1.ImageSetConfiguration) (v2alpha1.ImageSetConfiguration, error) {
+	merged := v2alpha1.ImageSetConfiguration{}
+	merged.SetGroupVersionKind(v2alpha1.GroupVersion.WithKind(v2alpha1.ImageSetConfigurationKind))
+
+	mirror := &merged.Mirror
+	for _, cfg := range cfgs {
+		platform := cfg.Mirror.Platform
+		mirror.Platform.Graph = mirror.Platform.Graph || platform.Graph
+		mirror.Platform.KubeVirtContainer = mirror.Platform.KubeVirtContainer || platform.KubeVirtContainer
+		if platform.Release != "" {
+			if mirror.Platform.Release != "" && mirror.Platform.Release != platform.Release {
+				return merged, fmt.Errorf("platform release is set to %s and %s, only one can be merged", mirror.Platform.Release, platform.Release)
+			}
+			mirror.Platform.Release = platform.Release
+		}
+		mirror.Platform.Channels = appendUnique(mirror.Platform.Channels, platform.Channels...)
+		mirror.Platform.Architectures = appendUnique(mirror.Platform.Architectures, platform.Architectures...)
+
+		for _, op := range cfg.Mirror.Operators {
+			uniqueName, err := op.GetUniqueName()
+			if err != nil {
+				return merged, err
+			}
+			idx := slices.IndexFunc(mirror.Operators, func(existing v2alpha1.Operator) bool {
+				existingName, _ := existing.GetUniqueName()
+				return existingName == uniqueName
+			})
+			if idx < 0 {
+				mirror.Operators = append(mirror.Operators, op)
+			} else if !reflect.DeepEqual(mirror.Operators[idx], op) {
+				return merged, fmt.Errorf("catalog %s is selected with different filtering, it can only be merged when the filtering is the same", uniqueName)
+			}
+		}
+
+		mirror.AdditionalImages = appendUnique(mirror.AdditionalImages, cfg.Mirror.AdditionalImages...)
+		mirror.Helm.Repositories = appendUnique(mirror.Helm.Repositories, cfg.Mirror.Helm.Repositories...)
+		mirror.Helm.Local = appendUnique(mirror.Helm.Local, cfg.Mirror.Helm.Local...)
+		mirror.BlockedImages = appendUnique(mirror.BlockedImages, cfg.Mirror.BlockedImages...)
+		// rules are ordered, the rules of the first configurations apply first
+		mirror.RewriteRules = appendUnique(mirror.RewriteRules, cfg.Mirror.RewriteRules...)
+		mirror.Samples = appendUnique(mirror.Samples, cfg.Mirror.Samples...)
+
+		merged.ArchiveSize = max(merged.ArchiveSize, cfg.ArchiveSize)
+	}
+
+	if err := Validate(&merged); err != nil {
+		return merged, err
+	}
+	return merged, nil
+}
+
+func appendUnique[T any](items []T, toAppend ...T) []T {
+	for _, item := range toAppend {
+		if !slices.ContainsFunc(items, func(existing T) bool { return reflect.DeepEqual(existing, item) }) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+)
+
+func TestMerge(t *testing.T) {
+	release := v2alpha1.ImageSetConfiguration{
+		ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+			ArchiveSize: 4,
+			Mirror: v2alpha1.Mirror{
+				Platform: v2alpha1.Platform{
+					Channels:      []v2alpha1.ReleaseChannel{{Name: "stable-4.16", MinVersion: "4.16.10", MaxVersion: "4.16.10"}},
+					Architectures: []string{"amd64"},
+					Graph:         true,
+				},
+				AdditionalImages: []v2alpha1.Image{{Name: "registry.redhat.io/ubi8/ubi:latest"}},
+			},
+		},
+	}
+	operators := v2alpha1.ImageSetConfiguration{
+		ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+			ArchiveSize: 2,
+			Mirror: v2alpha1.Mirror{
+				Platform: v2alpha1.Platform{
+					Channels:      []v2alpha1.ReleaseChannel{{Name: "stable-4.16", MinVersion: "4.16.10", MaxVersion: "4.16.10"}},
+					Architectures: []string{"amd64", "arm64"},
+				},
+				Operators: []v2alpha1.Operator{
+					{
+						Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.16",
+						IncludeConfig: v2alpha1.IncludeConfig{
+							Packages: []v2alpha1.IncludePackage{{Name: "aws-load-balancer-operator"}},
+						},
+					},
+				},
+				AdditionalImages: []v2alpha1.Image{{Name: "registry.redhat.io/ubi8/ubi:latest"}, {Name: "registry.redhat.io/ubi9/ubi:latest"}},
+			},
+		},
+	}
+
+	t.Run("Testing Merge : should pass", func(t *testing.T) {
+		merged, err := Merge(release, operators)
+		assert.NoError(t, err)
+		assert.Equal(t, v2alpha1.ImageSetConfigurationKind, merged.Kind)
+		assert.Equal(t, int64(4), merged.ArchiveSize)
+		assert.True(t, merged.Mirror.Platform.Graph)
+		assert.Len(t, merged.Mirror.Platform.Channels, 1)
+		assert.Equal(t, []string{"amd64", "arm64"}, merged.Mirror.Platform.Architectures)
+		assert.Equal(t, operators.Mirror.Operators, merged.Mirror.Operators)
+		assert.Equal(t, []v2alpha1.Image{{Name: "registry.redhat.io/ubi8/ubi:latest"}, {Name: "registry.redhat.io/ubi9/ubi:latest"}}, merged.Mirror.AdditionalImages)
+	})
+
+	t.Run("Testing Merge : should fail (same catalog, different filtering)", func(t *testing.T) {
+		otherOperators := v2alpha1.ImageSetConfiguration{}
+		otherOperators.Mirror.Operators = []v2alpha1.Operator{
+			{
+				Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.16",
+				IncludeConfig: v2alpha1.IncludeConfig{
+					Packages: []v2alpha1.IncludePackage{{Name: "devworkspace-operator"}},
+				},
+			},
+		}
+		_, err := Merge(operators, otherOperators)
+		assert.EqualError(t, err, "catalog registry.redhat.io/redhat/redhat-operator-index:v4.16 is selected with different filtering, it can only be merged when the filtering is the same")
+	})
+
+	t.Run("Testing Merge : should fail (different platform release)", func(t *testing.T) {
+		first := v2alpha1.ImageSetConfiguration{}
+		first.Mirror.Platform.Release = "oci:///releases/4.16.10"
+		second := v2alpha1.ImageSetConfiguration{}
+		second.Mirror.Platform.Release = "oci:///releases/4.17.0"
+		_, err := Merge(first, second)
+		assert.EqualError(t, err, "platform release is set to oci:///releases/4.16.10 and oci:///releases/4.17.0, only one can be merged")
+	})
+}
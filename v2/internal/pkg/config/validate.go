@@ -2,18 +2,24 @@ package config
 
 import (
 	"fmt"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/containers/image/v5/docker/reference"
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/image"
 )
 
 type validationFunc func(cfg *v2alpha1.ImageSetConfiguration) []error
 type validationDeleteFunc func(cfg *v2alpha1.DeleteImageSetConfiguration) error
 
-var validationChecks = []validationFunc{validateOperatorOptions, validateReleaseChannels}
-var validationDeleteChecks = []validationDeleteFunc{validateOperatorOptionsDelete, validateReleaseChannelsDelete}
+var validationChecks = []validationFunc{validateOperatorOptions, validateReleaseChannels, validateAdditionalImages, validateSamples}
+var validationDeleteChecks = []validationDeleteFunc{validateOperatorOptionsDelete, validateReleaseChannelsDelete, validateAdditionalImagesDelete, validateSamplesDelete}
 
 // Validate will check an ImagesetConfiguration for input errors.
 func Validate(cfg *v2alpha1.ImageSetConfiguration) error {
@@ -30,6 +36,10 @@ func validateOperatorOptions(cfg *v2alpha1.ImageSetConfiguration) []error {
 	seen := map[string]bool{}
 	errs := []error{}
 	for _, ctlg := range cfg.Mirror.Operators {
+		if err := validateCatalogTemplate(ctlg, cfg.Mirror.Platform); err != nil {
+			errs = append(errs, err)
+			continue
+		}
 		ctlgName, err := ctlg.GetUniqueName()
 		if err != nil {
 			errs = append(errs, err)
@@ -42,6 +52,13 @@ func validateOperatorOptions(cfg *v2alpha1.ImageSetConfiguration) []error {
 		if filterErrs := validateOperatorFiltering(ctlg); len(filterErrs) > 0 {
 			errs = append(errs, filterErrs...)
 		}
+		errs = append(errs, validateLocalCatalog(ctlg)...)
+		switch ctlg.Deprecated {
+		case "", v2alpha1.DeprecatedInclude, v2alpha1.DeprecatedExclude, v2alpha1.DeprecatedWarn:
+		default:
+			errs = append(errs, fmt.Errorf("catalog %q: deprecated %q is not valid, it should be one of %s, %s or %s",
+				ctlg.Catalog, ctlg.Deprecated, v2alpha1.DeprecatedInclude, v2alpha1.DeprecatedExclude, v2alpha1.DeprecatedWarn))
+		}
 
 		seen[ctlgName] = true
 	}
@@ -50,8 +67,49 @@ func validateOperatorOptions(cfg *v2alpha1.ImageSetConfiguration) []error {
 	}
 	return nil
 }
+
+// validateCatalogTemplate checks that a catalog template can be expanded per OCP version,
+// and that ocpVersions is only used with a catalog template.
+// The templates that can be expanded are replaced by their catalogs when the configuration is completed.
+func validateCatalogTemplate(ctlg v2alpha1.Operator, platform v2alpha1.Platform) error {
+	if ctlg.IsCatalogTemplate() {
+		if _, err := expandCatalogTemplate(ctlg, platform); err != nil {
+			return err
+		}
+		return fmt.Errorf("catalog %q: the catalog template is not expanded", ctlg.Catalog)
+	}
+	if len(ctlg.OCPVersions) > 1 {
+		return fmt.Errorf("catalog %q: ocpVersions requires a catalog template using {{ .OCPVersion }}", ctlg.Catalog)
+	}
+	return nil
+}
+
+// validateLocalCatalog checks that a catalog from a file-based catalog directory on disk
+// has what is needed to build its image
+func validateLocalCatalog(ctlg v2alpha1.Operator) []error {
+	errs := []error{}
+	if !ctlg.IsLocalFBC() {
+		if ctlg.BaseImage != "" {
+			errs = append(errs, fmt.Errorf("catalog %q: baseImage can only be set for a file-based catalog directory (fbc://)", ctlg.Catalog))
+		}
+		return errs
+	}
+	if ctlg.TargetCatalog == "" {
+		errs = append(errs, fmt.Errorf("catalog %q: targetCatalog is required for a file-based catalog directory", ctlg.Catalog))
+	}
+	if ctlg.BaseImage == "" {
+		errs = append(errs, fmt.Errorf("catalog %q: baseImage is required for a file-based catalog directory", ctlg.Catalog))
+	} else if _, err := reference.ParseNormalizedNamed(strings.TrimPrefix(ctlg.BaseImage, "docker://")); err != nil {
+		errs = append(errs, fmt.Errorf("catalog %q: baseImage %q is not a valid image reference: %v", ctlg.Catalog, ctlg.BaseImage, err))
+	}
+	return errs
+}
+
 func validateOperatorFiltering(ctlg v2alpha1.Operator) []error {
 	errs := []error{}
+	if ctlg.KeepLast < 0 {
+		errs = append(errs, fmt.Errorf("catalog %q: keepLast %d must be positive", ctlg.Catalog, ctlg.KeepLast))
+	}
 	if len(ctlg.Packages) > 0 {
 		for _, pkg := range ctlg.Packages {
 			if pkg.MaxVersion != "" || pkg.MinVersion != "" {
@@ -74,8 +132,14 @@ func validateOperatorFiltering(ctlg v2alpha1.Operator) []error {
 					}
 				}
 			}
+			if pkg.KeepLast < 0 {
+				errs = append(errs, fmt.Errorf("catalog %q: operator %q: keepLast %d must be positive", ctlg.Catalog, pkg.Name, pkg.KeepLast))
+			}
 			if len(pkg.Channels) > 0 {
 				for _, chFilter := range pkg.Channels {
+					if chFilter.KeepLast < 0 {
+						errs = append(errs, fmt.Errorf("catalog %q: operator %q: channel %q: keepLast %d must be positive", ctlg.Catalog, pkg.Name, chFilter.Name, chFilter.KeepLast))
+					}
 					if chFilter.MaxVersion != "" {
 						if _, err := semver.NewVersion(chFilter.MaxVersion); err != nil {
 							errs = append(errs, fmt.Errorf("catalog %q: operator %q: channel %q: maxVersion %q must respect semantic versioning notation", ctlg.Catalog, pkg.Name, chFilter.Name, chFilter.MaxVersion))
@@ -88,6 +152,7 @@ func validateOperatorFiltering(ctlg v2alpha1.Operator) []error {
 					}
 				}
 			}
+			errs = append(errs, validateSelectedBundles(ctlg, pkg)...)
 		}
 	}
 	if len(errs) > 0 {
@@ -95,6 +160,40 @@ func validateOperatorFiltering(ctlg v2alpha1.Operator) []error {
 	}
 	return nil
 }
+
+// validateSelectedBundles checks that the bundles selected by name are not mixed with
+// the other filtering of the package. Whether these bundles exist is only known
+// once the catalog is pulled, when it gets filtered.
+func validateSelectedBundles(ctlg v2alpha1.Operator, pkg v2alpha1.IncludePackage) []error {
+	errs := []error{}
+	if len(pkg.SelectedBundles) == 0 {
+		if pkg.SkipUpgradePath {
+			errs = append(errs, fmt.Errorf("catalog %q: operator %q: skipUpgradePath can only be set along with bundles", ctlg.Catalog, pkg.Name))
+		}
+		return errs
+	}
+	if ctlg.Full {
+		errs = append(errs, fmt.Errorf("catalog %q: operator %q: mixing both full: true and filtering by bundles is not allowed", ctlg.Catalog, pkg.Name))
+	}
+	if len(pkg.Channels) > 0 || pkg.MinVersion != "" || pkg.MaxVersion != "" {
+		errs = append(errs, fmt.Errorf("catalog %q: operator %q: mixing both filtering by bundles and filtering by channels or minVersion/maxVersion is not allowed", ctlg.Catalog, pkg.Name))
+	}
+	if pkg.KeepLast > 0 {
+		errs = append(errs, fmt.Errorf("catalog %q: operator %q: mixing both filtering by bundles and keepLast is not allowed", ctlg.Catalog, pkg.Name))
+	}
+	seen := map[string]bool{}
+	for _, bundle := range pkg.SelectedBundles {
+		switch {
+		case bundle.Name == "":
+			errs = append(errs, fmt.Errorf("catalog %q: operator %q: bundles must have a name", ctlg.Catalog, pkg.Name))
+		case seen[bundle.Name]:
+			errs = append(errs, fmt.Errorf("catalog %q: operator %q: bundle %q: duplicate found in configuration", ctlg.Catalog, pkg.Name, bundle.Name))
+		}
+		seen[bundle.Name] = true
+	}
+	return errs
+}
+
 func validateReleaseChannels(cfg *v2alpha1.ImageSetConfiguration) []error {
 	seen := map[string]bool{}
 	for _, channel := range cfg.Mirror.Platform.Channels {
@@ -108,6 +207,127 @@ func validateReleaseChannels(cfg *v2alpha1.ImageSetConfiguration) []error {
 	return nil
 }
 
+func validateAdditionalImages(cfg *v2alpha1.ImageSetConfiguration) []error {
+	errs := validateImagesTagsFilter(cfg.Mirror.AdditionalImages)
+	errs = append(errs, validateImagesTarget(cfg.Mirror.AdditionalImages)...)
+	errs = append(errs, validateRewriteRules(cfg.Mirror.RewriteRules)...)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validateImagesTarget(images []v2alpha1.Image) []error {
+	errs := []error{}
+	for _, img := range images {
+		if img.TargetRepo != "" && !v2alpha1.IsValidPathComponent(img.TargetRepo) {
+			errs = append(errs, fmt.Errorf("additional image %q: targetRepo %q is not a valid lowercase repository path without registry, tag nor digest", img.Name, img.TargetRepo))
+		}
+		if img.TargetTag != "" {
+			if !tagPattern.MatchString(img.TargetTag) {
+				errs = append(errs, fmt.Errorf("additional image %q: targetTag %q is not a valid tag", img.Name, img.TargetTag))
+			}
+			if img.Tags != nil {
+				errs = append(errs, fmt.Errorf("additional image %q: targetTag can't be used along with a tags filter", img.Name))
+			} else if imgSpec, err := image.ParseRef(img.Name); err == nil && !imgSpec.IsImageByDigestOnly() {
+				// the ImageTagMirrorSet of an image mirrored by tag can only redirect its repository, not its tag
+				errs = append(errs, fmt.Errorf("additional image %q: targetTag can only be used with an image referenced by digest", img.Name))
+			}
+		}
+	}
+	return errs
+}
+
+func validateRewriteRules(rules []v2alpha1.RewriteRule) []error {
+	errs := []error{}
+	for _, rule := range rules {
+		source, sourceIsNamespace := strings.CutSuffix(rule.Source, "/*")
+		target, targetIsNamespace := strings.CutSuffix(rule.Target, "/*")
+		if sourceIsNamespace != targetIsNamespace {
+			errs = append(errs, fmt.Errorf("rewrite rule %q -> %q: source and target must both end with /* or none of them", rule.Source, rule.Target))
+			continue
+		}
+		if _, err := reference.ParseNormalizedNamed(source); err != nil || strings.Contains(source, "*") {
+			errs = append(errs, fmt.Errorf("rewrite rule %q -> %q: source is not a valid repository", rule.Source, rule.Target))
+		}
+		if !v2alpha1.IsValidPathComponent(target) {
+			errs = append(errs, fmt.Errorf("rewrite rule %q -> %q: target is not a valid path under the destination registry", rule.Source, rule.Target))
+		}
+	}
+	return errs
+}
+
+var tagPattern = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+
+func validateImagesTagsFilter(images []v2alpha1.Image) []error {
+	errs := []error{}
+	for _, img := range images {
+		if img.Tags == nil {
+			continue
+		}
+		named, err := reference.ParseNormalizedNamed(img.Name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("additional image %q: %v", img.Name, err))
+		} else if !reference.IsNameOnly(named) {
+			errs = append(errs, fmt.Errorf("additional image %q: tags filter can only be used on a repository without tag nor digest", img.Name))
+		}
+		if img.Tags.Regex != "" {
+			if _, err := regexp.Compile(img.Tags.Regex); err != nil {
+				errs = append(errs, fmt.Errorf("additional image %q: invalid tags regex %q: %v", img.Name, img.Tags.Regex, err))
+			}
+		}
+		for _, ex := range img.Tags.Exclude {
+			if _, err := regexp.Compile(ex); err != nil {
+				errs = append(errs, fmt.Errorf("additional image %q: invalid tags exclude regex %q: %v", img.Name, ex, err))
+			}
+		}
+		if img.Tags.Latest < 0 {
+			errs = append(errs, fmt.Errorf("additional image %q: tags latest must be a positive number", img.Name))
+		}
+		if img.Tags.Since != "" {
+			if _, err := time.Parse(time.DateOnly, img.Tags.Since); err != nil {
+				errs = append(errs, fmt.Errorf("additional image %q: tags since %q needs to be in format yyyy-MM-dd", img.Name, img.Tags.Since))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validateSamples(cfg *v2alpha1.ImageSetConfiguration) []error {
+	errs := validateSampleImages(cfg.Mirror.Samples, cfg.Mirror.Platform)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateSampleImages checks that the samples are given as imagestream or imagestream:tag.
+// They are read from the release manifests, so a release is needed.
+func validateSampleImages(samples []v2alpha1.SampleImages, platform v2alpha1.Platform) []error {
+	errs := []error{}
+	if len(samples) > 0 && len(platform.Channels) == 0 && platform.Release == "" {
+		errs = append(errs, fmt.Errorf("samples: the samples imagestreams are read from the release, at least one platform channel or release is required"))
+	}
+	seen := map[string]bool{}
+	for _, sample := range samples {
+		name, tag, hasTag := strings.Cut(sample.Name, ":")
+		if msgs := validation.IsDNS1123Subdomain(name); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("sample %q: imagestream name is not valid: %s", sample.Name, strings.Join(msgs, ", ")))
+		}
+		if hasTag && !tagPattern.MatchString(tag) {
+			errs = append(errs, fmt.Errorf("sample %q: tag %q is not a valid tag", sample.Name, tag))
+		}
+		if seen[sample.Name] {
+			errs = append(errs, fmt.Errorf("sample %q: duplicate found in configuration", sample.Name))
+		}
+		seen[sample.Name] = true
+	}
+	return errs
+}
+
 // ValidateDelete will check an DeleteImagesetConfiguration for input errors.
 func ValidateDelete(cfg *v2alpha1.DeleteImageSetConfiguration) error {
 	var errs []error
@@ -122,6 +342,9 @@ func ValidateDelete(cfg *v2alpha1.DeleteImageSetConfiguration) error {
 func validateOperatorOptionsDelete(cfg *v2alpha1.DeleteImageSetConfiguration) error {
 	seen := map[string]bool{}
 	for _, ctlg := range cfg.Delete.Operators {
+		if err := validateCatalogTemplate(ctlg, cfg.Delete.Platform); err != nil {
+			return err
+		}
 		ctlgName, err := ctlg.GetUniqueName()
 		if err != nil {
 			return err
@@ -148,3 +371,21 @@ func validateReleaseChannelsDelete(cfg *v2alpha1.DeleteImageSetConfiguration) er
 	}
 	return nil
 }
+
+func validateAdditionalImagesDelete(cfg *v2alpha1.DeleteImageSetConfiguration) error {
+	errs := validateImagesTagsFilter(cfg.Delete.AdditionalImages)
+	errs = append(errs, validateImagesTarget(cfg.Delete.AdditionalImages)...)
+	errs = append(errs, validateRewriteRules(cfg.Delete.RewriteRules)...)
+	if len(errs) > 0 {
+		return utilerrors.NewAggregate(errs)
+	}
+	return nil
+}
+
+func validateSamplesDelete(cfg *v2alpha1.DeleteImageSetConfiguration) error {
+	errs := validateSampleImages(cfg.Delete.Samples, cfg.Delete.Platform)
+	if len(errs) > 0 {
+		return utilerrors.NewAggregate(errs)
+	}
+	return nil
+}
@@ -255,6 +255,159 @@ func TestValidate(t *testing.T) {
 			},
 			expError: "invalid configuration: catalog \"test-catalog1:latest\": operator \"operator1\": mixing both filtering by minVersion/maxVersion and filtering by channel minVersion/maxVersion is not allowed",
 		},
+		{
+			name: "Invalid/LocalCatalog",
+			config: &v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						Operators: []v2alpha1.Operator{
+							{
+								Catalog:   "fbc:///home/user/catalog/configs",
+								BaseImage: "registry.redhat.io/openshift4/OSE-operator-registry-rhel9:v4.16",
+							},
+							{
+								Catalog:   "test-catalog1:latest",
+								BaseImage: "registry.redhat.io/openshift4/ose-operator-registry-rhel9:v4.16",
+							},
+						},
+					},
+				},
+			},
+			expError: "invalid configuration: [catalog \"fbc:///home/user/catalog/configs\": targetCatalog is required for a file-based catalog directory, catalog \"fbc:///home/user/catalog/configs\": baseImage \"registry.redhat.io/openshift4/OSE-operator-registry-rhel9:v4.16\" is not a valid image reference: invalid reference format: repository name must be lowercase, catalog \"test-catalog1:latest\": baseImage can only be set for a file-based catalog directory (fbc://)]",
+		},
+		{
+			name: "Invalid/DeprecationPolicy",
+			config: &v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						Operators: []v2alpha1.Operator{
+							{
+								Catalog:    "test-catalog1:latest",
+								Deprecated: v2alpha1.DeprecatedExclude,
+							},
+							{
+								Catalog:    "test-catalog2:latest",
+								Deprecated: "skip",
+							},
+						},
+					},
+				},
+			},
+			expError: "invalid configuration: catalog \"test-catalog2:latest\": deprecated \"skip\" is not valid, it should be one of include, exclude or warn",
+		},
+		{
+			name: "Invalid/CatalogTemplate",
+			config: &v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						Operators: []v2alpha1.Operator{
+							{
+								Catalog: "registry.redhat.io/redhat/redhat-operator-index:v{{ .OCPVersion }}",
+							},
+							{
+								Catalog:     "registry.redhat.io/redhat/certified-operator-index:v{{ .OCPVersion }}",
+								OCPVersions: []string{"4.16.1"},
+							},
+							{
+								Catalog:     "registry.redhat.io/redhat/community-operator-index:v4.16",
+								OCPVersions: []string{"4.15", "4.16"},
+							},
+						},
+					},
+				},
+			},
+			expError: "invalid configuration: [catalog \"registry.redhat.io/redhat/redhat-operator-index:v{{ .OCPVersion }}\": ocpVersions or a platform channel is required to expand the catalog template, catalog \"registry.redhat.io/redhat/certified-operator-index:v{{ .OCPVersion }}\": OCP version \"4.16.1\" is not valid, it should be <major>.<minor>, catalog \"registry.redhat.io/redhat/community-operator-index:v4.16\": ocpVersions requires a catalog template using {{ .OCPVersion }}]",
+		},
+		{
+			name: "Invalid/CatalogFilteringByBundlesAndVersions",
+			config: &v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						Operators: []v2alpha1.Operator{
+							{
+								Catalog: "test-catalog1:latest",
+								IncludeConfig: v2alpha1.IncludeConfig{
+									Packages: []v2alpha1.IncludePackage{
+										{
+											Name: "operator1",
+											IncludeBundle: v2alpha1.IncludeBundle{
+												MinVersion: "1.2.3",
+											},
+											SelectedBundles: []v2alpha1.SelectedBundle{
+												{Name: "operator1.v1.2.3"},
+												{Name: "operator1.v1.2.3"},
+											},
+										},
+										{
+											Name:            "operator2",
+											SkipUpgradePath: true,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expError: "invalid configuration: [catalog \"test-catalog1:latest\": operator \"operator1\": mixing both filtering by bundles and filtering by channels or minVersion/maxVersion is not allowed, catalog \"test-catalog1:latest\": operator \"operator1\": bundle \"operator1.v1.2.3\": duplicate found in configuration, catalog \"test-catalog1:latest\": operator \"operator2\": skipUpgradePath can only be set along with bundles]",
+		},
+		{
+			name: "Invalid/KeepLast",
+			config: &v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						Operators: []v2alpha1.Operator{
+							{
+								Catalog:  "test-catalog1:latest",
+								KeepLast: -1,
+								IncludeConfig: v2alpha1.IncludeConfig{
+									Packages: []v2alpha1.IncludePackage{
+										{
+											Name:          "operator1",
+											IncludeBundle: v2alpha1.IncludeBundle{KeepLast: 2},
+											SelectedBundles: []v2alpha1.SelectedBundle{
+												{Name: "operator1.v1.2.3"},
+											},
+										},
+										{
+											Name: "operator2",
+											Channels: []v2alpha1.IncludeChannel{
+												{Name: "stable", IncludeBundle: v2alpha1.IncludeBundle{KeepLast: -3}},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expError: "invalid configuration: [catalog \"test-catalog1:latest\": keepLast -1 must be positive, catalog \"test-catalog1:latest\": operator \"operator1\": mixing both filtering by bundles and keepLast is not allowed, catalog \"test-catalog1:latest\": operator \"operator2\": channel \"stable\": keepLast -3 must be positive]",
+		},
+		{
+			name: "Invalid/CatalogFilteringByBundlesInFull",
+			config: &v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						Operators: []v2alpha1.Operator{
+							{
+								Catalog: "test-catalog1:latest",
+								Full:    true,
+								IncludeConfig: v2alpha1.IncludeConfig{
+									Packages: []v2alpha1.IncludePackage{
+										{
+											Name:            "operator1",
+											SelectedBundles: []v2alpha1.SelectedBundle{{Name: ""}},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expError: "invalid configuration: [catalog \"test-catalog1:latest\": operator \"operator1\": mixing both full: true and filtering by bundles is not allowed, catalog \"test-catalog1:latest\": operator \"operator1\": bundles must have a name]",
+		},
 		{
 			name: "Invalid/DuplicateChannels",
 			config: &v2alpha1.ImageSetConfiguration{
@@ -275,6 +428,101 @@ func TestValidate(t *testing.T) {
 			},
 			expError: "invalid configuration: release channel \"channel\": duplicate found in configuration",
 		},
+		{
+			name: "Valid/AdditionalImagesTagsFilter",
+			config: &v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						AdditionalImages: []v2alpha1.Image{
+							{
+								Name: "quay.io/base/image",
+								Tags: &v2alpha1.ImageTagsFilter{Regex: `^v2\.\d+\.\d+$`, Exclude: []string{"-rc"}, Latest: 5, Since: "2024-01-31"},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Invalid/AdditionalImagesTagsFilter",
+			config: &v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						AdditionalImages: []v2alpha1.Image{
+							{
+								Name: "quay.io/base/image:v2",
+								Tags: &v2alpha1.ImageTagsFilter{Regex: "(", Since: "31-01-2024"},
+							},
+						},
+					},
+				},
+			},
+			expError: "invalid configuration: [additional image \"quay.io/base/image:v2\": tags filter can only be used on a repository without tag nor digest, additional image \"quay.io/base/image:v2\": invalid tags regex \"(\": error parsing regexp: missing closing ): `(`, additional image \"quay.io/base/image:v2\": tags since \"31-01-2024\" needs to be in format yyyy-MM-dd]",
+		},
+		{
+			name: "Valid/AdditionalImagesTargetsAndRewriteRules",
+			config: &v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						AdditionalImages: []v2alpha1.Image{
+							{Name: "quay.io/base/image@sha256:b3a8e9f4a1ca5e1fd6a2e8e18b4bbbcc4a4a3ef1a6a7b2cf0a6e3a0a9ad2d6f5", TargetRepo: "team/image", TargetTag: "stable"},
+						},
+						RewriteRules: []v2alpha1.RewriteRule{
+							{Source: "docker.io/library/*", Target: "mirror/dockerhub/*"},
+							{Source: "ghcr.io/stefanprodan/podinfo", Target: "apps/podinfo"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Invalid/AdditionalImagesTargetsAndRewriteRules",
+			config: &v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						AdditionalImages: []v2alpha1.Image{
+							{Name: "quay.io/base/image", TargetRepo: "Team/Image", TargetTag: ":bad", Tags: &v2alpha1.ImageTagsFilter{Latest: 1}},
+							{Name: "quay.io/base/other:v1", TargetTag: "stable"},
+						},
+						RewriteRules: []v2alpha1.RewriteRule{
+							{Source: "docker.io/library/*", Target: "mirror/dockerhub"},
+						},
+					},
+				},
+			},
+			expError: "invalid configuration: [additional image \"quay.io/base/image\": targetRepo \"Team/Image\" is not a valid lowercase repository path without registry, tag nor digest, additional image \"quay.io/base/image\": targetTag \":bad\" is not a valid tag, additional image \"quay.io/base/image\": targetTag can't be used along with a tags filter, additional image \"quay.io/base/other:v1\": targetTag can only be used with an image referenced by digest, rewrite rule \"docker.io/library/*\" -> \"mirror/dockerhub\": source and target must both end with /* or none of them]",
+		},
+		{
+			name: "Valid/Samples",
+			config: &v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						Platform: v2alpha1.Platform{
+							Channels: []v2alpha1.ReleaseChannel{{Name: "stable-4.16"}},
+						},
+						Samples: []v2alpha1.SampleImages{
+							{Image: v2alpha1.Image{Name: "ruby"}},
+							{Image: v2alpha1.Image{Name: "nodejs:18-ubi8"}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Invalid/Samples",
+			config: &v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						Samples: []v2alpha1.SampleImages{
+							{Image: v2alpha1.Image{Name: "Ruby"}},
+							{Image: v2alpha1.Image{Name: "nodejs:-18"}},
+							{Image: v2alpha1.Image{Name: "nodejs:-18"}},
+						},
+					},
+				},
+			},
+			expError: "invalid configuration: [samples: the samples imagestreams are read from the release, at least one platform channel or release is required, sample \"Ruby\": imagestream name is not valid: a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*'), sample \"nodejs:-18\": tag \"-18\" is not a valid tag, sample \"nodejs:-18\": duplicate found in configuration]",
+		},
 	}
 
 	for _, c := range cases {
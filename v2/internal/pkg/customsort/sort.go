@@ -10,15 +10,17 @@ func (a ByTypePriority) Len() int      { return len(a) }
 func (a ByTypePriority) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
 func (a ByTypePriority) Less(i, j int) bool {
 	priority := map[string]int{
-		v2alpha1.TypeOCPReleaseContent.String():    1,
-		v2alpha1.TypeKubeVirtContainer.String():    2,
-		v2alpha1.TypeOCPRelease.String():           3,
-		v2alpha1.TypeCincinnatiGraph.String():      4,
-		v2alpha1.TypeOperatorRelatedImage.String(): 5,
-		v2alpha1.TypeGeneric.String():              6,
-		v2alpha1.TypeHelmImage.String():            7,
-		v2alpha1.TypeOperatorBundle.String():       8,
-		v2alpha1.TypeOperatorCatalog.String():      9,
+		v2alpha1.TypeOCPReleaseContent.String():       1,
+		v2alpha1.TypeKubeVirtContainer.String():       2,
+		v2alpha1.TypeOCPRelease.String():              3,
+		v2alpha1.TypeCincinnatiGraph.String():         4,
+		v2alpha1.TypeSampleImage.String():             5,
+		v2alpha1.TypeOperatorRelatedImage.String():    6,
+		v2alpha1.TypeOperatorDiscoveredImage.String(): 6,
+		v2alpha1.TypeGeneric.String():                 7,
+		v2alpha1.TypeHelmImage.String():               8,
+		v2alpha1.TypeOperatorBundle.String():          9,
+		v2alpha1.TypeOperatorCatalog.String():         10,
 	}
 
 	defaultPriority := 0
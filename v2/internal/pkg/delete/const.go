@@ -4,6 +4,7 @@ const (
 	deleteDir                   string = "/delete"
 	deleteImagesYaml            string = "delete/delete-images.yaml"
 	discYaml                    string = "delete/delete-imageset-config.yaml"
+	deleteImpactReport          string = "delete/delete-impact-report.json"
 	dockerProtocol              string = "docker://"
 	operatorImageExtractDir     string = "hold-operator"
 	ociProtocol                 string = "oci://"
@@ -26,4 +27,5 @@ const (
 	arm64                       string = "aarch64"
 	multi                       string = "multi"
 	releaseRepo                 string = "docker://quay.io/openshift-release-dev/ocp-release"
+	impactReportNote            string = "estimate from the local cache, an upper bound of the storage reclaimed: the blobs are only compared with the manifests of the local cache and the images to keep, in the repositories the images are deleted from. A blob still referenced by a manifest of the destination which isn't in the local cache, or by another repository of the destination, is not reclaimed"
 )
@@ -6,8 +6,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
-	"slices"
-	"sort"
+	"reflect"
 	"strings"
 
 	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
@@ -33,8 +32,9 @@ type DeleteImages struct {
 	LocalStorageFQDN string
 }
 
-// WriteDeleteMetaData
-func (o DeleteImages) WriteDeleteMetaData(images []v2alpha1.CopyImageSchema) error {
+// WriteDeleteMetaData - writes the delete list of images, leaving out the
+// images referenced by keep (the keep set is recorded for phase 2)
+func (o DeleteImages) WriteDeleteMetaData(images []v2alpha1.CopyImageSchema, keep []v2alpha1.CopyImageSchema) error {
 	o.Log.Info(emoji.PageFacingUp + " Generating delete file...")
 	o.Log.Info("%s file created", o.Opts.Global.WorkingDir+deleteDir)
 
@@ -52,31 +52,23 @@ func (o DeleteImages) WriteDeleteMetaData(images []v2alpha1.CopyImageSchema) err
 		o.Log.Error("%v ", err)
 	}
 
-	duplicates := []string{}
-	var items []v2alpha1.DeleteItem
-	for _, img := range images {
-		if slices.Contains(duplicates, img.Origin) {
-			o.Log.Debug("duplicate image found %s", img.Origin)
-		} else {
-			duplicates = append(duplicates, img.Origin)
-			item := v2alpha1.DeleteItem{
-				ImageName:      img.Origin,
-				ImageReference: img.Destination,
-				Type:           img.Type,
-			}
-			items = append(items, item)
-		}
+	images, conflicts, err := ExcludeKept(images, keep)
+	if err != nil {
+		return fmt.Errorf(deleteImagesErrMsg, err)
+	}
+	for _, img := range conflicts {
+		o.Log.Warn(emoji.Warning+"  %s is not added to the delete file: it is referenced by the images to keep", img.Origin)
 	}
 
-	// sort the items
-	sort.SliceStable(items, func(i, j int) bool {
-		return items[i].ImageReference < items[j].ImageReference
-	})
 	// marshal to yaml and write to file
 	deleteImageList := v2alpha1.DeleteImageList{
 		Kind:       "DeleteImageList",
 		APIVersion: "mirror.openshift.io/v2alpha1",
-		Items:      items,
+		Items:      deleteItems(images),
+	}
+	if len(keep) > 0 {
+		deleteImageList.Keep = deleteItems(keep)
+		deleteImageList.Conflicts = deleteItems(conflicts)
 	}
 	ymlData, err := yaml.Marshal(deleteImageList)
 	if err != nil {
@@ -86,6 +78,16 @@ func (o DeleteImages) WriteDeleteMetaData(images []v2alpha1.CopyImageSchema) err
 	if err != nil {
 		o.Log.Error(deleteImagesErrMsg, err)
 	}
+	// estimate the storage reclaimed by the delete, for review before phase 2
+	err = o.writeImpactReport(context.Background(), deleteImageList.Items, deleteImageList.Keep)
+	if err != nil {
+		o.Log.Warn(deleteImagesErrMsg, fmt.Sprintf("unable to generate the delete impact report: %v", err))
+	}
+	// a delete list generated from the difference between two image set
+	// configurations has no deleteimagesetconfig
+	if reflect.DeepEqual(o.Config.Mirror, v2alpha1.Mirror{}) {
+		return nil
+	}
 	// finally copy the deleteimagesetconfig for reference
 	disc := v2alpha1.DeleteImageSetConfiguration{
 		TypeMeta: metav1.TypeMeta{
@@ -98,6 +100,8 @@ func (o DeleteImages) WriteDeleteMetaData(images []v2alpha1.CopyImageSchema) err
 				Operators:        o.Config.Mirror.Operators,
 				AdditionalImages: o.Config.Mirror.AdditionalImages,
 				Helm:             o.Config.Mirror.Helm,
+				RewriteRules:     o.Config.Mirror.RewriteRules,
+				Samples:          o.Config.Mirror.Samples,
 			},
 		},
 	}
@@ -119,6 +123,12 @@ func (o DeleteImages) DeleteRegistryImages(deleteImageList v2alpha1.DeleteImageL
 
 	var batchError error
 
+	items, err := o.recheckKeep(context.Background(), deleteImageList)
+	if err != nil {
+		return err
+	}
+	deleteImageList.Items = items
+
 	increment := 1
 	if o.Opts.Global.ForceCacheDelete {
 		increment = 2
@@ -175,7 +185,7 @@ func (o DeleteImages) DeleteRegistryImages(deleteImageList v2alpha1.DeleteImageL
 		}
 
 		switch {
-		case img.Type.IsRelease():
+		case img.Type.IsRelease() || img.Type.IsSampleImage():
 			collectorSchema.TotalReleaseImages += increment
 		case img.Type.IsOperator():
 			collectorSchema.TotalOperatorImages += increment
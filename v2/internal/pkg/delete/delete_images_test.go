@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/containers/image/v5/types"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
@@ -146,7 +147,7 @@ func TestWriteMetaData(t *testing.T) {
 				Origin:      "test",
 			},
 		}
-		err := di.WriteDeleteMetaData(cpImages)
+		err := di.WriteDeleteMetaData(cpImages, nil)
 		if err != nil {
 			t.Fatalf("should not fail %v", err)
 		}
@@ -186,6 +187,11 @@ func (o *mockBlobs) GatherBlobs(ctx context.Context, image string) (map[string]s
 	return res, nil
 }
 
+func (o *mockBlobs) GatherImage(ctx context.Context, image string) (string, map[string]string, error) {
+	res, err := o.GatherBlobs(ctx, image)
+	return "sha256:95ad8395795ee0460baf05458f669d3b865535f213f015519ef9a221a6a08280", res, err
+}
+
 func (o mockManifest) GetImageIndex(dir string) (*v2alpha1.OCISchema, error) {
 	return &v2alpha1.OCISchema{}, nil
 }
@@ -213,3 +219,11 @@ func (o mockManifest) ConvertIndexToSingleManifest(dir string, oci *v2alpha1.OCI
 func (o mockManifest) GetDigest(ctx context.Context, sourceCtx *types.SystemContext, imgRef string) (string, error) {
 	return "", nil
 }
+
+func (o mockManifest) ListTags(ctx context.Context, sourceCtx *types.SystemContext, imgRef string) ([]string, error) {
+	return []string{}, nil
+}
+
+func (o mockManifest) GetImageCreationDate(ctx context.Context, sourceCtx *types.SystemContext, imgRef string) (time.Time, error) {
+	return time.Time{}, nil
+}
@@ -5,7 +5,7 @@ import (
 )
 
 type DeleteInterface interface {
-	WriteDeleteMetaData([]v2alpha1.CopyImageSchema) error
+	WriteDeleteMetaData(images []v2alpha1.CopyImageSchema, keep []v2alpha1.CopyImageSchema) error
 	ReadDeleteMetaData() (v2alpha1.DeleteImageList, error)
 	DeleteRegistryImages(images v2alpha1.DeleteImageList) error
 }
@@ -0,0 +1,135 @@
+package delete
+
+import (
+	"context"
+	"sort"
+
+	"github.com/containers/image/v5/types"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/emoji"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/image"
+)
+
+// ExcludeKept splits the images to delete between the ones that can be deleted,
+// and the conflicts: the ones still referenced by the keep set
+// (the images of other imageset configurations or workspaces mirrored to the same registry)
+func ExcludeKept(images, keep []v2alpha1.CopyImageSchema) ([]v2alpha1.CopyImageSchema, []v2alpha1.CopyImageSchema, error) {
+	if len(keep) == 0 {
+		return images, nil, nil
+	}
+	return excludeReferenced(images, keep)
+}
+
+// recheckKeep checks the images to delete against the keep set of the delete list,
+// right before deleting: besides the references, an image sharing its manifest
+// with an image of the keep set (same repository, another tag) is not deleted
+func (o DeleteImages) recheckKeep(ctx context.Context, deleteImageList v2alpha1.DeleteImageList) ([]v2alpha1.DeleteItem, error) {
+	if len(deleteImageList.Keep) == 0 {
+		return deleteImageList.Items, nil
+	}
+	o.Log.Info(emoji.Eyes+" Checking the images to delete against the %d images to keep...", len(deleteImageList.Keep))
+	keep := copyImages(deleteImageList.Keep)
+	remaining, conflicts, err := ExcludeKept(copyImages(deleteImageList.Items), keep)
+	if err != nil {
+		return nil, err
+	}
+
+	destCtx, err := o.Opts.DestImage.NewSystemContext()
+	if err != nil {
+		return nil, err
+	}
+	keepByRepository := map[string][]string{}
+	for _, img := range keep {
+		spec, err := image.ParseRef(img.Destination)
+		if err != nil {
+			return nil, err
+		}
+		keepByRepository[spec.Name] = append(keepByRepository[spec.Name], img.Destination)
+	}
+	deletable := []v2alpha1.CopyImageSchema{}
+	for _, img := range remaining {
+		spec, err := image.ParseRef(img.Destination)
+		if err != nil {
+			return nil, err
+		}
+		if o.sharesManifest(ctx, destCtx, img.Destination, keepByRepository[spec.Name]) {
+			conflicts = append(conflicts, img)
+			continue
+		}
+		deletable = append(deletable, img)
+	}
+
+	for _, img := range conflicts {
+		o.Log.Warn(emoji.Warning+"  %s is not deleted: it is referenced by the images to keep", img.Destination)
+	}
+	return deleteItems(deletable), nil
+}
+
+// sharesManifest returns true when the manifest of imgRef is the manifest of one of the references
+func (o DeleteImages) sharesManifest(ctx context.Context, destCtx *types.SystemContext, imgRef string, references []string) bool {
+	if len(references) == 0 {
+		return false
+	}
+	imgDigest := o.manifestDigest(ctx, destCtx, imgRef)
+	if imgDigest == "" {
+		return false
+	}
+	for _, ref := range references {
+		if o.manifestDigest(ctx, destCtx, ref) == imgDigest {
+			return true
+		}
+	}
+	return false
+}
+
+// manifestDigest returns the digest of the manifest of imgRef in the registry,
+// or an empty string when it cannot be found
+func (o DeleteImages) manifestDigest(ctx context.Context, destCtx *types.SystemContext, imgRef string) string {
+	spec, err := image.ParseRef(imgRef)
+	if err != nil {
+		return ""
+	}
+	if spec.IsImageByDigest() {
+		return spec.Digest
+	}
+	imgDigest, err := o.Manifest.GetDigest(ctx, destCtx, imgRef)
+	if err != nil {
+		o.Log.Debug("unable to get the digest of %s: %v", imgRef, err)
+		return ""
+	}
+	return imgDigest
+}
+
+// deleteItems converts the images to items of the delete list, removing the duplicates
+func deleteItems(images []v2alpha1.CopyImageSchema) []v2alpha1.DeleteItem {
+	seen := map[string]bool{}
+	items := []v2alpha1.DeleteItem{}
+	for _, img := range images {
+		if seen[img.Origin] {
+			continue
+		}
+		seen[img.Origin] = true
+		items = append(items, v2alpha1.DeleteItem{
+			ImageName:      img.Origin,
+			ImageReference: img.Destination,
+			Type:           img.Type,
+		})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].ImageReference < items[j].ImageReference
+	})
+	return items
+}
+
+func copyImages(items []v2alpha1.DeleteItem) []v2alpha1.CopyImageSchema {
+	images := make([]v2alpha1.CopyImageSchema, 0, len(items))
+	for _, item := range items {
+		images = append(images, v2alpha1.CopyImageSchema{
+			Origin:      item.ImageName,
+			Destination: item.ImageReference,
+			Type:        item.Type,
+		})
+	}
+	return images
+}
@@ -0,0 +1,114 @@
+package delete
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containers/image/v5/types"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+)
+
+// digestManifest resolves the digests of the registry
+type digestManifest struct {
+	mockManifest
+	digests map[string]string
+}
+
+func (o digestManifest) GetDigest(ctx context.Context, sourceCtx *types.SystemContext, imgRef string) (string, error) {
+	d, ok := o.digests[imgRef]
+	if !ok {
+		return "", fmt.Errorf("manifest unknown")
+	}
+	return d, nil
+}
+
+func TestKeep(t *testing.T) {
+	global := &mirror.GlobalOptions{
+		WorkingDir:        t.TempDir(),
+		DeleteDestination: "docker://localhost:5000/myregistry",
+	}
+	_, sharedOpts := mirror.SharedImageFlags()
+	_, deprecatedTLSVerifyOpt := mirror.DeprecatedTLSVerifyFlags()
+	_, srcOpts := mirror.ImageSrcFlags(global, sharedOpts, deprecatedTLSVerifyOpt, "src-", "screds")
+	_, destOpts := mirror.ImageDestFlags(global, sharedOpts, deprecatedTLSVerifyOpt, "dest-", "dcreds")
+	opts := mirror.CopyOptions{
+		Global:    global,
+		SrcImage:  srcOpts,
+		DestImage: destOpts,
+		Mode:      mirror.DiskToMirror,
+	}
+
+	images := []v2alpha1.CopyImageSchema{
+		{
+			Origin:      "docker://registry.redhat.io/ubi8/ubi:latest",
+			Destination: "docker://localhost:5000/myregistry/ubi8/ubi:latest",
+			Type:        v2alpha1.TypeGeneric,
+		},
+		{
+			Origin:      "docker://registry.redhat.io/ubi8/ubi:8.9",
+			Destination: "docker://localhost:5000/myregistry/ubi8/ubi:8.9",
+			Type:        v2alpha1.TypeGeneric,
+		},
+		{
+			Origin:      "docker://registry.redhat.io/rhel9/postgresql-15:latest",
+			Destination: "docker://localhost:5000/myregistry/rhel9/postgresql-15:latest",
+			Type:        v2alpha1.TypeGeneric,
+		},
+	}
+	keep := []v2alpha1.CopyImageSchema{
+		{
+			// the same image, related to an operator of another team
+			Origin:      "docker://registry.redhat.io/rhel9/postgresql-15:latest",
+			Destination: "docker://localhost:5000/myregistry/rhel9/postgresql-15:latest",
+			Type:        v2alpha1.TypeOperatorRelatedImage,
+		},
+		{
+			Origin:      "docker://registry.redhat.io/ubi8/ubi:8.10",
+			Destination: "docker://localhost:5000/myregistry/ubi8/ubi:8.10",
+			Type:        v2alpha1.TypeGeneric,
+		},
+	}
+
+	t.Run("Testing WriteDeleteMetaData : should leave out the images to keep", func(t *testing.T) {
+		di := New(clog.New("error"), opts, &mockBatch{}, &mockBlobs{}, v2alpha1.ImageSetConfiguration{}, &mockManifest{}, "/tmp")
+		err := di.WriteDeleteMetaData(images, keep)
+		assert.NoError(t, err)
+
+		data, err := os.ReadFile(filepath.Join(global.WorkingDir, deleteImagesYaml))
+		assert.NoError(t, err)
+		var list v2alpha1.DeleteImageList
+		assert.NoError(t, yaml.Unmarshal(data, &list))
+		assert.Len(t, list.Items, 2)
+		assert.Equal(t, "docker://localhost:5000/myregistry/ubi8/ubi:8.9", list.Items[0].ImageReference)
+		assert.Equal(t, "docker://localhost:5000/myregistry/ubi8/ubi:latest", list.Items[1].ImageReference)
+		assert.Len(t, list.Keep, 2)
+		assert.Equal(t, []v2alpha1.DeleteItem{{ImageName: images[2].Origin, ImageReference: images[2].Destination, Type: v2alpha1.TypeGeneric}}, list.Conflicts)
+	})
+
+	t.Run("Testing DeleteRegistryImages : should not delete the images sharing a manifest with the images to keep", func(t *testing.T) {
+		// ubi8/ubi:latest was retagged 8.10 since phase 1
+		manifest := digestManifest{digests: map[string]string{
+			"docker://localhost:5000/myregistry/ubi8/ubi:latest": "1dddb0988d16a1b7d3c4e5f60718293a4b5c6d7e8f90112233445566778899aa",
+			"docker://localhost:5000/myregistry/ubi8/ubi:8.10":   "1dddb0988d16a1b7d3c4e5f60718293a4b5c6d7e8f90112233445566778899aa",
+			"docker://localhost:5000/myregistry/ubi8/ubi:8.9":    "98f3fd7b8e8f2e1b24c4cf7d9a8d9f5d0e4b8e8c9b71a3e7cb3c6c0d2f1a4e5b",
+		}}
+		di := DeleteImages{Log: clog.New("error"), Opts: opts, Batch: &mockBatch{}, Manifest: manifest}
+		list := v2alpha1.DeleteImageList{
+			Items: deleteItems(images),
+			Keep:  deleteItems(keep),
+		}
+		items, err := di.recheckKeep(context.Background(), list)
+		assert.NoError(t, err)
+		assert.Equal(t, []v2alpha1.DeleteItem{{ImageName: images[1].Origin, ImageReference: images[1].Destination, Type: v2alpha1.TypeGeneric}}, items)
+
+		assert.NoError(t, di.DeleteRegistryImages(list))
+	})
+}
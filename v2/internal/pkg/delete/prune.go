@@ -0,0 +1,101 @@
+package delete
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/history"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/image"
+)
+
+// PruneImages returns the images of previous that are no longer part of current.
+// An image still referenced by current is kept, whatever its content type
+// (i.e an additional image that is also the related image of an operator):
+// it is matched by origin, by destination, and by repository and digest
+// for the images mirrored by digest.
+func PruneImages(previous, current []v2alpha1.CopyImageSchema) ([]v2alpha1.CopyImageSchema, error) {
+	pruned, _, err := excludeReferenced(previous, current)
+	return pruned, err
+}
+
+// excludeReferenced splits images between the ones not referenced by referencing,
+// and the ones that are
+func excludeReferenced(images, referencing []v2alpha1.CopyImageSchema) ([]v2alpha1.CopyImageSchema, []v2alpha1.CopyImageSchema, error) {
+	referenced := map[string]struct{}{}
+	for _, img := range referencing {
+		keys, err := referenceKeys(img)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, key := range keys {
+			referenced[key] = struct{}{}
+		}
+	}
+
+	var notReferenced, stillReferenced []v2alpha1.CopyImageSchema
+	for _, img := range images {
+		keys, err := referenceKeys(img)
+		if err != nil {
+			return nil, nil, err
+		}
+		isReferenced := false
+		for _, key := range keys {
+			if _, ok := referenced[key]; ok {
+				isReferenced = true
+				break
+			}
+		}
+		if isReferenced {
+			stillReferenced = append(stillReferenced, img)
+		} else {
+			notReferenced = append(notReferenced, img)
+		}
+	}
+	return notReferenced, stillReferenced, nil
+}
+
+// RunImages returns the images recorded by a run of the history, as they were
+// mirrored to destination: the destination of the run (the local cache) is replaced by destination.
+// For the runs recorded without their destination, only the registry is replaced.
+func RunImages(run history.Run, destination string) ([]v2alpha1.CopyImageSchema, error) {
+	runDestination := strings.TrimSuffix(strings.TrimPrefix(run.Destination, dockerProtocol), "/")
+	images := make([]v2alpha1.CopyImageSchema, 0, len(run.Images))
+	for _, img := range run.Images {
+		var imgType v2alpha1.ImageType
+		if err := imgType.UnmarshalJSON([]byte(strconv.Quote(img.Type))); err != nil || imgType == v2alpha1.TypeInvalid {
+			return nil, fmt.Errorf("image %s of run %d: invalid type %s", img.Origin, run.ID, img.Type)
+		}
+		ref := strings.TrimPrefix(img.Destination, dockerProtocol)
+		path, found := strings.CutPrefix(ref, runDestination+"/")
+		if runDestination == "" {
+			// the first component of the cache reference is the local cache registry
+			_, path, found = strings.Cut(ref, "/")
+		}
+		if !found {
+			return nil, fmt.Errorf("image %s of run %d: invalid reference %s", img.Origin, run.ID, img.Destination)
+		}
+		images = append(images, v2alpha1.CopyImageSchema{
+			Origin:      img.Origin,
+			Destination: strings.TrimSuffix(destination, "/") + "/" + path,
+			Type:        imgType,
+		})
+	}
+	return images, nil
+}
+
+func referenceKeys(img v2alpha1.CopyImageSchema) ([]string, error) {
+	keys := []string{img.Destination}
+	if img.Origin != "" {
+		keys = append(keys, img.Origin)
+	}
+	spec, err := image.ParseRef(img.Destination)
+	if err != nil {
+		return nil, err
+	}
+	if spec.IsImageByDigest() {
+		keys = append(keys, spec.Name+"@"+spec.Algorithm+":"+spec.Digest)
+	}
+	return keys, nil
+}
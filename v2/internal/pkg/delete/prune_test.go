@@ -0,0 +1,124 @@
+package delete
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/history"
+)
+
+func TestPruneImages(t *testing.T) {
+	previous := []v2alpha1.CopyImageSchema{
+		{
+			Origin:      "docker://quay.io/openshift-release-dev/ocp-release:4.15.1-x86_64",
+			Destination: "docker://localhost:5000/myregistry/openshift/release-images:4.15.1-x86_64",
+			Type:        v2alpha1.TypeOCPRelease,
+		},
+		{
+			Origin:      "docker://quay.io/openshift-release-dev/ocp-release:4.15.2-x86_64",
+			Destination: "docker://localhost:5000/myregistry/openshift/release-images:4.15.2-x86_64",
+			Type:        v2alpha1.TypeOCPRelease,
+		},
+		{
+			// dropped operator, but the image is an additional image of the current configuration
+			Origin:      "docker://registry.redhat.io/ubi8/ubi@sha256:1dddb0988d16a1b7d3c4e5f60718293a4b5c6d7e8f90112233445566778899aa",
+			Destination: "docker://localhost:5000/myregistry/ubi8/ubi@sha256:1dddb0988d16a1b7d3c4e5f60718293a4b5c6d7e8f90112233445566778899aa",
+			Type:        v2alpha1.TypeOperatorRelatedImage,
+		},
+		{
+			Origin:      "docker://registry.redhat.io/rhel9/postgresql-15@sha256:98f3fd7b8e8f2e1b24c4cf7d9a8d9f5d0e4b8e8c9b71a3e7cb3c6c0d2f1a4e5b",
+			Destination: "docker://localhost:5000/myregistry/rhel9/postgresql-15@sha256:98f3fd7b8e8f2e1b24c4cf7d9a8d9f5d0e4b8e8c9b71a3e7cb3c6c0d2f1a4e5b",
+			Type:        v2alpha1.TypeOperatorRelatedImage,
+		},
+	}
+	current := []v2alpha1.CopyImageSchema{
+		{
+			Origin:      "docker://quay.io/openshift-release-dev/ocp-release:4.15.2-x86_64",
+			Destination: "docker://localhost:5000/myregistry/openshift/release-images:4.15.2-x86_64",
+			Type:        v2alpha1.TypeOCPRelease,
+		},
+		{
+			Origin:      "docker://registry.redhat.io/ubi8/ubi:8.10",
+			Destination: "docker://localhost:5000/myregistry/ubi8/ubi:8.10@sha256:1dddb0988d16a1b7d3c4e5f60718293a4b5c6d7e8f90112233445566778899aa",
+			Type:        v2alpha1.TypeGeneric,
+		},
+	}
+
+	pruned, err := PruneImages(previous, current)
+	assert.NoError(t, err)
+	assert.Equal(t, []v2alpha1.CopyImageSchema{previous[0], previous[3]}, pruned)
+}
+
+func TestRunImages(t *testing.T) {
+	run := history.Run{
+		ID:   2,
+		Date: time.Date(2024, 9, 1, 10, 0, 0, 0, time.UTC),
+		Images: []history.RunImage{
+			{
+				Origin:      "docker://registry.redhat.io/ubi8/ubi:latest",
+				Destination: "docker://localhost:55000/ubi8/ubi:latest",
+				Type:        "generic",
+			},
+			{
+				Origin:      "docker://quay.io/openshift-release-dev/ocp-release:4.15.1-x86_64",
+				Destination: "docker://localhost:55000/openshift/release-images:4.15.1-x86_64",
+				Type:        "ocpRelease",
+			},
+		},
+	}
+
+	images, err := RunImages(run, "docker://localhost:5000/myregistry/")
+	assert.NoError(t, err)
+	assert.Equal(t, []v2alpha1.CopyImageSchema{
+		{
+			Origin:      "docker://registry.redhat.io/ubi8/ubi:latest",
+			Destination: "docker://localhost:5000/myregistry/ubi8/ubi:latest",
+			Type:        v2alpha1.TypeGeneric,
+		},
+		{
+			Origin:      "docker://quay.io/openshift-release-dev/ocp-release:4.15.1-x86_64",
+			Destination: "docker://localhost:5000/myregistry/openshift/release-images:4.15.1-x86_64",
+			Type:        v2alpha1.TypeOCPRelease,
+		},
+	}, images)
+
+	run.Images[0].Type = "unknown"
+	_, err = RunImages(run, "docker://localhost:5000/myregistry")
+	assert.Error(t, err)
+}
+
+func TestRunImagesNamespacedDestination(t *testing.T) {
+	run := history.Run{
+		ID:          3,
+		Destination: "docker://localhost:55000/team1",
+		Images: []history.RunImage{
+			{
+				Origin:      "docker://registry.redhat.io/ubi8/ubi:latest",
+				Destination: "docker://localhost:55000/team1/ubi8/ubi:latest",
+				Type:        "generic",
+			},
+		},
+	}
+
+	t.Run("Testing RunImages : should replace the destination of the run, namespace included", func(t *testing.T) {
+		images, err := RunImages(run, "docker://mirror.acme.com/team1")
+		assert.NoError(t, err)
+		assert.Equal(t, []v2alpha1.CopyImageSchema{
+			{
+				Origin:      "docker://registry.redhat.io/ubi8/ubi:latest",
+				Destination: "docker://mirror.acme.com/team1/ubi8/ubi:latest",
+				Type:        v2alpha1.TypeGeneric,
+			},
+		}, images)
+	})
+
+	t.Run("Testing RunImages : should fail on an image outside of the destination of the run", func(t *testing.T) {
+		outside := run
+		outside.Images = []history.RunImage{{Origin: "docker://registry.redhat.io/ubi8/ubi:latest", Destination: "docker://localhost:55000/team2/ubi8/ubi:latest", Type: "generic"}}
+		_, err := RunImages(outside, "docker://mirror.acme.com/team1")
+		assert.Error(t, err)
+	})
+}
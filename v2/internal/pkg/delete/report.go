@@ -0,0 +1,197 @@
+package delete
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/cache"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/emoji"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/image"
+)
+
+// ImpactReport estimates the storage reclaimed by deleting the images of a delete list:
+// the blobs of the deleted manifests which are no longer referenced by the manifests
+// remaining in the local cache, or by the images to keep, in the repositories the images are deleted from
+type ImpactReport struct {
+	// Note states what the estimate doesn't take into account
+	Note         string             `json:"note"`
+	Repositories []RepositoryImpact `json:"repositories"`
+	Total        Impact             `json:"total"`
+	// NotEstimated lists the images to delete which are not in the local cache
+	NotEstimated []string `json:"notEstimated,omitempty"`
+}
+
+// RepositoryImpact is the impact of the delete on a repository, for a content type.
+// A blob shared by several repositories is reclaimable for each of them,
+// it is counted once in the total.
+type RepositoryImpact struct {
+	Repository string `json:"repository"`
+	Type       string `json:"type"`
+	Impact
+}
+
+// Impact is the number of manifests deleted, along with the number
+// and the size of the blobs that become unreferenced
+type Impact struct {
+	Manifests        int   `json:"manifests"`
+	Blobs            int   `json:"blobs"`
+	ReclaimableBytes int64 `json:"reclaimableBytes"`
+}
+
+// writeImpactReport computes and writes the impact report of deleting items
+func (o DeleteImages) writeImpactReport(ctx context.Context, items, keep []v2alpha1.DeleteItem) error {
+	report, err := o.impactReport(ctx, items, keep)
+	if err != nil {
+		return err
+	}
+
+	filename := filepath.Join(o.Opts.Global.WorkingDir, deleteImpactReport)
+	if len(o.Opts.Global.DeleteID) > 0 {
+		filename = filepath.Join(o.Opts.Global.WorkingDir, strings.ReplaceAll(deleteImpactReport, ".", "-"+o.Opts.Global.DeleteID+"."))
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return err
+	}
+
+	for _, repository := range report.Repositories {
+		o.Log.Debug("%s (%s) : %d manifests, %d blobs, %s reclaimable", repository.Repository, repository.Type, repository.Manifests, repository.Blobs, cache.HumanBytes(repository.ReclaimableBytes))
+	}
+	if len(report.NotEstimated) > 0 {
+		o.Log.Warn(emoji.Warning+"  %d images to delete are not in the local cache, their blobs are not estimated", len(report.NotEstimated))
+	}
+	o.Log.Info(emoji.PageFacingUp+" %d manifests to delete, %d blobs (%s) reclaimable at most : see %s", report.Total.Manifests, report.Total.Blobs, cache.HumanBytes(report.Total.ReclaimableBytes), filename)
+	return nil
+}
+
+func (o DeleteImages) impactReport(ctx context.Context, items, keep []v2alpha1.DeleteItem) (ImpactReport, error) {
+	report := ImpactReport{Note: impactReportNote, Repositories: []RepositoryImpact{}}
+
+	deleted := map[string]bool{}
+	touched := map[string]bool{}
+	for _, item := range items {
+		ref := o.cacheReference(item.ImageReference)
+		deleted[ref] = true
+		if spec, err := image.ParseRef(ref); err == nil {
+			touched[spec.PathComponent] = true
+		}
+	}
+
+	// the blobs still referenced once the images are deleted, in the repositories touched by the delete
+	remainingRefs, err := o.cacheReferences(touched)
+	if err != nil {
+		return report, err
+	}
+	for _, item := range keep {
+		remainingRefs = append(remainingRefs, o.cacheReference(item.ImageReference))
+	}
+	remaining := map[string]bool{}
+	for _, ref := range remainingRefs {
+		if deleted[ref] {
+			continue
+		}
+		if spec, err := image.ParseRef(ref); err != nil || !touched[spec.PathComponent] {
+			continue
+		}
+		blobs, err := o.Blobs.GatherBlobs(ctx, ref)
+		if err != nil {
+			o.Log.Debug("unable to gather the blobs of %s: %v", ref, err)
+			continue
+		}
+		for blob := range blobs {
+			remaining[blob] = true
+		}
+	}
+
+	impacts := map[string]*RepositoryImpact{}
+	reclaimed := map[string]bool{}
+	for _, item := range items {
+		spec, err := image.ParseRef(item.ImageReference)
+		if err != nil {
+			return report, err
+		}
+		key := spec.PathComponent + "/" + item.Type.String()
+		impact, ok := impacts[key]
+		if !ok {
+			impact = &RepositoryImpact{Repository: spec.PathComponent, Type: item.Type.String()}
+			impacts[key] = impact
+		}
+		impact.Manifests++
+		report.Total.Manifests++
+
+		blobs, err := o.Blobs.GatherBlobs(ctx, o.cacheReference(item.ImageReference))
+		if err != nil {
+			o.Log.Debug("unable to gather the blobs of %s: %v", item.ImageReference, err)
+			report.NotEstimated = append(report.NotEstimated, item.ImageReference)
+			continue
+		}
+		for blob := range blobs {
+			if remaining[blob] {
+				continue
+			}
+			size := o.blobSize(blob)
+			impact.Blobs++
+			impact.ReclaimableBytes += size
+			if !reclaimed[blob] {
+				reclaimed[blob] = true
+				report.Total.Blobs++
+				report.Total.ReclaimableBytes += size
+			}
+		}
+	}
+
+	for _, impact := range impacts {
+		report.Repositories = append(report.Repositories, *impact)
+	}
+	sort.Slice(report.Repositories, func(i, j int) bool {
+		if report.Repositories[i].ReclaimableBytes != report.Repositories[j].ReclaimableBytes {
+			return report.Repositories[i].ReclaimableBytes > report.Repositories[j].ReclaimableBytes
+		}
+		return report.Repositories[i].Repository+report.Repositories[i].Type < report.Repositories[j].Repository+report.Repositories[j].Type
+	})
+	return report, nil
+}
+
+// cacheReference returns the reference of a destination image in the local cache
+func (o DeleteImages) cacheReference(imgRef string) string {
+	if len(o.Opts.Global.DeleteDestination) == 0 {
+		return imgRef
+	}
+	return strings.Replace(imgRef, o.Opts.Global.DeleteDestination, dockerProtocol+o.LocalStorageFQDN, 1)
+}
+
+// cacheReferences returns the references of the tagged manifests of the local cache in repositories
+func (o DeleteImages) cacheReferences(repositories map[string]bool) ([]string, error) {
+	tags, err := cache.Tags(o.LocalStorageDisk)
+	if err != nil {
+		return nil, err
+	}
+	refs := []string{}
+	for _, tag := range tags {
+		if repositories[tag.Repository] {
+			refs = append(refs, dockerProtocol+o.LocalStorageFQDN+"/"+tag.String())
+		}
+	}
+	return refs, nil
+}
+
+// blobSize returns the size of the blob in the local cache, 0 when it is not found
+func (o DeleteImages) blobSize(blob string) int64 {
+	hex := strings.TrimPrefix(blob, "sha256:")
+	if len(hex) < 2 {
+		return 0
+	}
+	info, err := os.Stat(filepath.Join(o.LocalStorageDisk, blobsDir, hex[:2], hex, "data"))
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
@@ -0,0 +1,125 @@
+package delete
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+)
+
+// cacheBlobs gathers the blobs of the images of the local cache
+type cacheBlobs struct {
+	blobs map[string][]string
+}
+
+func (o cacheBlobs) GatherBlobs(ctx context.Context, imgRef string) (map[string]string, error) {
+	blobs, ok := o.blobs[imgRef]
+	if !ok {
+		return nil, fmt.Errorf("manifest unknown")
+	}
+	res := map[string]string{}
+	for _, blob := range blobs {
+		res[blob] = ""
+	}
+	return res, nil
+}
+
+func (o cacheBlobs) GatherImage(ctx context.Context, imgRef string) (string, map[string]string, error) {
+	blobs, err := o.GatherBlobs(ctx, imgRef)
+	return "", blobs, err
+}
+
+func TestImpactReport(t *testing.T) {
+	cacheDir := t.TempDir()
+	workingDir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(workingDir, deleteDir), 0755))
+
+	blob := func(n int) string {
+		return fmt.Sprintf("sha256:%064d", n)
+	}
+	// blob n is n KiB
+	for n := 1; n <= 5; n++ {
+		hex := fmt.Sprintf("%064d", n)
+		blobDir := filepath.Join(cacheDir, blobsDir, hex[:2], hex)
+		assert.NoError(t, os.MkdirAll(blobDir, 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(blobDir, "data"), make([]byte, n*1024), 0600))
+	}
+	for _, tag := range []string{"ubi8/ubi/_manifests/tags/8.9", "ubi8/ubi/_manifests/tags/8.10", "rhel9/postgresql-15/_manifests/tags/latest", "ubi8/ubi-minimal/_manifests/tags/8.9"} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(cacheDir, "docker/registry/v2/repositories", tag, "current"), 0755))
+	}
+
+	global := &mirror.GlobalOptions{
+		WorkingDir:        workingDir,
+		DeleteDestination: "docker://mirror.acme.com/team1",
+	}
+	opts := mirror.CopyOptions{Global: global, LocalStorageFQDN: "localhost:55000"}
+	di := DeleteImages{
+		Log:  clog.New("error"),
+		Opts: opts,
+		Blobs: cacheBlobs{blobs: map[string][]string{
+			// ubi 8.9 shares its base layer with 8.10, which remains
+			"docker://localhost:55000/ubi8/ubi:8.9":               {blob(1), blob(2), blob(3)},
+			"docker://localhost:55000/ubi8/ubi:8.10":              {blob(1), blob(4)},
+			"docker://localhost:55000/rhel9/postgresql-15:latest": {blob(1), blob(5)},
+			// ubi-minimal is not touched by the delete, its blobs are not compared
+			"docker://localhost:55000/ubi8/ubi-minimal:8.9": {blob(2)},
+		}},
+		LocalStorageDisk: cacheDir,
+		LocalStorageFQDN: "localhost:55000",
+	}
+	items := []v2alpha1.DeleteItem{
+		{
+			ImageName:      "docker://registry.redhat.io/ubi8/ubi:8.9",
+			ImageReference: "docker://mirror.acme.com/team1/ubi8/ubi:8.9",
+			Type:           v2alpha1.TypeGeneric,
+		},
+		{
+			ImageName:      "docker://registry.redhat.io/rhel9/postgresql-15:latest",
+			ImageReference: "docker://mirror.acme.com/team1/rhel9/postgresql-15:latest",
+			Type:           v2alpha1.TypeOperatorRelatedImage,
+		},
+		{
+			ImageName:      "docker://registry.redhat.io/ubi9/ubi:latest",
+			ImageReference: "docker://mirror.acme.com/team1/ubi9/ubi:latest",
+			Type:           v2alpha1.TypeGeneric,
+		},
+	}
+
+	t.Run("Testing impact report : should estimate the reclaimable bytes", func(t *testing.T) {
+		report, err := di.impactReport(context.Background(), items, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, Impact{Manifests: 3, Blobs: 3, ReclaimableBytes: (2 + 3 + 5) * 1024}, report.Total)
+		assert.Equal(t, []RepositoryImpact{
+			{Repository: "team1/rhel9/postgresql-15", Type: "operatorRelatedImage", Impact: Impact{Manifests: 1, Blobs: 1, ReclaimableBytes: 5 * 1024}},
+			{Repository: "team1/ubi8/ubi", Type: "generic", Impact: Impact{Manifests: 1, Blobs: 2, ReclaimableBytes: 5 * 1024}},
+			{Repository: "team1/ubi9/ubi", Type: "generic", Impact: Impact{Manifests: 1}},
+		}, report.Repositories)
+		assert.Equal(t, []string{"docker://mirror.acme.com/team1/ubi9/ubi:latest"}, report.NotEstimated)
+		assert.Equal(t, impactReportNote, report.Note)
+	})
+
+	t.Run("Testing impact report : should not reclaim the blobs of the images to keep", func(t *testing.T) {
+		keep := []v2alpha1.DeleteItem{items[1]}
+		report, err := di.impactReport(context.Background(), items[:1], keep)
+		assert.NoError(t, err)
+		assert.Equal(t, Impact{Manifests: 1, Blobs: 2, ReclaimableBytes: 5 * 1024}, report.Total)
+	})
+
+	t.Run("Testing writeImpactReport : should write the report in the delete folder", func(t *testing.T) {
+		err := di.writeImpactReport(context.Background(), items, nil)
+		assert.NoError(t, err)
+		data, err := os.ReadFile(filepath.Join(workingDir, deleteImpactReport))
+		assert.NoError(t, err)
+		var report ImpactReport
+		assert.NoError(t, json.Unmarshal(data, &report))
+		assert.Equal(t, int64(10*1024), report.Total.ReclaimableBytes)
+	})
+}
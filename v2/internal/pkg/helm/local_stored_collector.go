@@ -500,14 +500,19 @@ func prepareM2DCopyBatch(images []v2alpha1.RelatedImage) ([]v2alpha1.CopyImageSc
 		}
 		src = imgSpec.ReferenceWithTransport
 
+		// the cache keeps the source path, rewrite rules only apply to the destination registry
+		destPath := imgSpec.PathComponent
+		if lsc.Opts.IsMirrorToMirror() {
+			destPath = destinationPath(imgSpec)
+		}
 		if imgSpec.IsImageByDigestOnly() {
 			tag := fmt.Sprintf("%s-%s", imgSpec.Algorithm, imgSpec.Digest)
 			if len(tag) > 128 {
 				tag = tag[:127]
 			}
-			dest = dockerProtocol + strings.Join([]string{destinationRegistry(), imgSpec.PathComponent + ":" + tag}, "/")
+			dest = dockerProtocol + strings.Join([]string{destinationRegistry(), destPath + ":" + tag}, "/")
 		} else {
-			dest = dockerProtocol + strings.Join([]string{destinationRegistry(), imgSpec.PathComponent + ":" + imgSpec.Tag}, "/")
+			dest = dockerProtocol + strings.Join([]string{destinationRegistry(), destPath + ":" + imgSpec.Tag}, "/")
 		}
 
 		lsc.Log.Debug("source %s", src)
@@ -535,13 +540,13 @@ func prepareD2MCopyBatch(images []v2alpha1.RelatedImage, generateV1TagsFromDiges
 			}
 			src = dockerProtocol + strings.Join([]string{lsc.Opts.LocalStorageFQDN, imgSpec.PathComponent + ":" + tag}, "/")
 			if generateV1TagsFromDigests {
-				dest = strings.Join([]string{lsc.Opts.Destination, imgSpec.PathComponent + ":latest"}, "/")
+				dest = strings.Join([]string{lsc.Opts.Destination, destinationPath(imgSpec) + ":latest"}, "/")
 			} else {
-				dest = strings.Join([]string{lsc.Opts.Destination, imgSpec.PathComponent + ":" + tag}, "/")
+				dest = strings.Join([]string{lsc.Opts.Destination, destinationPath(imgSpec) + ":" + tag}, "/")
 			}
 		} else {
 			src = dockerProtocol + strings.Join([]string{lsc.Opts.LocalStorageFQDN, imgSpec.PathComponent}, "/") + ":" + imgSpec.Tag
-			dest = strings.Join([]string{lsc.Opts.Destination, imgSpec.PathComponent}, "/") + ":" + imgSpec.Tag
+			dest = strings.Join([]string{lsc.Opts.Destination, destinationPath(imgSpec)}, "/") + ":" + imgSpec.Tag
 		}
 		if src == "" || dest == "" {
 			return result, fmt.Errorf("unable to determine src %s or dst %s for %s", src, dest, img.Name)
@@ -565,3 +570,12 @@ func destinationRegistry() string {
 	}
 	return lsc.destReg
 }
+
+// destinationPath returns the path of the image under the destination registry,
+// after applying the first matching rewrite rule
+func destinationPath(imgSpec image.ImageSpec) string {
+	if path, ok := v2alpha1.RewriteRepository(lsc.Config.Mirror.RewriteRules, imgSpec.Name); ok {
+		return path
+	}
+	return imgSpec.PathComponent
+}
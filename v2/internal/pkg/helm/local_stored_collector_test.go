@@ -47,6 +47,7 @@ type testCase struct {
 	caseName           string
 	mirrorMode         string
 	helmConfig         v2alpha1.Helm
+	rewriteRules       []v2alpha1.RewriteRule
 	localStorage       string
 	dest               string
 	generateV1DestTags bool
@@ -192,6 +193,30 @@ func TestHelmImageCollector(t *testing.T) {
 			},
 			expectedError: nil,
 		},
+		{
+			caseName:   "local helm chart with rewrite rules - MirrorToMirror: should pass",
+			mirrorMode: mirror.MirrorToMirror,
+			dest:       testDest,
+			helmConfig: v2alpha1.Helm{
+				Local: []v2alpha1.Chart{
+					{Name: "podinfo-local", Path: filepath.Join(testChartsDataPath, "podinfo-5.0.0.tgz")},
+				},
+			},
+			rewriteRules: []v2alpha1.RewriteRule{
+				{Source: "docker.io/library/*", Target: "mirror/dockerhub/*"},
+				{Source: "ghcr.io/stefanprodan/*", Target: "mirror/ghcr/*"},
+			},
+			generateV1DestTags: false,
+			expectedResult: []v2alpha1.CopyImageSchema{
+				{
+					Source:      "docker://ghcr.io/stefanprodan/podinfo:5.0.0",
+					Destination: testDest + "/mirror/ghcr/podinfo:5.0.0",
+					Origin:      "ghcr.io/stefanprodan/podinfo:5.0.0",
+					Type:        v2alpha1.TypeHelmImage,
+				},
+			},
+			expectedError: nil,
+		},
 		{
 			caseName:   "repositories helm chart - charts included - MirrorToMirror: should pass",
 			mirrorMode: mirror.MirrorToMirror,
@@ -307,6 +332,30 @@ func TestHelmImageCollector(t *testing.T) {
 			},
 			expectedError: nil,
 		},
+		{
+			caseName:     "local helm chart with rewrite rules - diskToMirror: should pass",
+			mirrorMode:   mirror.DiskToMirror,
+			localStorage: testLocalStorageFQDN,
+			dest:         testDest,
+			helmConfig: v2alpha1.Helm{
+				Local: []v2alpha1.Chart{
+					{Name: "podinfo-local", Path: filepath.Join(testChartsDataPath, "podinfo-5.0.0.tgz")},
+				},
+			},
+			rewriteRules: []v2alpha1.RewriteRule{
+				{Source: "ghcr.io/stefanprodan/podinfo", Target: "apps/podinfo"},
+			},
+			generateV1DestTags: false,
+			expectedResult: []v2alpha1.CopyImageSchema{
+				{
+					Source:      "docker://" + testLocalStorageFQDN + "/stefanprodan/podinfo:5.0.0",
+					Destination: testDest + "/apps/podinfo:5.0.0",
+					Origin:      "ghcr.io/stefanprodan/podinfo:5.0.0",
+					Type:        v2alpha1.TypeHelmImage,
+				},
+			},
+			expectedError: nil,
+		},
 		{
 			caseName:     "repositories helm chart - charts included - diskToMirror: should pass",
 			mirrorMode:   mirror.DiskToMirror,
@@ -497,6 +546,7 @@ func TestHelmImageCollector(t *testing.T) {
 			}
 
 			cfg.Mirror.Helm = testCase.helmConfig
+			cfg.Mirror.RewriteRules = testCase.rewriteRules
 
 			ctx := context.Background()
 
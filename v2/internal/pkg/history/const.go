@@ -1,9 +1,27 @@
 package history
 
-import "github.com/openshift/oc-mirror/v2/internal/pkg/common"
+import (
+	"time"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/common"
+)
 
 const (
 	historyPath       = ".history/"
 	historyNamePrefix = ".history-"
 	historyFakePath   = common.TestFolder + ".history-fake/"
+	dockerProtocol    = "docker://"
+	historyDatabase   = "history.db"
+	runsBucket        = "runs"
+	// databaseLockTimeout is the time to wait for another oc-mirror process
+	// using the same working-dir to release the history database
+	databaseLockTimeout = 10 * time.Second
+
+	historyConfigMediaType = "application/vnd.openshift.oc-mirror.history.config.v1+json"
+	historyLayerMediaType  = "application/vnd.openshift.oc-mirror.history.v1"
+	// registryPushAttempts is the number of times an append is tried
+	// when other runs keep updating the history artifact
+	registryPushAttempts = 5
+	// registryHistoryFiles is the number of history files kept in the history artifact
+	registryHistoryFiles = 10
 )
@@ -0,0 +1,204 @@
+package history
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+)
+
+// databaseHistory records each run (images and blobs) in a bbolt database
+// under the .history folder of the working-dir.
+// The .history-<date> files written by previous versions are still read,
+// so that the incremental baseline is kept when upgrading.
+type databaseHistory struct {
+	dbPath string
+	before time.Time
+	legacy history
+}
+
+// NewDatabaseHistory returns the History recorded in the database of the working-dir
+func NewDatabaseHistory(workingDir string, before time.Time, logg clog.PluggableLoggerInterface) (History, error) {
+	legacy, err := NewHistory(workingDir, before, logg, OSFileCreator{})
+	if err != nil {
+		return nil, err
+	}
+	return databaseHistory{
+		dbPath: filepath.Join(workingDir, historyPath, historyDatabase),
+		before: before,
+		legacy: legacy.(history),
+	}, nil
+}
+
+// NewDatabase gives access to the runs recorded in the database of the working-dir
+func NewDatabase(workingDir string, logg clog.PluggableLoggerInterface) (Database, error) {
+	dbPath := filepath.Join(workingDir, historyPath, historyDatabase)
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, fmt.Errorf("no history database found under %s: %w", workingDir, err)
+	}
+	h, err := NewDatabaseHistory(workingDir, time.Time{}, logg)
+	if err != nil {
+		return nil, err
+	}
+	return h.(databaseHistory), nil
+}
+
+// Read returns the blobs shipped by the runs before o.before (all the runs when not set)
+func (o databaseHistory) Read() (map[string]string, error) {
+	historyMap, err := o.legacy.Read()
+	if err != nil && !errors.Is(err, &EmptyHistoryError{}) {
+		return nil, err
+	}
+
+	runs, err := o.runs()
+	if err != nil {
+		return nil, err
+	}
+	for _, run := range runs {
+		if !o.before.IsZero() && !run.Date.Before(o.before) {
+			continue
+		}
+		for _, blob := range run.Blobs {
+			historyMap[blob] = ""
+		}
+	}
+	if len(historyMap) == 0 {
+		return historyMap, EmptyHistoryErrorf("no history metadata found under %s", filepath.Dir(filepath.Dir(o.dbPath)))
+	}
+	return historyMap, nil
+}
+
+// Append records a run without image information
+func (o databaseHistory) Append(blobsToAppend map[string]string) (map[string]string, error) {
+	blobs := make([]string, 0, len(blobsToAppend))
+	for blob := range blobsToAppend {
+		blobs = append(blobs, blob)
+	}
+	sort.Strings(blobs)
+	return o.AppendRun(Run{Blobs: blobs})
+}
+
+// AppendRun records the run and returns all the blobs shipped so far
+func (o databaseHistory) AppendRun(run Run) (map[string]string, error) {
+	historyBlobs, err := o.Read()
+	if err != nil && !errors.Is(err, &EmptyHistoryError{}) {
+		return nil, err
+	}
+
+	err = o.update(func(runs *bolt.Bucket) error {
+		id, err := runs.NextSequence()
+		if err != nil {
+			return err
+		}
+		run.ID = id
+		run.Date = time.Now().UTC()
+		data, err := json.Marshal(run)
+		if err != nil {
+			return err
+		}
+		return runs.Put(runKey(id), data)
+	})
+	if err != nil {
+		log.Error("unable to record run in history: %s", err.Error())
+		return historyBlobs, err
+	}
+
+	for _, blob := range run.Blobs {
+		historyBlobs[blob] = ""
+	}
+	return historyBlobs, nil
+}
+
+// Runs returns the summary of the recorded runs, oldest first
+func (o databaseHistory) Runs() ([]RunSummary, error) {
+	runs, err := o.runs()
+	if err != nil {
+		return nil, err
+	}
+	summaries := make([]RunSummary, 0, len(runs))
+	for _, run := range runs {
+		summaries = append(summaries, run.Summary())
+	}
+	return summaries, nil
+}
+
+// Run returns a recorded run
+func (o databaseHistory) Run(id uint64) (Run, error) {
+	var run Run
+	err := o.view(func(runs *bolt.Bucket) error {
+		var data []byte
+		if runs != nil {
+			data = runs.Get(runKey(id))
+		}
+		if data == nil {
+			return fmt.Errorf("run %d not found in history", id)
+		}
+		return json.Unmarshal(data, &run)
+	})
+	return run, err
+}
+
+func (o databaseHistory) runs() ([]Run, error) {
+	var runs []Run
+	err := o.view(func(bucket *bolt.Bucket) error {
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(k, v []byte) error {
+			var run Run
+			if err := json.Unmarshal(v, &run); err != nil {
+				return fmt.Errorf("unable to read run %d from history: %w", binary.BigEndian.Uint64(k), err)
+			}
+			runs = append(runs, run)
+			return nil
+		})
+	})
+	return runs, err
+}
+
+// view runs fn on the runs bucket, in a read-only transaction.
+// The bucket is nil before the first run is recorded.
+func (o databaseHistory) view(fn func(*bolt.Bucket) error) error {
+	if _, err := os.Stat(o.dbPath); errors.Is(err, os.ErrNotExist) {
+		return fn(nil)
+	}
+	db, err := bolt.Open(o.dbPath, 0600, &bolt.Options{Timeout: databaseLockTimeout, ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("unable to open history database %s: %w", o.dbPath, err)
+	}
+	defer db.Close()
+	return db.View(func(tx *bolt.Tx) error {
+		return fn(tx.Bucket([]byte(runsBucket)))
+	})
+}
+
+// update runs fn on the runs bucket, in a read-write transaction
+func (o databaseHistory) update(fn func(*bolt.Bucket) error) error {
+	db, err := bolt.Open(o.dbPath, 0600, &bolt.Options{Timeout: databaseLockTimeout})
+	if err != nil {
+		return fmt.Errorf("unable to open history database %s: %w", o.dbPath, err)
+	}
+	defer db.Close()
+	return db.Update(func(tx *bolt.Tx) error {
+		runs, err := tx.CreateBucketIfNotExists([]byte(runsBucket))
+		if err != nil {
+			return err
+		}
+		return fn(runs)
+	})
+}
+
+// runKey encodes the id in big endian, so that runs are iterated in the order they were recorded
+func runKey(id uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, id)
+	return key
+}
@@ -0,0 +1,133 @@
+package history
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/otiai10/copy"
+	"github.com/stretchr/testify/assert"
+
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+)
+
+func TestDatabaseHistory(t *testing.T) {
+	log := clog.New("trace")
+
+	t.Run("Testing AppendRun : should record the runs", func(t *testing.T) {
+		workingDir := t.TempDir()
+		h, err := NewDatabaseHistory(workingDir, time.Time{}, log)
+		assert.NoError(t, err)
+
+		historyMap, err := h.Read()
+		assert.True(t, errors.Is(err, &EmptyHistoryError{}))
+		assert.Empty(t, historyMap)
+
+		historyBlobs, err := h.AppendRun(Run{
+			Archive: "/tmp/archives",
+			Images: []RunImage{
+				{Origin: "quay.io/foo/bar:v1", Destination: "docker://localhost:55000/foo/bar:v1", Digest: "sha256:1dddb0988d16", Type: "additionalImage", Blobs: []string{"sha256:1dddb0988d16", "sha256:3658954f1990"}},
+			},
+			Blobs: []string{"sha256:1dddb0988d16", "sha256:3658954f1990"},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"sha256:1dddb0988d16": "", "sha256:3658954f1990": ""}, historyBlobs)
+
+		historyBlobs, err = h.Append(map[string]string{"sha256:e3dad360d035": ""})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"sha256:1dddb0988d16": "", "sha256:3658954f1990": "", "sha256:e3dad360d035": ""}, historyBlobs)
+
+		db, err := NewDatabase(workingDir, log)
+		assert.NoError(t, err)
+		runs, err := db.Runs()
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(runs))
+		assert.Equal(t, uint64(1), runs[0].ID)
+		assert.Equal(t, 1, runs[0].Images)
+		assert.Equal(t, 2, runs[0].Blobs)
+		assert.Equal(t, "/tmp/archives", runs[0].Archive)
+		assert.Equal(t, uint64(2), runs[1].ID)
+
+		run, err := db.Run(1)
+		assert.NoError(t, err)
+		assert.Equal(t, "quay.io/foo/bar:v1", run.Images[0].Origin)
+		assert.Equal(t, "sha256:1dddb0988d16", run.Images[0].Digest)
+
+		_, err = db.Run(3)
+		assert.EqualError(t, err, "run 3 not found in history")
+	})
+
+	t.Run("Testing Read : should only read runs before --since", func(t *testing.T) {
+		workingDir := t.TempDir()
+		h, err := NewDatabaseHistory(workingDir, time.Time{}, log)
+		assert.NoError(t, err)
+		_, err = h.Append(map[string]string{"sha256:1dddb0988d16": ""})
+		assert.NoError(t, err)
+		since := time.Now().UTC()
+
+		h, err = NewDatabaseHistory(workingDir, since, log)
+		assert.NoError(t, err)
+		_, err = h.Append(map[string]string{"sha256:20f695d2a913": ""})
+		assert.NoError(t, err)
+
+		historyMap, err := h.Read()
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"sha256:1dddb0988d16": ""}, historyMap)
+
+		h, err = NewDatabaseHistory(workingDir, time.Time{}, log)
+		assert.NoError(t, err)
+		historyMap, err = h.Read()
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"sha256:1dddb0988d16": "", "sha256:20f695d2a913": ""}, historyMap)
+	})
+
+	t.Run("Testing Read : should include history files of previous versions", func(t *testing.T) {
+		workingDir := t.TempDir()
+		assert.NoError(t, copy.Copy(filepath.Join(historyFakePath, historyPath), filepath.Join(workingDir, historyPath)))
+		h, err := NewDatabaseHistory(workingDir, time.Time{}, log)
+		assert.NoError(t, err)
+		_, err = h.Append(map[string]string{"sha256:20f695d2a913": ""})
+		assert.NoError(t, err)
+
+		historyMap, err := h.Read()
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"sha256:1dddb0988d16": "",
+			"sha256:3658954f1990": "",
+			"sha256:e3dad360d035": "",
+			"sha256:422e4fbe1ed8": "",
+			"sha256:20f695d2a913": "",
+		}, historyMap)
+		// no new history file
+		files, err := os.ReadDir(filepath.Join(workingDir, historyPath))
+		assert.NoError(t, err)
+		assert.Equal(t, 3, len(files))
+	})
+
+	t.Run("Testing NewDatabase : no database should fail", func(t *testing.T) {
+		_, err := NewDatabase(t.TempDir(), log)
+		assert.Error(t, err)
+	})
+}
+
+func TestDiffRuns(t *testing.T) {
+	from := Run{ID: 1, Images: []RunImage{
+		{Origin: "quay.io/foo/bar:v1", Digest: "sha256:1dddb0988d16"},
+		{Origin: "quay.io/foo/baz:v1", Digest: "sha256:3658954f1990"},
+		{Origin: "quay.io/foo/qux:v1", Digest: "sha256:e3dad360d035"},
+	}}
+	to := Run{ID: 2, Images: []RunImage{
+		{Origin: "quay.io/foo/bar:v1", Digest: "sha256:1dddb0988d16"},
+		{Origin: "quay.io/foo/baz:v1", Digest: "sha256:422e4fbe1ed8"},
+		{Origin: "quay.io/foo/new:v1", Digest: "sha256:20f695d2a913"},
+	}}
+	assert.Equal(t, RunDiff{
+		From:    1,
+		To:      2,
+		Added:   []RunImage{{Origin: "quay.io/foo/new:v1", Digest: "sha256:20f695d2a913"}},
+		Removed: []RunImage{{Origin: "quay.io/foo/qux:v1", Digest: "sha256:e3dad360d035"}},
+		Changed: []ImageChange{{Origin: "quay.io/foo/baz:v1", From: "sha256:3658954f1990", To: "sha256:422e4fbe1ed8"}},
+	}, DiffRuns(from, to))
+}
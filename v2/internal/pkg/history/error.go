@@ -22,3 +22,24 @@ func (e *EmptyHistoryError) Is(err error) bool {
 	_, ok := err.(*EmptyHistoryError)
 	return ok
 }
+
+// This specific error type is returned when the history artifact
+// was updated by another run between reading and appending to it
+type ConcurrentUpdateError struct {
+	message string
+}
+
+func (e *ConcurrentUpdateError) Error() string {
+	return e.message
+}
+
+func ConcurrentUpdateErrorf(format string, a ...any) *ConcurrentUpdateError {
+	return &ConcurrentUpdateError{
+		message: fmt.Sprintf(format, a...),
+	}
+}
+
+func (e *ConcurrentUpdateError) Is(err error) bool {
+	_, ok := err.(*ConcurrentUpdateError)
+	return ok
+}
@@ -10,6 +10,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/containers/image/v5/types"
+
 	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
 )
 
@@ -22,6 +24,15 @@ type history struct {
 	fileCreator FileCreator
 }
 
+// New returns the History of backend: the database in the .history folder of the working-dir
+// when backend is empty, or an OCI artifact in a registry when backend is a docker:// reference
+func New(backend, workingDir string, before time.Time, logg clog.PluggableLoggerInterface, sysCtx *types.SystemContext) (History, error) {
+	if strings.HasPrefix(backend, dockerProtocol) {
+		return NewRegistryHistory(backend, before, logg, sysCtx)
+	}
+	return NewDatabaseHistory(workingDir, before, logg)
+}
+
 func NewHistory(workingDir string, before time.Time, logg clog.PluggableLoggerInterface, fileCreator FileCreator) (History, error) {
 	if logg == nil {
 		log = clog.New("error")
@@ -163,6 +174,10 @@ func (o history) Append(blobsToAppend map[string]string) (map[string]string, err
 
 }
 
+func (o history) AppendRun(run Run) (map[string]string, error) {
+	return o.Append(runBlobs(run))
+}
+
 func (o history) newFileName() string {
 	return filepath.Join(o.historyDir, historyNamePrefix+time.Now().UTC().Format(time.RFC3339))
 }
@@ -171,3 +186,11 @@ func (OSFileCreator) Create(filename string) (io.WriteCloser, error) {
 	file, err := os.Create(filename)
 	return file, err
 }
+
+func runBlobs(run Run) map[string]string {
+	blobs := make(map[string]string, len(run.Blobs))
+	for _, blob := range run.Blobs {
+		blobs[blob] = ""
+	}
+	return blobs
+}
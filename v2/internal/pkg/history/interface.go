@@ -5,6 +5,15 @@ import "io"
 type History interface {
 	Read() (map[string]string, error)
 	Append(map[string]string) (map[string]string, error)
+	// AppendRun records the images mirrored by a run along with the blobs added to its archive.
+	// Backends that only keep blobs record the blobs of the run.
+	AppendRun(Run) (map[string]string, error)
+}
+
+// Database gives access to the runs recorded in the history
+type Database interface {
+	Runs() ([]RunSummary, error)
+	Run(id uint64) (Run, error)
 }
 
 type FileCreator interface {
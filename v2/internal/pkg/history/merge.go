@@ -0,0 +1,72 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+)
+
+// Merge records the history of the source working-dirs in the history of workingDir:
+// the blobs of the legacy history files are kept in a single file, and the runs
+// are recorded in the order of their date, with new ids.
+func Merge(workingDir string, sources []string, logg clog.PluggableLoggerInterface) error {
+	merged, err := NewDatabaseHistory(workingDir, time.Time{}, logg)
+	if err != nil {
+		return err
+	}
+
+	legacyBlobs := map[string]string{}
+	var runs []Run
+	for _, source := range sources {
+		h, err := NewDatabaseHistory(source, time.Time{}, logg)
+		if err != nil {
+			return err
+		}
+		sourceHistory := h.(databaseHistory)
+		blobs, err := sourceHistory.legacy.Read()
+		if err != nil && !errors.Is(err, &EmptyHistoryError{}) {
+			return err
+		}
+		for blob := range blobs {
+			legacyBlobs[blob] = ""
+		}
+		sourceRuns, err := sourceHistory.runs()
+		if err != nil {
+			return err
+		}
+		runs = append(runs, sourceRuns...)
+	}
+
+	if len(legacyBlobs) > 0 {
+		if _, err := merged.(databaseHistory).legacy.Append(legacyBlobs); err != nil {
+			return err
+		}
+	}
+	if len(runs) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].Date.Before(runs[j].Date) })
+	return merged.(databaseHistory).update(func(bucket *bolt.Bucket) error {
+		for _, run := range runs {
+			id, err := bucket.NextSequence()
+			if err != nil {
+				return err
+			}
+			run.ID = id
+			data, err := json.Marshal(run)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put(runKey(id), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,54 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+)
+
+func TestMerge(t *testing.T) {
+	log := clog.New("trace")
+
+	first := t.TempDir()
+	legacy, err := NewHistory(first, time.Time{}, log, OSFileCreator{})
+	assert.NoError(t, err)
+	_, err = legacy.Append(map[string]string{"sha256:1dddb0988d16": ""})
+	assert.NoError(t, err)
+	h, err := NewDatabaseHistory(first, time.Time{}, log)
+	assert.NoError(t, err)
+	_, err = h.AppendRun(Run{Archive: "/archives/team1", Blobs: []string{"sha256:3658954f1990"}, Images: []RunImage{{Origin: "quay.io/foo/bar:v1", Digest: "sha256:3658954f1990"}}})
+	assert.NoError(t, err)
+
+	second := t.TempDir()
+	h, err = NewDatabaseHistory(second, time.Time{}, log)
+	assert.NoError(t, err)
+	_, err = h.AppendRun(Run{Archive: "/archives/team2", Blobs: []string{"sha256:e3dad360d035"}})
+	assert.NoError(t, err)
+
+	merged := t.TempDir()
+	assert.NoError(t, Merge(merged, []string{second, first}, log))
+
+	h, err = NewDatabaseHistory(merged, time.Time{}, log)
+	assert.NoError(t, err)
+	historyMap, err := h.Read()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"sha256:1dddb0988d16": "", "sha256:3658954f1990": "", "sha256:e3dad360d035": ""}, historyMap)
+
+	db, err := NewDatabase(merged, log)
+	assert.NoError(t, err)
+	runs, err := db.Runs()
+	assert.NoError(t, err)
+	// runs are recorded in the order of their date, whatever the order of the sources
+	assert.Len(t, runs, 2)
+	assert.Equal(t, uint64(1), runs[0].ID)
+	assert.Equal(t, "/archives/team1", runs[0].Archive)
+	assert.Equal(t, uint64(2), runs[1].ID)
+	assert.Equal(t, "/archives/team2", runs[1].Archive)
+
+	run, err := db.Run(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "quay.io/foo/bar:v1", run.Images[0].Origin)
+}
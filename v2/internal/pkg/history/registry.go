@@ -0,0 +1,256 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/containers/image/v5/types"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	ggcrtypes "github.com/google/go-containerregistry/pkg/v1/types"
+	specv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+)
+
+// registryHistory keeps the history files as the layers of an OCI artifact
+// pushed to a registry, so that mirrorToDisk runs on different (possibly ephemeral)
+// hosts share the same incremental baseline.
+// The backend has no locking: the registry API has no conditional writes. Appending checks the digest
+// of the artifact right before moving the tag to the new artifact, and reads the history again and retries
+// when it changed since it was read. A run updating the tag between the check and the move is still overwritten:
+// the blobs it recorded are then missing from the history, and are shipped again by the next mirrorToDisk.
+// Only the last registryHistoryFiles history files are kept: --since can't go back further.
+type registryHistory struct {
+	ref        name.Reference
+	before     time.Time
+	remoteOpts []remote.Option
+}
+
+// registryArtifact is the state of the history artifact, as read from the registry
+type registryArtifact struct {
+	// revision is the digest of the artifact manifest, empty when the artifact doesn't exist yet
+	revision string
+	image    v1.Image
+	files    []v1.Descriptor
+}
+
+// NewRegistryHistory returns a History stored in the registry repository of reference
+// (docker://registry/namespace/repository[:tag]). The registry is accessed as the images
+// are copied with sysCtx: with its credentials (--src-creds, --src-authfile, else the default
+// auth files) and its TLS verification (--src-tls-verify, else the registries.conf entry of the registry).
+func NewRegistryHistory(reference string, before time.Time, logg clog.PluggableLoggerInterface, sysCtx *types.SystemContext) (History, error) {
+	if logg == nil {
+		log = clog.New("error")
+	} else {
+		log = logg
+	}
+	insecure, err := mirror.IsInsecureRegistry(sysCtx, strings.TrimPrefix(reference, dockerProtocol))
+	if err != nil {
+		return nil, fmt.Errorf("invalid history backend %s: %w", reference, err)
+	}
+	nameOpts := []name.Option{name.StrictValidation}
+	roundTripper := remote.DefaultTransport
+	if insecure {
+		nameOpts = append(nameOpts, name.Insecure)
+		insecureTransport := remote.DefaultTransport.(*http.Transport).Clone()
+		insecureTransport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true, MinVersion: tls.VersionTLS12} //nolint:gosec // requested with --src-tls-verify=false or registries.conf
+		roundTripper = insecureTransport
+	}
+	ref, err := name.ParseReference(strings.TrimPrefix(reference, dockerProtocol), nameOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid history backend %s: %w", reference, err)
+	}
+	if _, ok := ref.(name.Digest); ok {
+		return nil, fmt.Errorf("invalid history backend %s: a tag is expected, the artifact is updated at each mirroring", reference)
+	}
+	return registryHistory{
+		ref:    ref,
+		before: before,
+		remoteOpts: []remote.Option{
+			remote.WithAuthFromKeychain(mirror.CredentialsKeychain{SysCtx: sysCtx}),
+			remote.WithTransport(roundTripper),
+		},
+	}, nil
+}
+
+func (o registryHistory) Read() (map[string]string, error) {
+	artifact, err := o.fetch()
+	if err != nil {
+		return nil, err
+	}
+	return o.readBlobs(artifact)
+}
+
+func (o registryHistory) Append(blobsToAppend map[string]string) (map[string]string, error) {
+	for attempt := 1; ; attempt++ {
+		artifact, err := o.fetch()
+		if err != nil {
+			return nil, err
+		}
+		historyBlobs, err := o.readBlobs(artifact)
+		if err != nil && !errors.Is(err, &EmptyHistoryError{}) {
+			return nil, err
+		}
+		for k, v := range blobsToAppend {
+			historyBlobs[k] = v
+		}
+
+		var content bytes.Buffer
+		for blob := range historyBlobs {
+			content.WriteString(blob + "\n")
+		}
+
+		err = o.push(artifact, historyNamePrefix+time.Now().UTC().Format(time.RFC3339), content.Bytes())
+		if err == nil {
+			return historyBlobs, nil
+		}
+		if !errors.Is(err, &ConcurrentUpdateError{}) || attempt == registryPushAttempts {
+			return historyBlobs, err
+		}
+		log.Debug("history %s was updated by another run, retrying (%d/%d)", o.ref.String(), attempt, registryPushAttempts)
+	}
+}
+
+// AppendRun only keeps the blobs of the run: the images are recorded in the
+// database of the working-dir, which isn't shared between hosts
+func (o registryHistory) AppendRun(run Run) (map[string]string, error) {
+	return o.Append(runBlobs(run))
+}
+
+// fetch reads the manifest of the history artifact. A missing artifact is not an error:
+// it is the state of the history before the first mirroring.
+func (o registryHistory) fetch() (registryArtifact, error) {
+	desc, err := remote.Get(o.ref, o.remoteOpts...)
+	if isNotFound(err) {
+		return registryArtifact{}, nil
+	} else if err != nil {
+		return registryArtifact{}, fmt.Errorf("unable to get history artifact %s: %w", o.ref.String(), err)
+	}
+	img, err := desc.Image()
+	if err != nil {
+		return registryArtifact{}, fmt.Errorf("unable to read history artifact %s: %w", o.ref.String(), err)
+	}
+	manifest, err := img.Manifest()
+	if err != nil {
+		return registryArtifact{}, fmt.Errorf("unable to read history artifact %s: %w", o.ref.String(), err)
+	}
+	artifact := registryArtifact{revision: desc.Digest.String(), image: img}
+	for _, layer := range manifest.Layers {
+		if layer.MediaType == historyLayerMediaType && strings.HasPrefix(layer.Annotations[specv1.AnnotationTitle], historyNamePrefix) {
+			artifact.files = append(artifact.files, layer)
+		}
+	}
+	return artifact, nil
+}
+
+// readBlobs returns the blobs of the latest history file of the artifact (before o.before when set)
+func (o registryHistory) readBlobs(artifact registryArtifact) (map[string]string, error) {
+	historyMap := make(map[string]string)
+
+	var latestFile *v1.Descriptor
+	var latestTime time.Time
+	for i, file := range artifact.files {
+		fileTime, err := time.Parse(time.RFC3339, strings.TrimPrefix(file.Annotations[specv1.AnnotationTitle], historyNamePrefix))
+		if err != nil {
+			log.Error("unable to parse time from history file %s: %s", file.Annotations[specv1.AnnotationTitle], err.Error())
+			return nil, err
+		}
+		if fileTime.After(latestTime) && (o.before.IsZero() || fileTime.Before(o.before)) {
+			latestFile = &artifact.files[i]
+			latestTime = fileTime
+		}
+	}
+	if latestFile == nil {
+		return historyMap, EmptyHistoryErrorf("no history metadata found under %s", o.ref.String())
+	}
+
+	layer, err := artifact.image.LayerByDigest(latestFile.Digest)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := layer.Compressed()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		historyMap[scanner.Text()] = ""
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return historyMap, nil
+}
+
+// push adds the history file to the artifact read in previous, as long as the artifact
+// in the registry is still at the same revision.
+// The new artifact is first pushed by digest, so that only the update of the tag, a single
+// request, happens after the revision is checked.
+func (o registryHistory) push(previous registryArtifact, filename string, content []byte) error {
+	img, err := o.appendFile(previous, filename, content)
+	if err != nil {
+		return err
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		return err
+	}
+	if err := remote.Write(o.ref.Context().Digest(digest.String()), img, o.remoteOpts...); err != nil {
+		return fmt.Errorf("unable to push history artifact %s: %w", o.ref.String(), err)
+	}
+
+	current, err := remote.Head(o.ref, o.remoteOpts...)
+	switch {
+	case isNotFound(err):
+		if previous.revision != "" {
+			return ConcurrentUpdateErrorf("history artifact %s was removed since it was read", o.ref.String())
+		}
+	case err != nil:
+		return fmt.Errorf("unable to get history artifact %s: %w", o.ref.String(), err)
+	case current.Digest.String() != previous.revision:
+		return ConcurrentUpdateErrorf("history artifact %s was updated since it was read (%s, now %s)", o.ref.String(), previous.revision, current.Digest.String())
+	}
+	if err := remote.Put(o.ref, img, o.remoteOpts...); err != nil {
+		return fmt.Errorf("unable to push history artifact %s: %w", o.ref.String(), err)
+	}
+	return nil
+}
+
+// appendFile returns the artifact of previous with the history file added. Each history file
+// lists all the blobs mirrored so far: only the last registryHistoryFiles files are kept.
+func (o registryHistory) appendFile(previous registryArtifact, filename string, content []byte) (v1.Image, error) {
+	addenda := []mutate.Addendum{}
+	for _, file := range previous.files[max(0, len(previous.files)-registryHistoryFiles+1):] {
+		layer, err := previous.image.LayerByDigest(file.Digest)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read history artifact %s: %w", o.ref.String(), err)
+		}
+		addenda = append(addenda, mutate.Addendum{Layer: layer, Annotations: file.Annotations})
+	}
+	addenda = append(addenda, mutate.Addendum{
+		Layer:       static.NewLayer(content, historyLayerMediaType),
+		Annotations: map[string]string{specv1.AnnotationTitle: filename},
+	})
+	base := mutate.ConfigMediaType(mutate.MediaType(empty.Image, ggcrtypes.OCIManifestSchema1), historyConfigMediaType)
+	return mutate.Append(base, addenda...)
+}
+
+func isNotFound(err error) bool {
+	var terr *transport.Error
+	return errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound
+}
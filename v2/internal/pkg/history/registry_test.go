@@ -0,0 +1,125 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containers/image/v5/types"
+	specv1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryHistory(t *testing.T) {
+	server := testutils.CreateRegistry()
+	t.Cleanup(server.Close)
+	backend := dockerProtocol + strings.TrimPrefix(server.URL, "http://") + "/oc-mirror/history:latest"
+	insecureCtx := &types.SystemContext{DockerInsecureSkipTLSVerify: types.OptionalBoolTrue}
+
+	t.Run("Testing New : should select the backend", func(t *testing.T) {
+		h, err := New(backend, t.TempDir(), time.Time{}, clog.New("trace"), insecureCtx)
+		assert.NoError(t, err)
+		assert.IsType(t, registryHistory{}, h)
+
+		h, err = New("", t.TempDir(), time.Time{}, clog.New("trace"), insecureCtx)
+		assert.NoError(t, err)
+		assert.IsType(t, databaseHistory{}, h)
+
+		_, err = New(dockerProtocol+"registry.example.com/history@sha256:20f695d2a91352d4eaa25107535126727b5945bff38ed36a3e59590f495046f0", t.TempDir(), time.Time{}, clog.New("trace"), insecureCtx)
+		assert.Error(t, err)
+	})
+
+	t.Run("Testing Read/Append : should share the history through the registry", func(t *testing.T) {
+		h, err := NewRegistryHistory(backend, time.Time{}, clog.New("trace"), insecureCtx)
+		assert.NoError(t, err)
+
+		historyMap, err := h.Read()
+		assert.True(t, errors.Is(err, &EmptyHistoryError{}))
+		assert.Empty(t, historyMap)
+
+		historyBlobs, err := h.Append(map[string]string{"sha256:1dddb0988d16": ""})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"sha256:1dddb0988d16": ""}, historyBlobs)
+
+		// history file names have a precision of one second
+		time.Sleep(time.Second)
+
+		// another host
+		secondAppend := time.Now().UTC().Truncate(time.Second)
+		other, err := NewRegistryHistory(backend, time.Time{}, clog.New("trace"), insecureCtx)
+		assert.NoError(t, err)
+		historyBlobs, err = other.Append(map[string]string{"sha256:20f695d2a913": ""})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"sha256:1dddb0988d16": "", "sha256:20f695d2a913": ""}, historyBlobs)
+
+		historyMap, err = h.Read()
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"sha256:1dddb0988d16": "", "sha256:20f695d2a913": ""}, historyMap)
+
+		// --since: only the history before the date is taken into account
+		since, err := NewRegistryHistory(backend, secondAppend, clog.New("trace"), insecureCtx)
+		assert.NoError(t, err)
+		historyMap, err = since.Read()
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"sha256:1dddb0988d16": ""}, historyMap)
+	})
+
+	t.Run("Testing push : should only keep the last history files", func(t *testing.T) {
+		cappedBackend := dockerProtocol + strings.TrimPrefix(server.URL, "http://") + "/oc-mirror/capped:latest"
+		h, err := NewRegistryHistory(cappedBackend, time.Time{}, clog.New("trace"), insecureCtx)
+		assert.NoError(t, err)
+		rh := h.(registryHistory)
+
+		start := time.Now().UTC()
+		for i := 0; i < registryHistoryFiles+2; i++ {
+			artifact, err := rh.fetch()
+			assert.NoError(t, err)
+			content := fmt.Sprintf("sha256:%012d\n", i)
+			assert.NoError(t, rh.push(artifact, historyNamePrefix+start.Add(time.Duration(i)*time.Second).Format(time.RFC3339), []byte(content)))
+		}
+
+		artifact, err := rh.fetch()
+		assert.NoError(t, err)
+		assert.Len(t, artifact.files, registryHistoryFiles)
+		assert.Equal(t, historyNamePrefix+start.Add(2*time.Second).Format(time.RFC3339), artifact.files[0].Annotations[specv1.AnnotationTitle])
+		historyMap, err := h.Read()
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{fmt.Sprintf("sha256:%012d", registryHistoryFiles+1): ""}, historyMap)
+	})
+
+	t.Run("Testing push : should detect concurrent updates", func(t *testing.T) {
+		lockedBackend := dockerProtocol + strings.TrimPrefix(server.URL, "http://") + "/oc-mirror/locked:latest"
+		h, err := NewRegistryHistory(lockedBackend, time.Time{}, clog.New("trace"), insecureCtx)
+		assert.NoError(t, err)
+		rh := h.(registryHistory)
+
+		artifact, err := rh.fetch()
+		assert.NoError(t, err)
+		assert.Equal(t, "", artifact.revision)
+
+		// another run updates the history in the meantime
+		_, err = h.Append(map[string]string{"sha256:422e4fbe1ed8": ""})
+		assert.NoError(t, err)
+
+		err = rh.push(artifact, historyNamePrefix+time.Now().UTC().Format(time.RFC3339), []byte("sha256:e3dad360d035\n"))
+		assert.True(t, errors.Is(err, &ConcurrentUpdateError{}))
+		// the tag still points to the update of the other run
+		historyMap, err := h.Read()
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"sha256:422e4fbe1ed8": ""}, historyMap)
+
+		artifact, err = rh.fetch()
+		assert.NoError(t, err)
+		assert.NotEqual(t, "", artifact.revision)
+		err = rh.push(artifact, historyNamePrefix+time.Now().UTC().Add(time.Second).Format(time.RFC3339), []byte("sha256:e3dad360d035\n"))
+		assert.NoError(t, err)
+		historyMap, err = h.Read()
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"sha256:e3dad360d035": ""}, historyMap)
+	})
+}
@@ -0,0 +1,92 @@
+package history
+
+import (
+	"sort"
+	"time"
+)
+
+// Run is what a mirroring run recorded in the history:
+// the images it mirrored and the blobs added to its archive
+type Run struct {
+	ID      uint64    `json:"id"`
+	Date    time.Time `json:"date"`
+	Archive string    `json:"archive,omitempty"`
+	// Destination is the registry, along with its namespace, the images were mirrored to
+	Destination string     `json:"destination,omitempty"`
+	Images      []RunImage `json:"images"`
+	Blobs       []string   `json:"blobs"`
+}
+
+// RunImage is an image mirrored during a run, along with the blobs it references
+type RunImage struct {
+	Origin      string   `json:"origin"`
+	Destination string   `json:"destination"`
+	Digest      string   `json:"digest,omitempty"`
+	Type        string   `json:"type"`
+	Blobs       []string `json:"blobs,omitempty"`
+}
+
+// RunSummary is the short description of a run, as listed by the history command
+type RunSummary struct {
+	ID      uint64    `json:"id"`
+	Date    time.Time `json:"date"`
+	Archive string    `json:"archive,omitempty"`
+	Images  int       `json:"images"`
+	Blobs   int       `json:"blobs"`
+}
+
+// RunDiff lists the images added, removed or changed (different manifest digest) between two runs
+type RunDiff struct {
+	From    uint64        `json:"from"`
+	To      uint64        `json:"to"`
+	Added   []RunImage    `json:"added"`
+	Removed []RunImage    `json:"removed"`
+	Changed []ImageChange `json:"changed"`
+}
+
+// ImageChange is an image mirrored in both runs, with a different manifest digest
+type ImageChange struct {
+	Origin string `json:"origin"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+}
+
+// Summary returns the short description of the run
+func (r Run) Summary() RunSummary {
+	return RunSummary{
+		ID:      r.ID,
+		Date:    r.Date,
+		Archive: r.Archive,
+		Images:  len(r.Images),
+		Blobs:   len(r.Blobs),
+	}
+}
+
+// DiffRuns compares the images of two runs, identified by their origin
+func DiffRuns(from, to Run) RunDiff {
+	diff := RunDiff{From: from.ID, To: to.ID}
+	fromImages := make(map[string]RunImage, len(from.Images))
+	for _, img := range from.Images {
+		fromImages[img.Origin] = img
+	}
+	toImages := make(map[string]RunImage, len(to.Images))
+	for _, img := range to.Images {
+		toImages[img.Origin] = img
+		previous, ok := fromImages[img.Origin]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, img)
+		case previous.Digest != img.Digest:
+			diff.Changed = append(diff.Changed, ImageChange{Origin: img.Origin, From: previous.Digest, To: img.Digest})
+		}
+	}
+	for _, img := range from.Images {
+		if _, ok := toImages[img.Origin]; !ok {
+			diff.Removed = append(diff.Removed, img)
+		}
+	}
+	sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i].Origin < diff.Added[j].Origin })
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].Origin < diff.Removed[j].Origin })
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].Origin < diff.Changed[j].Origin })
+	return diff
+}
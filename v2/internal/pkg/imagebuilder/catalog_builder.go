@@ -4,10 +4,13 @@ import (
 	"archive/tar"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -19,6 +22,7 @@ import (
 	"github.com/openshift/oc-mirror/v2/internal/pkg/image"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/log"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+	"github.com/operator-framework/operator-registry/pkg/cache"
 	"github.com/otiai10/copy"
 )
 
@@ -32,6 +36,13 @@ const (
 	operatorCatalogFilteredImageDir = "filtered-catalog-image"
 	operatorCatalogImageDir         = "catalog-image"
 	operatorCatalogConfigDir        = "catalog-config"
+	// defaultServeCacheDir is where the catalog images expect the opm serve cache
+	// when their command does not set --cache-dir
+	defaultServeCacheDir    = "/tmp/cache"
+	serveCacheDirFlag       = "--cache-dir"
+	serveCacheIntegrityFlag = "--cache-enforce-integrity"
+	// serveCacheUid is the user running opm serve in the catalog images
+	serveCacheUid = 1001
 )
 
 type GCRCatalogBuilder struct {
@@ -78,6 +89,31 @@ func (c GCRCatalogBuilder) RebuildCatalog(ctx context.Context, catalogCopyRef v2
 	}
 	layersToDelete = append(layersToDelete, deletedConfigLayer)
 
+	configCMD := []string{"serve", "/configs"}
+
+	// The serve cache is only built when the opm binary of the catalog image reads the format
+	// oc-mirror builds. Otherwise, the catalog pods build it at startup.
+	cacheDir, cacheFormat := c.serveCache(originCatalogLayoutDir)
+	if cacheFormat == cache.FormatPogrebV1 {
+		cacheLayerToAdd, err := serveCacheLayer(ctx, configPath, cacheDir)
+		if err != nil {
+			return fmt.Errorf("error building the serve cache of catalog %s : %v", catalogCopyRef.Origin, err)
+		}
+		layersToAdd = append(layersToAdd, cacheLayerToAdd)
+
+		// the cache of the original catalog does not match the filtered configs
+		deletedCacheLayer, err := deleteLayer(filepath.Join(filepath.Dir(cacheDir), ".wh."+filepath.Base(cacheDir)))
+		if err != nil {
+			return fmt.Errorf("error preparing to delete old %s from catalog %s : %v", cacheDir, catalogCopyRef.Origin, err)
+		}
+		layersToDelete = append(layersToDelete, deletedCacheLayer)
+
+		// the cache is rebuilt at startup, rather than failing, if opm computes its digest differently
+		configCMD = append(configCMD, serveCacheDirFlag+"="+cacheDir, serveCacheIntegrityFlag+"=false")
+	} else {
+		c.Logger.Debug("catalog %s has no %s serve cache in %s, the cache is built when the catalog starts", catalogCopyRef.Origin, cache.FormatPogrebV1, cacheDir)
+	}
+
 	// Deleted layers must be added first in the slice
 	// so that the /configs and /tmp directories are deleted
 	// and then added back from the layers rebuilt from the new FBC.
@@ -96,8 +132,6 @@ func (c GCRCatalogBuilder) RebuildCatalog(ctx context.Context, catalogCopyRef v2
 		return fmt.Errorf("error creating OCI layout: %v", err)
 	}
 
-	configCMD := []string{"serve", "/configs"}
-
 	var srcCache string
 	filteredDir := filepath.Dir(configPath)
 	destRef, err := image.ParseRef(catalogCopyRef.Destination)
@@ -131,6 +165,147 @@ func (c GCRCatalogBuilder) RebuildCatalog(ctx context.Context, catalogCopyRef v2
 	return nil
 }
 
+// serveCache returns the cache directory the command of the catalog image serves from
+// (the default one when the command does not set it), and the format of the cache the image
+// ships there, as built by its opm binary. The format is empty when the image ships no cache.
+func (c GCRCatalogBuilder) serveCache(layoutDir string) (string, string) {
+	img, err := layoutImage(layoutDir)
+	if err != nil {
+		c.Logger.Debug("unable to read catalog image %s: %v", layoutDir, err)
+		return defaultServeCacheDir, ""
+	}
+	cacheDir := defaultServeCacheDir
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		c.Logger.Debug("unable to read the command of catalog image %s, using cache directory %s: %v", layoutDir, defaultServeCacheDir, err)
+	} else if dir := cacheDirFlag(append(slices.Clone(cfg.Config.Entrypoint), cfg.Config.Cmd...)); dir != "" {
+		cacheDir = dir
+	}
+	format, err := imageCacheFormat(img, cacheDir)
+	if err != nil {
+		c.Logger.Debug("unable to read the cache of catalog image %s: %v", layoutDir, err)
+		return cacheDir, ""
+	}
+	return cacheDir, format
+}
+
+// layoutImage returns the first image of the OCI layout, its first platform for a multi-arch image
+func layoutImage(layoutDir string) (v1.Image, error) {
+	layoutPath, err := layout.FromPath(layoutDir)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := layoutPath.ImageIndex()
+	if err != nil {
+		return nil, err
+	}
+	return firstImage(idx)
+}
+
+func firstImage(idx v1.ImageIndex) (v1.Image, error) {
+	idxManifest, err := idx.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	if len(idxManifest.Manifests) == 0 {
+		return nil, fmt.Errorf("no image found in the index")
+	}
+	desc := idxManifest.Manifests[0]
+	if desc.MediaType.IsIndex() {
+		innerIdx, err := idx.ImageIndex(desc.Digest)
+		if err != nil {
+			return nil, err
+		}
+		return firstImage(innerIdx)
+	}
+	return idx.Image(desc.Digest)
+}
+
+// cacheDirFlag returns the value of the --cache-dir flag of the command, if any
+func cacheDirFlag(cmd []string) string {
+	for i, arg := range cmd {
+		if dir, ok := strings.CutPrefix(arg, serveCacheDirFlag+"="); ok {
+			return dir
+		}
+		if arg == serveCacheDirFlag && i+1 < len(cmd) {
+			return cmd[i+1]
+		}
+	}
+	return ""
+}
+
+// imageCacheFormat returns the format of the cache found in cacheDir in the layers of the image,
+// which is the directory the cache backend writes to (pogreb.v1), looking at the top most layers first
+func imageCacheFormat(img v1.Image, cacheDir string) (string, error) {
+	layers, err := img.Layers()
+	if err != nil {
+		return "", err
+	}
+	for i := len(layers) - 1; i >= 0; i-- {
+		format, found, err := layerCacheFormat(layers[i], cacheDir)
+		if err != nil || found {
+			return format, err
+		}
+	}
+	return "", nil
+}
+
+// layerCacheFormat returns the format of the cache the layer writes in cacheDir, if any.
+// A layer deleting cacheDir is reported as found, without format.
+func layerCacheFormat(layer v1.Layer, cacheDir string) (string, bool, error) {
+	rc, err := layer.Uncompressed()
+	if err != nil {
+		return "", false, err
+	}
+	defer rc.Close()
+
+	cacheDir = path.Clean("/" + cacheDir)
+	whiteout := path.Join(path.Dir(cacheDir), ".wh."+path.Base(cacheDir))
+	tr := tar.NewReader(rc)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return "", false, nil
+		}
+		if err != nil {
+			return "", false, err
+		}
+		name := path.Clean("/" + hdr.Name)
+		if name == whiteout {
+			return "", true, nil
+		}
+		if rest, ok := strings.CutPrefix(name, cacheDir+"/"); ok && !strings.HasPrefix(rest, ".wh.") {
+			format, _, _ := strings.Cut(rest, "/")
+			return format, true, nil
+		}
+	}
+}
+
+// serveCacheLayer builds the opm serve cache of the filtered configs in-process,
+// as `opm serve --cache-only` does in the catalog image, and returns it as a layer
+// at cacheDir, so that the catalog pods do not have to build it at startup
+func serveCacheLayer(ctx context.Context, configPath, cacheDir string) (v1.Layer, error) {
+	tmpDir, err := os.MkdirTemp("", "serve-cache-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	buildDir := filepath.Join(tmpDir, filepath.Base(cacheDir))
+	serveCache, err := cache.New(buildDir, cache.WithFormat(cache.FormatPogrebV1))
+	if err != nil {
+		return nil, err
+	}
+	if err := serveCache.Build(ctx, os.DirFS(configPath)); err != nil {
+		serveCache.Close()
+		return nil, err
+	}
+	if err := serveCache.Close(); err != nil {
+		return nil, err
+	}
+	return LayerFromPathWithUidGid(cacheDir, buildDir, serveCacheUid, 0)
+}
+
 // LayerFromPath will write the contents of the path(s) the target
 // directory specifying the target UID/GID and build a v1.Layer.
 // Use gid = -1 , uid = -1 if you don't want to override.
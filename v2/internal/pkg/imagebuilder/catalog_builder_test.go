@@ -0,0 +1,146 @@
+package imagebuilder
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/common"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/operator-framework/operator-registry/pkg/cache"
+)
+
+const testConfigs = `{"schema": "olm.package", "name": "database", "defaultChannel": "stable"}
+{"schema": "olm.channel", "package": "database", "name": "stable", "entries": [{"name": "database.v1.0.0"}]}
+{"schema": "olm.bundle", "package": "database", "name": "database.v1.0.0", "image": "quay.io/example/database-bundle:v1.0.0", "properties": [{"type": "olm.package", "value": {"packageName": "database", "version": "1.0.0"}}]}
+`
+
+func TestServeCacheLayer(t *testing.T) {
+
+	t.Run("Testing serveCacheLayer : should build the serve cache of the configs", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "configs")
+		if err := os.MkdirAll(filepath.Join(configPath, "database"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(configPath, "database", "index.json"), []byte(testConfigs), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		layer, err := serveCacheLayer(context.Background(), configPath, defaultServeCacheDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rc, err := layer.Uncompressed()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rc.Close()
+
+		files := map[string]bool{}
+		tr := tar.NewReader(rc)
+		for {
+			hdr, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(hdr.Name, defaultServeCacheDir) {
+				t.Errorf("unexpected file %s outside of %s", hdr.Name, defaultServeCacheDir)
+			}
+			if hdr.Uid != serveCacheUid || hdr.Gid != 0 {
+				t.Errorf("unexpected owner %d:%d of %s", hdr.Uid, hdr.Gid, hdr.Name)
+			}
+			files[hdr.Name] = true
+		}
+		if !files[defaultServeCacheDir+"/pogreb.v1/digest"] {
+			t.Errorf("the cache digest is missing from the layer: %v", files)
+		}
+	})
+
+	t.Run("Testing serveCacheLayer : should fail on invalid configs", func(t *testing.T) {
+		configPath := t.TempDir()
+		if err := os.WriteFile(filepath.Join(configPath, "index.json"), []byte(`{"schema": "olm.package"`), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := serveCacheLayer(context.Background(), configPath, defaultServeCacheDir); err == nil {
+			t.Fatalf("should fail")
+		}
+	})
+}
+
+func TestServeCache(t *testing.T) {
+
+	t.Run("Testing cacheDirFlag : should return the cache directory of the command", func(t *testing.T) {
+		cases := map[string][]string{
+			"/var/cache/opm": {"/bin/opm", "serve", "/configs", "--cache-dir=/var/cache/opm"},
+			"/tmp/opm-cache": {"serve", "/configs", "--cache-dir", "/tmp/opm-cache"},
+			"":               {"serve", "/configs"},
+		}
+		for expected, cmd := range cases {
+			if dir := cacheDirFlag(cmd); dir != expected {
+				t.Errorf("expected %q for %v, got %q", expected, cmd, dir)
+			}
+		}
+	})
+
+	t.Run("Testing serveCache : should default when the image does not set the cache directory", func(t *testing.T) {
+		c := GCRCatalogBuilder{Logger: clog.New("trace")}
+		if dir, format := c.serveCache(common.TestFolder + "simple-test-bundle"); dir != defaultServeCacheDir || format != "" {
+			t.Errorf("expected %s without cache, got %s with cache %q", defaultServeCacheDir, dir, format)
+		}
+		if dir, format := c.serveCache(filepath.Join(t.TempDir(), "no-layout")); dir != defaultServeCacheDir || format != "" {
+			t.Errorf("expected %s without cache, got %s with cache %q", defaultServeCacheDir, dir, format)
+		}
+	})
+
+	t.Run("Testing imageCacheFormat : should return the format of the cache of the top most layer", func(t *testing.T) {
+		layer := func(files ...string) v1.Layer {
+			contents := map[string][]byte{}
+			for _, f := range files {
+				contents[f] = []byte("content")
+			}
+			l, err := crane.Layer(contents)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return l
+		}
+		cases := []struct {
+			cacheDir string
+			layers   []v1.Layer
+			expected string
+		}{
+			{defaultServeCacheDir, []v1.Layer{layer("configs/index.json"), layer("tmp/cache/pogreb.v1/digest")}, cache.FormatPogrebV1},
+			{"/var/cache/opm", []v1.Layer{layer("var/cache/opm/pogreb.v1/db/main.pix")}, cache.FormatPogrebV1},
+			// a cache of another format
+			{defaultServeCacheDir, []v1.Layer{layer("tmp/cache/digest", "tmp/cache/packages.json")}, "digest"},
+			// the cache is deleted by a later layer
+			{defaultServeCacheDir, []v1.Layer{layer("tmp/cache/pogreb.v1/digest"), layer("tmp/.wh.cache")}, ""},
+			{defaultServeCacheDir, []v1.Layer{layer("configs/index.json")}, ""},
+		}
+		for _, testCase := range cases {
+			img, err := mutate.AppendLayers(empty.Image, testCase.layers...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			format, err := imageCacheFormat(img, testCase.cacheDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if format != testCase.expected {
+				t.Errorf("expected format %q in %s, got %q", testCase.expected, testCase.cacheDir, format)
+			}
+		}
+	})
+}
@@ -2,6 +2,7 @@ package manifest
 
 import (
 	"context"
+	"time"
 
 	"github.com/containers/image/v5/types"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
@@ -15,4 +16,6 @@ type ManifestInterface interface {
 	GetReleaseSchema(filePath string) ([]v2alpha1.RelatedImage, error)
 	ConvertIndexToSingleManifest(dir string, oci *v2alpha1.OCISchema) error
 	GetDigest(ctx context.Context, sourceCtx *types.SystemContext, imgRef string) (string, error)
+	ListTags(ctx context.Context, sourceCtx *types.SystemContext, imgRef string) ([]string, error)
+	GetImageCreationDate(ctx context.Context, sourceCtx *types.SystemContext, imgRef string) (time.Time, error)
 }
@@ -15,7 +15,10 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/containers/image/v5/docker"
+	"github.com/containers/image/v5/image"
 	"github.com/containers/image/v5/manifest"
 	"github.com/containers/image/v5/transports/alltransports"
 	"github.com/containers/image/v5/types"
@@ -256,6 +259,49 @@ func (o Manifest) GetDigest(ctx context.Context, sourceCtx *types.SystemContext,
 	return digestString, nil
 }
 
+// ListTags returns the tags of the repository referenced by imgRef (docker transport only)
+func (o Manifest) ListTags(ctx context.Context, sourceCtx *types.SystemContext, imgRef string) ([]string, error) {
+	setInternalLog(o.Log)
+
+	srcRef, err := alltransports.ParseImageName(imgRef)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source name %s: %v", imgRef, err)
+	}
+	if srcRef.Transport().Name() != docker.Transport.Name() {
+		return nil, fmt.Errorf("listing tags is only supported for docker references: %s", imgRef)
+	}
+	return docker.GetRepositoryTags(ctx, sourceCtx, srcRef)
+}
+
+// GetImageCreationDate returns the creation date found in the image config of imgRef.
+// For manifest lists, the instance matching sourceCtx (os/arch) is used.
+func (o Manifest) GetImageCreationDate(ctx context.Context, sourceCtx *types.SystemContext, imgRef string) (time.Time, error) {
+	setInternalLog(o.Log)
+
+	srcRef, err := alltransports.ParseImageName(imgRef)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid source name %s: %v", imgRef, err)
+	}
+	src, err := srcRef.NewImageSource(ctx, sourceCtx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer src.Close()
+
+	img, err := image.FromSource(ctx, sourceCtx, src)
+	if err != nil {
+		return time.Time{}, err
+	}
+	info, err := img.Inspect(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if info.Created == nil {
+		return time.Time{}, fmt.Errorf("no creation date found for %s", imgRef)
+	}
+	return *info.Created, nil
+}
+
 func setInternalLog(log clog.PluggableLoggerInterface) {
 	if internalLog == nil {
 		internalLog = log
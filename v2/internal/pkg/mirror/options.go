@@ -57,6 +57,12 @@ type GlobalOptions struct {
 	DeleteYaml         string        // This flag will use the contents of the indicated yaml as basis to delete the local cache and remote registry
 	CacheDir           string        // Path to the cache directory
 	IsTerminal         bool          // Whether we're running in a terminal console or not
+	RegistriesConf     bool          // Generate a registries.conf.d drop-in under cluster-resources
+	PolicyJSON         bool          // Generate a policy.json matching the registries.conf.d drop-in
+	RegistriesWrapper  string        // Wrap the registries.conf.d drop-in (and policy.json) in a MachineConfig or a Butane config
+	RegistriesRole     string        // MachineConfigPool role targeted by the MachineConfig or Butane wrapper
+	HistoryBackend     string        // Registry repository (docker://) where the history of mirrored blobs is kept, instead of the working-dir
+	OperatorInstalls   bool          // Generate ClusterExtension, Subscription and OperatorGroup templates for the operator packages mirrored
 }
 
 type CopyOptions struct {
@@ -0,0 +1,48 @@
+package mirror
+
+import (
+	"fmt"
+
+	"github.com/containers/image/v5/pkg/docker/config"
+	"github.com/containers/image/v5/pkg/sysregistriesv2"
+	"github.com/containers/image/v5/types"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// IsInsecureRegistry is true when TLS verification is disabled in sysCtx, or for the registry of reference in registries.conf.
+// It fails when the registry is blocked in registries.conf.
+func IsInsecureRegistry(sysCtx *types.SystemContext, reference string) (bool, error) {
+	registry, err := sysregistriesv2.FindRegistry(sysCtx, reference)
+	if err != nil {
+		return false, err
+	}
+	if registry != nil && registry.Blocked {
+		return false, fmt.Errorf("registry %s is blocked in registries.conf", registry.Prefix)
+	}
+	if sysCtx != nil && sysCtx.DockerInsecureSkipTLSVerify == types.OptionalBoolTrue {
+		return true, nil
+	}
+	return registry != nil && registry.Insecure, nil
+}
+
+// CredentialsKeychain resolves the credentials of a registry from the system context
+// (--*-creds, --*-authfile, else the default auth files), as containers/image does when copying the images
+type CredentialsKeychain struct {
+	SysCtx *types.SystemContext
+}
+
+func (k CredentialsKeychain) Resolve(target authn.Resource) (authn.Authenticator, error) {
+	registry := target.RegistryStr()
+	if registry == name.DefaultRegistry {
+		registry = "docker.io"
+	}
+	creds, err := config.GetCredentials(k.SysCtx, registry)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the credentials of %s: %w", registry, err)
+	}
+	if creds == (types.DockerAuthConfig{}) {
+		return authn.Anonymous, nil
+	}
+	return authn.FromConfig(authn.AuthConfig{Username: creds.Username, Password: creds.Password, IdentityToken: creds.IdentityToken}), nil
+}
@@ -0,0 +1,63 @@
+package mirror
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/containers/image/v5/types"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryCredentials(t *testing.T) {
+	t.Run("Testing CredentialsKeychain : should use the credentials of the authfile", func(t *testing.T) {
+		authFile := filepath.Join(t.TempDir(), "auth.json")
+		auth := base64.StdEncoding.EncodeToString([]byte("mirror:secret"))
+		assert.NoError(t, os.WriteFile(authFile, []byte(`{"auths": {"registry.example.com": {"auth": "`+auth+`"}}}`), 0600))
+		keychain := CredentialsKeychain{SysCtx: &types.SystemContext{AuthFilePath: authFile}}
+
+		authenticator, err := keychain.Resolve(name.MustParseReference("registry.example.com/oc-mirror/history:latest").Context())
+		assert.NoError(t, err)
+		authConfig, err := authenticator.Authorization()
+		assert.NoError(t, err)
+		assert.Equal(t, "mirror", authConfig.Username)
+		assert.Equal(t, "secret", authConfig.Password)
+
+		authenticator, err = keychain.Resolve(name.MustParseReference("other.example.com/oc-mirror/history:latest").Context())
+		assert.NoError(t, err)
+		assert.Equal(t, authn.Anonymous, authenticator)
+	})
+
+	t.Run("Testing IsInsecureRegistry : should follow the tls verification and registries.conf", func(t *testing.T) {
+		registriesConf := filepath.Join(t.TempDir(), "registries.conf")
+		assert.NoError(t, os.WriteFile(registriesConf, []byte(`
+[[registry]]
+location = "insecure.example.com"
+insecure = true
+
+[[registry]]
+location = "blocked.example.com"
+blocked = true
+`), 0600))
+		sysCtx := &types.SystemContext{SystemRegistriesConfPath: registriesConf}
+
+		insecure, err := IsInsecureRegistry(sysCtx, "insecure.example.com/oc-mirror/history:latest")
+		assert.NoError(t, err)
+		assert.True(t, insecure)
+
+		insecure, err = IsInsecureRegistry(sysCtx, "registry.example.com/oc-mirror/history:latest")
+		assert.NoError(t, err)
+		assert.False(t, insecure)
+
+		sysCtx.DockerInsecureSkipTLSVerify = types.OptionalBoolTrue
+		insecure, err = IsInsecureRegistry(sysCtx, "registry.example.com/oc-mirror/history:latest")
+		assert.NoError(t, err)
+		assert.True(t, insecure)
+
+		_, err = IsInsecureRegistry(sysCtx, "blocked.example.com/oc-mirror/history:latest")
+		assert.Error(t, err)
+	})
+}
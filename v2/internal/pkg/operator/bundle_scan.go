@@ -0,0 +1,206 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/image"
+)
+
+// scanBundleImages returns, by bundle name, the images referenced in the manifests of the bundles
+// of the declarative config which are missing from their relatedImages.
+// The images found in a bundle are saved in the working-dir, under the directory of the catalog digest,
+// so that each bundle is pulled once, and so that diskToMirror mirrors the same images
+// without access to the source registries.
+// A bundle that cannot be scanned is reported, and mirrored with its relatedImages only.
+func (o FilterCollector) scanBundleImages(ctx context.Context, dc *declcfg.DeclarativeConfig, catalogDir string, copyImageSchemaMap *v2alpha1.CopyImageSchemaMap) map[string][]v2alpha1.RelatedImage {
+	scanDir := filepath.Join(catalogDir, operatorCatalogBundleScansDir)
+	if err := os.MkdirAll(scanDir, 0755); err != nil {
+		o.Log.Warn(collectorPrefix+"unable to scan the bundles: %v", err)
+		return nil
+	}
+
+	discovered := map[string][]v2alpha1.RelatedImage{}
+	for _, bundle := range dc.Bundles {
+		scanPath := filepath.Join(scanDir, bundle.Name+".json")
+		refs, err := readBundleScan(scanPath)
+		if errors.Is(err, os.ErrNotExist) {
+			if o.Opts.IsDiskToMirror() || o.Opts.IsDelete() {
+				o.Log.Warn(collectorPrefix+"bundle %s was not scanned during mirrorToDisk, only its relatedImages are mirrored", bundle.Name)
+				continue
+			}
+			o.Log.Debug(collectorPrefix+"scanning the manifests of bundle %s", bundle.Name)
+			refs, err = o.scanBundle(ctx, bundle, scanDir)
+			if err == nil {
+				err = writeBundleScan(scanPath, refs)
+			}
+		}
+		if err != nil {
+			o.Log.Warn(collectorPrefix+"unable to scan bundle %s, only its relatedImages are mirrored: %v", bundle.Name, err)
+			continue
+		}
+		if ris := o.discoveredImages(bundle, refs, copyImageSchemaMap); len(ris) > 0 {
+			discovered[bundle.Name] = ris
+		}
+	}
+	return discovered
+}
+
+// scanBundle pulls the bundle image in a temporary directory and returns the images referenced in its manifests
+func (o FilterCollector) scanBundle(ctx context.Context, bundle declcfg.Bundle, scanDir string) ([]declcfg.RelatedImage, error) {
+	bundleDir, err := os.MkdirTemp(scanDir, "bundle-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(bundleDir)
+
+	imageDir := filepath.Join(bundleDir, "image")
+	optsCopy := o.Opts
+	optsCopy.Stdout = io.Discard
+	if err := o.Mirror.Run(ctx, dockerProtocol+bundle.Image, ociProtocolTrimmed+imageDir, "copy", &optsCopy); err != nil {
+		return nil, fmt.Errorf("unable to pull %s: %w", bundle.Image, err)
+	}
+
+	oci, config, err := o.imageManifest(imageDir)
+	if err != nil {
+		return nil, err
+	}
+	label := config.Config.Labels.OperatorsOperatorframeworkIoBundleManifestsV1
+	if label == "" {
+		label = bundleManifestsDir
+	}
+	manifestsDir := filepath.Join(bundleDir, "content")
+	if err := o.Manifest.ExtractLayersOCI(filepath.Join(imageDir, blobsDir), manifestsDir, label, oci); err != nil {
+		return nil, err
+	}
+	return manifestImages(filepath.Join(manifestsDir, label))
+}
+
+// discoveredImages returns the images found in the manifests of the bundle which are not
+// the bundle image or one of its relatedImages
+func (o FilterCollector) discoveredImages(bundle declcfg.Bundle, refs []declcfg.RelatedImage, copyImageSchemaMap *v2alpha1.CopyImageSchemaMap) []v2alpha1.RelatedImage {
+	known := map[string]bool{bundle.Image: true}
+	for _, ri := range bundle.RelatedImages {
+		known[ri.Image] = true
+	}
+
+	var relatedImages []v2alpha1.RelatedImage
+	for _, ref := range refs {
+		if known[ref.Image] {
+			continue
+		}
+		imgSpec, err := image.ParseRef(ref.Image)
+		if err != nil {
+			o.Log.Debug(collectorPrefix+"bundle %s: skipping %s: %v", bundle.Name, ref.Image, err)
+			continue
+		}
+		o.Log.Debug(collectorPrefix+"bundle %s: image %s is missing from the relatedImages", bundle.Name, ref.Image)
+		addImageOwner(copyImageSchemaMap, imgSpec, bundle.Package, bundle)
+		relatedImages = append(relatedImages, v2alpha1.RelatedImage{Name: ref.Name, Image: ref.Image, Type: v2alpha1.TypeOperatorDiscoveredImage})
+	}
+	return relatedImages
+}
+
+// manifestImages returns the images referenced by the manifests of a bundle:
+// the containers of the deployments of the ClusterServiceVersion (or of any other workload manifest),
+// their RELATED_IMAGE_* environment variables, and the relatedImages of the ClusterServiceVersion.
+func manifestImages(manifestsDir string) ([]declcfg.RelatedImage, error) {
+	found := map[string]string{}
+	err := filepath.WalkDir(manifestsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !slices.Contains([]string{".yaml", ".yml", ".json"}, filepath.Ext(path)) {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		decoder := utilyaml.NewYAMLOrJSONDecoder(f, 4096)
+		for {
+			var obj interface{}
+			if err := decoder.Decode(&obj); err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				return fmt.Errorf("unable to parse %s: %w", path, err)
+			}
+			collectImages(obj, found)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	refs := []declcfg.RelatedImage{}
+	for _, img := range sortedKeys(found) {
+		refs = append(refs, declcfg.RelatedImage{Name: found[img], Image: img})
+	}
+	return refs, nil
+}
+
+// collectImages walks a manifest and adds to found the image of each container (or relatedImage),
+// and the value of each RELATED_IMAGE_* environment variable, along with their name
+func collectImages(node interface{}, found map[string]string) {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		name, _ := n["name"].(string)
+		if img, ok := n["image"].(string); ok {
+			addFoundImage(found, name, img)
+		}
+		if value, ok := n["value"].(string); ok && strings.HasPrefix(name, relatedImageEnvPrefix) {
+			addFoundImage(found, strings.ToLower(strings.TrimPrefix(name, relatedImageEnvPrefix)), value)
+		}
+		for _, v := range n {
+			collectImages(v, found)
+		}
+	case []interface{}:
+		for _, v := range n {
+			collectImages(v, found)
+		}
+	}
+}
+
+func addFoundImage(found map[string]string, name, img string) {
+	img = strings.TrimSpace(img)
+	if img == "" {
+		return
+	}
+	if _, ok := found[img]; !ok || found[img] == "" {
+		found[img] = name
+	}
+}
+
+func readBundleScan(path string) ([]declcfg.RelatedImage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var refs []declcfg.RelatedImage
+	if err := json.Unmarshal(data, &refs); err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", path, err)
+	}
+	return refs, nil
+}
+
+func writeBundleScan(path string, refs []declcfg.RelatedImage) error {
+	data, err := json.Marshal(refs)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
@@ -0,0 +1,147 @@
+package operator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+)
+
+const testCSV = `apiVersion: operators.coreos.com/v1alpha1
+kind: ClusterServiceVersion
+metadata:
+  name: database.v1.0.0
+  annotations:
+    containerImage: quay.io/example/database-operator:v1.0.0
+spec:
+  install:
+    strategy: deployment
+    spec:
+      deployments:
+      - name: database-operator
+        spec:
+          template:
+            spec:
+              initContainers:
+              - name: init
+                image: quay.io/example/database-init:v1.0.0
+              containers:
+              - name: manager
+                image: quay.io/example/database-operator:v1.0.0
+                env:
+                - name: RELATED_IMAGE_POSTGRES
+                  value: quay.io/example/postgres:15
+                - name: WATCH_NAMESPACE
+                  value: ""
+  relatedImages:
+  - name: backup
+    image: quay.io/example/database-backup:v1.0.0
+`
+
+const testCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: databases.example.com
+spec:
+  versions:
+  - name: v1
+    schema:
+      openAPIV3Schema:
+        properties:
+          image:
+            type: string
+`
+
+func TestManifestImages(t *testing.T) {
+	manifestsDir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(manifestsDir, "database.clusterserviceversion.yaml"), []byte(testCSV), 0600))
+	assert.NoError(t, os.WriteFile(filepath.Join(manifestsDir, "databases.crd.yaml"), []byte(testCRD), 0600))
+	assert.NoError(t, os.WriteFile(filepath.Join(manifestsDir, "README"), []byte("image: quay.io/example/not-a-manifest:v1"), 0600))
+
+	t.Run("Testing manifestImages : should return the images of the containers, environment variables and relatedImages", func(t *testing.T) {
+		refs, err := manifestImages(manifestsDir)
+		assert.NoError(t, err)
+		assert.Equal(t, []declcfg.RelatedImage{
+			{Name: "backup", Image: "quay.io/example/database-backup:v1.0.0"},
+			{Name: "init", Image: "quay.io/example/database-init:v1.0.0"},
+			{Name: "manager", Image: "quay.io/example/database-operator:v1.0.0"},
+			{Name: "postgres", Image: "quay.io/example/postgres:15"},
+		}, refs)
+	})
+
+	t.Run("Testing manifestImages : should fail on an invalid manifest", func(t *testing.T) {
+		assert.NoError(t, os.WriteFile(filepath.Join(manifestsDir, "invalid.yaml"), []byte("kind: [Service"), 0600))
+		_, err := manifestImages(manifestsDir)
+		assert.ErrorContains(t, err, "invalid.yaml")
+	})
+}
+
+func TestScanBundleImages(t *testing.T) {
+	log := clog.New("trace")
+	dc := &declcfg.DeclarativeConfig{
+		Bundles: []declcfg.Bundle{
+			{
+				Name:    "database.v1.0.0",
+				Package: "database",
+				Image:   "quay.io/example/database-bundle:v1.0.0",
+				RelatedImages: []declcfg.RelatedImage{
+					{Image: "quay.io/example/database-bundle:v1.0.0"},
+					{Name: "manager", Image: "quay.io/example/database-operator:v1.0.0"},
+				},
+			},
+		},
+	}
+	scan := []declcfg.RelatedImage{
+		{Name: "init", Image: "quay.io/example/database-init:v1.0.0"},
+		{Name: "manager", Image: "quay.io/example/database-operator:v1.0.0"},
+		{Name: "postgres", Image: "quay.io/example/postgres:15"},
+	}
+
+	t.Run("Testing scanBundleImages : should return the images missing from the relatedImages of the bundles", func(t *testing.T) {
+		tempDir := t.TempDir()
+		ex := setupFilterCollector_DiskToMirror(tempDir, log)
+		catalogDir := filepath.Join(tempDir, "working-dir", operatorCatalogsDir, "redhat-operator-index", "digest")
+		assert.NoError(t, os.MkdirAll(filepath.Join(catalogDir, operatorCatalogBundleScansDir), 0755))
+		assert.NoError(t, writeBundleScan(filepath.Join(catalogDir, operatorCatalogBundleScansDir, "database.v1.0.0.json"), scan))
+
+		copyImageSchemaMap := &v2alpha1.CopyImageSchemaMap{OperatorsByImage: make(map[string]map[string]struct{}), BundlesByImage: make(map[string]map[string]string)}
+		discovered := ex.scanBundleImages(context.TODO(), dc, catalogDir, copyImageSchemaMap)
+		assert.Equal(t, map[string][]v2alpha1.RelatedImage{
+			"database.v1.0.0": {
+				{Name: "init", Image: "quay.io/example/database-init:v1.0.0", Type: v2alpha1.TypeOperatorDiscoveredImage},
+				{Name: "postgres", Image: "quay.io/example/postgres:15", Type: v2alpha1.TypeOperatorDiscoveredImage},
+			},
+		}, discovered)
+		assert.Contains(t, copyImageSchemaMap.OperatorsByImage["docker://quay.io/example/postgres:15"], "database")
+		assert.Equal(t, "database.v1.0.0", copyImageSchemaMap.BundlesByImage["docker://quay.io/example/postgres:15"]["quay.io/example/database-bundle:v1.0.0"])
+	})
+
+	t.Run("Testing scanBundleImages : diskToMirror should not pull the bundles not scanned", func(t *testing.T) {
+		tempDir := t.TempDir()
+		ex := setupFilterCollector_DiskToMirror(tempDir, log)
+		ex.Mirror = MockMirror{Fail: true}
+		copyImageSchemaMap := &v2alpha1.CopyImageSchemaMap{OperatorsByImage: make(map[string]map[string]struct{}), BundlesByImage: make(map[string]map[string]string)}
+		discovered := ex.scanBundleImages(context.TODO(), dc, filepath.Join(tempDir, "catalog"), copyImageSchemaMap)
+		assert.Empty(t, discovered)
+	})
+
+	t.Run("Testing scanBundleImages : should skip the bundles that cannot be pulled", func(t *testing.T) {
+		tempDir := t.TempDir()
+		ex := setupFilterCollector_MirrorToDisk(tempDir, log, &MockManifest{})
+		ex.Mirror = MockMirror{Fail: true}
+		catalogDir := filepath.Join(tempDir, "catalog")
+		copyImageSchemaMap := &v2alpha1.CopyImageSchemaMap{OperatorsByImage: make(map[string]map[string]struct{}), BundlesByImage: make(map[string]map[string]string)}
+		discovered := ex.scanBundleImages(context.TODO(), dc, catalogDir, copyImageSchemaMap)
+		assert.Empty(t, discovered)
+		assert.NoFileExists(t, filepath.Join(catalogDir, operatorCatalogBundleScansDir, "database.v1.0.0.json"))
+		entries, err := os.ReadDir(filepath.Join(catalogDir, operatorCatalogBundleScansDir))
+		assert.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+}
@@ -0,0 +1,192 @@
+package operator
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+)
+
+// channelGraph holds the upgrade edges between the entries of a channel
+type channelGraph struct {
+	entries []string
+	// older maps an entry to the entries it replaces or skips, newer is the reverse
+	older map[string][]string
+	newer map[string][]string
+}
+
+func newChannelGraph(ch declcfg.Channel) channelGraph {
+	graph := channelGraph{older: map[string][]string{}, newer: map[string][]string{}}
+	for _, entry := range ch.Entries {
+		graph.entries = append(graph.entries, entry.Name)
+		for _, replaced := range append([]string{entry.Replaces}, entry.Skips...) {
+			if replaced == "" {
+				continue
+			}
+			graph.older[entry.Name] = append(graph.older[entry.Name], replaced)
+			graph.newer[replaced] = append(graph.newer[replaced], entry.Name)
+		}
+	}
+	slices.Sort(graph.entries)
+	return graph
+}
+
+// reachable returns start and all the names reachable from it following edges
+func reachable(start string, edges map[string][]string) map[string]bool {
+	visited := map[string]bool{start: true}
+	queue := []string{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range edges[current] {
+			if !visited[next] {
+				visited[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return visited
+}
+
+// selectBundles returns a copy of the catalog filter where, for each package filtered by bundles,
+// the bundles selected are completed with the channel entries connecting them, unless SkipUpgradePath is set.
+// It fails when a selected bundle is not found in the catalog.
+func selectBundles(dc declcfg.DeclarativeConfig, op v2alpha1.Operator) (v2alpha1.Operator, error) {
+	packages := make([]v2alpha1.IncludePackage, 0, len(op.Packages))
+	for _, pkg := range op.Packages {
+		if len(pkg.SelectedBundles) == 0 {
+			packages = append(packages, pkg)
+			continue
+		}
+		inCatalog := map[string]bool{}
+		for _, b := range dc.Bundles {
+			if b.Package == pkg.Name {
+				inCatalog[b.Name] = true
+			}
+		}
+		missing := []string{}
+		selected := map[string]bool{}
+		for _, b := range pkg.SelectedBundles {
+			if !inCatalog[b.Name] {
+				missing = append(missing, b.Name)
+			}
+			selected[b.Name] = true
+		}
+		if len(missing) > 0 {
+			return op, fmt.Errorf("catalog %q: operator %q: bundles %s not found in the catalog", op.Catalog, pkg.Name, strings.Join(missing, ", "))
+		}
+		if !pkg.SkipUpgradePath {
+			connectSelectedBundles(dc, pkg.Name, selected)
+		}
+
+		names := make([]string, 0, len(selected))
+		for name := range selected {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		pkg.SelectedBundles = make([]v2alpha1.SelectedBundle, 0, len(names))
+		for _, name := range names {
+			pkg.SelectedBundles = append(pkg.SelectedBundles, v2alpha1.SelectedBundle{Name: name})
+		}
+		packages = append(packages, pkg)
+	}
+	op.Packages = packages
+	return op, nil
+}
+
+// connectSelectedBundles adds to selected the entries needed for each channel of the package
+// to keep a single head. As the selection applies to all the channels of the package,
+// entries added for a channel may in turn need connecting in another channel.
+func connectSelectedBundles(dc declcfg.DeclarativeConfig, pkg string, selected map[string]bool) {
+	for added := true; added; {
+		added = false
+		for _, ch := range dc.Channels {
+			if ch.Package != pkg {
+				continue
+			}
+			for _, name := range connectingEntries(newChannelGraph(ch), selected) {
+				if !selected[name] {
+					selected[name] = true
+					added = true
+				}
+			}
+		}
+	}
+}
+
+// connectingEntries returns the entries of the channel lying between the selected ones,
+// and, when the selected entries still have several heads, the entries from their
+// closest common upgrade to each of these heads
+func connectingEntries(graph channelGraph, selected map[string]bool) []string {
+	kept := map[string]bool{}
+	for _, name := range graph.entries {
+		if selected[name] {
+			kept[name] = true
+		}
+	}
+	if len(kept) < 2 {
+		return nil
+	}
+	olderThan := map[string]map[string]bool{}
+	newerThan := map[string]map[string]bool{}
+	for _, name := range graph.entries {
+		olderThan[name] = reachable(name, graph.older)
+		newerThan[name] = reachable(name, graph.newer)
+	}
+
+	// between keeps the entries older than one of newest and newer than one of oldest
+	between := func(newest, oldest map[string]bool) {
+		for _, name := range graph.entries {
+			if kept[name] || !anyOf(newest, func(n string) bool { return olderThan[n][name] }) {
+				continue
+			}
+			if anyOf(oldest, func(o string) bool { return newerThan[o][name] }) {
+				kept[name] = true
+			}
+		}
+	}
+	between(maps.Clone(kept), maps.Clone(kept))
+
+	heads := map[string]bool{}
+	for name := range kept {
+		if !slices.ContainsFunc(graph.newer[name], func(n string) bool { return kept[n] }) {
+			heads[name] = true
+		}
+	}
+	if len(heads) > 1 {
+		common := []string{}
+		for _, name := range graph.entries {
+			if !anyOf(heads, func(head string) bool { return !newerThan[head][name] }) {
+				common = append(common, name)
+			}
+		}
+		// the closest common upgrade is older than no other common upgrade
+		for _, candidate := range common {
+			if !slices.ContainsFunc(common, func(other string) bool { return other != candidate && olderThan[candidate][other] }) {
+				between(map[string]bool{candidate: true}, heads)
+				break
+			}
+		}
+	}
+
+	result := []string{}
+	for _, name := range graph.entries {
+		if kept[name] && !selected[name] {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+func anyOf(set map[string]bool, predicate func(string) bool) bool {
+	for name := range set {
+		if predicate(name) {
+			return true
+		}
+	}
+	return false
+}
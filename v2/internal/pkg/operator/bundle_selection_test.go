@@ -0,0 +1,135 @@
+package operator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+)
+
+func bundleSelectionCatalog() declcfg.DeclarativeConfig {
+	return declcfg.DeclarativeConfig{
+		Packages: []declcfg.Package{
+			{Schema: declcfg.SchemaPackage, Name: "database", DefaultChannel: "stable"},
+			{Schema: declcfg.SchemaPackage, Name: "proxy", DefaultChannel: "stable"},
+		},
+		Channels: []declcfg.Channel{
+			testChannel("database", "stable",
+				declcfg.ChannelEntry{Name: "database.v1.0.0"},
+				declcfg.ChannelEntry{Name: "database.v1.1.0", Replaces: "database.v1.0.0"},
+				declcfg.ChannelEntry{Name: "database.v1.2.0", Replaces: "database.v1.1.0"},
+				declcfg.ChannelEntry{Name: "database.v2.0.0", Replaces: "database.v1.2.0"},
+			),
+			testChannel("database", "fast", declcfg.ChannelEntry{Name: "database.v2.0.0"}),
+			// proxy.v2.0.0-a and proxy.v2.0.0-b are both upgrades of proxy.v1.0.0
+			testChannel("proxy", "stable",
+				declcfg.ChannelEntry{Name: "proxy.v1.0.0"},
+				declcfg.ChannelEntry{Name: "proxy.v2.0.0-a", Replaces: "proxy.v1.0.0"},
+				declcfg.ChannelEntry{Name: "proxy.v2.0.0-b", Skips: []string{"proxy.v1.0.0"}},
+				declcfg.ChannelEntry{Name: "proxy.v3.0.0", Replaces: "proxy.v2.0.0-a", Skips: []string{"proxy.v2.0.0-b"}},
+				declcfg.ChannelEntry{Name: "proxy.v4.0.0", Replaces: "proxy.v3.0.0"},
+			),
+		},
+		Bundles: []declcfg.Bundle{
+			testBundle("database", "1.0.0"),
+			testBundle("database", "1.1.0"),
+			testBundle("database", "1.2.0"),
+			testBundle("database", "2.0.0"),
+			testBundle("proxy", "1.0.0"),
+			testBundle("proxy", "2.0.0-a"),
+			testBundle("proxy", "2.0.0-b"),
+			testBundle("proxy", "3.0.0"),
+			testBundle("proxy", "4.0.0"),
+		},
+	}
+}
+
+func selection(names ...string) []v2alpha1.SelectedBundle {
+	bundles := []v2alpha1.SelectedBundle{}
+	for _, name := range names {
+		bundles = append(bundles, v2alpha1.SelectedBundle{Name: name})
+	}
+	return bundles
+}
+
+func TestSelectBundles(t *testing.T) {
+	type testCase struct {
+		caseName        string
+		pkg             v2alpha1.IncludePackage
+		expectedBundles []v2alpha1.SelectedBundle
+		expectedError   string
+	}
+	testCases := []testCase{
+		{
+			caseName:        "Testing selectBundles : should add the entries between the selected bundles",
+			pkg:             v2alpha1.IncludePackage{Name: "database", SelectedBundles: selection("database.v1.2.0", "database.v1.0.0")},
+			expectedBundles: selection("database.v1.0.0", "database.v1.1.0", "database.v1.2.0"),
+		},
+		{
+			caseName:        "Testing selectBundles : should add the closest common upgrade of the selected bundles",
+			pkg:             v2alpha1.IncludePackage{Name: "proxy", SelectedBundles: selection("proxy.v2.0.0-a", "proxy.v2.0.0-b")},
+			expectedBundles: selection("proxy.v2.0.0-a", "proxy.v2.0.0-b", "proxy.v3.0.0"),
+		},
+		{
+			caseName:        "Testing selectBundles : should keep the selection as is when skipUpgradePath is set",
+			pkg:             v2alpha1.IncludePackage{Name: "database", SelectedBundles: selection("database.v1.0.0", "database.v1.2.0"), SkipUpgradePath: true},
+			expectedBundles: selection("database.v1.0.0", "database.v1.2.0"),
+		},
+		{
+			caseName:      "Testing selectBundles : should fail when a bundle is not in the catalog",
+			pkg:           v2alpha1.IncludePackage{Name: "database", SelectedBundles: selection("database.v1.0.0", "database.v9.9.9", "proxy.v1.0.0")},
+			expectedError: "catalog \"registry.redhat.io/redhat/redhat-operator-index:v4.16\": operator \"database\": bundles database.v9.9.9, proxy.v1.0.0 not found in the catalog",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			op := v2alpha1.Operator{
+				Catalog:       "registry.redhat.io/redhat/redhat-operator-index:v4.16",
+				IncludeConfig: v2alpha1.IncludeConfig{Packages: []v2alpha1.IncludePackage{testCase.pkg}},
+			}
+			res, err := selectBundles(bundleSelectionCatalog(), op)
+			if testCase.expectedError != "" {
+				assert.EqualError(t, err, testCase.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedBundles, res.Packages[0].SelectedBundles)
+			// the filter of the imageset configuration is left untouched
+			assert.Equal(t, testCase.pkg.SelectedBundles, op.Packages[0].SelectedBundles)
+		})
+	}
+}
+
+func TestFilterCatalogSelectedBundles(t *testing.T) {
+	op := v2alpha1.Operator{
+		Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.16",
+		IncludeConfig: v2alpha1.IncludeConfig{Packages: []v2alpha1.IncludePackage{
+			{Name: "database", SelectedBundles: selection("database.v1.0.0", "database.v1.2.0")},
+		}},
+	}
+
+	t.Run("Testing filterCatalog : should keep the selected bundles and their upgrade path", func(t *testing.T) {
+		res, _, err := filterCatalog(context.TODO(), bundleSelectionCatalog(), op)
+		assert.NoError(t, err)
+		names := []string{}
+		for _, b := range res.Bundles {
+			names = append(names, b.Name)
+		}
+		assert.ElementsMatch(t, []string{"database.v1.0.0", "database.v1.1.0", "database.v1.2.0"}, names)
+		// the fast channel has none of the bundles selected
+		assert.Equal(t, []declcfg.Channel{testChannel("database", "stable",
+			declcfg.ChannelEntry{Name: "database.v1.0.0"},
+			declcfg.ChannelEntry{Name: "database.v1.1.0", Replaces: "database.v1.0.0"},
+			declcfg.ChannelEntry{Name: "database.v1.2.0", Replaces: "database.v1.1.0"},
+		)}, res.Channels)
+	})
+
+	t.Run("Testing filterCatalog : should fail on a channel with several heads when skipUpgradePath is set", func(t *testing.T) {
+		op.Packages[0].SkipUpgradePath = true
+		_, _, err := filterCatalog(context.TODO(), bundleSelectionCatalog(), op)
+		assert.ErrorContains(t, err, "filtering on the selected bundles leads to invalidating channel \"stable\" for package \"database\"")
+	})
+}
@@ -0,0 +1,276 @@
+package operator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+)
+
+// catalogDiff holds the changes, for the packages mirrored, between the filtered catalogs of two digests of a catalog
+type catalogDiff struct {
+	Catalog        string        `json:"catalog"`
+	PreviousDigest string        `json:"previousDigest"`
+	Digest         string        `json:"digest"`
+	Packages       []packageDiff `json:"packages"`
+}
+
+type packageDiff struct {
+	Name string `json:"name"`
+	// Status is set when the whole package was added or removed
+	Status          string           `json:"status,omitempty"`
+	DefaultChannel  *valueChange     `json:"defaultChannel,omitempty"`
+	AddedChannels   []string         `json:"addedChannels,omitempty"`
+	RemovedChannels []string         `json:"removedChannels,omitempty"`
+	ChannelHeads    []headChange     `json:"channelHeads,omitempty"`
+	AddedBundles    []string         `json:"addedBundles,omitempty"`
+	RemovedBundles  []string         `json:"removedBundles,omitempty"`
+	Deprecations    []deprecatedItem `json:"deprecations,omitempty"`
+}
+
+type valueChange struct {
+	Previous string `json:"previous"`
+	Current  string `json:"current"`
+}
+
+type headChange struct {
+	Channel string `json:"channel"`
+	valueChange
+}
+
+// deprecatedItem is a deprecation that was not in the previous catalog
+type deprecatedItem struct {
+	// Schema is olm.package, olm.channel or olm.bundle
+	Schema  string `json:"schema"`
+	Name    string `json:"name,omitempty"`
+	Message string `json:"message"`
+}
+
+const (
+	packageAdded   = "added"
+	packageRemoved = "removed"
+)
+
+// packageContent gathers the content of a package to compare
+type packageContent struct {
+	defaultChannel string
+	heads          map[string]string
+	bundles        map[string]bool
+	deprecations   map[string]declcfg.DeprecationEntry
+}
+
+func packageContents(dc declcfg.DeclarativeConfig) map[string]*packageContent {
+	contents := map[string]*packageContent{}
+	content := func(name string) *packageContent {
+		if _, ok := contents[name]; !ok {
+			contents[name] = &packageContent{heads: map[string]string{}, bundles: map[string]bool{}, deprecations: map[string]declcfg.DeprecationEntry{}}
+		}
+		return contents[name]
+	}
+	for _, pkg := range dc.Packages {
+		content(pkg.Name).defaultChannel = pkg.DefaultChannel
+	}
+	for _, ch := range dc.Channels {
+		content(ch.Package).heads[ch.Name] = upgradeHead(ch)
+	}
+	for _, b := range dc.Bundles {
+		content(b.Package).bundles[b.Name] = true
+	}
+	for _, deprecation := range dc.Deprecations {
+		for _, entry := range deprecation.Entries {
+			content(deprecation.Package).deprecations[entry.Reference.Schema+"/"+entry.Reference.Name] = entry
+		}
+	}
+	return contents
+}
+
+// upgradeHead returns the entries of the channel that no other entry replaces or skips:
+// unlike channelHead, it relies on the upgrade edges rather than on the versions
+func upgradeHead(ch declcfg.Channel) string {
+	graph := newChannelGraph(ch)
+	heads := []string{}
+	for _, name := range graph.entries {
+		if len(graph.newer[name]) == 0 {
+			heads = append(heads, name)
+		}
+	}
+	return strings.Join(heads, ", ")
+}
+
+// diffCatalogs compares the filtered catalogs of two digests of a catalog
+func diffCatalogs(previous, current declcfg.DeclarativeConfig) []packageDiff {
+	previousContents := packageContents(previous)
+	currentContents := packageContents(current)
+
+	names := []string{}
+	for name := range previousContents {
+		names = append(names, name)
+	}
+	for name := range currentContents {
+		if _, ok := previousContents[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	diffs := []packageDiff{}
+	for _, name := range names {
+		before, wasThere := previousContents[name]
+		after, isThere := currentContents[name]
+		diff := packageDiff{Name: name}
+		switch {
+		case !wasThere:
+			diff.Status = packageAdded
+			before = &packageContent{}
+		case !isThere:
+			diff.Status = packageRemoved
+			after = &packageContent{}
+		}
+		if wasThere && isThere && before.defaultChannel != after.defaultChannel {
+			diff.DefaultChannel = &valueChange{Previous: before.defaultChannel, Current: after.defaultChannel}
+		}
+		diff.AddedChannels, diff.RemovedChannels = addedAndRemoved(before.heads, after.heads)
+		for _, ch := range sortedKeys(after.heads) {
+			if head, ok := before.heads[ch]; ok && head != after.heads[ch] {
+				diff.ChannelHeads = append(diff.ChannelHeads, headChange{Channel: ch, valueChange: valueChange{Previous: head, Current: after.heads[ch]}})
+			}
+		}
+		diff.AddedBundles, diff.RemovedBundles = addedAndRemoved(before.bundles, after.bundles)
+		for _, key := range sortedKeys(after.deprecations) {
+			if _, ok := before.deprecations[key]; !ok {
+				entry := after.deprecations[key]
+				diff.Deprecations = append(diff.Deprecations, deprecatedItem{Schema: entry.Reference.Schema, Name: entry.Reference.Name, Message: entry.Message})
+			}
+		}
+		if diff.Status != "" || diff.DefaultChannel != nil || len(diff.AddedChannels)+len(diff.RemovedChannels)+len(diff.ChannelHeads)+
+			len(diff.AddedBundles)+len(diff.RemovedBundles)+len(diff.Deprecations) > 0 {
+			diffs = append(diffs, diff)
+		}
+	}
+	return diffs
+}
+
+func addedAndRemoved[V any](before, after map[string]V) ([]string, []string) {
+	var added, removed []string
+	for _, key := range sortedKeys(after) {
+		if _, ok := before[key]; !ok {
+			added = append(added, key)
+		}
+	}
+	for _, key := range sortedKeys(before) {
+		if _, ok := after[key]; !ok {
+			removed = append(removed, key)
+		}
+	}
+	return added, removed
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// markdown renders the diff for a review by the operator owners
+func (d catalogDiff) markdown() string {
+	var md bytes.Buffer
+	fmt.Fprintf(&md, "# Catalog %s\n\n", d.Catalog)
+	fmt.Fprintf(&md, "Changes of the packages mirrored between catalog digests `%s` and `%s`.\n", d.PreviousDigest, d.Digest)
+	if len(d.Packages) == 0 {
+		md.WriteString("\nNo change.\n")
+	}
+	list := func(title string, items []string) {
+		if len(items) > 0 {
+			fmt.Fprintf(&md, "- %s: %s\n", title, strings.Join(items, ", "))
+		}
+	}
+	for _, pkg := range d.Packages {
+		fmt.Fprintf(&md, "\n## %s\n\n", pkg.Name)
+		if pkg.Status != "" {
+			fmt.Fprintf(&md, "- package %s\n", pkg.Status)
+		}
+		if pkg.DefaultChannel != nil {
+			fmt.Fprintf(&md, "- default channel: %s -> %s\n", pkg.DefaultChannel.Previous, pkg.DefaultChannel.Current)
+		}
+		list("channels added", pkg.AddedChannels)
+		list("channels removed", pkg.RemovedChannels)
+		for _, head := range pkg.ChannelHeads {
+			fmt.Fprintf(&md, "- head of channel %s: %s -> %s\n", head.Channel, head.Previous, head.Current)
+		}
+		list("bundles added", pkg.AddedBundles)
+		list("bundles removed", pkg.RemovedBundles)
+		for _, deprecation := range pkg.Deprecations {
+			name := deprecation.Name
+			if name == "" {
+				name = pkg.Name
+			}
+			fmt.Fprintf(&md, "- deprecated %s %s: %s\n", strings.TrimPrefix(deprecation.Schema, "olm."), name, deprecation.Message)
+		}
+	}
+	return md.String()
+}
+
+// reportCatalogDiff writes, when the catalog digest changed since the catalog was last filtered with the same filter,
+// the changes of the filtered catalog in the working-dir, as json and markdown:
+// working-dir/operator-catalogs/<name>/<catalog digest>/catalog-diffs/<filter digest>.{json,md}
+// The catalog digest last filtered is kept in working-dir/operator-catalogs/<name>/last-filtered/<filter digest>
+func (o FilterCollector) reportCatalogDiff(catalog, componentName, catalogDigest, filterDigest string, filteredDC declcfg.DeclarativeConfig) (string, error) {
+	catalogDir := filepath.Join(o.Opts.Global.WorkingDir, operatorCatalogsDir, componentName)
+	lastFilteredPath := filepath.Join(catalogDir, operatorCatalogLastFilteredDir, filterDigest)
+
+	previousDigest, err := os.ReadFile(lastFilteredPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	if err := createFolders([]string{filepath.Dir(lastFilteredPath)}); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(lastFilteredPath, []byte(catalogDigest), 0600); err != nil {
+		return "", err
+	}
+	if len(previousDigest) == 0 || string(previousDigest) == catalogDigest {
+		return "", nil
+	}
+
+	previousConfigDir := filepath.Join(catalogDir, string(previousDigest), operatorCatalogFilteredDir, filterDigest, operatorCatalogConfigDir)
+	if _, err := os.Stat(previousConfigDir); err != nil {
+		o.Log.Debug(collectorPrefix+"no catalog diff for %s: %v", catalog, err)
+		return "", nil
+	}
+	previousDC, err := o.ctlgHandler.getDeclarativeConfig(previousConfigDir)
+	if err != nil {
+		return "", err
+	}
+
+	diff := catalogDiff{
+		Catalog:        catalog,
+		PreviousDigest: string(previousDigest),
+		Digest:         catalogDigest,
+		Packages:       diffCatalogs(*previousDC, filteredDC),
+	}
+	diffDir := filepath.Join(catalogDir, catalogDigest, operatorCatalogDiffDir)
+	if err := createFolders([]string{diffDir}); err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(diff, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(diffDir, filterDigest+".json"), data, 0600); err != nil {
+		return "", err
+	}
+	reportPath := filepath.Join(diffDir, filterDigest+".md")
+	if err := os.WriteFile(reportPath, []byte(diff.markdown()), 0600); err != nil {
+		return "", err
+	}
+	return reportPath, nil
+}
@@ -0,0 +1,132 @@
+package operator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/stretchr/testify/assert"
+
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+)
+
+func TestDiffCatalogs(t *testing.T) {
+	previous := declcfg.DeclarativeConfig{
+		Packages: []declcfg.Package{
+			{Schema: declcfg.SchemaPackage, Name: "database", DefaultChannel: "stable"},
+			{Schema: declcfg.SchemaPackage, Name: "proxy", DefaultChannel: "stable"},
+			{Schema: declcfg.SchemaPackage, Name: "storage", DefaultChannel: "stable"},
+		},
+		Channels: []declcfg.Channel{
+			testChannel("database", "stable", declcfg.ChannelEntry{Name: "database.v1.0.0"}),
+			testChannel("database", "beta", declcfg.ChannelEntry{Name: "database.v1.0.0"}),
+			testChannel("proxy", "stable", declcfg.ChannelEntry{Name: "proxy.v1.0.0"}),
+			testChannel("storage", "stable", declcfg.ChannelEntry{Name: "storage.v1.0.0"}),
+		},
+		Bundles: []declcfg.Bundle{
+			testBundle("database", "1.0.0"),
+			testBundle("proxy", "1.0.0"),
+			testBundle("storage", "1.0.0"),
+		},
+	}
+	current := declcfg.DeclarativeConfig{
+		Packages: []declcfg.Package{
+			{Schema: declcfg.SchemaPackage, Name: "database", DefaultChannel: "fast"},
+			{Schema: declcfg.SchemaPackage, Name: "proxy", DefaultChannel: "stable"},
+			{Schema: declcfg.SchemaPackage, Name: "certs", DefaultChannel: "stable"},
+		},
+		Channels: []declcfg.Channel{
+			testChannel("database", "stable",
+				declcfg.ChannelEntry{Name: "database.v1.0.0"},
+				declcfg.ChannelEntry{Name: "database.v1.1.0", Replaces: "database.v1.0.0"},
+			),
+			testChannel("database", "fast", declcfg.ChannelEntry{Name: "database.v1.1.0"}),
+			testChannel("proxy", "stable", declcfg.ChannelEntry{Name: "proxy.v1.0.0"}),
+			testChannel("certs", "stable", declcfg.ChannelEntry{Name: "certs.v1.0.0"}),
+		},
+		Bundles: []declcfg.Bundle{
+			testBundle("database", "1.0.0"),
+			testBundle("database", "1.1.0"),
+			testBundle("proxy", "1.0.0"),
+			testBundle("certs", "1.0.0"),
+		},
+		Deprecations: []declcfg.Deprecation{
+			{Schema: declcfg.SchemaDeprecation, Package: "database", Entries: []declcfg.DeprecationEntry{
+				{Reference: declcfg.PackageScopedReference{Schema: declcfg.SchemaBundle, Name: "database.v1.0.0"}, Message: "database.v1.0.0 is deprecated"},
+			}},
+		},
+	}
+
+	t.Run("Testing diffCatalogs : should report the changes of each package", func(t *testing.T) {
+		assert.Equal(t, []packageDiff{
+			{Name: "certs", Status: packageAdded, AddedChannels: []string{"stable"}, AddedBundles: []string{"certs.v1.0.0"}},
+			{
+				Name:            "database",
+				DefaultChannel:  &valueChange{Previous: "stable", Current: "fast"},
+				AddedChannels:   []string{"fast"},
+				RemovedChannels: []string{"beta"},
+				ChannelHeads:    []headChange{{Channel: "stable", valueChange: valueChange{Previous: "database.v1.0.0", Current: "database.v1.1.0"}}},
+				AddedBundles:    []string{"database.v1.1.0"},
+				Deprecations:    []deprecatedItem{{Schema: declcfg.SchemaBundle, Name: "database.v1.0.0", Message: "database.v1.0.0 is deprecated"}},
+			},
+			{Name: "storage", Status: packageRemoved, RemovedChannels: []string{"stable"}, RemovedBundles: []string{"storage.v1.0.0"}},
+		}, diffCatalogs(previous, current))
+	})
+
+	t.Run("Testing diffCatalogs : should report no change for the same catalog", func(t *testing.T) {
+		assert.Empty(t, diffCatalogs(current, current))
+	})
+}
+
+func TestReportCatalogDiff(t *testing.T) {
+	tempDir := t.TempDir()
+	ex := setupFilterCollector_MirrorToDisk(tempDir, clog.New("trace"), &MockManifest{})
+	catalogDir := filepath.Join(tempDir, "working-dir", operatorCatalogsDir, "redhat-operator-index")
+
+	// the mock handler renders the previous catalog with package op1, bundle abc
+	current := declcfg.DeclarativeConfig{
+		Packages: []declcfg.Package{{Name: "op1", DefaultChannel: "ch1"}},
+		Channels: []declcfg.Channel{{Name: "ch1", Package: "op1", Entries: []declcfg.ChannelEntry{{Name: "abc"}, {Name: "def", Replaces: "abc"}}}},
+		Bundles:  []declcfg.Bundle{{Name: "abc", Package: "op1"}, {Name: "def", Package: "op1"}},
+	}
+
+	t.Run("Testing reportCatalogDiff : should not report anything on the first filtering", func(t *testing.T) {
+		reportPath, err := ex.reportCatalogDiff("registry.redhat.io/redhat/redhat-operator-index:v4.16", "redhat-operator-index", "digest-1", "filter", current)
+		assert.NoError(t, err)
+		assert.Empty(t, reportPath)
+		lastDigest, err := os.ReadFile(filepath.Join(catalogDir, operatorCatalogLastFilteredDir, "filter"))
+		assert.NoError(t, err)
+		assert.Equal(t, "digest-1", string(lastDigest))
+	})
+
+	t.Run("Testing reportCatalogDiff : should not report anything when the previous catalog is missing", func(t *testing.T) {
+		reportPath, err := ex.reportCatalogDiff("registry.redhat.io/redhat/redhat-operator-index:v4.16", "redhat-operator-index", "digest-2", "filter", current)
+		assert.NoError(t, err)
+		assert.Empty(t, reportPath)
+	})
+
+	t.Run("Testing reportCatalogDiff : should report the changes since the digest last filtered", func(t *testing.T) {
+		assert.NoError(t, os.MkdirAll(filepath.Join(catalogDir, "digest-2", operatorCatalogFilteredDir, "filter", operatorCatalogConfigDir), 0755))
+		reportPath, err := ex.reportCatalogDiff("registry.redhat.io/redhat/redhat-operator-index:v4.16", "redhat-operator-index", "digest-3", "filter", current)
+		assert.NoError(t, err)
+		assert.Equal(t, filepath.Join(catalogDir, "digest-3", operatorCatalogDiffDir, "filter.md"), reportPath)
+
+		md, err := os.ReadFile(reportPath)
+		assert.NoError(t, err)
+		assert.Equal(t, "# Catalog registry.redhat.io/redhat/redhat-operator-index:v4.16\n\n"+
+			"Changes of the packages mirrored between catalog digests `digest-2` and `digest-3`.\n\n"+
+			"## op1\n\n"+
+			"- head of channel ch1: abc -> def\n"+
+			"- bundles added: def\n", string(md))
+
+		data, err := os.ReadFile(filepath.Join(catalogDir, "digest-3", operatorCatalogDiffDir, "filter.json"))
+		assert.NoError(t, err)
+		var diff catalogDiff
+		assert.NoError(t, json.Unmarshal(data, &diff))
+		assert.Equal(t, "digest-2", diff.PreviousDigest)
+		assert.Equal(t, "digest-3", diff.Digest)
+		assert.Len(t, diff.Packages, 1)
+	})
+}
@@ -78,6 +78,10 @@ func filterFromImageSetConfig(iscCatalogFilter v2alpha1.Operator) (filter.Filter
 			if op.MaxVersion != "" {
 				p.VersionRange += " <=" + op.MaxVersion
 			}
+			if p.VersionRange == "" && !iscCatalogFilter.Full && len(op.SelectedBundles) == 0 && (op.KeepLast > 0 || iscCatalogFilter.KeepLast > 0) {
+				// keep the whole channels rather than their heads, applyKeepLast trims them afterwards
+				p.VersionRange = keepLastVersionRange
+			}
 			if len(op.Channels) > 0 {
 				p.Channels = []filter.Channel{}
 				for _, ch := range op.Channels {
@@ -91,22 +95,52 @@ func filterFromImageSetConfig(iscCatalogFilter v2alpha1.Operator) (filter.Filter
 					if ch.MaxVersion != "" {
 						filterChan.VersionRange += " <=" + ch.MaxVersion
 					}
+					if filterChan.VersionRange == "" && p.VersionRange == "" && !iscCatalogFilter.Full && ch.KeepLast > 0 {
+						filterChan.VersionRange = keepLastVersionRange
+					}
 					p.Channels = append(p.Channels, filterChan)
 				}
 			}
+			for _, b := range op.SelectedBundles {
+				p.SelectedBundles = append(p.SelectedBundles, filter.SelectedBundle{Name: b.Name})
+			}
 			catFilter.Packages = append(catFilter.Packages, p)
 		}
 	}
 	return catFilter, catFilter.Validate()
 }
 
-func filterCatalog(ctx context.Context, operatorCatalog declcfg.DeclarativeConfig, iscCatalogFilter v2alpha1.Operator) (*declcfg.DeclarativeConfig, error) {
+// filterCatalog filters the catalog according to the imageset configuration, keeps the last entries of the channels
+// when keepLast is set, then applies its deprecation policy.
+// It returns the deprecations to report along with the filtered catalog.
+func filterCatalog(ctx context.Context, operatorCatalog declcfg.DeclarativeConfig, iscCatalogFilter v2alpha1.Operator) (*declcfg.DeclarativeConfig, []deprecationNotice, error) {
+	iscCatalogFilter, err := selectBundles(operatorCatalog, iscCatalogFilter)
+	if err != nil {
+		return nil, nil, err
+	}
 	config, err := filterFromImageSetConfig(iscCatalogFilter)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	// without packages, keepLast applies to the channels of the whole catalog
+	inFull := iscCatalogFilter.Full || (iscCatalogFilter.KeepLast > 0 && len(iscCatalogFilter.Packages) == 0)
+	ctlgFilter := filter.NewMirrorFilter(config, []filter.FilterOption{filter.InFull(inFull)}...)
+	// the filter prunes the deprecation entries in place, the deprecation policy needs the original ones
+	original := operatorCatalog
+	original.Deprecations = slices.Clone(operatorCatalog.Deprecations)
+	for i := range original.Deprecations {
+		original.Deprecations[i].Entries = slices.Clone(original.Deprecations[i].Entries)
 	}
-	ctlgFilter := filter.NewMirrorFilter(config, []filter.FilterOption{filter.InFull(iscCatalogFilter.Full)}...)
-	return ctlgFilter.FilterCatalog(ctx, &operatorCatalog)
+	filtered, err := ctlgFilter.FilterCatalog(ctx, &operatorCatalog)
+	if err != nil {
+		return nil, nil, err
+	}
+	applyKeepLast(filtered, iscCatalogFilter)
+	notices, err := applyDeprecationPolicy(original, filtered, iscCatalogFilter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return filtered, notices, nil
 }
 
 func (o catalogHandler) getCatalog(filePath string) (OperatorCatalog, error) {
@@ -466,25 +500,30 @@ func handleRelatedImages(bundle declcfg.Bundle, operatorName string, copyImageSc
 			return relatedImages, fmt.Errorf("error parsing image %s: %w", ri.Image, err)
 		}
 
-		operators := copyImageSchemaMap.OperatorsByImage[imgSpec.ReferenceWithTransport]
-
-		if _, found := operators[operatorName]; !found {
-			if operators == nil {
-				copyImageSchemaMap.OperatorsByImage[imgSpec.ReferenceWithTransport] = make(map[string]struct{})
-			}
-			copyImageSchemaMap.OperatorsByImage[imgSpec.ReferenceWithTransport][operatorName] = struct{}{}
-		}
-
-		bundles := copyImageSchemaMap.BundlesByImage[imgSpec.ReferenceWithTransport]
-		if _, found := bundles[bundle.Name]; !found {
-			if bundles == nil {
-				copyImageSchemaMap.BundlesByImage[imgSpec.ReferenceWithTransport] = make(map[string]string)
-			}
-			copyImageSchemaMap.BundlesByImage[imgSpec.ReferenceWithTransport][bundle.Image] = bundle.Name
-		}
+		addImageOwner(copyImageSchemaMap, imgSpec, operatorName, bundle)
 
 		relatedImages = append(relatedImages, relatedImage)
 	}
 
 	return relatedImages, nil
 }
+
+// addImageOwner records the operator and the bundle an image is mirrored for
+func addImageOwner(copyImageSchemaMap *v2alpha1.CopyImageSchemaMap, imgSpec image.ImageSpec, operatorName string, bundle declcfg.Bundle) {
+	operators := copyImageSchemaMap.OperatorsByImage[imgSpec.ReferenceWithTransport]
+
+	if _, found := operators[operatorName]; !found {
+		if operators == nil {
+			copyImageSchemaMap.OperatorsByImage[imgSpec.ReferenceWithTransport] = make(map[string]struct{})
+		}
+		copyImageSchemaMap.OperatorsByImage[imgSpec.ReferenceWithTransport][operatorName] = struct{}{}
+	}
+
+	bundles := copyImageSchemaMap.BundlesByImage[imgSpec.ReferenceWithTransport]
+	if _, found := bundles[bundle.Name]; !found {
+		if bundles == nil {
+			copyImageSchemaMap.BundlesByImage[imgSpec.ReferenceWithTransport] = make(map[string]string)
+		}
+		copyImageSchemaMap.BundlesByImage[imgSpec.ReferenceWithTransport][bundle.Image] = bundle.Name
+	}
+}
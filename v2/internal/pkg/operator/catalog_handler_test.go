@@ -1124,7 +1124,7 @@ func TestFilterCatalog(t *testing.T) {
 		t.Run(testCase.caseName, func(t *testing.T) {
 			dc, err := handler.getDeclarativeConfig(filepath.Join(common.TestFolder, "configs"))
 			assert.NoError(t, err)
-			res, err := filterCatalog(context.TODO(), *dc, testCase.cfg)
+			res, _, err := filterCatalog(context.TODO(), *dc, testCase.cfg)
 			if testCase.expectedError != nil && (err == nil || err.Error() != testCase.expectedError.Error()) {
 				assert.EqualError(t, err, testCase.expectedError.Error())
 			}
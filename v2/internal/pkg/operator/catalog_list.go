@@ -0,0 +1,251 @@
+package operator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+	"github.com/otiai10/copy"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/image"
+)
+
+// PackageSummary describes a package of an operator catalog
+type PackageSummary struct {
+	Name           string `json:"name"`
+	DefaultChannel string `json:"defaultChannel"`
+	Head           string `json:"head"`
+}
+
+// ChannelSummary describes a channel of a package
+type ChannelSummary struct {
+	Package string `json:"package"`
+	Name    string `json:"name"`
+	Head    string `json:"head"`
+	Bundles int    `json:"bundles"`
+}
+
+// BundleSummary describes a bundle of a package, and the number of images it relates to
+type BundleSummary struct {
+	Package       string   `json:"package"`
+	Name          string   `json:"name"`
+	Version       string   `json:"version"`
+	Channels      []string `json:"channels"`
+	RelatedImages int      `json:"relatedImages"`
+}
+
+// RenderCatalog pulls the catalog image (or copies the oci catalog) in the working-dir,
+// extracts its declarative config and loads it with the catalog handler.
+// The layout in the working-dir is the one of the operator collector, so that a catalog
+// listed before mirroring is not pulled twice.
+func (o OperatorCollector) RenderCatalog(ctx context.Context, catalog string) (OperatorCatalog, error) {
+	imgSpec, err := image.ParseRef(catalog)
+	if err != nil {
+		return OperatorCatalog{}, err
+	}
+
+	sourceCtx, err := o.Opts.SrcImage.NewSystemContext()
+	if err != nil {
+		return OperatorCatalog{}, err
+	}
+	catalogDigest, err := o.Manifest.GetDigest(ctx, sourceCtx, imgSpec.ReferenceWithTransport)
+	if err != nil {
+		return OperatorCatalog{}, fmt.Errorf(collectorPrefix+"unable to get the digest of catalog %s: %v", catalog, err)
+	}
+
+	imageIndexDir := filepath.Join(o.Opts.Global.WorkingDir, operatorCatalogsDir, imgSpec.ComponentName(), catalogDigest)
+	configsDir := filepath.Join(imageIndexDir, operatorCatalogConfigDir)
+	catalogImageDir := filepath.Join(imageIndexDir, operatorCatalogImageDir)
+	if err := createFolders([]string{configsDir, catalogImageDir}); err != nil {
+		return OperatorCatalog{}, err
+	}
+
+	if _, err := os.Stat(filepath.Join(catalogImageDir, "index.json")); errors.Is(err, os.ErrNotExist) {
+		if imgSpec.Transport == ociProtocol {
+			if err := copy.Copy(imgSpec.PathComponent, catalogImageDir); err != nil {
+				return OperatorCatalog{}, err
+			}
+		} else {
+			optsCopy := o.Opts
+			optsCopy.Stdout = io.Discard
+			if err := o.Mirror.Run(ctx, dockerProtocol+strings.TrimPrefix(catalog, dockerProtocol), ociProtocolTrimmed+catalogImageDir, "copy", &optsCopy); err != nil {
+				return OperatorCatalog{}, fmt.Errorf(collectorPrefix+"unable to pull catalog %s: %v", catalog, err)
+			}
+		}
+	}
+
+	label, err := o.extractCatalogConfigs(catalogImageDir, configsDir)
+	if err != nil {
+		return OperatorCatalog{}, fmt.Errorf(collectorPrefix+"unable to extract the declarative config of %s: %v", catalog, err)
+	}
+	return o.ctlgHandler.getCatalog(filepath.Join(configsDir, label))
+}
+
+// extractCatalogConfigs extracts the layers holding the declarative config of the catalog
+// (identified by the operators.operatorframework.io.index.configs.v1 label) and returns the label
+func (o OperatorCollector) extractCatalogConfigs(catalogImageDir, configsDir string) (string, error) {
+	oci, config, err := o.imageManifest(catalogImageDir)
+	if err != nil {
+		return "", err
+	}
+	label := config.Config.Labels.OperatorsOperatorframeworkIoIndexConfigsV1
+	if err := o.Manifest.ExtractLayersOCI(filepath.Join(catalogImageDir, blobsDir), configsDir, label, oci); err != nil {
+		return "", err
+	}
+	return label, nil
+}
+
+// imageManifest returns the manifest and the config of an image pulled in oci format.
+// For a manifest list, the ones of the first architecture are returned.
+func (o OperatorCollector) imageManifest(imageDir string) (*v2alpha1.OCISchema, *v2alpha1.OperatorConfigSchema, error) {
+	oci, err := o.Manifest.GetImageIndex(imageDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if isMultiManifestIndex(*oci) {
+		if err := o.Manifest.ConvertIndexToSingleManifest(imageDir, oci); err != nil {
+			return nil, nil, err
+		}
+		if oci, err = o.Manifest.GetImageIndex(imageDir); err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(oci.Manifests) == 0 {
+		return nil, nil, fmt.Errorf("no manifests found in %s", imageDir)
+	}
+	manifestDigest, err := digest.Parse(oci.Manifests[0].Digest)
+	if err != nil {
+		return nil, nil, err
+	}
+	oci, err = o.Manifest.GetImageManifest(filepath.Join(imageDir, blobsDir, manifestDigest.Encoded()))
+	if err != nil {
+		return nil, nil, err
+	}
+	// manifest list: all architectures share the same configs
+	if len(oci.Manifests) > 1 && oci.Config.Size == 0 {
+		subDigest, err := digest.Parse(oci.Manifests[0].Digest)
+		if err != nil {
+			return nil, nil, err
+		}
+		if oci, err = o.Manifest.GetImageManifest(filepath.Join(imageDir, blobsDir, subDigest.Encoded())); err != nil {
+			return nil, nil, err
+		}
+	}
+	configDigest, err := digest.Parse(oci.Config.Digest)
+	if err != nil {
+		return nil, nil, err
+	}
+	config, err := o.Manifest.GetOperatorConfig(filepath.Join(imageDir, blobsDir, configDigest.Encoded()))
+	if err != nil {
+		return nil, nil, err
+	}
+	return oci, config, nil
+}
+
+// ListPackages returns the packages of the catalog along with the head of their default channel
+func ListPackages(operatorCatalog OperatorCatalog) []PackageSummary {
+	packages := make([]PackageSummary, 0, len(operatorCatalog.Packages))
+	for name, pkg := range operatorCatalog.Packages {
+		packages = append(packages, PackageSummary{
+			Name:           name,
+			DefaultChannel: pkg.DefaultChannel,
+			Head:           channelHead(operatorCatalog.ChannelEntries[name][pkg.DefaultChannel]),
+		})
+	}
+	sort.Slice(packages, func(i, j int) bool { return packages[i].Name < packages[j].Name })
+	return packages
+}
+
+// ListChannels returns the channels of a package with their head and number of bundles
+func ListChannels(operatorCatalog OperatorCatalog, pkg string) ([]ChannelSummary, error) {
+	if _, ok := operatorCatalog.Packages[pkg]; !ok {
+		return nil, fmt.Errorf("package %s not found in catalog", pkg)
+	}
+	channels := make([]ChannelSummary, 0, len(operatorCatalog.Channels[pkg]))
+	for _, ch := range operatorCatalog.Channels[pkg] {
+		channels = append(channels, ChannelSummary{
+			Package: pkg,
+			Name:    ch.Name,
+			Head:    channelHead(operatorCatalog.ChannelEntries[pkg][ch.Name]),
+			Bundles: len(ch.Entries),
+		})
+	}
+	sort.Slice(channels, func(i, j int) bool { return channels[i].Name < channels[j].Name })
+	return channels, nil
+}
+
+// ListBundles returns the bundles of a package (limited to a channel when set),
+// sorted by version, with the number of related images of each bundle
+func ListBundles(operatorCatalog OperatorCatalog, pkg, channel string) ([]BundleSummary, error) {
+	if _, ok := operatorCatalog.Packages[pkg]; !ok {
+		return nil, fmt.Errorf("package %s not found in catalog", pkg)
+	}
+	if _, ok := operatorCatalog.ChannelEntries[pkg][channel]; channel != "" && !ok {
+		return nil, fmt.Errorf("channel %s not found in package %s", channel, pkg)
+	}
+
+	channelsByBundle := make(map[string][]string)
+	for chName, entries := range operatorCatalog.ChannelEntries[pkg] {
+		for name := range entries {
+			channelsByBundle[name] = append(channelsByBundle[name], chName)
+		}
+	}
+
+	var bundles []BundleSummary
+	for name, bundle := range operatorCatalog.BundlesByPkgAndName[pkg] {
+		channels := channelsByBundle[name]
+		if channel != "" && !slices.Contains(channels, channel) {
+			continue
+		}
+		sort.Strings(channels)
+		bundles = append(bundles, BundleSummary{
+			Package:       pkg,
+			Name:          name,
+			Version:       bundleVersion(bundle),
+			Channels:      channels,
+			RelatedImages: len(bundle.RelatedImages),
+		})
+	}
+	sort.SliceStable(bundles, func(i, j int) bool {
+		vi, erri := semver.ParseTolerant(bundles[i].Version)
+		vj, errj := semver.ParseTolerant(bundles[j].Version)
+		if erri != nil || errj != nil {
+			return bundles[i].Name < bundles[j].Name
+		}
+		return vi.LT(vj)
+	})
+	return bundles, nil
+}
+
+// channelHead reuses the bundle filtering without min/max, which only keeps the channel head
+func channelHead(chEntries map[string]declcfg.ChannelEntry) string {
+	if len(chEntries) == 0 {
+		return ""
+	}
+	heads, err := filterBundles(chEntries, "", "", false)
+	if err != nil || len(heads) == 0 {
+		return ""
+	}
+	return heads[0]
+}
+
+func bundleVersion(bundle declcfg.Bundle) string {
+	props, err := property.Parse(bundle.Properties)
+	if err != nil || len(props.Packages) == 0 {
+		// fallback on the version in the bundle name
+		_, version, _ := strings.Cut(bundle.Name, ".")
+		return strings.TrimPrefix(version, "v")
+	}
+	return props.Packages[0].Version
+}
@@ -0,0 +1,83 @@
+package operator
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/common"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+	"github.com/stretchr/testify/assert"
+)
+
+// sourceMirror records the sources of the copies, and fails them
+type sourceMirror struct {
+	sources *[]string
+}
+
+func (o sourceMirror) Run(ctx context.Context, src, dest string, mode mirror.Mode, opts *mirror.CopyOptions) error {
+	*o.sources = append(*o.sources, src)
+	return fmt.Errorf("forced mirror run fail")
+}
+
+func (o sourceMirror) Check(ctx context.Context, image string, opts *mirror.CopyOptions, asCopySrc bool) (bool, error) {
+	return true, nil
+}
+
+func TestCatalogList(t *testing.T) {
+	log := clog.New("trace")
+	handler := &catalogHandler{Log: log}
+	operatorCatalog, err := handler.getCatalog(filepath.Join(common.TestFolder, "configs"))
+	assert.NoError(t, err)
+
+	t.Run("Testing ListPackages : should return the default channel head", func(t *testing.T) {
+		packages := ListPackages(operatorCatalog)
+		assert.Equal(t, []PackageSummary{
+			{Name: "jaeger-product", DefaultChannel: "stable", Head: "jaeger-operator.v1.51.0-1"},
+		}, packages)
+	})
+
+	t.Run("Testing ListChannels : should return heads and number of bundles", func(t *testing.T) {
+		channels, err := ListChannels(operatorCatalog, "jaeger-product")
+		assert.NoError(t, err)
+		assert.Equal(t, []ChannelSummary{
+			{Package: "jaeger-product", Name: "stable", Head: "jaeger-operator.v1.51.0-1", Bundles: 6},
+		}, channels)
+
+		_, err = ListChannels(operatorCatalog, "unknown")
+		assert.EqualError(t, err, "package unknown not found in catalog")
+	})
+
+	t.Run("Testing ListBundles : should be sorted by version", func(t *testing.T) {
+		bundles, err := ListBundles(operatorCatalog, "jaeger-product", "stable")
+		assert.NoError(t, err)
+		assert.Equal(t, 6, len(bundles))
+		assert.Equal(t, "jaeger-operator.v1.30.2", bundles[0].Name)
+		assert.Equal(t, "1.30.2", bundles[0].Version)
+		assert.Equal(t, []string{"stable"}, bundles[0].Channels)
+		assert.NotZero(t, bundles[0].RelatedImages)
+		assert.Equal(t, "jaeger-operator.v1.51.0-1", bundles[5].Name)
+
+		_, err = ListBundles(operatorCatalog, "jaeger-product", "fast")
+		assert.EqualError(t, err, "channel fast not found in package jaeger-product")
+	})
+}
+
+func TestRenderCatalog(t *testing.T) {
+	t.Run("Testing RenderCatalog : should pull the catalog with a single docker:// prefix", func(t *testing.T) {
+		sources := []string{}
+		ex := setupFilterCollector_MirrorToDisk(t.TempDir(), clog.New("trace"), &MockManifest{})
+		ex.Mirror = sourceMirror{sources: &sources}
+
+		for _, catalog := range []string{"docker://registry.redhat.io/redhat/redhat-operator-index:v4.16", "registry.redhat.io/redhat/redhat-operator-index:v4.16"} {
+			_, err := ex.RenderCatalog(context.Background(), catalog)
+			assert.Error(t, err)
+		}
+		assert.Equal(t, []string{
+			"docker://registry.redhat.io/redhat/redhat-operator-index:v4.16",
+			"docker://registry.redhat.io/redhat/redhat-operator-index:v4.16",
+		}, sources)
+	})
+}
@@ -2,6 +2,7 @@ package operator
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"hash/fnv"
 	"path"
@@ -79,9 +80,65 @@ func (o OperatorCollector) cachedCatalog(catalog v2alpha1.Operator, filteredTag
 	return src, nil
 }
 
+// checkVersionedCatalogs checks that the catalogs expanded from a catalog template per OCP version exist.
+// Unlike the other catalogs, which are skipped when the registry does not have them, a missing catalog
+// for an OCP version is reported as an error.
+func (o OperatorCollector) checkVersionedCatalogs(ctx context.Context) error {
+	sourceCtx, err := o.Opts.SrcImage.NewSystemContext()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, op := range o.Config.Mirror.Operators {
+		if len(op.OCPVersions) == 0 {
+			continue
+		}
+		imgSpec, err := image.ParseRef(op.Catalog)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if _, err := o.Manifest.GetDigest(ctx, sourceCtx, imgSpec.ReferenceWithTransport); err != nil {
+			errs = append(errs, fmt.Errorf(collectorPrefix+"catalog %s for OCP %s not found: %v", op.Catalog, op.OCPVersions[0], err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // catalogDigest: method used during diskToMirror in order to discover the catalog's digest from a reference by tag.
 // It queries the cache registry instead of the registry set in the `catalog` reference
 func (o OperatorCollector) catalogDigest(ctx context.Context, catalog v2alpha1.Operator) (string, error) {
+	src, err := o.cachedCatalogSource(catalog)
+	if err != nil {
+		return "", err
+	}
+
+	imgSpec, err := image.ParseRef(src)
+	if err != nil {
+		o.Log.Error(errMsg, err.Error())
+		return "", err
+	}
+
+	sourceCtx, err := o.Opts.SrcImage.NewSystemContext()
+	if err != nil {
+		return "", err
+	}
+	// OCPBUGS-37948 : No TLS verification when getting manifests from the cache registry
+	if strings.Contains(src, o.Opts.LocalStorageFQDN) { // when copying from cache, use HTTP
+		sourceCtx.DockerInsecureSkipTLSVerify = types.OptionalBoolTrue
+	}
+
+	catalogDigest, err := o.Manifest.GetDigest(ctx, sourceCtx, imgSpec.ReferenceWithTransport)
+	if err != nil {
+		o.Log.Error(errMsg, err.Error())
+		return "", err
+	}
+	return catalogDigest, nil
+}
+
+// cachedCatalogSource returns the reference of the catalog, as copied to the cache registry
+// by mirrorToDisk, so that diskToMirror reads it without access to the source registry
+func (o OperatorCollector) cachedCatalogSource(catalog v2alpha1.Operator) (string, error) {
 	var src string
 
 	srcImgSpec, err := image.ParseRef(catalog.Catalog)
@@ -109,28 +166,7 @@ func (o OperatorCollector) catalogDigest(ctx context.Context, catalog v2alpha1.O
 	default:
 		src = src + ":" + srcImgSpec.Tag
 	}
-
-	imgSpec, err := image.ParseRef(src)
-	if err != nil {
-		o.Log.Error(errMsg, err.Error())
-		return "", err
-	}
-
-	sourceCtx, err := o.Opts.SrcImage.NewSystemContext()
-	if err != nil {
-		return "", err
-	}
-	// OCPBUGS-37948 : No TLS verification when getting manifests from the cache registry
-	if strings.Contains(src, o.Opts.LocalStorageFQDN) { // when copying from cache, use HTTP
-		sourceCtx.DockerInsecureSkipTLSVerify = types.OptionalBoolTrue
-	}
-
-	catalogDigest, err := o.Manifest.GetDigest(ctx, sourceCtx, imgSpec.ReferenceWithTransport)
-	if err != nil {
-		o.Log.Error(errMsg, err.Error())
-		return "", err
-	}
-	return catalogDigest, nil
+	return src, nil
 }
 
 func (o OperatorCollector) prepareD2MCopyBatch(images map[string][]v2alpha1.RelatedImage) ([]v2alpha1.CopyImageSchema, error) {
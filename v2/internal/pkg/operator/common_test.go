@@ -1,6 +1,7 @@
 package operator
 
 import (
+	"context"
 	"os"
 	"testing"
 
@@ -447,3 +448,28 @@ func TestPrepareM2MCopyBatch(t *testing.T) {
 	}
 
 }
+
+func TestCheckVersionedCatalogs(t *testing.T) {
+	log := clog.New("trace")
+	cfg := v2alpha1.ImageSetConfiguration{
+		ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+			Mirror: v2alpha1.Mirror{
+				Operators: []v2alpha1.Operator{
+					{Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.16", OCPVersions: []string{"4.16"}, TargetTag: "v4.16"},
+					{Catalog: "registry.redhat.io/redhat/certified-operator-index:v4.16"},
+				},
+			},
+		},
+	}
+
+	t.Run("Testing checkVersionedCatalogs : should pass when the catalogs exist", func(t *testing.T) {
+		ex := setupFilterCollector_MirrorToDisk(t.TempDir(), log, &MockManifest{}).withConfig(cfg)
+		assert.NoError(t, ex.checkVersionedCatalogs(context.TODO()))
+	})
+
+	t.Run("Testing checkVersionedCatalogs : should fail when a catalog of an OCP version does not exist", func(t *testing.T) {
+		ex := setupFilterCollector_MirrorToDisk(t.TempDir(), log, &MockManifest{FailDigest: true}).withConfig(cfg)
+		err := ex.checkVersionedCatalogs(context.TODO())
+		assert.EqualError(t, err, collectorPrefix+"catalog registry.redhat.io/redhat/redhat-operator-index:v4.16 for OCP 4.16 not found: manifest unknown")
+	})
+}
@@ -1,20 +1,26 @@
 package operator
 
 const (
-	operatorImageExtractDir           = "hold-operator" //TODO ALEX REMOVE ME when filtered_collector.go is the default
-	dockerProtocol                    = "docker://"
-	ociProtocol                       = "oci://"
-	ociProtocolTrimmed                = "oci:"
-	operatorImageDir                  = "operator-images" //TODO ALEX REMOVE ME when filtered_collector.go is the default
-	operatorCatalogsDir        string = "operator-catalogs"
-	operatorCatalogConfigDir   string = "catalog-config"
-	operatorCatalogImageDir    string = "catalog-image"
-	operatorCatalogFilteredDir string = "filtered-catalogs"
-	blobsDir                          = "blobs/sha256"
-	collectorPrefix                   = "[OperatorImageCollector] "
-	errMsg                            = collectorPrefix + "%s"
-	logsFile                          = "operator.log"
-	errorSemver                string = " semver %v "
-	filteredCatalogDir                = "filtered-operator"
-	digestIncorrectMessage     string = "the digests seem to be incorrect for %s: %s "
+	operatorImageExtractDir               = "hold-operator" //TODO ALEX REMOVE ME when filtered_collector.go is the default
+	dockerProtocol                        = "docker://"
+	ociProtocol                           = "oci://"
+	ociProtocolTrimmed                    = "oci:"
+	operatorImageDir                      = "operator-images" //TODO ALEX REMOVE ME when filtered_collector.go is the default
+	operatorCatalogsDir            string = "operator-catalogs"
+	operatorCatalogConfigDir       string = "catalog-config"
+	operatorCatalogImageDir        string = "catalog-image"
+	operatorCatalogFilteredDir     string = "filtered-catalogs"
+	operatorCatalogLastFilteredDir string = "last-filtered"
+	operatorCatalogDiffDir         string = "catalog-diffs"
+	operatorCatalogBundleScansDir  string = "bundle-scans"
+	bundleManifestsDir             string = "manifests/"
+	relatedImageEnvPrefix          string = "RELATED_IMAGE_"
+	keepLastVersionRange           string = ">=0.0.0-0" // any version, pre-releases included
+	blobsDir                              = "blobs/sha256"
+	collectorPrefix                       = "[OperatorImageCollector] "
+	errMsg                                = collectorPrefix + "%s"
+	logsFile                              = "operator.log"
+	errorSemver                    string = " semver %v "
+	filteredCatalogDir                    = "filtered-operator"
+	digestIncorrectMessage         string = "the digests seem to be incorrect for %s: %s "
 )
@@ -0,0 +1,367 @@
+package operator
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+)
+
+// dependencyAddition is a bundle added to a filtered catalog because it provides
+// (or leads to a bundle providing) a dependency of a bundle of the filtered catalog
+type dependencyAddition struct {
+	Package     string
+	Bundle      string
+	RequiredBy  string
+	Requirement string
+}
+
+// catalogIndex indexes the bundles of a declarative config and what they provide
+type catalogIndex struct {
+	bundles  map[string]declcfg.Bundle
+	versions map[string]semver.Version
+	// gvks is the set of group/version/kind provided by each bundle
+	gvks map[string][]property.GVK
+}
+
+func newCatalogIndex(dc *declcfg.DeclarativeConfig) (catalogIndex, error) {
+	index := catalogIndex{
+		bundles:  map[string]declcfg.Bundle{},
+		versions: map[string]semver.Version{},
+		gvks:     map[string][]property.GVK{},
+	}
+	for _, b := range dc.Bundles {
+		props, err := property.Parse(b.Properties)
+		if err != nil {
+			return index, fmt.Errorf("bundle %s: %v", b.Name, err)
+		}
+		index.bundles[bundleKey(b.Package, b.Name)] = b
+		if len(props.Packages) > 0 {
+			if version, err := semver.Parse(props.Packages[0].Version); err == nil {
+				index.versions[bundleKey(b.Package, b.Name)] = version
+			}
+		}
+		index.gvks[bundleKey(b.Package, b.Name)] = props.GVKs
+	}
+	return index, nil
+}
+
+// resolveDependencies adds to the filtered catalog the bundles of the original catalog providing the
+// packages (olm.package.required) and the APIs (olm.gvk.required) required by its bundles, until nothing
+// new is added. The latest version satisfying a requirement is added, along with the channel entries
+// connecting it to the entries already in the filtered channels, so that each channel keeps a single head.
+// The bundles, channels and packages of excluded are never added: a requirement only provided by them is an error.
+// It returns the bundles added, and the requirements that the original catalog cannot satisfy.
+func resolveDependencies(original, filtered *declcfg.DeclarativeConfig, excluded deprecatedRefs) ([]dependencyAddition, []string, error) {
+	originalIndex, err := newCatalogIndex(original)
+	if err != nil {
+		return nil, nil, err
+	}
+	var additions []dependencyAddition
+	unresolved := map[string]bool{}
+	for {
+		filteredIndex, err := newCatalogIndex(filtered)
+		if err != nil {
+			return nil, nil, err
+		}
+		addition, found, err := resolveNext(originalIndex, filteredIndex, original, filtered, excluded, unresolved)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !found {
+			break
+		}
+		additions = append(additions, addition...)
+	}
+	unresolvedRequirements := make([]string, 0, len(unresolved))
+	for requirement := range unresolved {
+		unresolvedRequirements = append(unresolvedRequirements, requirement)
+	}
+	sort.Strings(unresolvedRequirements)
+	return additions, unresolvedRequirements, nil
+}
+
+// resolveNext resolves the first requirement of the filtered bundles that is not satisfied
+func resolveNext(originalIndex, filteredIndex catalogIndex, original, filtered *declcfg.DeclarativeConfig, excluded deprecatedRefs, unresolved map[string]bool) ([]dependencyAddition, bool, error) {
+	for _, b := range filtered.Bundles {
+		props, err := property.Parse(b.Properties)
+		if err != nil {
+			return nil, false, fmt.Errorf("bundle %s: %v", b.Name, err)
+		}
+		for _, required := range props.PackagesRequired {
+			requirement := fmt.Sprintf("package %s %s", required.PackageName, required.VersionRange)
+			versionRange, err := semver.ParseRange(required.VersionRange)
+			if err != nil {
+				unresolved[requirement+" (invalid version range)"] = true
+				continue
+			}
+			providesPackage := func(index catalogIndex, key string) bool {
+				version, ok := index.versions[key]
+				return ok && index.bundles[key].Package == required.PackageName && versionRange(version)
+			}
+			if unresolved[requirement] || provided(filteredIndex, providesPackage) {
+				continue
+			}
+			additions, ok, err := addProvider(originalIndex, filteredIndex, original, filtered, excluded, providesPackage)
+			if err != nil {
+				return nil, false, fmt.Errorf("bundle %s requires %s: %v", b.Name, requirement, err)
+			}
+			if !ok {
+				unresolved[requirement] = true
+				continue
+			}
+			return withRequirement(additions, b.Name, requirement), true, nil
+		}
+		for _, required := range props.GVKsRequired {
+			requirement := fmt.Sprintf("api %s/%s %s", required.Group, required.Version, required.Kind)
+			providesGVK := func(index catalogIndex, key string) bool {
+				return slices.Contains(index.gvks[key], property.GVK(required))
+			}
+			if unresolved[requirement] || provided(filteredIndex, providesGVK) {
+				continue
+			}
+			additions, ok, err := addProvider(originalIndex, filteredIndex, original, filtered, excluded, providesGVK)
+			if err != nil {
+				return nil, false, fmt.Errorf("bundle %s requires %s: %v", b.Name, requirement, err)
+			}
+			if !ok {
+				unresolved[requirement] = true
+				continue
+			}
+			return withRequirement(additions, b.Name, requirement), true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+func provided(index catalogIndex, provides func(catalogIndex, string) bool) bool {
+	for key := range index.bundles {
+		if provides(index, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// addProvider adds the best bundle of the original catalog providing the requirement to the filtered catalog.
+// The bundles of the packages already in the filtered catalog are preferred, then the bundles of the default
+// channel of their package, then the latest versions.
+// The bundles of excluded are not candidates, it fails when they are the only bundles providing the requirement.
+func addProvider(originalIndex, filteredIndex catalogIndex, original, filtered *declcfg.DeclarativeConfig, excluded deprecatedRefs, provides func(catalogIndex, string) bool) ([]dependencyAddition, bool, error) {
+	filteredPackages := map[string]bool{}
+	for _, pkg := range filtered.Packages {
+		filteredPackages[pkg.Name] = true
+	}
+	defaultChannels := map[string]string{}
+	for _, pkg := range original.Packages {
+		defaultChannels[pkg.Name] = pkg.DefaultChannel
+	}
+	inDefaultChannel := map[string]bool{}
+	for _, ch := range original.Channels {
+		if ch.Name != defaultChannels[ch.Package] {
+			continue
+		}
+		for _, entry := range ch.Entries {
+			inDefaultChannel[bundleKey(ch.Package, entry.Name)] = true
+		}
+	}
+
+	var candidates, deprecatedCandidates []string
+	for key := range originalIndex.bundles {
+		if !provides(originalIndex, key) {
+			continue
+		}
+		if b := originalIndex.bundles[key]; excluded.excludesBundle(b.Package, b.Name) {
+			deprecatedCandidates = append(deprecatedCandidates, b.Name)
+			continue
+		}
+		candidates = append(candidates, key)
+	}
+	if len(candidates) == 0 && len(deprecatedCandidates) > 0 {
+		sort.Strings(deprecatedCandidates)
+		return nil, false, fmt.Errorf("only provided by deprecated bundles excluded by the deprecation policy: %s", strings.Join(deprecatedCandidates, ", "))
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		bi, bj := originalIndex.bundles[candidates[i]], originalIndex.bundles[candidates[j]]
+		if filteredPackages[bi.Package] != filteredPackages[bj.Package] {
+			return filteredPackages[bi.Package]
+		}
+		if inDefaultChannel[candidates[i]] != inDefaultChannel[candidates[j]] {
+			return inDefaultChannel[candidates[i]]
+		}
+		if bi.Package != bj.Package {
+			return bi.Package < bj.Package
+		}
+		vi, vj := originalIndex.versions[candidates[i]], originalIndex.versions[candidates[j]]
+		if !vi.EQ(vj) {
+			return vi.GT(vj)
+		}
+		return bi.Name < bj.Name
+	})
+
+	for _, key := range candidates {
+		if additions := addBundle(originalIndex, filteredIndex, original, filtered, excluded, originalIndex.bundles[key]); len(additions) > 0 {
+			return additions, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// addBundle adds the bundle to the channels of the filtered catalog it belongs to, along with the
+// entries connecting it to the entries already in the channels. The package is added when needed.
+// The channels of excluded, and the paths through bundles of excluded, are left out.
+func addBundle(originalIndex, filteredIndex catalogIndex, original, filtered *declcfg.DeclarativeConfig, excluded deprecatedRefs, b declcfg.Bundle) []dependencyAddition {
+	added := map[string]bool{}
+	var addedChannels []string
+	for _, originalChannel := range original.Channels {
+		if originalChannel.Package != b.Package || !slices.ContainsFunc(originalChannel.Entries, func(e declcfg.ChannelEntry) bool { return e.Name == b.Name }) {
+			continue
+		}
+		if _, deprecated := excluded.lookup(originalChannel.Package, declcfg.SchemaChannel, originalChannel.Name); deprecated {
+			continue
+		}
+		channelIndex := slices.IndexFunc(filtered.Channels, func(ch declcfg.Channel) bool {
+			return ch.Package == originalChannel.Package && ch.Name == originalChannel.Name
+		})
+		if channelIndex < 0 {
+			filtered.Channels = append(filtered.Channels, declcfg.Channel{
+				Schema:  originalChannel.Schema,
+				Package: originalChannel.Package,
+				Name:    originalChannel.Name,
+			})
+			channelIndex = len(filtered.Channels) - 1
+		}
+		path, ok := upgradePath(originalChannel, filtered.Channels[channelIndex], b.Name)
+		if !ok || slices.ContainsFunc(path, func(entry declcfg.ChannelEntry) bool { return excluded.excludesBundle(b.Package, entry.Name) }) {
+			continue
+		}
+		for _, entry := range path {
+			filtered.Channels[channelIndex].Entries = append(filtered.Channels[channelIndex].Entries, entry)
+			added[entry.Name] = true
+		}
+		addedChannels = append(addedChannels, originalChannel.Name)
+	}
+	// remove the channels created for the bundle, which could not receive it
+	filtered.Channels = slices.DeleteFunc(filtered.Channels, func(ch declcfg.Channel) bool { return len(ch.Entries) == 0 })
+	if len(addedChannels) == 0 {
+		return nil
+	}
+
+	if !slices.ContainsFunc(filtered.Packages, func(pkg declcfg.Package) bool { return pkg.Name == b.Package }) {
+		for _, pkg := range original.Packages {
+			if pkg.Name != b.Package {
+				continue
+			}
+			if !slices.Contains(addedChannels, pkg.DefaultChannel) {
+				sort.Strings(addedChannels)
+				pkg.DefaultChannel = addedChannels[0]
+			}
+			filtered.Packages = append(filtered.Packages, pkg)
+		}
+	}
+
+	var additions []dependencyAddition
+	names := make([]string, 0, len(added))
+	for name := range added {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		key := bundleKey(b.Package, name)
+		if _, ok := filteredIndex.bundles[key]; ok {
+			continue
+		}
+		bundle, ok := originalIndex.bundles[key]
+		if !ok {
+			continue
+		}
+		filteredIndex.bundles[key] = bundle
+		filtered.Bundles = append(filtered.Bundles, bundle)
+		additions = append(additions, dependencyAddition{Package: b.Package, Bundle: name})
+	}
+	return additions
+}
+
+// upgradePath returns the entries of the original channel to add to the filtered channel so that
+// it contains bundleName: the entry of bundleName, and the entries between bundleName and the
+// entries of the filtered channel, through the replaces and skips of the entries.
+// It returns false when bundleName cannot be connected to the entries of the filtered channel.
+func upgradePath(originalChannel, filteredChannel declcfg.Channel, bundleName string) ([]declcfg.ChannelEntry, bool) {
+	inFiltered := map[string]bool{}
+	for _, entry := range filteredChannel.Entries {
+		inFiltered[entry.Name] = true
+	}
+	if inFiltered[bundleName] {
+		return nil, true
+	}
+	entries := map[string]declcfg.ChannelEntry{}
+	// edges from an entry to the entries it replaces or skips (older), and the reverse (newer)
+	older := map[string][]string{}
+	newer := map[string][]string{}
+	for _, entry := range originalChannel.Entries {
+		entries[entry.Name] = entry
+		for _, replaced := range append([]string{entry.Replaces}, entry.Skips...) {
+			if replaced == "" {
+				continue
+			}
+			older[entry.Name] = append(older[entry.Name], replaced)
+			newer[replaced] = append(newer[replaced], entry.Name)
+		}
+	}
+	if len(filteredChannel.Entries) == 0 {
+		return []declcfg.ChannelEntry{entries[bundleName]}, true
+	}
+	for _, edges := range []map[string][]string{newer, older} {
+		if path := shortestPath(bundleName, edges, inFiltered); path != nil {
+			result := make([]declcfg.ChannelEntry, 0, len(path))
+			for _, name := range path {
+				if entry, ok := entries[name]; ok {
+					result = append(result, entry)
+				}
+			}
+			return result, true
+		}
+	}
+	return nil, false
+}
+
+// shortestPath returns the names from start to the last name before reaching one of targets, following edges
+func shortestPath(start string, edges map[string][]string, targets map[string]bool) []string {
+	previous := map[string]string{start: ""}
+	queue := []string{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range edges[current] {
+			if _, visited := previous[next]; visited {
+				continue
+			}
+			if targets[next] {
+				path := []string{current}
+				for name := previous[current]; name != ""; name = previous[name] {
+					path = append(path, name)
+				}
+				return path
+			}
+			previous[next] = current
+			queue = append(queue, next)
+		}
+	}
+	return nil
+}
+
+func withRequirement(additions []dependencyAddition, requiredBy, requirement string) []dependencyAddition {
+	for i := range additions {
+		additions[i].RequiredBy = requiredBy
+		additions[i].Requirement = requirement
+	}
+	return additions
+}
+
+func bundleKey(pkg, name string) string {
+	return pkg + "/" + name
+}
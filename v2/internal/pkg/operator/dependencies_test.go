@@ -0,0 +1,219 @@
+package operator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/operator-framework/operator-registry/alpha/property"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+)
+
+// testBundle returns a bundle of pkg at version, along with the properties given
+func testBundle(pkg, version string, props ...property.Property) declcfg.Bundle {
+	return declcfg.Bundle{
+		Schema:     declcfg.SchemaBundle,
+		Name:       pkg + ".v" + version,
+		Package:    pkg,
+		Image:      "registry.redhat.io/" + pkg + "/bundle:v" + version,
+		Properties: append([]property.Property{property.MustBuildPackage(pkg, version)}, props...),
+	}
+}
+
+func testChannel(pkg, name string, entries ...declcfg.ChannelEntry) declcfg.Channel {
+	return declcfg.Channel{Schema: declcfg.SchemaChannel, Package: pkg, Name: name, Entries: entries}
+}
+
+func gvkRequired(group, version, kind string) property.Property {
+	value, _ := json.Marshal(property.GVKRequired{Group: group, Version: version, Kind: kind})
+	return property.Property{Type: property.TypeGVKRequired, Value: value}
+}
+
+func dependenciesCatalog() *declcfg.DeclarativeConfig {
+	return &declcfg.DeclarativeConfig{
+		Packages: []declcfg.Package{
+			{Schema: declcfg.SchemaPackage, Name: "app", DefaultChannel: "stable"},
+			{Schema: declcfg.SchemaPackage, Name: "database", DefaultChannel: "stable"},
+			{Schema: declcfg.SchemaPackage, Name: "storage", DefaultChannel: "stable"},
+			{Schema: declcfg.SchemaPackage, Name: "certs", DefaultChannel: "stable"},
+		},
+		Channels: []declcfg.Channel{
+			testChannel("app", "stable", declcfg.ChannelEntry{Name: "app.v1.0.0"}),
+			testChannel("database", "stable",
+				declcfg.ChannelEntry{Name: "database.v1.0.0"},
+				declcfg.ChannelEntry{Name: "database.v1.1.0", Replaces: "database.v1.0.0"},
+				declcfg.ChannelEntry{Name: "database.v2.0.0", Replaces: "database.v1.1.0"},
+			),
+			testChannel("database", "fast",
+				declcfg.ChannelEntry{Name: "database.v2.0.0"},
+			),
+			testChannel("storage", "stable", declcfg.ChannelEntry{Name: "storage.v0.5.0"}),
+			testChannel("certs", "stable",
+				declcfg.ChannelEntry{Name: "certs.v1.0.0"},
+				declcfg.ChannelEntry{Name: "certs.v1.1.0", Replaces: "certs.v1.0.0"},
+				declcfg.ChannelEntry{Name: "certs.v1.2.0", Replaces: "certs.v1.1.0"},
+			),
+		},
+		Bundles: []declcfg.Bundle{
+			testBundle("app", "1.0.0", property.MustBuildPackageRequired("database", ">=1.0.0 <2.0.0")),
+			testBundle("database", "1.0.0"),
+			testBundle("database", "1.1.0", gvkRequired("storage.example.com", "v1", "Volume")),
+			testBundle("database", "2.0.0"),
+			testBundle("storage", "0.5.0", property.MustBuildGVK("storage.example.com", "v1", "Volume")),
+			testBundle("certs", "1.0.0", property.MustBuildPackageRequired("issuer", ">=1.0.0")),
+			testBundle("certs", "1.1.0"),
+			testBundle("certs", "1.2.0", property.MustBuildPackageRequired("certs-webhook", "<1.0.0 <<2")),
+		},
+	}
+}
+
+func TestResolveDependencies(t *testing.T) {
+	t.Run("Testing resolveDependencies : should add the packages and apis required, transitively", func(t *testing.T) {
+		original := dependenciesCatalog()
+		filtered := &declcfg.DeclarativeConfig{
+			Packages: []declcfg.Package{original.Packages[0]},
+			Channels: []declcfg.Channel{original.Channels[0]},
+			Bundles:  []declcfg.Bundle{original.Bundles[0]},
+		}
+
+		additions, unresolved, err := resolveDependencies(original, filtered, nil)
+		assert.NoError(t, err)
+		assert.Empty(t, unresolved)
+		assert.Equal(t, []dependencyAddition{
+			// the latest version of the range, in the default channel
+			{Package: "database", Bundle: "database.v1.1.0", RequiredBy: "app.v1.0.0", Requirement: "package database >=1.0.0 <2.0.0"},
+			{Package: "storage", Bundle: "storage.v0.5.0", RequiredBy: "database.v1.1.0", Requirement: "api storage.example.com/v1 Volume"},
+		}, additions)
+
+		assert.Len(t, filtered.Packages, 3)
+		assert.Contains(t, filtered.Channels, testChannel("database", "stable", declcfg.ChannelEntry{Name: "database.v1.1.0", Replaces: "database.v1.0.0"}))
+		assert.Contains(t, filtered.Channels, testChannel("storage", "stable", declcfg.ChannelEntry{Name: "storage.v0.5.0"}))
+		assert.Len(t, filtered.Bundles, 3)
+	})
+
+	t.Run("Testing resolveDependencies : should connect the bundle added to the entries of the channel", func(t *testing.T) {
+		original := dependenciesCatalog()
+		// database 2.0.0 is kept, app requires database < 2.0.0
+		filtered := &declcfg.DeclarativeConfig{
+			Packages: []declcfg.Package{original.Packages[0], original.Packages[1]},
+			Channels: []declcfg.Channel{
+				original.Channels[0],
+				testChannel("database", "stable", declcfg.ChannelEntry{Name: "database.v2.0.0", Replaces: "database.v1.1.0"}),
+			},
+			Bundles: []declcfg.Bundle{original.Bundles[0], original.Bundles[3]},
+		}
+		// storage is not in the catalog anymore
+		original.Bundles = original.Bundles[:4]
+
+		additions, unresolved, err := resolveDependencies(original, filtered, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"api storage.example.com/v1 Volume"}, unresolved)
+		assert.Equal(t, []dependencyAddition{
+			{Package: "database", Bundle: "database.v1.1.0", RequiredBy: "app.v1.0.0", Requirement: "package database >=1.0.0 <2.0.0"},
+		}, additions)
+		assert.Contains(t, filtered.Channels, testChannel("database", "stable",
+			declcfg.ChannelEntry{Name: "database.v2.0.0", Replaces: "database.v1.1.0"},
+			declcfg.ChannelEntry{Name: "database.v1.1.0", Replaces: "database.v1.0.0"},
+		))
+	})
+
+	t.Run("Testing resolveDependencies : should add the entries between the bundle and the channel", func(t *testing.T) {
+		original := dependenciesCatalog()
+		original.Bundles = append(original.Bundles, testBundle("app", "2.0.0", property.MustBuildPackageRequired("certs", ">=1.0.0 <1.1.0")))
+		filtered := &declcfg.DeclarativeConfig{
+			Packages: []declcfg.Package{original.Packages[0], original.Packages[3]},
+			Channels: []declcfg.Channel{
+				testChannel("app", "stable", declcfg.ChannelEntry{Name: "app.v2.0.0"}),
+				testChannel("certs", "stable", declcfg.ChannelEntry{Name: "certs.v1.2.0", Replaces: "certs.v1.1.0"}),
+			},
+			Bundles: []declcfg.Bundle{original.Bundles[len(original.Bundles)-1], original.Bundles[7]},
+		}
+
+		additions, unresolved, err := resolveDependencies(original, filtered, nil)
+		assert.NoError(t, err)
+		// certs 1.0.0 requires issuer, not in the catalog, certs 1.2.0 has an invalid range
+		assert.Equal(t, []string{"package certs-webhook <1.0.0 <<2 (invalid version range)", "package issuer >=1.0.0"}, unresolved)
+		assert.Equal(t, []dependencyAddition{
+			{Package: "certs", Bundle: "certs.v1.0.0", RequiredBy: "app.v2.0.0", Requirement: "package certs >=1.0.0 <1.1.0"},
+			{Package: "certs", Bundle: "certs.v1.1.0", RequiredBy: "app.v2.0.0", Requirement: "package certs >=1.0.0 <1.1.0"},
+		}, additions)
+		assert.Len(t, filtered.Channels[1].Entries, 3)
+	})
+	t.Run("Testing resolveDependencies : should not add the deprecated bundles excluded", func(t *testing.T) {
+		original := dependenciesCatalog()
+		original.Deprecations = []declcfg.Deprecation{
+			{Schema: declcfg.SchemaDeprecation, Package: "database", Entries: []declcfg.DeprecationEntry{
+				deprecationEntry(declcfg.SchemaBundle, "database.v1.1.0", "database.v1.1.0 corrupts data"),
+			}},
+		}
+		filtered := &declcfg.DeclarativeConfig{
+			Packages: []declcfg.Package{original.Packages[0]},
+			Channels: []declcfg.Channel{original.Channels[0]},
+			Bundles:  []declcfg.Bundle{original.Bundles[0]},
+		}
+
+		additions, unresolved, err := resolveDependencies(original, filtered, newDeprecatedRefs(*original))
+		assert.NoError(t, err)
+		assert.Empty(t, unresolved)
+		assert.Equal(t, []dependencyAddition{
+			{Package: "database", Bundle: "database.v1.0.0", RequiredBy: "app.v1.0.0", Requirement: "package database >=1.0.0 <2.0.0"},
+		}, additions)
+		assert.Contains(t, filtered.Channels, testChannel("database", "stable", declcfg.ChannelEntry{Name: "database.v1.0.0"}))
+	})
+
+	t.Run("Testing resolveDependencies : should fail when only deprecated bundles excluded provide a dependency", func(t *testing.T) {
+		original := dependenciesCatalog()
+		original.Deprecations = []declcfg.Deprecation{
+			{Schema: declcfg.SchemaDeprecation, Package: "database", Entries: []declcfg.DeprecationEntry{
+				deprecationEntry(declcfg.SchemaPackage, "", "database is replaced by postgresql"),
+			}},
+		}
+		filtered := &declcfg.DeclarativeConfig{
+			Packages: []declcfg.Package{original.Packages[0]},
+			Channels: []declcfg.Channel{original.Channels[0]},
+			Bundles:  []declcfg.Bundle{original.Bundles[0]},
+		}
+
+		_, _, err := resolveDependencies(original, filtered, newDeprecatedRefs(*original))
+		assert.EqualError(t, err, "bundle app.v1.0.0 requires package database >=1.0.0 <2.0.0: only provided by deprecated bundles excluded by the deprecation policy: database.v1.0.0, database.v1.1.0")
+	})
+}
+
+func TestAddDependenciesDeprecated(t *testing.T) {
+	deprecatedCatalog := func() *declcfg.DeclarativeConfig {
+		original := dependenciesCatalog()
+		original.Deprecations = []declcfg.Deprecation{
+			{Schema: declcfg.SchemaDeprecation, Package: "database", Entries: []declcfg.DeprecationEntry{
+				deprecationEntry(declcfg.SchemaBundle, "database.v1.1.0", "database.v1.1.0 corrupts data"),
+			}},
+		}
+		return original
+	}
+	filteredApp := func(original *declcfg.DeclarativeConfig) *declcfg.DeclarativeConfig {
+		return &declcfg.DeclarativeConfig{
+			Packages: []declcfg.Package{original.Packages[0]},
+			Channels: []declcfg.Channel{original.Channels[0]},
+			Bundles:  []declcfg.Bundle{original.Bundles[0]},
+		}
+	}
+	ex := FilterCollector{OperatorCollector{Log: clog.New("trace")}}
+
+	t.Run("Testing addDependencies : the exclude policy should not add a deprecated dependency", func(t *testing.T) {
+		original := deprecatedCatalog()
+		filtered := filteredApp(original)
+		err := ex.addDependencies(original, filtered, v2alpha1.Operator{Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.16", Deprecated: v2alpha1.DeprecatedExclude})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"app.v1.0.0", "database.v1.0.0"}, filteredBundleNames(*filtered))
+	})
+
+	t.Run("Testing addDependencies : the warn policy should add a deprecated dependency", func(t *testing.T) {
+		original := deprecatedCatalog()
+		filtered := filteredApp(original)
+		err := ex.addDependencies(original, filtered, v2alpha1.Operator{Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.16", Deprecated: v2alpha1.DeprecatedWarn})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"app.v1.0.0", "database.v1.1.0", "storage.v0.5.0"}, filteredBundleNames(*filtered))
+	})
+}
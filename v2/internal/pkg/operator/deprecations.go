@@ -0,0 +1,266 @@
+package operator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+)
+
+// deprecationNotice is a deprecation of the catalog applying to the content filtered
+type deprecationNotice struct {
+	Package string
+	// Schema is olm.package, olm.channel or olm.bundle
+	Schema   string
+	Name     string
+	Message  string
+	Excluded bool
+}
+
+func (n deprecationNotice) String() string {
+	name := n.Name
+	if n.Schema == declcfg.SchemaPackage {
+		name = n.Package
+	}
+	notice := fmt.Sprintf("package %s: %s %s is deprecated", n.Package, strings.TrimPrefix(n.Schema, "olm."), name)
+	if n.Excluded {
+		notice += " (excluded)"
+	}
+	return notice + ": " + strings.TrimSpace(n.Message)
+}
+
+// deprecatedRefs indexes the deprecation entries of a catalog by package and reference
+type deprecatedRefs map[string]map[declcfg.PackageScopedReference]string
+
+func newDeprecatedRefs(dc declcfg.DeclarativeConfig) deprecatedRefs {
+	refs := deprecatedRefs{}
+	for _, deprecation := range dc.Deprecations {
+		if _, ok := refs[deprecation.Package]; !ok {
+			refs[deprecation.Package] = map[declcfg.PackageScopedReference]string{}
+		}
+		for _, entry := range deprecation.Entries {
+			refs[deprecation.Package][entry.Reference] = entry.Message
+		}
+	}
+	return refs
+}
+
+func (refs deprecatedRefs) lookup(pkg, schema, name string) (string, bool) {
+	if schema == declcfg.SchemaPackage {
+		name = ""
+	}
+	message, ok := refs[pkg][declcfg.PackageScopedReference{Schema: schema, Name: name}]
+	return message, ok
+}
+
+// excludesBundle is true when the bundle, or its package, is deprecated
+func (refs deprecatedRefs) excludesBundle(pkg, name string) bool {
+	if _, ok := refs.lookup(pkg, declcfg.SchemaPackage, ""); ok {
+		return true
+	}
+	_, ok := refs.lookup(pkg, declcfg.SchemaBundle, name)
+	return ok
+}
+
+// applyDeprecationPolicy applies the deprecation policy of the catalog to the filtered catalog,
+// according to the deprecations of the original catalog, and returns the deprecations to report.
+// With exclude, the deprecated packages, channels and bundles are removed, along with the channel entries
+// which would only upgrade to the channel head through deprecated bundles.
+func applyDeprecationPolicy(original declcfg.DeclarativeConfig, filtered *declcfg.DeclarativeConfig, op v2alpha1.Operator) ([]deprecationNotice, error) {
+	notices := []deprecationNotice{}
+	switch op.Deprecated {
+	case v2alpha1.DeprecatedWarn:
+		notices = deprecationNotices(keptDeprecations(original, *filtered))
+	case v2alpha1.DeprecatedExclude:
+		var err error
+		notices, err = excludeDeprecated(newDeprecatedRefs(original), filtered, op.Catalog)
+		if err != nil {
+			return nil, err
+		}
+	}
+	filtered.Deprecations = keptDeprecations(original, *filtered)
+	return notices, nil
+}
+
+// deprecationNotices returns a notice per deprecation entry
+func deprecationNotices(deprecations []declcfg.Deprecation) []deprecationNotice {
+	notices := []deprecationNotice{}
+	for _, deprecation := range deprecations {
+		for _, entry := range deprecation.Entries {
+			notices = append(notices, deprecationNotice{Package: deprecation.Package, Schema: entry.Reference.Schema, Name: entry.Reference.Name, Message: entry.Message})
+		}
+	}
+	return notices
+}
+
+func excludeDeprecated(refs deprecatedRefs, filtered *declcfg.DeclarativeConfig, catalog string) ([]deprecationNotice, error) {
+	notices := []deprecationNotice{}
+	exclude := func(pkg, schema, name, message string) {
+		notices = append(notices, deprecationNotice{Package: pkg, Schema: schema, Name: name, Message: message, Excluded: true})
+	}
+
+	excludedPackages := map[string]bool{}
+	for _, pkg := range filtered.Packages {
+		if message, ok := refs.lookup(pkg.Name, declcfg.SchemaPackage, ""); ok {
+			excludedPackages[pkg.Name] = true
+			exclude(pkg.Name, declcfg.SchemaPackage, "", message)
+		}
+	}
+
+	excludedBundles := map[string]bool{}
+	for _, b := range filtered.Bundles {
+		if excludedPackages[b.Package] {
+			continue
+		}
+		if message, ok := refs.lookup(b.Package, declcfg.SchemaBundle, b.Name); ok {
+			excludedBundles[bundleKey(b.Package, b.Name)] = true
+			exclude(b.Package, declcfg.SchemaBundle, b.Name, message)
+		}
+	}
+
+	channels := []declcfg.Channel{}
+	keptBundles := map[string]bool{}
+	for _, ch := range filtered.Channels {
+		if excludedPackages[ch.Package] {
+			continue
+		}
+		if message, ok := refs.lookup(ch.Package, declcfg.SchemaChannel, ch.Name); ok {
+			exclude(ch.Package, declcfg.SchemaChannel, ch.Name, message)
+			continue
+		}
+		entries, unreachable := withoutDeprecatedEntries(ch, func(name string) bool { return excludedBundles[bundleKey(ch.Package, name)] })
+		for _, name := range unreachable {
+			exclude(ch.Package, declcfg.SchemaBundle, name, fmt.Sprintf("no upgrade path to the head of channel %s without deprecated bundles", ch.Name))
+		}
+		if len(entries) == 0 {
+			continue
+		}
+		ch.Entries = entries
+		channels = append(channels, ch)
+		for _, entry := range entries {
+			keptBundles[bundleKey(ch.Package, entry.Name)] = true
+		}
+	}
+	filtered.Channels = channels
+
+	filtered.Bundles = slices.DeleteFunc(filtered.Bundles, func(b declcfg.Bundle) bool {
+		return !keptBundles[bundleKey(b.Package, b.Name)]
+	})
+
+	packages := []declcfg.Package{}
+	for _, pkg := range filtered.Packages {
+		if excludedPackages[pkg.Name] {
+			continue
+		}
+		pkgChannels := []string{}
+		for _, ch := range filtered.Channels {
+			if ch.Package == pkg.Name {
+				pkgChannels = append(pkgChannels, ch.Name)
+			}
+		}
+		if len(pkgChannels) == 0 {
+			continue
+		}
+		if !slices.Contains(pkgChannels, pkg.DefaultChannel) {
+			return nil, fmt.Errorf("catalog %q: operator %q: the default channel %q is empty once the deprecated content is excluded, set defaultChannel to one of %s",
+				catalog, pkg.Name, pkg.DefaultChannel, strings.Join(pkgChannels, ", "))
+		}
+		packages = append(packages, pkg)
+	}
+	filtered.Packages = packages
+	filtered.Others = slices.DeleteFunc(filtered.Others, func(meta declcfg.Meta) bool {
+		return meta.Package != "" && excludedPackages[meta.Package]
+	})
+	return notices, nil
+}
+
+// withoutDeprecatedEntries returns the entries of the channel left once the deprecated ones are removed,
+// following OLM's channel traversal: the head becomes the newest entry of the replaces chain that is not deprecated,
+// and only the entries of its replaces chain, and the entries they skip, are kept.
+// It also returns the entries that are not deprecated, but not reachable anymore.
+func withoutDeprecatedEntries(ch declcfg.Channel, deprecated func(string) bool) ([]declcfg.ChannelEntry, []string) {
+	entries := map[string]declcfg.ChannelEntry{}
+	for _, entry := range ch.Entries {
+		entries[entry.Name] = entry
+	}
+	graph := newChannelGraph(ch)
+	head := ""
+	for _, name := range graph.entries {
+		if len(graph.newer[name]) == 0 {
+			head = name
+			break
+		}
+	}
+	for ; head != "" && deprecated(head); head = entries[head].Replaces {
+		if _, ok := entries[entries[head].Replaces]; !ok {
+			head = ""
+			break
+		}
+	}
+
+	kept := map[string]bool{}
+	inChain := map[string]bool{}
+	for name := head; name != "" && !deprecated(name) && !inChain[name]; name = entries[name].Replaces {
+		if _, ok := entries[name]; !ok {
+			break
+		}
+		inChain[name] = true
+		kept[name] = true
+		for _, skipped := range entries[name].Skips {
+			if _, ok := entries[skipped]; ok && !deprecated(skipped) {
+				kept[skipped] = true
+			}
+		}
+	}
+
+	result := []declcfg.ChannelEntry{}
+	unreachable := []string{}
+	for _, entry := range ch.Entries {
+		switch {
+		case kept[entry.Name]:
+			result = append(result, entry)
+		case !deprecated(entry.Name):
+			unreachable = append(unreachable, entry.Name)
+		}
+	}
+	return result, unreachable
+}
+
+// keptDeprecations returns the deprecations of the original catalog
+// for the packages, channels and bundles of the filtered catalog
+func keptDeprecations(original, filtered declcfg.DeclarativeConfig) []declcfg.Deprecation {
+	present := map[string]map[declcfg.PackageScopedReference]bool{}
+	add := func(pkg, schema, name string) {
+		if _, ok := present[pkg]; !ok {
+			present[pkg] = map[declcfg.PackageScopedReference]bool{}
+		}
+		present[pkg][declcfg.PackageScopedReference{Schema: schema, Name: name}] = true
+	}
+	for _, pkg := range filtered.Packages {
+		add(pkg.Name, declcfg.SchemaPackage, "")
+	}
+	for _, ch := range filtered.Channels {
+		add(ch.Package, declcfg.SchemaChannel, ch.Name)
+	}
+	for _, b := range filtered.Bundles {
+		add(b.Package, declcfg.SchemaBundle, b.Name)
+	}
+
+	var deprecations []declcfg.Deprecation
+	for _, deprecation := range original.Deprecations {
+		entries := []declcfg.DeprecationEntry{}
+		for _, entry := range deprecation.Entries {
+			if present[deprecation.Package][entry.Reference] {
+				entries = append(entries, entry)
+			}
+		}
+		if len(entries) > 0 {
+			deprecation.Entries = entries
+			deprecations = append(deprecations, deprecation)
+		}
+	}
+	return deprecations
+}
@@ -0,0 +1,142 @@
+package operator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+)
+
+func deprecationEntry(schema, name, message string) declcfg.DeprecationEntry {
+	return declcfg.DeprecationEntry{Reference: declcfg.PackageScopedReference{Schema: schema, Name: name}, Message: message}
+}
+
+func deprecationsCatalog() declcfg.DeclarativeConfig {
+	return declcfg.DeclarativeConfig{
+		Packages: []declcfg.Package{
+			{Schema: declcfg.SchemaPackage, Name: "database", DefaultChannel: "stable"},
+			{Schema: declcfg.SchemaPackage, Name: "proxy", DefaultChannel: "stable"},
+		},
+		Channels: []declcfg.Channel{
+			testChannel("database", "stable",
+				declcfg.ChannelEntry{Name: "database.v1.0.0"},
+				declcfg.ChannelEntry{Name: "database.v1.1.0", Replaces: "database.v1.0.0"},
+				declcfg.ChannelEntry{Name: "database.v1.2.0", Replaces: "database.v1.1.0"},
+				declcfg.ChannelEntry{Name: "database.v2.0.0", Replaces: "database.v1.2.0"},
+			),
+			testChannel("database", "fast", declcfg.ChannelEntry{Name: "database.v2.0.0"}),
+			testChannel("proxy", "stable", declcfg.ChannelEntry{Name: "proxy.v1.0.0"}),
+		},
+		Bundles: []declcfg.Bundle{
+			testBundle("database", "1.0.0"),
+			testBundle("database", "1.1.0"),
+			testBundle("database", "1.2.0"),
+			testBundle("database", "2.0.0"),
+			testBundle("proxy", "1.0.0"),
+		},
+		Deprecations: []declcfg.Deprecation{
+			{Schema: declcfg.SchemaDeprecation, Package: "database", Entries: []declcfg.DeprecationEntry{
+				deprecationEntry(declcfg.SchemaBundle, "database.v2.0.0", "database.v2.0.0 corrupts data"),
+				deprecationEntry(declcfg.SchemaChannel, "fast", "the fast channel is no longer maintained"),
+			}},
+			{Schema: declcfg.SchemaDeprecation, Package: "proxy", Entries: []declcfg.DeprecationEntry{
+				deprecationEntry(declcfg.SchemaPackage, "", "proxy is replaced by gateway"),
+			}},
+		},
+	}
+}
+
+func TestFilterCatalogDeprecations(t *testing.T) {
+	op := v2alpha1.Operator{
+		Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.16",
+		Full:    true,
+		IncludeConfig: v2alpha1.IncludeConfig{Packages: []v2alpha1.IncludePackage{
+			{Name: "database"},
+			{Name: "proxy"},
+		}},
+	}
+
+	t.Run("Testing filterCatalog : should keep the deprecations of the content mirrored", func(t *testing.T) {
+		res, notices, err := filterCatalog(context.TODO(), deprecationsCatalog(), op)
+		assert.NoError(t, err)
+		assert.Empty(t, notices)
+		assert.Equal(t, deprecationsCatalog().Deprecations, res.Deprecations)
+	})
+
+	t.Run("Testing filterCatalog : should report the deprecations with warn", func(t *testing.T) {
+		op.Deprecated = v2alpha1.DeprecatedWarn
+		res, notices, err := filterCatalog(context.TODO(), deprecationsCatalog(), op)
+		assert.NoError(t, err)
+		assert.Len(t, res.Bundles, 5)
+		assert.Equal(t, []string{
+			"package database: bundle database.v2.0.0 is deprecated: database.v2.0.0 corrupts data",
+			"package database: channel fast is deprecated: the fast channel is no longer maintained",
+			"package proxy: package proxy is deprecated: proxy is replaced by gateway",
+		}, noticeStrings(notices))
+	})
+
+	t.Run("Testing filterCatalog : should remove the deprecated content with exclude", func(t *testing.T) {
+		op.Deprecated = v2alpha1.DeprecatedExclude
+		res, notices, err := filterCatalog(context.TODO(), deprecationsCatalog(), op)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"package proxy: package proxy is deprecated (excluded): proxy is replaced by gateway",
+			"package database: bundle database.v2.0.0 is deprecated (excluded): database.v2.0.0 corrupts data",
+			"package database: channel fast is deprecated (excluded): the fast channel is no longer maintained",
+		}, noticeStrings(notices))
+		assert.Equal(t, []declcfg.Package{{Schema: declcfg.SchemaPackage, Name: "database", DefaultChannel: "stable"}}, res.Packages)
+		// database.v1.2.0 becomes the head of the channel
+		assert.Equal(t, []declcfg.Channel{testChannel("database", "stable",
+			declcfg.ChannelEntry{Name: "database.v1.0.0"},
+			declcfg.ChannelEntry{Name: "database.v1.1.0", Replaces: "database.v1.0.0"},
+			declcfg.ChannelEntry{Name: "database.v1.2.0", Replaces: "database.v1.1.0"},
+		)}, res.Channels)
+		assert.Len(t, res.Bundles, 3)
+		assert.Empty(t, res.Deprecations)
+	})
+
+	t.Run("Testing filterCatalog : should fail when the default channel is excluded", func(t *testing.T) {
+		dc := deprecationsCatalog()
+		dc.Packages[0].DefaultChannel = "fast"
+		_, _, err := filterCatalog(context.TODO(), dc, op)
+		assert.EqualError(t, err, "catalog \"registry.redhat.io/redhat/redhat-operator-index:v4.16\": operator \"database\": the default channel \"fast\" is empty once the deprecated content is excluded, set defaultChannel to one of stable")
+	})
+}
+
+func TestWithoutDeprecatedEntries(t *testing.T) {
+	ch := testChannel("database", "stable",
+		declcfg.ChannelEntry{Name: "database.v1.0.0"},
+		declcfg.ChannelEntry{Name: "database.v1.1.0", Replaces: "database.v1.0.0"},
+		declcfg.ChannelEntry{Name: "database.v1.2.0", Replaces: "database.v1.1.0", Skips: []string{"database.v1.1.0"}},
+		declcfg.ChannelEntry{Name: "database.v2.0.0", Replaces: "database.v1.2.0"},
+	)
+
+	t.Run("Testing withoutDeprecatedEntries : should drop the entries only reachable through a deprecated bundle", func(t *testing.T) {
+		entries, unreachable := withoutDeprecatedEntries(ch, func(name string) bool { return name == "database.v1.1.0" })
+		assert.Equal(t, []declcfg.ChannelEntry{ch.Entries[2], ch.Entries[3]}, entries)
+		assert.Equal(t, []string{"database.v1.0.0"}, unreachable)
+	})
+
+	t.Run("Testing withoutDeprecatedEntries : should follow the replaces chain through the entries skipped", func(t *testing.T) {
+		entries, unreachable := withoutDeprecatedEntries(ch, func(name string) bool { return false })
+		assert.Equal(t, ch.Entries, entries)
+		assert.Empty(t, unreachable)
+	})
+
+	t.Run("Testing withoutDeprecatedEntries : should empty the channel when all its entries are deprecated", func(t *testing.T) {
+		entries, unreachable := withoutDeprecatedEntries(ch, func(name string) bool { return true })
+		assert.Empty(t, entries)
+		assert.Empty(t, unreachable)
+	})
+}
+
+func noticeStrings(notices []deprecationNotice) []string {
+	result := []string{}
+	for _, notice := range notices {
+		result = append(result, notice.String())
+	}
+	return result
+}
@@ -2,8 +2,6 @@ package operator
 
 import (
 	"context"
-	"crypto/md5"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -49,6 +47,14 @@ func (o *FilterCollector) OperatorImageCollector(ctx context.Context) (v2alpha1.
 	collectorSchema := v2alpha1.CollectorSchema{}
 	copyImageSchemaMap := &v2alpha1.CopyImageSchemaMap{OperatorsByImage: make(map[string]map[string]struct{}), BundlesByImage: make(map[string]map[string]string)}
 
+	// the source registry is only accessed during mirrorToDisk and mirrorToMirror
+	if o.Opts.IsMirrorToDisk() || o.Opts.IsMirrorToMirror() {
+		if err := o.checkVersionedCatalogs(ctx); err != nil {
+			o.Log.Error(errMsg, err.Error())
+			return v2alpha1.CollectorSchema{}, err
+		}
+	}
+
 	for _, op := range o.Config.Mirror.Operators {
 		var catalogImage string
 		// download the operator index image
@@ -92,7 +98,21 @@ func (o *FilterCollector) OperatorImageCollector(ctx context.Context) (v2alpha1.
 		//OCPBUGS-36214: For diskToMirror (and delete), access to the source registry is not guaranteed
 		catalogDigest := ""
 		if o.Opts.Mode == mirror.DiskToMirror || o.Opts.Mode == string(mirror.DeleteMode) {
-			d, err := o.catalogDigest(ctx, op)
+			var d string
+			if op.IsLocalFBC() {
+				d, err = o.cachedLocalCatalogDigest(ctx, op, imgSpec)
+			} else {
+				d, err = o.catalogDigest(ctx, op)
+			}
+			if err != nil {
+				o.Log.Error(errMsg, err.Error())
+				spinner.Abort(true)
+				spinner.Wait()
+				return v2alpha1.CollectorSchema{}, err
+			}
+			catalogDigest = d
+		} else if op.IsLocalFBC() {
+			d, err := localCatalogDigest(imgSpec.Reference)
 			if err != nil {
 				o.Log.Error(errMsg, err.Error())
 				spinner.Abort(true)
@@ -135,7 +155,7 @@ func (o *FilterCollector) OperatorImageCollector(ctx context.Context) (v2alpha1.
 		var filteredDC *declcfg.DeclarativeConfig
 		var isAlreadyFiltered bool
 
-		filterDigest, err := digestOfFilter(op)
+		filterDigest, err := op.FilterDigest()
 		if err != nil {
 			spinner.Abort(true)
 			spinner.Wait()
@@ -185,60 +205,91 @@ func (o *FilterCollector) OperatorImageCollector(ctx context.Context) (v2alpha1.
 			if collectorSchema.CatalogToFBCMap == nil {
 				collectorSchema.CatalogToFBCMap = make(map[string]v2alpha1.CatalogFilterResult)
 			}
+			// the content excluded when the catalog was filtered is no longer in the filtered catalog:
+			// only the deprecations it kept are reported
+			var deprecations []string
+			if op.Deprecated == v2alpha1.DeprecatedWarn {
+				for _, notice := range deprecationNotices(filteredDC.Deprecations) {
+					o.Log.Warn(collectorPrefix+"catalog %s: %s", op.Catalog, notice)
+					deprecations = append(deprecations, notice.String())
+				}
+			}
 			result := v2alpha1.CatalogFilterResult{
 				OperatorFilter:     op,
 				FilteredConfigPath: filterConfigDir,
 				ToRebuild:          false,
+				CatalogDir:         imageIndexDir,
+				Deprecations:       deprecations,
 			}
 			collectorSchema.CatalogToFBCMap[imgSpec.ReferenceWithTransport] = result
 
 		} else {
 			toRebuild := true
-			if imgSpec.Transport == ociProtocol {
-				if _, err := os.Stat(filepath.Join(catalogImageDir, "index.json")); errors.Is(err, os.ErrNotExist) {
-					// delete the existing directory and untarred cache contents
-					os.RemoveAll(catalogImageDir)
-					os.RemoveAll(configsDir)
-					// copy all contents to the working dir
-					err := copy.Copy(imgSpec.PathComponent, catalogImageDir)
-					if err != nil {
-						o.Log.Error(errMsg, err.Error())
-						spinner.Abort(true)
-						spinner.Wait()
-						return v2alpha1.CollectorSchema{}, err
-					}
+			var originalDC *declcfg.DeclarativeConfig
+			var deprecations []string
+			if op.IsLocalFBC() {
+				originalDC, err = o.localCatalogConfig(ctx, op, imgSpec, catalogImageDir)
+				if err != nil {
+					o.Log.Error(errMsg, err.Error())
+					spinner.Abort(true)
+					spinner.Wait()
+					return v2alpha1.CollectorSchema{}, err
 				}
+				catalogImage = op.Catalog
+				catalogName = op.TargetCatalog
+			} else {
+				if imgSpec.Transport == ociProtocol {
+					if _, err := os.Stat(filepath.Join(catalogImageDir, "index.json")); errors.Is(err, os.ErrNotExist) {
+						// delete the existing directory and untarred cache contents
+						os.RemoveAll(catalogImageDir)
+						os.RemoveAll(configsDir)
+						// copy all contents to the working dir
+						err := copy.Copy(imgSpec.PathComponent, catalogImageDir)
+						if err != nil {
+							o.Log.Error(errMsg, err.Error())
+							spinner.Abort(true)
+							spinner.Wait()
+							return v2alpha1.CollectorSchema{}, err
+						}
+					}
 
-				if len(op.TargetCatalog) > 0 {
-					catalogName = op.TargetCatalog
+					if len(op.TargetCatalog) > 0 {
+						catalogName = op.TargetCatalog
+					} else {
+						catalogName = path.Base(imgSpec.Reference)
+					}
 				} else {
-					catalogName = path.Base(imgSpec.Reference)
-				}
-			} else {
-				src := dockerProtocol + op.Catalog
-				dest := ociProtocolTrimmed + catalogImageDir
+					src := dockerProtocol + op.Catalog
+					dest := ociProtocolTrimmed + catalogImageDir
+					copyCatalog := true
+					// OCPBUGS-36214: for diskToMirror (and delete), access to the source registry is not guaranteed:
+					// the catalog image is the one mirrorToDisk saved in the working-dir, or else the one in the cache
+					if o.Opts.Mode == mirror.DiskToMirror || o.Opts.Mode == string(mirror.DeleteMode) {
+						_, statErr := os.Stat(filepath.Join(catalogImageDir, "index.json"))
+						copyCatalog = statErr != nil
+						src, err = o.cachedCatalogSource(op)
+						if err != nil {
+							o.Log.Error(errMsg, err.Error())
+							spinner.Abort(true)
+							spinner.Wait()
+							return v2alpha1.CollectorSchema{}, err
+						}
+					}
 
-				optsCopy := o.Opts
-				optsCopy.Stdout = io.Discard
+					if copyCatalog {
+						optsCopy := o.Opts
+						optsCopy.Stdout = io.Discard
 
-				err = o.Mirror.Run(ctx, src, dest, "copy", &optsCopy)
+						err = o.Mirror.Run(ctx, src, dest, "copy", &optsCopy)
 
-				if err != nil {
-					o.Log.Error(errMsg, err.Error())
+						if err != nil {
+							o.Log.Error(errMsg, err.Error())
+						}
+					}
 				}
-			}
-
-			// it's in oci format so we can go directly to the index.json file
-			oci, err := o.Manifest.GetImageIndex(catalogImageDir)
-			if err != nil {
-				o.Log.Error(errMsg, err.Error())
-				spinner.Abort(true)
-				spinner.Wait()
-				return v2alpha1.CollectorSchema{}, err
-			}
 
-			if isMultiManifestIndex(*oci) && imgSpec.Transport == ociProtocol {
-				err = o.Manifest.ConvertIndexToSingleManifest(catalogImageDir, oci)
+				// it's in oci format so we can go directly to the index.json file
+				oci, err := o.Manifest.GetImageIndex(catalogImageDir)
 				if err != nil {
 					o.Log.Error(errMsg, err.Error())
 					spinner.Abort(true)
@@ -246,124 +297,152 @@ func (o *FilterCollector) OperatorImageCollector(ctx context.Context) (v2alpha1.
 					return v2alpha1.CollectorSchema{}, err
 				}
 
-				oci, err = o.Manifest.GetImageIndex(catalogImageDir)
+				if isMultiManifestIndex(*oci) && imgSpec.Transport == ociProtocol {
+					err = o.Manifest.ConvertIndexToSingleManifest(catalogImageDir, oci)
+					if err != nil {
+						o.Log.Error(errMsg, err.Error())
+						spinner.Abort(true)
+						spinner.Wait()
+						return v2alpha1.CollectorSchema{}, err
+					}
+
+					oci, err = o.Manifest.GetImageIndex(catalogImageDir)
+					if err != nil {
+						o.Log.Error(errMsg, err.Error())
+						spinner.Abort(true)
+						spinner.Wait()
+						return v2alpha1.CollectorSchema{}, err
+					}
+
+					sourceOCIDir, err := filepath.Abs(imgSpec.Reference)
+					if err != nil {
+						o.Log.Error(errMsg, err.Error())
+						return v2alpha1.CollectorSchema{}, err
+					}
+					catalogImage = ociProtocol + sourceOCIDir
+				} else {
+					catalogImage = op.Catalog
+				}
+
+				if len(oci.Manifests) == 0 {
+					o.Log.Error(collectorPrefix+"no manifests found for %s ", op.Catalog)
+					spinner.Abort(true)
+					spinner.Wait()
+					return v2alpha1.CollectorSchema{}, fmt.Errorf(collectorPrefix+"no manifests found for %s ", op.Catalog)
+				}
+
+				validDigest, err := digest.Parse(oci.Manifests[0].Digest)
 				if err != nil {
-					o.Log.Error(errMsg, err.Error())
+					o.Log.Error(collectorPrefix+digestIncorrectMessage, op.Catalog, err.Error())
 					spinner.Abort(true)
 					spinner.Wait()
-					return v2alpha1.CollectorSchema{}, err
+					return v2alpha1.CollectorSchema{}, fmt.Errorf(collectorPrefix+"the digests seem to be incorrect for %s: %s ", op.Catalog, err.Error())
 				}
 
-				sourceOCIDir, err := filepath.Abs(imgSpec.Reference)
+				manifest := validDigest.Encoded()
+				o.Log.Debug(collectorPrefix+"manifest %s", manifest)
+				// read the operator image manifest
+				manifestDir := filepath.Join(catalogImageDir, blobsDir, manifest)
+				oci, err = o.Manifest.GetImageManifest(manifestDir)
 				if err != nil {
 					o.Log.Error(errMsg, err.Error())
+					spinner.Abort(true)
+					spinner.Wait()
 					return v2alpha1.CollectorSchema{}, err
 				}
-				catalogImage = ociProtocol + sourceOCIDir
-			} else {
-				catalogImage = op.Catalog
-			}
 
-			if len(oci.Manifests) == 0 {
-				o.Log.Error(collectorPrefix+"no manifests found for %s ", op.Catalog)
-				spinner.Abort(true)
-				spinner.Wait()
-				return v2alpha1.CollectorSchema{}, fmt.Errorf(collectorPrefix+"no manifests found for %s ", op.Catalog)
-			}
-
-			validDigest, err := digest.Parse(oci.Manifests[0].Digest)
-			if err != nil {
-				o.Log.Error(collectorPrefix+digestIncorrectMessage, op.Catalog, err.Error())
-				spinner.Abort(true)
-				spinner.Wait()
-				return v2alpha1.CollectorSchema{}, fmt.Errorf(collectorPrefix+"the digests seem to be incorrect for %s: %s ", op.Catalog, err.Error())
-			}
-
-			manifest := validDigest.Encoded()
-			o.Log.Debug(collectorPrefix+"manifest %s", manifest)
-			// read the operator image manifest
-			manifestDir := filepath.Join(catalogImageDir, blobsDir, manifest)
-			oci, err = o.Manifest.GetImageManifest(manifestDir)
-			if err != nil {
-				o.Log.Error(errMsg, err.Error())
-				spinner.Abort(true)
-				spinner.Wait()
-				return v2alpha1.CollectorSchema{}, err
-			}
+				// we need to check if oci returns multi manifests
+				// (from manifest list) also oci.Config will be nil
+				// we are only interested in the first manifest as all
+				// architecture "configs" will be exactly the same
+				if len(oci.Manifests) > 1 && oci.Config.Size == 0 {
+					subDigest, err := digest.Parse(oci.Manifests[0].Digest)
+					if err != nil {
+						o.Log.Error(collectorPrefix+digestIncorrectMessage, op.Catalog, err.Error())
+						spinner.Abort(true)
+						spinner.Wait()
+						return v2alpha1.CollectorSchema{}, fmt.Errorf(collectorPrefix+"the digests seem to be incorrect for %s: %s ", op.Catalog, err.Error())
+					}
+					manifestDir := filepath.Join(catalogImageDir, blobsDir, subDigest.Encoded())
+					oci, err = o.Manifest.GetImageManifest(manifestDir)
+					if err != nil {
+						o.Log.Error(collectorPrefix+"manifest %s: %s ", op.Catalog, err.Error())
+						spinner.Abort(true)
+						spinner.Wait()
+						return v2alpha1.CollectorSchema{}, fmt.Errorf(collectorPrefix+"manifest %s: %s ", op.Catalog, err.Error())
+					}
+				}
 
-			// we need to check if oci returns multi manifests
-			// (from manifest list) also oci.Config will be nil
-			// we are only interested in the first manifest as all
-			// architecture "configs" will be exactly the same
-			if len(oci.Manifests) > 1 && oci.Config.Size == 0 {
-				subDigest, err := digest.Parse(oci.Manifests[0].Digest)
+				// read the config digest to get the detailed manifest
+				// looking for the lable to search for a specific folder
+				configDigest, err := digest.Parse(oci.Config.Digest)
 				if err != nil {
 					o.Log.Error(collectorPrefix+digestIncorrectMessage, op.Catalog, err.Error())
 					spinner.Abort(true)
 					spinner.Wait()
 					return v2alpha1.CollectorSchema{}, fmt.Errorf(collectorPrefix+"the digests seem to be incorrect for %s: %s ", op.Catalog, err.Error())
 				}
-				manifestDir := filepath.Join(catalogImageDir, blobsDir, subDigest.Encoded())
-				oci, err = o.Manifest.GetImageManifest(manifestDir)
+				catalogDir := filepath.Join(catalogImageDir, blobsDir, configDigest.Encoded())
+				ocs, err := o.Manifest.GetOperatorConfig(catalogDir)
 				if err != nil {
-					o.Log.Error(collectorPrefix+"manifest %s: %s ", op.Catalog, err.Error())
+					o.Log.Error(errMsg, err.Error())
 					spinner.Abort(true)
 					spinner.Wait()
-					return v2alpha1.CollectorSchema{}, fmt.Errorf(collectorPrefix+"manifest %s: %s ", op.Catalog, err.Error())
+					return v2alpha1.CollectorSchema{}, err
 				}
-			}
 
-			// read the config digest to get the detailed manifest
-			// looking for the lable to search for a specific folder
-			configDigest, err := digest.Parse(oci.Config.Digest)
-			if err != nil {
-				o.Log.Error(collectorPrefix+digestIncorrectMessage, op.Catalog, err.Error())
-				spinner.Abort(true)
-				spinner.Wait()
-				return v2alpha1.CollectorSchema{}, fmt.Errorf(collectorPrefix+"the digests seem to be incorrect for %s: %s ", op.Catalog, err.Error())
-			}
-			catalogDir := filepath.Join(catalogImageDir, blobsDir, configDigest.Encoded())
-			ocs, err := o.Manifest.GetOperatorConfig(catalogDir)
-			if err != nil {
-				o.Log.Error(errMsg, err.Error())
-				spinner.Abort(true)
-				spinner.Wait()
-				return v2alpha1.CollectorSchema{}, err
-			}
+				label = ocs.Config.Labels.OperatorsOperatorframeworkIoIndexConfigsV1
+				o.Log.Debug(collectorPrefix+"label %s", label)
 
-			label = ocs.Config.Labels.OperatorsOperatorframeworkIoIndexConfigsV1
-			o.Log.Debug(collectorPrefix+"label %s", label)
-
-			// untar all the blobs for the operator
-			// if the layer with "label (from previous step) is found to a specific folder"
-			fromDir := strings.Join([]string{catalogImageDir, blobsDir}, "/")
-			err = o.Manifest.ExtractLayersOCI(fromDir, configsDir, label, oci)
-			if err != nil {
-				spinner.Abort(true)
-				spinner.Wait()
-				return v2alpha1.CollectorSchema{}, err
-			}
+				// untar all the blobs for the operator
+				// if the layer with "label (from previous step) is found to a specific folder"
+				fromDir := strings.Join([]string{catalogImageDir, blobsDir}, "/")
+				err = o.Manifest.ExtractLayersOCI(fromDir, configsDir, label, oci)
+				if err != nil {
+					spinner.Abort(true)
+					spinner.Wait()
+					return v2alpha1.CollectorSchema{}, err
+				}
 
-			originalDC, err := o.ctlgHandler.getDeclarativeConfig(filepath.Join(configsDir, label))
-			if err != nil {
-				spinner.Abort(true)
-				spinner.Wait()
-				return v2alpha1.CollectorSchema{}, err
+				originalDC, err = o.ctlgHandler.getDeclarativeConfig(filepath.Join(configsDir, label))
+				if err != nil {
+					spinner.Abort(true)
+					spinner.Wait()
+					return v2alpha1.CollectorSchema{}, err
+				}
 			}
 
-			if !isFullCatalog(op) {
+			// a catalog from a file-based catalog directory always needs to be built,
+			// as well as a catalog from which the deprecated content is excluded
+			if !isFullCatalog(op) || op.IsLocalFBC() || op.Deprecated == v2alpha1.DeprecatedExclude {
 
 				var filteredDigestPath string
 				var filterDigest string
 
-				filteredDC, err = filterCatalog(ctx, *originalDC, op)
+				var notices []deprecationNotice
+				filteredDC, notices, err = filterCatalog(ctx, *originalDC, op)
 				if err != nil {
 					spinner.Abort(true)
 					spinner.Wait()
 					return v2alpha1.CollectorSchema{}, err
 				}
+				for _, notice := range notices {
+					o.Log.Warn(collectorPrefix+"catalog %s: %s", op.Catalog, notice)
+					deprecations = append(deprecations, notice.String())
+				}
+
+				if !op.SkipDependencies {
+					err = o.addDependencies(originalDC, filteredDC, op)
+					if err != nil {
+						spinner.Abort(true)
+						spinner.Wait()
+						return v2alpha1.CollectorSchema{}, err
+					}
+					// the bundles added keep their deprecations
+					filteredDC.Deprecations = keptDeprecations(*originalDC, *filteredDC)
+				}
 
-				filterDigest, err = digestOfFilter(op)
+				filterDigest, err = op.FilterDigest()
 				if err != nil {
 					o.Log.Error(errMsg, err.Error())
 					spinner.Abort(true)
@@ -390,6 +469,15 @@ func (o *FilterCollector) OperatorImageCollector(ctx context.Context) (v2alpha1.
 					return v2alpha1.CollectorSchema{}, err
 				}
 
+				if filterDigest != "" {
+					reportPath, err := o.reportCatalogDiff(op.Catalog, imgSpec.ComponentName(), catalogDigest, filterDigest, *filteredDC)
+					if err != nil {
+						o.Log.Warn(collectorPrefix+"unable to report the changes of catalog %s: %v", op.Catalog, err)
+					} else if reportPath != "" {
+						o.Log.Info(collectorPrefix+"catalog %s changed since the last filtering, see %s", op.Catalog, reportPath)
+					}
+				}
+
 				if collectorSchema.CatalogToFBCMap == nil {
 					collectorSchema.CatalogToFBCMap = make(map[string]v2alpha1.CatalogFilterResult)
 				}
@@ -397,6 +485,8 @@ func (o *FilterCollector) OperatorImageCollector(ctx context.Context) (v2alpha1.
 					OperatorFilter:     op,
 					FilteredConfigPath: filteredDigestPath,
 					ToRebuild:          toRebuild,
+					CatalogDir:         imageIndexDir,
+					Deprecations:       deprecations,
 				}
 				collectorSchema.CatalogToFBCMap[imgSpec.ReferenceWithTransport] = result
 
@@ -404,6 +494,12 @@ func (o *FilterCollector) OperatorImageCollector(ctx context.Context) (v2alpha1.
 				rebuiltTag = ""
 				toRebuild = false
 				filteredDC = originalDC
+				if op.Deprecated == v2alpha1.DeprecatedWarn {
+					for _, notice := range deprecationNotices(originalDC.Deprecations) {
+						o.Log.Warn(collectorPrefix+"catalog %s: %s", op.Catalog, notice)
+						deprecations = append(deprecations, notice.String())
+					}
+				}
 				if collectorSchema.CatalogToFBCMap == nil {
 					collectorSchema.CatalogToFBCMap = make(map[string]v2alpha1.CatalogFilterResult)
 				}
@@ -411,6 +507,8 @@ func (o *FilterCollector) OperatorImageCollector(ctx context.Context) (v2alpha1.
 					OperatorFilter:     op,
 					FilteredConfigPath: "", // this value is not relevant: no rebuilding required
 					ToRebuild:          toRebuild,
+					CatalogDir:         imageIndexDir,
+					Deprecations:       deprecations,
 				}
 				collectorSchema.CatalogToFBCMap[imgSpec.ReferenceWithTransport] = result
 			}
@@ -425,6 +523,12 @@ func (o *FilterCollector) OperatorImageCollector(ctx context.Context) (v2alpha1.
 			}
 		}
 
+		if op.DeepScan {
+			for bundleName, discovered := range o.scanBundleImages(ctx, filteredDC, imageIndexDir, copyImageSchemaMap) {
+				ri[bundleName] = append(ri[bundleName], discovered...)
+			}
+		}
+
 		//OCPBUGS-45059
 		//TODO remove me when the migration from oc-mirror v1 to v2 ends
 		if imgSpec.Transport == ociProtocol && o.isDeleteOfV1CatalogFromDisk() {
@@ -437,7 +541,7 @@ func (o *FilterCollector) OperatorImageCollector(ctx context.Context) (v2alpha1.
 		var targetCatalog string
 		if len(op.TargetTag) > 0 {
 			targetTag = op.TargetTag
-		} else if imgSpec.Transport == ociProtocol {
+		} else if imgSpec.Transport == ociProtocol || op.IsLocalFBC() {
 			// for this case only, img.ParseRef(in its current state)
 			// will not be able to determine the digest.
 			// this leaves the oci imgSpec with no tag nor digest as it
@@ -508,6 +612,27 @@ func (o *FilterCollector) OperatorImageCollector(ctx context.Context) (v2alpha1.
 	return collectorSchema, nil
 }
 
+// addDependencies adds the bundles providing the dependencies of the filtered bundles to the filtered catalog.
+// With the exclude deprecation policy, the deprecated bundles are not added.
+func (o FilterCollector) addDependencies(originalDC, filteredDC *declcfg.DeclarativeConfig, op v2alpha1.Operator) error {
+	catalog := op.Catalog
+	var excluded deprecatedRefs
+	if op.Deprecated == v2alpha1.DeprecatedExclude {
+		excluded = newDeprecatedRefs(*originalDC)
+	}
+	additions, unresolved, err := resolveDependencies(originalDC, filteredDC, excluded)
+	if err != nil {
+		return fmt.Errorf(collectorPrefix+"unable to resolve the dependencies of %s: %v", catalog, err)
+	}
+	for _, addition := range additions {
+		o.Log.Info(collectorPrefix+"%s : adding bundle %s of package %s, dependency (%s) of %s", catalog, addition.Bundle, addition.Package, addition.Requirement, addition.RequiredBy)
+	}
+	for _, requirement := range unresolved {
+		o.Log.Warn(collectorPrefix+"%s : dependency %s not found in the catalog", catalog, requirement)
+	}
+	return nil
+}
+
 func isFullCatalog(catalog v2alpha1.Operator) bool {
 	return len(catalog.IncludeConfig.Packages) == 0 && catalog.Full
 }
@@ -525,18 +650,6 @@ func createFolders(paths []string) error {
 	return errors.Join(errs...)
 }
 
-func digestOfFilter(catalog v2alpha1.Operator) (string, error) {
-	c := catalog
-	c.TargetCatalog = ""
-	c.TargetTag = ""
-	c.TargetCatalogSourceTemplate = ""
-	pkgs, err := json.Marshal(c)
-	if err != nil {
-		return "", err
-	}
-	return fmt.Sprintf("%x", md5.Sum(pkgs))[0:32], nil
-}
-
 func (o FilterCollector) isAlreadyFiltered(ctx context.Context, srcImage, filteredImageDigest string) bool {
 
 	imgSpec, err := image.ParseRef(srcImage)
@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+
 	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
 	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
@@ -273,6 +275,107 @@ func TestFilterCollectorM2D(t *testing.T) {
 		_ = New(log, "working-dir", ex.Config, ex.Opts, ex.Mirror, manifest)
 	})
 }
+func TestFilterCollectorLocalCatalog(t *testing.T) {
+	log := clog.New("trace")
+	tempDir := t.TempDir()
+	ctx := context.Background()
+
+	catalogDir := filepath.Join(tempDir, "curated", "configs")
+	assert.NoError(t, os.MkdirAll(filepath.Join(catalogDir, "op1"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(catalogDir, "op1", "catalog.json"), []byte(`{"schema":"olm.package","name":"op1"}`), 0644))
+
+	op := v2alpha1.Operator{
+		Catalog:       "fbc://" + catalogDir,
+		BaseImage:     "registry.redhat.io/openshift4/ose-operator-registry-rhel9:v4.16",
+		TargetCatalog: "curated/catalog",
+	}
+	cfg := v2alpha1.ImageSetConfiguration{
+		ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+			Mirror: v2alpha1.Mirror{Operators: []v2alpha1.Operator{op}},
+		},
+	}
+	filterDigest, err := op.FilterDigest()
+	assert.NoError(t, err)
+
+	t.Run("Testing OperatorImageCollector - Mirror to disk: should build the catalog of a local directory", func(t *testing.T) {
+		ex := setupFilterCollector_MirrorToDisk(tempDir, log, &MockManifest{Log: log}).withConfig(cfg)
+		res, err := ex.OperatorImageCollector(ctx)
+		assert.NoError(t, err)
+		assert.Contains(t, res.AllImages, v2alpha1.CopyImageSchema{
+			Source:      "fbc://" + catalogDir,
+			Destination: "docker://localhost:9999/curated/catalog:latest",
+			Origin:      "fbc://" + catalogDir,
+			Type:        v2alpha1.TypeOperatorCatalog,
+			RebuiltTag:  filterDigest,
+		})
+		result, ok := res.CatalogToFBCMap["fbc://"+catalogDir]
+		assert.True(t, ok)
+		assert.True(t, result.ToRebuild)
+
+		catalogDigest, err := localCatalogDigest(catalogDir)
+		assert.NoError(t, err)
+		assert.Equal(t, filepath.Join(tempDir, "working-dir", operatorCatalogsDir, "configs", catalogDigest, operatorCatalogFilteredDir, filterDigest, operatorCatalogConfigDir), result.FilteredConfigPath)
+	})
+
+	t.Run("Testing localCatalogDigest : should change along with the content of the directory", func(t *testing.T) {
+		before, err := localCatalogDigest(catalogDir)
+		assert.NoError(t, err)
+		again, err := localCatalogDigest(catalogDir)
+		assert.NoError(t, err)
+		assert.Equal(t, before, again)
+
+		assert.NoError(t, os.WriteFile(filepath.Join(catalogDir, "op1", "catalog.json"), []byte(`{"schema":"olm.package","name":"op2"}`), 0644))
+		after, err := localCatalogDigest(catalogDir)
+		assert.NoError(t, err)
+		assert.NotEqual(t, before, after)
+
+		_, err = localCatalogDigest(filepath.Join(tempDir, "missing"))
+		assert.Error(t, err)
+	})
+}
+
+func TestFilterCollectorAlreadyFilteredDeprecations(t *testing.T) {
+	log := clog.New("trace")
+	tempDir := t.TempDir()
+	ctx := context.Background()
+
+	op := v2alpha1.Operator{
+		Catalog:    "certified-operators:v4.7",
+		Deprecated: v2alpha1.DeprecatedWarn,
+		IncludeConfig: v2alpha1.IncludeConfig{
+			Packages: []v2alpha1.IncludePackage{{Name: "op1"}},
+		},
+	}
+	// the catalog was filtered by a previous run: its filtered catalog is in the cache
+	filterDigest, err := op.FilterDigest()
+	assert.NoError(t, err)
+	filteredDir := filepath.Join(tempDir, "working-dir", operatorCatalogsDir, "certified-operators",
+		"f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea", operatorCatalogFilteredDir, filterDigest)
+	assert.NoError(t, os.MkdirAll(filteredDir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(filteredDir, "digest"), []byte("f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea"), 0600))
+
+	ex := setupFilterCollector_MirrorToDisk(tempDir, log, &MockManifest{Log: log})
+	ex.ctlgHandler = MockHandler{Log: log, Deprecations: []declcfg.Deprecation{
+		{Schema: declcfg.SchemaDeprecation, Package: "op1", Entries: []declcfg.DeprecationEntry{
+			deprecationEntry(declcfg.SchemaBundle, "abc", "abc is no longer supported"),
+		}},
+	}}
+	ex = ex.withConfig(v2alpha1.ImageSetConfiguration{
+		ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+			Mirror: v2alpha1.Mirror{Operators: []v2alpha1.Operator{op}},
+		},
+	})
+
+	t.Run("Testing OperatorImageCollector - already filtered catalog: should report the deprecations of the filtered catalog", func(t *testing.T) {
+		res, err := ex.OperatorImageCollector(ctx)
+		assert.NoError(t, err)
+		result, ok := res.CatalogToFBCMap["docker://certified-operators:v4.7"]
+		assert.True(t, ok)
+		assert.False(t, result.ToRebuild)
+		assert.Equal(t, []string{"package op1: bundle abc is deprecated: abc is no longer supported"}, result.Deprecations)
+	})
+}
+
 func TestFilterCollectorD2M(t *testing.T) {
 	log := clog.New("trace")
 
@@ -391,6 +494,53 @@ func TestFilterCollectorD2M(t *testing.T) {
 
 }
 
+func TestFilterCollectorD2MCatalogSource(t *testing.T) {
+	log := clog.New("trace")
+	ctx := context.Background()
+
+	config := v2alpha1.ImageSetConfiguration{
+		ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+			Mirror: v2alpha1.Mirror{
+				Operators: []v2alpha1.Operator{
+					{
+						Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.14",
+						IncludeConfig: v2alpha1.IncludeConfig{
+							Packages: []v2alpha1.IncludePackage{{Name: "devworkspace-operator"}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	t.Run("Testing OperatorImageCollector - Disk to mirror : should copy the catalog from the cache, not the source registry", func(t *testing.T) {
+		var sources []string
+		ex := setupFilterCollector_DiskToMirror(t.TempDir(), log)
+		ex.Mirror = &MockMirror{Sources: &sources}
+		ex = ex.withConfig(config)
+		res, err := ex.OperatorImageCollector(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"docker://localhost:9999/redhat/redhat-operator-index:v4.14"}, sources)
+		assert.True(t, res.CatalogToFBCMap["docker://registry.redhat.io/redhat/redhat-operator-index:v4.14"].ToRebuild)
+	})
+
+	t.Run("Testing OperatorImageCollector - Disk to mirror : should use the catalog image of the working-dir", func(t *testing.T) {
+		var sources []string
+		tempDir := t.TempDir()
+		ex := setupFilterCollector_DiskToMirror(tempDir, log)
+		ex.Mirror = &MockMirror{Sources: &sources}
+		ex = ex.withConfig(config)
+		// the digest of the catalog returned by MockManifest
+		catalogImageDir := filepath.Join(tempDir, "working-dir", operatorCatalogsDir, "redhat-operator-index", "f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea", operatorCatalogImageDir)
+		assert.NoError(t, os.MkdirAll(catalogImageDir, 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(catalogImageDir, "index.json"), []byte("{}"), 0600))
+		res, err := ex.OperatorImageCollector(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, sources)
+		assert.Equal(t, filepath.Dir(catalogImageDir), res.CatalogToFBCMap["docker://registry.redhat.io/redhat/redhat-operator-index:v4.14"].CatalogDir)
+	})
+}
+
 func TestFilterCollectorM2M(t *testing.T) {
 	log := clog.New("trace")
 
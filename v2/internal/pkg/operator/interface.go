@@ -12,6 +12,12 @@ type CollectorInterface interface {
 	OperatorImageCollector(ctx context.Context) (v2alpha1.CollectorSchema, error)
 }
 
+// CatalogRendererInterface loads the declarative config of an operator catalog,
+// used by the list commands
+type CatalogRendererInterface interface {
+	RenderCatalog(ctx context.Context, catalog string) (OperatorCatalog, error)
+}
+
 type catalogHandlerInterface interface {
 	getDeclarativeConfig(filePath string) (*declcfg.DeclarativeConfig, error)
 	getCatalog(filePath string) (OperatorCatalog, error)
@@ -0,0 +1,145 @@
+package operator
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+)
+
+// keepLastOf returns the number of entries to keep in the channel of the package:
+// the keepLast of the channel, else the one of the package, else the one of the catalog.
+// The packages filtered by bundles are kept as selected.
+func keepLastOf(op v2alpha1.Operator, pkgName, chName string) int {
+	for _, pkg := range op.Packages {
+		if pkg.Name != pkgName {
+			continue
+		}
+		if len(pkg.SelectedBundles) > 0 {
+			return 0
+		}
+		for _, ch := range pkg.Channels {
+			if ch.Name == chName && ch.KeepLast > 0 {
+				return ch.KeepLast
+			}
+		}
+		if pkg.KeepLast > 0 {
+			return pkg.KeepLast
+		}
+	}
+	return op.KeepLast
+}
+
+// applyKeepLast trims each channel of the filtered catalog to the number of entries its keepLast sets,
+// keeping the head and the entries closest to it in the upgrade graph, so that each entry kept
+// upgrades to the head through entries kept. The bundles left out of all the channels of their package are removed.
+func applyKeepLast(filtered *declcfg.DeclarativeConfig, op v2alpha1.Operator) {
+	versions := map[string]semver.Version{}
+	for _, b := range filtered.Bundles {
+		if v, err := semver.Parse(bundleVersion(b)); err == nil {
+			versions[bundleKey(b.Package, b.Name)] = v
+		}
+	}
+
+	trimmed := map[string]bool{}
+	keptBundles := map[string]bool{}
+	for i, ch := range filtered.Channels {
+		if n := keepLastOf(op, ch.Package, ch.Name); n > 0 && len(ch.Entries) > n {
+			kept := lastEntries(ch, n, func(name string) (semver.Version, bool) {
+				v, ok := versions[bundleKey(ch.Package, name)]
+				return v, ok
+			})
+			if len(kept) > 0 {
+				filtered.Channels[i].Entries = slices.DeleteFunc(slices.Clone(ch.Entries), func(entry declcfg.ChannelEntry) bool {
+					return !kept[entry.Name]
+				})
+				trimmed[ch.Package] = true
+			}
+		}
+		for _, entry := range filtered.Channels[i].Entries {
+			keptBundles[bundleKey(ch.Package, entry.Name)] = true
+		}
+	}
+	filtered.Bundles = slices.DeleteFunc(filtered.Bundles, func(b declcfg.Bundle) bool {
+		return trimmed[b.Package] && !keptBundles[bundleKey(b.Package, b.Name)]
+	})
+}
+
+// lastEntries returns the n entries of the channel closest to its head. The channel is walked from the head,
+// each entry leading to the entries it replaces, skips or covers with its skipRange, the newest versions first.
+// It returns nothing when the channel has no head.
+func lastEntries(ch declcfg.Channel, n int, version func(string) (semver.Version, bool)) map[string]bool {
+	graph := newChannelGraph(ch)
+	inChannel := map[string]bool{}
+	for _, name := range graph.entries {
+		inChannel[name] = true
+	}
+	for _, entry := range ch.Entries {
+		if entry.SkipRange == "" {
+			continue
+		}
+		inRange, err := semver.ParseRange(entry.SkipRange)
+		if err != nil {
+			continue
+		}
+		for _, other := range graph.entries {
+			if v, ok := version(other); ok && other != entry.Name && inRange(v) {
+				graph.older[entry.Name] = append(graph.older[entry.Name], other)
+				graph.newer[other] = append(graph.newer[other], entry.Name)
+			}
+		}
+	}
+
+	newestFirst := func(names []string) {
+		slices.SortFunc(names, func(a, b string) int {
+			va, okA := version(a)
+			vb, okB := version(b)
+			switch {
+			case okA && okB:
+				if c := vb.Compare(va); c != 0 {
+					return c
+				}
+			case okA:
+				return -1
+			case okB:
+				return 1
+			}
+			return strings.Compare(a, b)
+		})
+	}
+
+	heads := []string{}
+	for _, name := range graph.entries {
+		if len(graph.newer[name]) == 0 {
+			heads = append(heads, name)
+		}
+	}
+	if len(heads) == 0 {
+		return nil
+	}
+	newestFirst(heads)
+
+	kept := map[string]bool{heads[0]: true}
+	for level := heads[:1]; len(level) > 0 && len(kept) < n; {
+		next := []string{}
+		for _, name := range level {
+			for _, older := range graph.older[name] {
+				if inChannel[older] && !kept[older] && !slices.Contains(next, older) {
+					next = append(next, older)
+				}
+			}
+		}
+		newestFirst(next)
+		for _, name := range next {
+			if len(kept) == n {
+				break
+			}
+			kept[name] = true
+		}
+		level = next
+	}
+	return kept
+}
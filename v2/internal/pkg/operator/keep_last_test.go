@@ -0,0 +1,152 @@
+package operator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+)
+
+func keepLastCatalog() declcfg.DeclarativeConfig {
+	return declcfg.DeclarativeConfig{
+		Packages: []declcfg.Package{
+			{Schema: declcfg.SchemaPackage, Name: "database", DefaultChannel: "stable"},
+			{Schema: declcfg.SchemaPackage, Name: "proxy", DefaultChannel: "stable"},
+		},
+		Channels: []declcfg.Channel{
+			testChannel("database", "stable",
+				declcfg.ChannelEntry{Name: "database.v1.0.0"},
+				declcfg.ChannelEntry{Name: "database.v1.1.0", Replaces: "database.v1.0.0"},
+				declcfg.ChannelEntry{Name: "database.v1.2.0", Replaces: "database.v1.1.0"},
+				declcfg.ChannelEntry{Name: "database.v1.3.0", Replaces: "database.v1.2.0"},
+				declcfg.ChannelEntry{Name: "database.v2.0.0", Replaces: "database.v1.3.0"},
+			),
+			testChannel("database", "fast",
+				declcfg.ChannelEntry{Name: "database.v1.3.0"},
+				declcfg.ChannelEntry{Name: "database.v2.0.0", Replaces: "database.v1.3.0"},
+			),
+			// proxy.v1.1.0 upgrades to the head through its skipRange only
+			testChannel("proxy", "stable",
+				declcfg.ChannelEntry{Name: "proxy.v1.0.0"},
+				declcfg.ChannelEntry{Name: "proxy.v1.1.0", Replaces: "proxy.v1.0.0"},
+				declcfg.ChannelEntry{Name: "proxy.v1.2.0", Replaces: "proxy.v1.1.0"},
+				declcfg.ChannelEntry{Name: "proxy.v2.0.0", Replaces: "proxy.v1.0.0", Skips: []string{"proxy.v1.2.0"}, SkipRange: ">=1.1.0 <2.0.0"},
+			),
+		},
+		Bundles: []declcfg.Bundle{
+			testBundle("database", "1.0.0"),
+			testBundle("database", "1.1.0"),
+			testBundle("database", "1.2.0"),
+			testBundle("database", "1.3.0"),
+			testBundle("database", "2.0.0"),
+			testBundle("proxy", "1.0.0"),
+			testBundle("proxy", "1.1.0"),
+			testBundle("proxy", "1.2.0"),
+			testBundle("proxy", "2.0.0"),
+		},
+	}
+}
+
+func channelEntryNames(dc declcfg.DeclarativeConfig) map[string][]string {
+	names := map[string][]string{}
+	for _, ch := range dc.Channels {
+		for _, entry := range ch.Entries {
+			names[ch.Package+"/"+ch.Name] = append(names[ch.Package+"/"+ch.Name], entry.Name)
+		}
+	}
+	return names
+}
+
+func filteredBundleNames(dc declcfg.DeclarativeConfig) []string {
+	names := []string{}
+	for _, b := range dc.Bundles {
+		names = append(names, b.Name)
+	}
+	return names
+}
+
+func TestApplyKeepLast(t *testing.T) {
+	t.Run("Testing applyKeepLast : should keep the head of each channel and the entries closest to it", func(t *testing.T) {
+		dc := keepLastCatalog()
+		applyKeepLast(&dc, v2alpha1.Operator{KeepLast: 3})
+		assert.Equal(t, map[string][]string{
+			"database/stable": {"database.v1.2.0", "database.v1.3.0", "database.v2.0.0"},
+			"database/fast":   {"database.v1.3.0", "database.v2.0.0"},
+			// proxy.v1.1.0 is kept over proxy.v1.0.0 as it is in the skipRange of the head
+			"proxy/stable": {"proxy.v1.1.0", "proxy.v1.2.0", "proxy.v2.0.0"},
+		}, channelEntryNames(dc))
+		assert.ElementsMatch(t, []string{
+			"database.v1.2.0", "database.v1.3.0", "database.v2.0.0",
+			"proxy.v1.1.0", "proxy.v1.2.0", "proxy.v2.0.0",
+		}, filteredBundleNames(dc))
+	})
+
+	t.Run("Testing applyKeepLast : the keepLast of the channel should override the one of the package and of the catalog", func(t *testing.T) {
+		dc := keepLastCatalog()
+		applyKeepLast(&dc, v2alpha1.Operator{
+			IncludeConfig: v2alpha1.IncludeConfig{Packages: []v2alpha1.IncludePackage{
+				{
+					Name:          "database",
+					IncludeBundle: v2alpha1.IncludeBundle{KeepLast: 2},
+					Channels:      []v2alpha1.IncludeChannel{{Name: "fast", IncludeBundle: v2alpha1.IncludeBundle{KeepLast: 1}}},
+				},
+			}},
+		})
+		assert.Equal(t, map[string][]string{
+			"database/stable": {"database.v1.3.0", "database.v2.0.0"},
+			"database/fast":   {"database.v2.0.0"},
+			"proxy/stable":    {"proxy.v1.0.0", "proxy.v1.1.0", "proxy.v1.2.0", "proxy.v2.0.0"},
+		}, channelEntryNames(dc))
+		assert.ElementsMatch(t, []string{
+			"database.v1.3.0", "database.v2.0.0",
+			"proxy.v1.0.0", "proxy.v1.1.0", "proxy.v1.2.0", "proxy.v2.0.0",
+		}, filteredBundleNames(dc))
+	})
+}
+
+func TestFilterCatalogKeepLast(t *testing.T) {
+	t.Run("Testing filterCatalog : should keep the last entries of the channels of a package", func(t *testing.T) {
+		op := v2alpha1.Operator{
+			Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.16",
+			IncludeConfig: v2alpha1.IncludeConfig{Packages: []v2alpha1.IncludePackage{
+				{Name: "database", IncludeBundle: v2alpha1.IncludeBundle{KeepLast: 2}},
+			}},
+		}
+		res, _, err := filterCatalog(context.TODO(), keepLastCatalog(), op)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string][]string{
+			"database/stable": {"database.v1.3.0", "database.v2.0.0"},
+			"database/fast":   {"database.v1.3.0", "database.v2.0.0"},
+		}, channelEntryNames(*res))
+		assert.ElementsMatch(t, []string{"database.v1.3.0", "database.v2.0.0"}, filteredBundleNames(*res))
+	})
+
+	t.Run("Testing filterCatalog : should keep the last entries of the channels within the version range", func(t *testing.T) {
+		op := v2alpha1.Operator{
+			Catalog: "registry.redhat.io/redhat/redhat-operator-index:v4.16",
+			IncludeConfig: v2alpha1.IncludeConfig{Packages: []v2alpha1.IncludePackage{
+				{Name: "database", DefaultChannel: "stable", IncludeBundle: v2alpha1.IncludeBundle{MaxVersion: "1.3.0", KeepLast: 2}},
+			}},
+		}
+		res, _, err := filterCatalog(context.TODO(), keepLastCatalog(), op)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"database.v1.2.0", "database.v1.3.0"}, channelEntryNames(*res)["database/stable"])
+	})
+
+	t.Run("Testing filterCatalog : should keep the last entries of all the channels of the catalog", func(t *testing.T) {
+		op := v2alpha1.Operator{
+			Catalog:  "registry.redhat.io/redhat/redhat-operator-index:v4.16",
+			KeepLast: 2,
+		}
+		res, _, err := filterCatalog(context.TODO(), keepLastCatalog(), op)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string][]string{
+			"database/stable": {"database.v1.3.0", "database.v2.0.0"},
+			"database/fast":   {"database.v1.3.0", "database.v2.0.0"},
+			"proxy/stable":    {"proxy.v1.2.0", "proxy.v2.0.0"},
+		}, channelEntryNames(*res))
+	})
+}
@@ -0,0 +1,91 @@
+package operator
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/operator-framework/operator-registry/alpha/declcfg"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/image"
+)
+
+// localCatalogDigest computes the digest of the content of a file-based catalog directory:
+// it stands for the digest of the catalog image, which does not exist until the catalog is built
+func localCatalogDigest(dir string) (string, error) {
+	hash := sha256.New()
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		fmt.Fprintf(hash, "%s\x00", filepath.ToSlash(rel))
+		_, err = io.Copy(hash, file)
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to compute the digest of catalog %s: %v", dir, err)
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
+
+// cachedLocalCatalogDigest finds, during diskToMirror, the digest under which the catalog built from a
+// file-based catalog directory was filtered: the directory is not expected to be available,
+// so the working-dir entry is the one matching the catalog built in the cache
+func (o FilterCollector) cachedLocalCatalogDigest(ctx context.Context, op v2alpha1.Operator, imgSpec image.ImageSpec) (string, error) {
+	filterDigest, err := op.FilterDigest()
+	if err != nil {
+		return "", err
+	}
+	cached, err := o.cachedCatalog(op, filterDigest)
+	if err != nil {
+		return "", err
+	}
+	// working-dir/operator-catalogs/<name>/<catalog digest>/filtered-catalogs/<filter digest>/digest
+	digestPaths, err := filepath.Glob(filepath.Join(o.Opts.Global.WorkingDir, operatorCatalogsDir, imgSpec.ComponentName(), "*", operatorCatalogFilteredDir, filterDigest, "digest"))
+	if err != nil {
+		return "", err
+	}
+	for _, digestPath := range digestPaths {
+		rebuiltDigest, err := os.ReadFile(digestPath)
+		if err != nil {
+			return "", err
+		}
+		if o.isAlreadyFiltered(ctx, cached, string(rebuiltDigest)) {
+			return filepath.Base(filepath.Dir(filepath.Dir(filepath.Dir(digestPath)))), nil
+		}
+	}
+	return "", fmt.Errorf("catalog %s was not built by the mirrorToDisk: %s not found", op.Catalog, cached)
+}
+
+// localCatalogConfig copies the base image of a file-based catalog directory, which the filtered
+// catalog gets built from, and returns the declarative config rendered from the directory
+func (o FilterCollector) localCatalogConfig(ctx context.Context, op v2alpha1.Operator, imgSpec image.ImageSpec, catalogImageDir string) (*declcfg.DeclarativeConfig, error) {
+	baseImage, err := image.ParseRef(op.BaseImage)
+	if err != nil {
+		return nil, err
+	}
+	optsCopy := o.Opts
+	optsCopy.Stdout = io.Discard
+	if err := o.Mirror.Run(ctx, baseImage.ReferenceWithTransport, ociProtocolTrimmed+catalogImageDir, "copy", &optsCopy); err != nil {
+		return nil, fmt.Errorf("unable to copy the base image %s of catalog %s: %v", op.BaseImage, op.Catalog, err)
+	}
+	dc, err := o.ctlgHandler.getDeclarativeConfig(imgSpec.Reference)
+	if err != nil {
+		return nil, fmt.Errorf("unable to render catalog %s: %v", op.Catalog, err)
+	}
+	return dc, nil
+}
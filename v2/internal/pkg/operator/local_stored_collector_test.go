@@ -8,6 +8,7 @@ import (
 
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/containers/image/v5/types"
 	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
@@ -24,6 +25,8 @@ import (
 
 type MockMirror struct {
 	Fail bool
+	// Sources records the sources of the images copied, when set
+	Sources *[]string
 }
 
 type MockManifest struct {
@@ -31,10 +34,12 @@ type MockManifest struct {
 	FailImageIndex    bool
 	FailImageManifest bool
 	FailExtract       bool
+	FailDigest        bool
 }
 
 type MockHandler struct {
-	Log clog.PluggableLoggerInterface
+	Log          clog.PluggableLoggerInterface
+	Deprecations []declcfg.Deprecation
 }
 
 var (
@@ -745,6 +750,9 @@ func setupCollector_MirrorToDisk(tempDir string, log clog.PluggableLoggerInterfa
 }
 
 func (o MockMirror) Run(ctx context.Context, src, dest string, mode mirror.Mode, opts *mirror.CopyOptions) error {
+	if o.Sources != nil {
+		*o.Sources = append(*o.Sources, src)
+	}
 	if o.Fail {
 		return fmt.Errorf("forced mirror run fail")
 	}
@@ -829,9 +837,20 @@ func (o MockManifest) ConvertIndexToSingleManifest(dir string, oci *v2alpha1.OCI
 }
 
 func (o MockManifest) GetDigest(ctx context.Context, sourceCtx *types.SystemContext, imgRef string) (string, error) {
+	if o.FailDigest {
+		return "", fmt.Errorf("manifest unknown")
+	}
 	return "f30638f60452062aba36a26ee6c036feead2f03b28f2c47f2b0a991e41baebea", nil
 }
 
+func (o MockManifest) ListTags(ctx context.Context, sourceCtx *types.SystemContext, imgRef string) ([]string, error) {
+	return []string{}, nil
+}
+
+func (o MockManifest) GetImageCreationDate(ctx context.Context, sourceCtx *types.SystemContext, imgRef string) (time.Time, error) {
+	return time.Time{}, nil
+}
+
 func (ex *LocalStorageCollector) withConfig(cfg v2alpha1.ImageSetConfiguration) *LocalStorageCollector {
 	ex.Config = cfg
 	return ex
@@ -884,5 +903,6 @@ func (o MockHandler) getDeclarativeConfig(filePath string) (*declcfg.Declarative
 				},
 			},
 		},
+		Deprecations: o.Deprecations,
 	}, nil
 }
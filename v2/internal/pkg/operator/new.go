@@ -26,3 +26,11 @@ func NewWithFilter(log clog.PluggableLoggerInterface,
 ) CollectorInterface {
 	return &FilterCollector{OperatorCollector{Log: log, LogsDir: logsDir, Config: config, Opts: opts, Mirror: mirror, Manifest: manifest, LocalStorageFQDN: opts.LocalStorageFQDN, ctlgHandler: catalogHandler{Log: log}}}
 }
+
+func NewCatalogRenderer(log clog.PluggableLoggerInterface,
+	opts mirror.CopyOptions,
+	mirror mirror.MirrorInterface,
+	manifest manifest.ManifestInterface,
+) CatalogRendererInterface {
+	return &OperatorCollector{Log: log, Opts: opts, Mirror: mirror, Manifest: manifest, ctlgHandler: catalogHandler{Log: log}}
+}
@@ -32,4 +32,6 @@ const (
 	logFile                        = "release.log"
 	releaseImagePathComponents     = "openshift/release-images"
 	releaseComponentPathComponents = "openshift/release"
+	samplesDir                     = "samples"
+	skippedImageStreamsFile        = "skipped-imagestreams.json"
 )
@@ -0,0 +1,92 @@
+package release
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver/v4"
+)
+
+const (
+	// releaseChannelsMetadata is the node metadata listing the channels a release belongs to
+	releaseChannelsMetadata = "io.openshift.upgrades.graph.release.channels"
+	// candidateChannelPrefix is the channel where all the releases of a version are published
+	candidateChannelPrefix = "candidate-"
+)
+
+// ReleaseSummary describes a release of an update channel
+type ReleaseSummary struct {
+	Version string `json:"version"`
+	Image   string `json:"image"`
+}
+
+// ListReleases returns the releases published in the channel, sorted by version
+func ListReleases(ctx context.Context, cs CincinnatiSchema, channel string) ([]ReleaseSummary, error) {
+	cs.Client.SetQueryParams(cs.CincinnatiParams.Arch, channel, "")
+	graph, err := getGraphData(ctx, cs)
+	if err != nil {
+		return nil, &Error{
+			Reason:  "APIRequestError",
+			Message: fmt.Sprintf(ChannelInfo, channel, err),
+			cause:   err,
+		}
+	}
+	if len(graph.Nodes) == 0 {
+		return nil, &Error{
+			Reason:  "NoVersionsFound",
+			Message: fmt.Sprintf("no cluster versions found in the %q channel", channel),
+		}
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool {
+		return graph.Nodes[i].Version.LT(graph.Nodes[j].Version)
+	})
+	releases := make([]ReleaseSummary, 0, len(graph.Nodes))
+	for _, node := range graph.Nodes {
+		releases = append(releases, ReleaseSummary{Version: node.Version.String(), Image: node.Image})
+	}
+	return releases, nil
+}
+
+// ListChannels returns the channels in which the releases of version (major.minor) are published.
+// The releases are read from the candidate channel of the version, which contains all of them.
+func ListChannels(ctx context.Context, cs CincinnatiSchema, version string) ([]string, error) {
+	requested, err := semver.ParseTolerant(version)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version %s: %v", version, err)
+	}
+	channel := fmt.Sprintf("%s%d.%d", candidateChannelPrefix, requested.Major, requested.Minor)
+	cs.Client.SetQueryParams(cs.CincinnatiParams.Arch, channel, "")
+	graph, err := getGraphData(ctx, cs)
+	if err != nil {
+		return nil, &Error{
+			Reason:  "APIRequestError",
+			Message: fmt.Sprintf(ChannelInfo, channel, err),
+			cause:   err,
+		}
+	}
+
+	seen := make(map[string]bool)
+	var channels []string
+	for _, node := range graph.Nodes {
+		if node.Version.Major != requested.Major || node.Version.Minor != requested.Minor {
+			continue
+		}
+		for _, ch := range strings.Split(node.Metadata[releaseChannelsMetadata], ",") {
+			ch = strings.TrimSpace(ch)
+			if ch != "" && !seen[ch] {
+				seen[ch] = true
+				channels = append(channels, ch)
+			}
+		}
+	}
+	if len(channels) == 0 {
+		return nil, &Error{
+			Reason:  "NoChannelsFound",
+			Message: fmt.Sprintf("no channels found for version %s", version),
+		}
+	}
+	sort.Strings(channels)
+	return channels, nil
+}
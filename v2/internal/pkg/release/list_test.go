@@ -0,0 +1,78 @@
+package release
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListReleases(t *testing.T) {
+	log := clog.New("trace")
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		channels := r.URL.Query()["channel"]
+		switch channels[len(channels)-1] {
+		case "candidate-4.16", "stable-4.16":
+			_, _ = w.Write([]byte(`{
+				"nodes": [
+					{"version": "4.16.2", "payload": "quay.io/openshift-release-dev/ocp-release:4.16.2-x86_64",
+					 "metadata": {"io.openshift.upgrades.graph.release.channels": "candidate-4.16,fast-4.16,stable-4.16"}},
+					{"version": "4.16.0", "payload": "quay.io/openshift-release-dev/ocp-release:4.16.0-x86_64",
+					 "metadata": {"io.openshift.upgrades.graph.release.channels": "candidate-4.16,fast-4.16,stable-4.16,candidate-4.17"}},
+					{"version": "4.15.20", "payload": "quay.io/openshift-release-dev/ocp-release:4.15.20-x86_64",
+					 "metadata": {"io.openshift.upgrades.graph.release.channels": "candidate-4.15,stable-4.15"}}
+				],
+				"edges": [[2,1],[1,0]]
+			}`))
+		default:
+			_, _ = w.Write([]byte(`{"nodes": [], "edges": []}`))
+		}
+	}
+	ts := httptest.NewServer(http.HandlerFunc(handler))
+	t.Cleanup(ts.Close)
+
+	newSchema := func(t *testing.T) CincinnatiSchema {
+		endpoint, err := url.Parse(ts.URL)
+		if err != nil {
+			t.Fatalf("should not fail endpoint parse")
+		}
+		return CincinnatiSchema{
+			Log:              log,
+			Opts:             mirror.CopyOptions{Mode: mirror.MirrorToDisk, Global: &mirror.GlobalOptions{}},
+			Client:           &mockClient{url: endpoint},
+			CincinnatiParams: CincinnatiParams{GraphDataDir: t.TempDir(), Arch: "amd64"},
+		}
+	}
+
+	t.Run("Testing ListReleases : should return the releases sorted by version", func(t *testing.T) {
+		releases, err := ListReleases(context.Background(), newSchema(t), "stable-4.16")
+		assert.NoError(t, err)
+		assert.Equal(t, []ReleaseSummary{
+			{Version: "4.15.20", Image: "quay.io/openshift-release-dev/ocp-release:4.15.20-x86_64"},
+			{Version: "4.16.0", Image: "quay.io/openshift-release-dev/ocp-release:4.16.0-x86_64"},
+			{Version: "4.16.2", Image: "quay.io/openshift-release-dev/ocp-release:4.16.2-x86_64"},
+		}, releases)
+	})
+
+	t.Run("Testing ListReleases : empty channel should fail", func(t *testing.T) {
+		_, err := ListReleases(context.Background(), newSchema(t), "stable-4.99")
+		assert.Error(t, err)
+	})
+
+	t.Run("Testing ListChannels : should return the channels of the version", func(t *testing.T) {
+		channels, err := ListChannels(context.Background(), newSchema(t), "4.16")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"candidate-4.16", "candidate-4.17", "fast-4.16", "stable-4.16"}, channels)
+	})
+
+	t.Run("Testing ListChannels : invalid version should fail", func(t *testing.T) {
+		_, err := ListChannels(context.Background(), newSchema(t), "four")
+		assert.Error(t, err)
+	})
+}
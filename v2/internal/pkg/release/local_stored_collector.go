@@ -55,6 +55,7 @@ func (o *LocalStorageCollector) ReleaseImageCollector(ctx context.Context) ([]v2
 	o.Log.Debug(collectorPrefix+"setting copy option o.Opts.MultiArch=%s when collecting releases image", o.Opts.MultiArch)
 	var allImages []v2alpha1.CopyImageSchema
 	var imageIndexDir string
+	var sampleImageStreams []string
 	if o.Opts.IsMirrorToDisk() || o.Opts.IsMirrorToMirror() {
 		releases, err := o.Cincinnati.GetReleaseReferenceImages(ctx)
 		if err != nil {
@@ -148,6 +149,15 @@ func (o *LocalStorageCollector) ReleaseImageCollector(ctx context.Context) ([]v2
 				}
 			}
 
+			if len(o.Config.Mirror.Samples) > 0 {
+				sampleImages, imageStreams, err := o.getSampleImages(cacheDir)
+				if err != nil {
+					return []v2alpha1.CopyImageSchema{}, fmt.Errorf(errMsg, err.Error())
+				}
+				allRelatedImages = append(allRelatedImages, sampleImages...)
+				sampleImageStreams = append(sampleImageStreams, imageStreams...)
+			}
+
 			//add the release image itself
 			allRelatedImages = append(allRelatedImages, v2alpha1.RelatedImage{Image: value.Source, Name: value.Source, Type: v2alpha1.TypeOCPRelease})
 			tmpAllImages, err := o.prepareM2DCopyBatch(allRelatedImages, releaseTag)
@@ -221,6 +231,15 @@ func (o *LocalStorageCollector) ReleaseImageCollector(ctx context.Context) ([]v2
 				}
 			}
 
+			if len(o.Config.Mirror.Samples) > 0 {
+				sampleImages, imageStreams, err := o.getSampleImages(releaseDir)
+				if err != nil {
+					return []v2alpha1.CopyImageSchema{}, fmt.Errorf(errMsg, err.Error())
+				}
+				releaseRelatedImages = append(releaseRelatedImages, sampleImages...)
+				sampleImageStreams = append(sampleImageStreams, imageStreams...)
+			}
+
 			releaseCopyImages, err := o.prepareD2MCopyBatch(releaseRelatedImages, releaseTag)
 			if err != nil {
 				o.Log.Error(errMsg, err.Error())
@@ -274,6 +293,12 @@ func (o *LocalStorageCollector) ReleaseImageCollector(ctx context.Context) ([]v2
 		}
 	}
 
+	if len(o.Config.Mirror.Samples) > 0 {
+		if err := o.writeSkippedImageStreams(sampleImageStreams); err != nil {
+			return []v2alpha1.CopyImageSchema{}, err
+		}
+	}
+
 	//OCPBUGS-43275: deduplicating
 	slices.SortFunc(allImages, func(a, b v2alpha1.CopyImageSchema) int {
 		cmp := strings.Compare(a.Origin, b.Origin)
@@ -550,7 +575,8 @@ func preparePathComponents(imgSpec image.ImageSpec, imgType v2alpha1.ImageType,
 	switch {
 	case imgType == v2alpha1.TypeOCPRelease:
 		pathComponents = releaseImagePathComponents
-	case imgType == v2alpha1.TypeCincinnatiGraph:
+	case imgType == v2alpha1.TypeCincinnatiGraph || imgType == v2alpha1.TypeSampleImage:
+		// samples keep their path, the samples operator only substitutes the registry
 		pathComponents = imgSpec.PathComponent
 	case imgType == v2alpha1.TypeOCPReleaseContent && imgName != "":
 		pathComponents = releaseComponentPathComponents
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/containers/image/v5/types"
 	"github.com/google/uuid"
@@ -742,6 +743,14 @@ func (o MockManifest) GetDigest(ctx context.Context, sourceCtx *types.SystemCont
 	return "3ef0b0141abd1548f60c4f3b23ecfc415142b0e842215f38e98610a3b2e52419", nil
 }
 
+func (o MockManifest) ListTags(ctx context.Context, sourceCtx *types.SystemContext, imgRef string) ([]string, error) {
+	return []string{}, nil
+}
+
+func (o MockManifest) GetImageCreationDate(ctx context.Context, sourceCtx *types.SystemContext, imgRef string) (time.Time, error) {
+	return time.Time{}, nil
+}
+
 func (o MockCincinnati) GetReleaseReferenceImages(ctx context.Context) ([]v2alpha1.CopyImageSchema, error) {
 	var res []v2alpha1.CopyImageSchema
 	res = append(res, v2alpha1.CopyImageSchema{Type: v2alpha1.TypeOCPRelease, Source: "quay.io/openshift-release-dev/ocp-release:4.13.10-x86_64", Origin: "quay.io/openshift-release-dev/ocp-release:4.13.10-x86_64"})
@@ -800,3 +809,11 @@ func (o *ManifestMock) GetDigest(ctx context.Context, sourceCtx *types.SystemCon
 	args := o.Called(ctx, sourceCtx, imgRef)
 	return args.String(0), args.Error(1)
 }
+
+func (o *ManifestMock) ListTags(ctx context.Context, sourceCtx *types.SystemContext, imgRef string) ([]string, error) {
+	return []string{}, nil
+}
+
+func (o *ManifestMock) GetImageCreationDate(ctx context.Context, sourceCtx *types.SystemContext, imgRef string) (time.Time, error) {
+	return time.Time{}, nil
+}
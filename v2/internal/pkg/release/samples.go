@@ -0,0 +1,134 @@
+package release
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	imagev1 "github.com/openshift/api/image/v1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// getSampleImages returns the images of the samples operator imagestreams found in the
+// release manifests and selected by the samples of the imagesetconfig, along with the names
+// of all the imagestreams found.
+func (o LocalStorageCollector) getSampleImages(releaseArtifactsDir string) ([]v2alpha1.RelatedImage, []string, error) {
+	imageStreams, err := readSampleImageStreams(filepath.Join(releaseArtifactsDir, releaseManifests))
+	if err != nil {
+		return nil, nil, err
+	}
+	names := make([]string, 0, len(imageStreams))
+	for _, is := range imageStreams {
+		names = append(names, is.Name)
+	}
+	images := selectSampleImages(imageStreams, o.Config.Mirror.Samples)
+	if len(images) == 0 {
+		o.Log.Warn(collectorPrefix+"no samples imagestream of the release matches the samples in the imagesetconfig (%s)", releaseArtifactsDir)
+	}
+	return images, names, nil
+}
+
+// readSampleImageStreams parses all the ImageStreams declared in the release manifests,
+// except image-references which describes the release payload itself.
+func readSampleImageStreams(manifestsDir string) ([]imagev1.ImageStream, error) {
+	entries, err := os.ReadDir(manifestsDir)
+	if err != nil {
+		return nil, fmt.Errorf("reading release manifests %v", err)
+	}
+	var imageStreams []imagev1.ImageStream
+	for _, entry := range entries {
+		if entry.IsDir() || entry.Name() == imageReferences {
+			continue
+		}
+		ext := filepath.Ext(entry.Name())
+		if ext != ".yaml" && ext != ".yml" && ext != ".json" {
+			continue
+		}
+		file, err := os.Open(filepath.Join(manifestsDir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("reading release manifest %s %v", entry.Name(), err)
+		}
+		decoder := utilyaml.NewYAMLOrJSONDecoder(file, 4096)
+		for {
+			var is imagev1.ImageStream
+			if err := decoder.Decode(&is); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				file.Close()
+				return nil, fmt.Errorf("parsing release manifest %s %v", entry.Name(), err)
+			}
+			if is.Kind == "ImageStream" {
+				imageStreams = append(imageStreams, is)
+			}
+		}
+		file.Close()
+	}
+	return imageStreams, nil
+}
+
+// selectSampleImages keeps the DockerImage references of the imagestream tags
+// selected either by imagestream name or by name:tag
+func selectSampleImages(imageStreams []imagev1.ImageStream, samples []v2alpha1.SampleImages) []v2alpha1.RelatedImage {
+	var images []v2alpha1.RelatedImage
+	for _, is := range imageStreams {
+		for _, tag := range is.Spec.Tags {
+			if tag.From == nil || tag.From.Kind != "DockerImage" || tag.From.Name == "" {
+				continue
+			}
+			if !isSampleSelected(samples, is.Name, tag.Name) {
+				continue
+			}
+			images = append(images, v2alpha1.RelatedImage{
+				Name:  is.Name + ":" + tag.Name,
+				Image: tag.From.Name,
+				Type:  v2alpha1.TypeSampleImage,
+			})
+		}
+	}
+	return images
+}
+
+func isSampleSelected(samples []v2alpha1.SampleImages, imageStream, tag string) bool {
+	for _, sample := range samples {
+		name, sampleTag, hasTag := strings.Cut(sample.Name, ":")
+		if name == imageStream && (!hasTag || sampleTag == tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeSkippedImageStreams records in the working-dir the samples imagestreams
+// of the releases that were not mirrored, so that they are excluded from the
+// samples operator configuration generated with the cluster resources.
+func (o LocalStorageCollector) writeSkippedImageStreams(allImageStreams []string) error {
+	var skipped []string
+	for _, name := range allImageStreams {
+		selected := slices.ContainsFunc(o.Config.Mirror.Samples, func(sample v2alpha1.SampleImages) bool {
+			isName, _, _ := strings.Cut(sample.Name, ":")
+			return isName == name
+		})
+		if !selected && !slices.Contains(skipped, name) {
+			skipped = append(skipped, name)
+		}
+	}
+	sort.Strings(skipped)
+
+	dir := filepath.Join(o.Opts.Global.WorkingDir, samplesDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf(errMsg, err.Error())
+	}
+	data, err := json.Marshal(skipped)
+	if err != nil {
+		return fmt.Errorf(errMsg, err.Error())
+	}
+	return os.WriteFile(filepath.Join(dir, skippedImageStreamsFile), data, 0600)
+}
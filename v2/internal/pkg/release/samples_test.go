@@ -0,0 +1,128 @@
+package release
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+	"github.com/stretchr/testify/assert"
+)
+
+const samplesImageStreams = `apiVersion: image.openshift.io/v1
+kind: ImageStream
+metadata:
+  name: ruby
+  namespace: openshift
+spec:
+  tags:
+  - name: "3.0-ubi8"
+    from:
+      kind: DockerImage
+      name: registry.redhat.io/ubi8/ruby-30:latest
+  - name: "3.1-ubi9"
+    from:
+      kind: DockerImage
+      name: registry.redhat.io/ubi9/ruby-31@sha256:7c4ef7434c97c8aaf6cd310874790b915b3c61fc902eea255f9177058ea9aff3
+  - name: latest
+    from:
+      kind: ImageStreamTag
+      name: 3.1-ubi9
+---
+apiVersion: image.openshift.io/v1
+kind: ImageStream
+metadata:
+  name: perl
+  namespace: openshift
+spec:
+  tags:
+  - name: "5.32-ubi8"
+    from:
+      kind: DockerImage
+      name: registry.redhat.io/ubi8/perl-532:latest
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: not-an-imagestream
+`
+
+func TestSampleImages(t *testing.T) {
+	log := clog.New("trace")
+
+	releaseDir := t.TempDir()
+	manifestsDir := filepath.Join(releaseDir, releaseManifests)
+	assert.NoError(t, os.MkdirAll(manifestsDir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(manifestsDir, "0000_50_cluster-samples-operator_imagestreams.yaml"), []byte(samplesImageStreams), 0644))
+	// image-references describes the payload and is not a sample
+	assert.NoError(t, os.WriteFile(filepath.Join(manifestsDir, imageReferences), []byte(`{"kind":"ImageStream","apiVersion":"image.openshift.io/v1","metadata":{"name":"4.16.0"},"spec":{"tags":[{"name":"cli","from":{"kind":"DockerImage","name":"quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:6d76ffca7a233213325907bae611e835b49c5b933095be1328351f4f5fc67615"}}]}}`), 0644))
+
+	t.Run("Testing readSampleImageStreams : should only read imagestreams", func(t *testing.T) {
+		imageStreams, err := readSampleImageStreams(manifestsDir)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(imageStreams))
+		assert.Equal(t, "ruby", imageStreams[0].Name)
+		assert.Equal(t, "perl", imageStreams[1].Name)
+	})
+
+	t.Run("Testing getSampleImages : should select by imagestream and by tag", func(t *testing.T) {
+		workingDir := t.TempDir()
+		ex := &LocalStorageCollector{
+			Log: log,
+			Config: v2alpha1.ImageSetConfiguration{
+				ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+					Mirror: v2alpha1.Mirror{
+						Samples: []v2alpha1.SampleImages{
+							{Image: v2alpha1.Image{Name: "ruby:3.1-ubi9"}},
+							{Image: v2alpha1.Image{Name: "nodejs"}},
+						},
+					},
+				},
+			},
+			Opts: mirror.CopyOptions{Global: &mirror.GlobalOptions{WorkingDir: workingDir}},
+		}
+		images, imageStreams, err := ex.getSampleImages(releaseDir)
+		assert.NoError(t, err)
+		assert.Equal(t, []v2alpha1.RelatedImage{
+			{
+				Name:  "ruby:3.1-ubi9",
+				Image: "registry.redhat.io/ubi9/ruby-31@sha256:7c4ef7434c97c8aaf6cd310874790b915b3c61fc902eea255f9177058ea9aff3",
+				Type:  v2alpha1.TypeSampleImage,
+			},
+		}, images)
+		assert.Equal(t, []string{"ruby", "perl"}, imageStreams)
+
+		assert.NoError(t, ex.writeSkippedImageStreams(append(imageStreams, imageStreams...)))
+		data, err := os.ReadFile(filepath.Join(workingDir, samplesDir, skippedImageStreamsFile))
+		assert.NoError(t, err)
+		var skipped []string
+		assert.NoError(t, json.Unmarshal(data, &skipped))
+		assert.Equal(t, []string{"perl"}, skipped)
+	})
+
+	t.Run("Testing selectSampleImages : whole imagestream", func(t *testing.T) {
+		imageStreams, err := readSampleImageStreams(manifestsDir)
+		assert.NoError(t, err)
+		images := selectSampleImages(imageStreams, []v2alpha1.SampleImages{{Image: v2alpha1.Image{Name: "ruby"}}})
+		assert.Equal(t, 2, len(images))
+		assert.Equal(t, "registry.redhat.io/ubi8/ruby-30:latest", images[0].Image)
+	})
+
+	t.Run("Testing preparePathComponents and prepareTag : samples keep their path", func(t *testing.T) {
+		ex := &LocalStorageCollector{
+			Log:              log,
+			LocalStorageFQDN: "localhost:55000",
+			Opts:             mirror.CopyOptions{Mode: mirror.MirrorToDisk, Global: &mirror.GlobalOptions{}},
+		}
+		res, err := ex.prepareM2DCopyBatch([]v2alpha1.RelatedImage{
+			{Name: "ruby:3.0-ubi8", Image: "registry.redhat.io/ubi8/ruby-30:latest", Type: v2alpha1.TypeSampleImage},
+			{Name: "ruby:3.1-ubi9", Image: "registry.redhat.io/ubi9/ruby-31@sha256:7c4ef7434c97c8aaf6cd310874790b915b3c61fc902eea255f9177058ea9aff3", Type: v2alpha1.TypeSampleImage},
+		}, "4.16.0-x86_64")
+		assert.NoError(t, err)
+		assert.Equal(t, "docker://localhost:55000/ubi8/ruby-30:latest", res[0].Destination)
+		assert.Equal(t, "docker://localhost:55000/ubi9/ruby-31:sha256-7c4ef7434c97c8aaf6cd310874790b915b3c61fc902eea255f9177058ea9aff3", res[1].Destination)
+	})
+}
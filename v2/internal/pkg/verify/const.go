@@ -0,0 +1,6 @@
+package verify
+
+const (
+	verifyErrMsg   string = "[verify] %v"
+	dockerProtocol string = "docker://"
+)
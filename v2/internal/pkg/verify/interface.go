@@ -0,0 +1,17 @@
+package verify
+
+import (
+	"context"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+)
+
+type VerifyInterface interface {
+	// Verify compares the destination registry with the images collected for the imageset configuration
+	Verify(ctx context.Context, images []v2alpha1.CopyImageSchema) (Report, error)
+}
+
+// RepositoryLister lists the repositories of a registry
+type RepositoryLister interface {
+	ListRepositories(ctx context.Context, registry string) ([]string, error)
+}
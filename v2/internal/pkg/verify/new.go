@@ -0,0 +1,21 @@
+package verify
+
+import (
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/manifest"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+)
+
+func New(log clog.PluggableLoggerInterface,
+	opts *mirror.CopyOptions,
+	mirror mirror.MirrorInterface,
+	manifest manifest.ManifestInterface,
+) VerifyInterface {
+	return &Verifier{
+		Log:          log,
+		Opts:         opts,
+		Mirror:       mirror,
+		Manifest:     manifest,
+		Repositories: NewRegistryCatalog(opts),
+	}
+}
@@ -0,0 +1,49 @@
+package verify
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+)
+
+// registryCatalog lists the repositories of a registry with the catalog API (/v2/_catalog)
+type registryCatalog struct {
+	opts *mirror.CopyOptions
+}
+
+// NewRegistryCatalog returns a RepositoryLister using the catalog API of the registry.
+// The registry is accessed as the images are copied to the destination: with the destination
+// credentials (--dest-creds, --dest-authfile, else the default auth files) and TLS verification
+// (--dest-tls-verify, else the registries.conf entry of the registry).
+func NewRegistryCatalog(opts *mirror.CopyOptions) RepositoryLister {
+	return registryCatalog{opts: opts}
+}
+
+func (o registryCatalog) ListRepositories(ctx context.Context, registry string) ([]string, error) {
+	sysCtx, err := o.opts.DestImage.NewSystemContext()
+	if err != nil {
+		return nil, err
+	}
+	insecure, err := mirror.IsInsecureRegistry(sysCtx, registry)
+	if err != nil {
+		return nil, err
+	}
+	nameOpts := []name.Option{}
+	roundTripper := remote.DefaultTransport
+	if insecure {
+		nameOpts = append(nameOpts, name.Insecure)
+		insecureTransport := remote.DefaultTransport.(*http.Transport).Clone()
+		insecureTransport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true, MinVersion: tls.VersionTLS12} //nolint:gosec // requested with --dest-tls-verify=false or registries.conf
+		roundTripper = insecureTransport
+	}
+	reg, err := name.NewRegistry(registry, nameOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return remote.Catalog(ctx, reg, remote.WithAuthFromKeychain(mirror.CredentialsKeychain{SysCtx: sysCtx}), remote.WithTransport(roundTripper))
+}
@@ -0,0 +1,44 @@
+package verify
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+	"github.com/openshift/oc-mirror/v2/internal/testutils"
+)
+
+func TestRegistryCatalog(t *testing.T) {
+	server := testutils.CreateRegistry()
+	t.Cleanup(server.Close)
+	imgRef, err := testutils.WriteTestImage(server, "")
+	assert.NoError(t, err)
+	registry, _, _ := strings.Cut(imgRef, "/")
+
+	global := &mirror.GlobalOptions{SecurePolicy: false}
+	_, sharedOpts := mirror.SharedImageFlags()
+	_, deprecatedTLSVerifyOpt := mirror.DeprecatedTLSVerifyFlags()
+	destFlags, destOpts := mirror.ImageDestFlags(global, sharedOpts, deprecatedTLSVerifyOpt, "dest-", "dcreds")
+	opts := &mirror.CopyOptions{Global: global, DestImage: destOpts}
+
+	t.Run("Testing ListRepositories : should list the repositories of the destination", func(t *testing.T) {
+		destOpts.TlsVerify = false
+		repositories, err := NewRegistryCatalog(opts).ListRepositories(context.Background(), registry)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"bar"}, repositories)
+	})
+
+	t.Run("Testing ListRepositories : should fail when the destination credentials can't be read", func(t *testing.T) {
+		destOpts.TlsVerify = false
+		authFile := filepath.Join(t.TempDir(), "auth.json")
+		assert.NoError(t, os.WriteFile(authFile, []byte("not json"), 0600))
+		assert.NoError(t, destFlags.Set("dest-authfile", authFile))
+		_, err := NewRegistryCatalog(opts).ListRepositories(context.Background(), registry)
+		assert.ErrorContains(t, err, "unable to get the credentials of "+registry)
+	})
+}
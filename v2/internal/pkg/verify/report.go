@@ -0,0 +1,115 @@
+package verify
+
+import (
+	"strings"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/image"
+)
+
+// Report is the result of the verification of a destination registry
+type Report struct {
+	Destination string        `json:"destination"`
+	Summary     ReportSummary `json:"summary"`
+	// Missing are the images of the imageset configuration not found in the destination
+	Missing []ImageDrift `json:"missing"`
+	// Mismatched are the images found in the destination with a digest different from the source
+	Mismatched []ImageDrift `json:"mismatched"`
+	// Unverified are the images found in the destination whose digest could not be compared,
+	// as the digest of the source could not be fetched
+	Unverified []ImageDrift `json:"unverified"`
+	// ExtraRepositories are the repositories under the destination that no image of the
+	// imageset configuration is mirrored to
+	ExtraRepositories []string `json:"extraRepositories"`
+}
+
+// ReportSummary counts the images and repositories of a Report
+type ReportSummary struct {
+	Expected          int `json:"expected"`
+	Verified          int `json:"verified"`
+	Missing           int `json:"missing"`
+	Mismatched        int `json:"mismatched"`
+	Unverified        int `json:"unverified"`
+	ExtraRepositories int `json:"extraRepositories"`
+}
+
+// ImageDrift is an image of the imageset configuration that is not as expected in the destination
+type ImageDrift struct {
+	Origin         string `json:"origin"`
+	Destination    string `json:"destination"`
+	Type           string `json:"type"`
+	ExpectedDigest string `json:"expectedDigest,omitempty"`
+	ActualDigest   string `json:"actualDigest,omitempty"`
+}
+
+// HasDrift is true when images are missing or mismatched in the destination.
+// Extra repositories are reported, but are not considered as a drift.
+func (r Report) HasDrift() bool {
+	return len(r.Missing) > 0 || len(r.Mismatched) > 0
+}
+
+// IsVerified is true when all the images are in the destination, and the digests
+// of the images referenced by tag were compared with the source and match
+func (r Report) IsVerified() bool {
+	return !r.HasDrift() && len(r.Unverified) == 0
+}
+
+// MissingConfig returns an imageset configuration mirroring the missing images, each of them
+// in the section of its type:
+//   - a catalog with the operator filter it has in isc
+//   - a release payload as the release of the platform, only one can be set
+//   - the other images referenced by a docker reference as additional images
+//
+// It also returns the missing images that can't be expressed in the imageset configuration:
+// the images of a release payload, the graph image, the images of an oci layout,
+// and the catalogs or release payloads that don't fit in it.
+func (r Report) MissingConfig(isc v2alpha1.ImageSetConfiguration) (v2alpha1.ImageSetConfiguration, []ImageDrift) {
+	cfg := v2alpha1.ImageSetConfiguration{}
+	cfg.SetGroupVersionKind(v2alpha1.GroupVersion.WithKind(v2alpha1.ImageSetConfigurationKind))
+	skipped := []ImageDrift{}
+	seen := map[string]bool{}
+	for _, img := range r.Missing {
+		if seen[img.Origin] {
+			continue
+		}
+		seen[img.Origin] = true
+		switch img.Type {
+		case v2alpha1.TypeOperatorCatalog.String():
+			op, found := catalogOperator(isc, img.Origin)
+			if !found {
+				skipped = append(skipped, img)
+				continue
+			}
+			cfg.Mirror.Operators = append(cfg.Mirror.Operators, op)
+		case v2alpha1.TypeOCPRelease.String():
+			if cfg.Mirror.Platform.Release != "" {
+				skipped = append(skipped, img)
+				continue
+			}
+			cfg.Mirror.Platform.Release = img.Origin
+		case v2alpha1.TypeOCPReleaseContent.String(), v2alpha1.TypeCincinnatiGraph.String(), v2alpha1.TypeKubeVirtContainer.String():
+			skipped = append(skipped, img)
+		default:
+			name, isDocker := strings.CutPrefix(img.Origin, dockerProtocol)
+			if !isDocker {
+				skipped = append(skipped, img)
+				continue
+			}
+			cfg.Mirror.AdditionalImages = append(cfg.Mirror.AdditionalImages, v2alpha1.Image{Name: name})
+		}
+	}
+	return cfg, skipped
+}
+
+// catalogOperator returns the operator of isc mirroring the catalog origin
+func catalogOperator(isc v2alpha1.ImageSetConfiguration, origin string) (v2alpha1.Operator, bool) {
+	for _, op := range isc.Mirror.Operators {
+		if op.Catalog == origin || dockerProtocol+op.Catalog == origin {
+			return op, true
+		}
+		if spec, err := image.ParseRef(op.Catalog); err == nil && spec.ReferenceWithTransport == origin {
+			return op, true
+		}
+	}
+	return v2alpha1.Operator{}, false
+}
@@ -0,0 +1,147 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/containers/image/v5/types"
+	digest "github.com/opencontainers/go-digest"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/image"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/manifest"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+)
+
+type Verifier struct {
+	Log          clog.PluggableLoggerInterface
+	Opts         *mirror.CopyOptions
+	Mirror       mirror.MirrorInterface
+	Manifest     manifest.ManifestInterface
+	Repositories RepositoryLister
+}
+
+// Verify checks that each image exists in the destination and, when the destination
+// is referenced by tag, that its digest is the digest of the source.
+// It then lists the repositories under the destination that aren't expected.
+func (o *Verifier) Verify(ctx context.Context, images []v2alpha1.CopyImageSchema) (Report, error) {
+	report := Report{
+		Destination:       o.Opts.Destination,
+		Missing:           []ImageDrift{},
+		Mismatched:        []ImageDrift{},
+		Unverified:        []ImageDrift{},
+		ExtraRepositories: []string{},
+	}
+	srcCtx, err := o.Opts.SrcImage.NewSystemContext()
+	if err != nil {
+		return report, fmt.Errorf(verifyErrMsg, err)
+	}
+	destCtx, err := o.Opts.DestImage.NewSystemContext()
+	if err != nil {
+		return report, fmt.Errorf(verifyErrMsg, err)
+	}
+
+	expectedRepositories := map[string]bool{}
+	seen := map[string]bool{}
+	for _, img := range images {
+		if seen[img.Destination] {
+			continue
+		}
+		seen[img.Destination] = true
+		report.Summary.Expected++
+
+		destSpec, err := image.ParseRef(img.Destination)
+		if err != nil {
+			return report, fmt.Errorf(verifyErrMsg, err)
+		}
+		expectedRepositories[destSpec.PathComponent] = true
+
+		drift := ImageDrift{Origin: img.Origin, Destination: img.Destination, Type: img.Type.String()}
+		exists, err := o.Mirror.Check(ctx, img.Destination, o.Opts, false)
+		if err != nil {
+			o.Log.Debug("unable to check existence of %s in destination: %v", img.Destination, err)
+		}
+		if err != nil || !exists {
+			report.Missing = append(report.Missing, drift)
+			continue
+		}
+		// an image mirrored by digest can only be found with its digest.
+		// In mirrorToMirror, the source of a catalog is the catalog before it is filtered (rebuilt).
+		if destSpec.IsImageByDigest() || (img.Type == v2alpha1.TypeOperatorCatalog && o.Opts.IsMirrorToMirror()) {
+			report.Summary.Verified++
+			continue
+		}
+
+		expected, err := o.digest(ctx, srcCtx, img.Source)
+		if err != nil {
+			o.Log.Warn("unable to get the digest of %s, the digest of %s is not verified: %v", img.Source, img.Destination, err)
+			report.Unverified = append(report.Unverified, drift)
+			continue
+		}
+		actual, err := o.digest(ctx, destCtx, img.Destination)
+		if err != nil {
+			return report, fmt.Errorf(verifyErrMsg, fmt.Errorf("unable to get the digest of %s: %w", img.Destination, err))
+		}
+		if expected != actual {
+			drift.ExpectedDigest = expected
+			drift.ActualDigest = actual
+			report.Mismatched = append(report.Mismatched, drift)
+			continue
+		}
+		report.Summary.Verified++
+	}
+
+	extra, err := o.extraRepositories(ctx, expectedRepositories)
+	if err != nil {
+		// some registries don't implement the catalog API, the images are verified anyway
+		o.Log.Warn("unable to list the repositories of %s, extra repositories are not reported: %v", o.Opts.Destination, err)
+	}
+	report.ExtraRepositories = append(report.ExtraRepositories, extra...)
+
+	report.Summary.Missing = len(report.Missing)
+	report.Summary.Mismatched = len(report.Mismatched)
+	report.Summary.Unverified = len(report.Unverified)
+	report.Summary.ExtraRepositories = len(report.ExtraRepositories)
+	return report, nil
+}
+
+// digest returns the digest of the manifest of imgRef, from the reference when it has one
+func (o *Verifier) digest(ctx context.Context, sysCtx *types.SystemContext, imgRef string) (string, error) {
+	spec, err := image.ParseRef(imgRef)
+	if err != nil {
+		return "", err
+	}
+	if spec.IsImageByDigest() {
+		return spec.Algorithm + ":" + spec.Digest, nil
+	}
+	encoded, err := o.Manifest.GetDigest(ctx, sysCtx, imgRef)
+	if err != nil {
+		return "", err
+	}
+	return digest.NewDigestFromEncoded(digest.SHA256, encoded).String(), nil
+}
+
+// extraRepositories returns the repositories of the destination registry, under
+// the path of the destination, which are not in expected
+func (o *Verifier) extraRepositories(ctx context.Context, expected map[string]bool) ([]string, error) {
+	registry, prefix, _ := strings.Cut(strings.TrimPrefix(o.Opts.Destination, dockerProtocol), "/")
+	prefix = strings.TrimSuffix(prefix, "/")
+	repositories, err := o.Repositories.ListRepositories(ctx, registry)
+	if err != nil {
+		return nil, err
+	}
+	extra := []string{}
+	for _, repository := range repositories {
+		if prefix != "" && repository != prefix && !strings.HasPrefix(repository, prefix+"/") {
+			continue
+		}
+		if !expected[repository] {
+			extra = append(extra, repository)
+		}
+	}
+	sort.Strings(extra)
+	return extra, nil
+}
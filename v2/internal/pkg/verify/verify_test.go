@@ -0,0 +1,281 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/containers/image/v5/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/oc-mirror/v2/internal/pkg/api/v2alpha1"
+	clog "github.com/openshift/oc-mirror/v2/internal/pkg/log"
+	"github.com/openshift/oc-mirror/v2/internal/pkg/mirror"
+)
+
+type mockMirror struct {
+	existing map[string]bool
+}
+
+type mockManifest struct {
+	digests map[string]string
+}
+
+type mockRepositoryLister struct {
+	repositories []string
+	err          error
+}
+
+func (o mockMirror) Run(ctx context.Context, src, dest string, mode mirror.Mode, opts *mirror.CopyOptions) error {
+	return nil
+}
+
+func (o mockMirror) Check(ctx context.Context, image string, opts *mirror.CopyOptions, asCopySrc bool) (bool, error) {
+	return o.existing[image], nil
+}
+
+func (o mockManifest) GetImageIndex(dir string) (*v2alpha1.OCISchema, error) {
+	return nil, nil
+}
+
+func (o mockManifest) GetImageManifest(file string) (*v2alpha1.OCISchema, error) {
+	return nil, nil
+}
+
+func (o mockManifest) GetOperatorConfig(file string) (*v2alpha1.OperatorConfigSchema, error) {
+	return nil, nil
+}
+
+func (o mockManifest) ExtractLayersOCI(filePath, toPath, label string, oci *v2alpha1.OCISchema) error {
+	return nil
+}
+
+func (o mockManifest) GetReleaseSchema(filePath string) ([]v2alpha1.RelatedImage, error) {
+	return nil, nil
+}
+
+func (o mockManifest) ConvertIndexToSingleManifest(dir string, oci *v2alpha1.OCISchema) error {
+	return nil
+}
+
+func (o mockManifest) GetDigest(ctx context.Context, sourceCtx *types.SystemContext, imgRef string) (string, error) {
+	d, ok := o.digests[imgRef]
+	if !ok {
+		return "", fmt.Errorf("manifest unknown")
+	}
+	return d, nil
+}
+
+func (o mockManifest) ListTags(ctx context.Context, sourceCtx *types.SystemContext, imgRef string) ([]string, error) {
+	return nil, nil
+}
+
+func (o mockManifest) GetImageCreationDate(ctx context.Context, sourceCtx *types.SystemContext, imgRef string) (time.Time, error) {
+	return time.Time{}, nil
+}
+
+func (o mockRepositoryLister) ListRepositories(ctx context.Context, registry string) ([]string, error) {
+	return o.repositories, o.err
+}
+
+func TestVerify(t *testing.T) {
+	global := &mirror.GlobalOptions{SecurePolicy: false}
+	_, sharedOpts := mirror.SharedImageFlags()
+	_, deprecatedTLSVerifyOpt := mirror.DeprecatedTLSVerifyFlags()
+	_, srcOpts := mirror.ImageSrcFlags(global, sharedOpts, deprecatedTLSVerifyOpt, "src-", "screds")
+	_, destOpts := mirror.ImageDestFlags(global, sharedOpts, deprecatedTLSVerifyOpt, "dest-", "dcreds")
+	opts := &mirror.CopyOptions{Global: global, SrcImage: srcOpts, DestImage: destOpts, Destination: "docker://mirror.acme.com/team1", Mode: mirror.MirrorToMirror}
+
+	images := []v2alpha1.CopyImageSchema{
+		{
+			Source:      "docker://registry.redhat.io/ubi8/ubi:latest",
+			Destination: "docker://mirror.acme.com/team1/ubi8/ubi:latest",
+			Origin:      "docker://registry.redhat.io/ubi8/ubi:latest",
+			Type:        v2alpha1.TypeGeneric,
+		},
+		{
+			Source:      "docker://registry.redhat.io/ubi9/ubi:latest",
+			Destination: "docker://mirror.acme.com/team1/ubi9/ubi:latest",
+			Origin:      "docker://registry.redhat.io/ubi9/ubi:latest",
+			Type:        v2alpha1.TypeGeneric,
+		},
+		{
+			Source:      "docker://quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:7c8e8f4a8d3ad5d8c36d6f7fb3d4a0e7d4e05a0f9a1b1bd2e5e7a9c09e7f41f0",
+			Destination: "docker://mirror.acme.com/team1/openshift-release-dev/ocp-v4.0-art-dev:4.16.10-x86_64-etcd",
+			Origin:      "docker://quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:7c8e8f4a8d3ad5d8c36d6f7fb3d4a0e7d4e05a0f9a1b1bd2e5e7a9c09e7f41f0",
+			Type:        v2alpha1.TypeOCPReleaseContent,
+		},
+		{
+			Source:      "docker://registry.redhat.io/rhel9/postgresql-15@sha256:98f3fd7b8e8f2e1b24c4cf7d9a8d9f5d0e4b8e8c9b71a3e7cb3c6c0d2f1a4e5b",
+			Destination: "docker://mirror.acme.com/team1/rhel9/postgresql-15@sha256:98f3fd7b8e8f2e1b24c4cf7d9a8d9f5d0e4b8e8c9b71a3e7cb3c6c0d2f1a4e5b",
+			Origin:      "docker://registry.redhat.io/rhel9/postgresql-15@sha256:98f3fd7b8e8f2e1b24c4cf7d9a8d9f5d0e4b8e8c9b71a3e7cb3c6c0d2f1a4e5b",
+			Type:        v2alpha1.TypeOperatorRelatedImage,
+		},
+	}
+
+	ex := &Verifier{
+		Log:  clog.New("trace"),
+		Opts: opts,
+		Mirror: mockMirror{existing: map[string]bool{
+			"docker://mirror.acme.com/team1/ubi8/ubi:latest":                                                                             true,
+			"docker://mirror.acme.com/team1/openshift-release-dev/ocp-v4.0-art-dev:4.16.10-x86_64-etcd":                                  true,
+			"docker://mirror.acme.com/team1/rhel9/postgresql-15@sha256:98f3fd7b8e8f2e1b24c4cf7d9a8d9f5d0e4b8e8c9b71a3e7cb3c6c0d2f1a4e5b": true,
+		}},
+		Manifest: mockManifest{digests: map[string]string{
+			"docker://registry.redhat.io/ubi8/ubi:latest":                                               "1dddb0988d16a1b7d3c4e5f60718293a4b5c6d7e8f90112233445566778899aa",
+			"docker://mirror.acme.com/team1/ubi8/ubi:latest":                                            "1dddb0988d16a1b7d3c4e5f60718293a4b5c6d7e8f90112233445566778899aa",
+			"docker://mirror.acme.com/team1/openshift-release-dev/ocp-v4.0-art-dev:4.16.10-x86_64-etcd": "e3dad360d035a1b7d3c4e5f60718293a4b5c6d7e8f90112233445566778899aa",
+		}},
+		Repositories: mockRepositoryLister{repositories: []string{
+			"team1/ubi8/ubi",
+			"team1/openshift-release-dev/ocp-v4.0-art-dev",
+			"team1/rhel9/postgresql-15",
+			"team1/manual/push",
+			"team2/ubi8/ubi",
+		}},
+	}
+
+	t.Run("Testing Verify : should report the drift", func(t *testing.T) {
+		report, err := ex.Verify(context.Background(), images)
+		assert.NoError(t, err)
+		assert.True(t, report.HasDrift())
+		assert.Equal(t, ReportSummary{Expected: 4, Verified: 2, Missing: 1, Mismatched: 1, ExtraRepositories: 1}, report.Summary)
+		assert.Equal(t, []ImageDrift{{Origin: "docker://registry.redhat.io/ubi9/ubi:latest", Destination: "docker://mirror.acme.com/team1/ubi9/ubi:latest", Type: "generic"}}, report.Missing)
+		assert.Equal(t, "docker://mirror.acme.com/team1/openshift-release-dev/ocp-v4.0-art-dev:4.16.10-x86_64-etcd", report.Mismatched[0].Destination)
+		assert.Equal(t, "sha256:7c8e8f4a8d3ad5d8c36d6f7fb3d4a0e7d4e05a0f9a1b1bd2e5e7a9c09e7f41f0", report.Mismatched[0].ExpectedDigest)
+		assert.Equal(t, "sha256:e3dad360d035a1b7d3c4e5f60718293a4b5c6d7e8f90112233445566778899aa", report.Mismatched[0].ActualDigest)
+		assert.Equal(t, []string{"team1/manual/push"}, report.ExtraRepositories)
+
+		missing, skipped := report.MissingConfig(v2alpha1.ImageSetConfiguration{})
+		assert.Equal(t, v2alpha1.ImageSetConfigurationKind, missing.Kind)
+		assert.Equal(t, []v2alpha1.Image{{Name: "registry.redhat.io/ubi9/ubi:latest"}}, missing.Mirror.AdditionalImages)
+		assert.Empty(t, skipped)
+	})
+
+	t.Run("Testing Verify : should not count an image as verified when the source digest is unknown", func(t *testing.T) {
+		unknownSource := *ex
+		unknownSource.Manifest = mockManifest{digests: map[string]string{
+			"docker://mirror.acme.com/team1/ubi8/ubi:latest":                                            "1dddb0988d16a1b7d3c4e5f60718293a4b5c6d7e8f90112233445566778899aa",
+			"docker://mirror.acme.com/team1/openshift-release-dev/ocp-v4.0-art-dev:4.16.10-x86_64-etcd": "e3dad360d035a1b7d3c4e5f60718293a4b5c6d7e8f90112233445566778899aa",
+		}}
+		report, err := unknownSource.Verify(context.Background(), images)
+		assert.NoError(t, err)
+		assert.Equal(t, ReportSummary{Expected: 4, Verified: 1, Missing: 1, Mismatched: 1, Unverified: 1, ExtraRepositories: 1}, report.Summary)
+		assert.Equal(t, []ImageDrift{{Origin: "docker://registry.redhat.io/ubi8/ubi:latest", Destination: "docker://mirror.acme.com/team1/ubi8/ubi:latest", Type: "generic"}}, report.Unverified)
+		assert.False(t, report.IsVerified())
+	})
+
+	t.Run("Testing Verify : should verify the images when the catalog API is not available", func(t *testing.T) {
+		noCatalog := *ex
+		noCatalog.Repositories = mockRepositoryLister{err: fmt.Errorf("UNSUPPORTED")}
+		report, err := noCatalog.Verify(context.Background(), images)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, report.Summary.Verified)
+		assert.Empty(t, report.ExtraRepositories)
+	})
+}
+
+func TestMissingConfig(t *testing.T) {
+	isc := v2alpha1.ImageSetConfiguration{
+		ImageSetConfigurationSpec: v2alpha1.ImageSetConfigurationSpec{
+			Mirror: v2alpha1.Mirror{
+				Operators: []v2alpha1.Operator{
+					{
+						Catalog:       "registry.redhat.io/redhat/redhat-operator-index:v4.16",
+						IncludeConfig: v2alpha1.IncludeConfig{Packages: []v2alpha1.IncludePackage{{Name: "database"}}},
+					},
+					{Catalog: "registry.redhat.io/redhat/certified-operator-index:v4.16"},
+				},
+			},
+		},
+	}
+
+	type testCase struct {
+		caseName        string
+		missing         []ImageDrift
+		expectedMirror  v2alpha1.Mirror
+		expectedSkipped []ImageDrift
+	}
+	testCases := []testCase{
+		{
+			caseName: "Testing MissingConfig : a catalog should be mirrored with its operator filter",
+			missing: []ImageDrift{
+				{Origin: "docker://registry.redhat.io/redhat/redhat-operator-index:v4.16", Type: v2alpha1.TypeOperatorCatalog.String()},
+				{Origin: "docker://registry.redhat.io/redhat/redhat-operator-index:v4.16", Type: v2alpha1.TypeOperatorCatalog.String()},
+			},
+			expectedMirror: v2alpha1.Mirror{Operators: []v2alpha1.Operator{isc.Mirror.Operators[0]}},
+		},
+		{
+			caseName: "Testing MissingConfig : a catalog not in the imageset configuration should be skipped",
+			missing: []ImageDrift{
+				{Origin: "docker://registry.redhat.io/redhat/community-operator-index:v4.16", Type: v2alpha1.TypeOperatorCatalog.String()},
+			},
+			expectedSkipped: []ImageDrift{
+				{Origin: "docker://registry.redhat.io/redhat/community-operator-index:v4.16", Type: v2alpha1.TypeOperatorCatalog.String()},
+			},
+		},
+		{
+			caseName: "Testing MissingConfig : the images of an operator should be additional images",
+			missing: []ImageDrift{
+				{Origin: "docker://registry.redhat.io/rhel9/postgresql-15@sha256:98f3fd7b8e8f2e1b24c4cf7d9a8d9f5d0e4b8e8c9b71a3e7cb3c6c0d2f1a4e5b", Type: v2alpha1.TypeOperatorRelatedImage.String()},
+				{Origin: "docker://registry.redhat.io/database/database-bundle@sha256:7c8e8f4a8d3ad5d8c36d6f7fb3d4a0e7d4e05a0f9a1b1bd2e5e7a9c09e7f41f0", Type: v2alpha1.TypeOperatorBundle.String()},
+			},
+			expectedMirror: v2alpha1.Mirror{AdditionalImages: []v2alpha1.Image{
+				{Name: "registry.redhat.io/rhel9/postgresql-15@sha256:98f3fd7b8e8f2e1b24c4cf7d9a8d9f5d0e4b8e8c9b71a3e7cb3c6c0d2f1a4e5b"},
+				{Name: "registry.redhat.io/database/database-bundle@sha256:7c8e8f4a8d3ad5d8c36d6f7fb3d4a0e7d4e05a0f9a1b1bd2e5e7a9c09e7f41f0"},
+			}},
+		},
+		{
+			caseName: "Testing MissingConfig : a release payload should be the release of the platform",
+			missing: []ImageDrift{
+				{Origin: "docker://quay.io/openshift-release-dev/ocp-release:4.16.10-x86_64", Type: v2alpha1.TypeOCPRelease.String()},
+				{Origin: "docker://quay.io/openshift-release-dev/ocp-release:4.16.11-x86_64", Type: v2alpha1.TypeOCPRelease.String()},
+			},
+			expectedMirror: v2alpha1.Mirror{Platform: v2alpha1.Platform{Release: "docker://quay.io/openshift-release-dev/ocp-release:4.16.10-x86_64"}},
+			expectedSkipped: []ImageDrift{
+				{Origin: "docker://quay.io/openshift-release-dev/ocp-release:4.16.11-x86_64", Type: v2alpha1.TypeOCPRelease.String()},
+			},
+		},
+		{
+			caseName: "Testing MissingConfig : the images of a release payload and the graph image should be skipped",
+			missing: []ImageDrift{
+				{Origin: "docker://quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:7c8e8f4a8d3ad5d8c36d6f7fb3d4a0e7d4e05a0f9a1b1bd2e5e7a9c09e7f41f0", Type: v2alpha1.TypeOCPReleaseContent.String()},
+				{Origin: "docker://localhost:55000/openshift/graph-image:latest", Type: v2alpha1.TypeCincinnatiGraph.String()},
+			},
+			expectedSkipped: []ImageDrift{
+				{Origin: "docker://quay.io/openshift-release-dev/ocp-v4.0-art-dev@sha256:7c8e8f4a8d3ad5d8c36d6f7fb3d4a0e7d4e05a0f9a1b1bd2e5e7a9c09e7f41f0", Type: v2alpha1.TypeOCPReleaseContent.String()},
+				{Origin: "docker://localhost:55000/openshift/graph-image:latest", Type: v2alpha1.TypeCincinnatiGraph.String()},
+			},
+		},
+		{
+			caseName: "Testing MissingConfig : additional, helm and sample images should be additional images, except from an oci layout",
+			missing: []ImageDrift{
+				{Origin: "docker://registry.redhat.io/ubi9/ubi:latest", Type: v2alpha1.TypeGeneric.String()},
+				{Origin: "docker://quay.io/helm/nginx:1.25", Type: v2alpha1.TypeHelmImage.String()},
+				{Origin: "docker://registry.redhat.io/ubi8/ruby-30:latest", Type: v2alpha1.TypeSampleImage.String()},
+				{Origin: "oci:///home/user/images/ubi", Type: v2alpha1.TypeGeneric.String()},
+			},
+			expectedMirror: v2alpha1.Mirror{AdditionalImages: []v2alpha1.Image{
+				{Name: "registry.redhat.io/ubi9/ubi:latest"},
+				{Name: "quay.io/helm/nginx:1.25"},
+				{Name: "registry.redhat.io/ubi8/ruby-30:latest"},
+			}},
+			expectedSkipped: []ImageDrift{
+				{Origin: "oci:///home/user/images/ubi", Type: v2alpha1.TypeGeneric.String()},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.caseName, func(t *testing.T) {
+			report := Report{Missing: testCase.missing}
+			missing, skipped := report.MissingConfig(isc)
+			assert.Equal(t, v2alpha1.ImageSetConfigurationKind, missing.Kind)
+			assert.Equal(t, testCase.expectedMirror, missing.Mirror)
+			if testCase.expectedSkipped == nil {
+				testCase.expectedSkipped = []ImageDrift{}
+			}
+			assert.Equal(t, testCase.expectedSkipped, skipped)
+		})
+	}
+}